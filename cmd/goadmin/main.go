@@ -2,11 +2,11 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"os"
 
-	"goadmin/internal/core"
-	"goadmin/internal/modules/host"
+	"goadmin/internal/redact"
 	"goadmin/internal/transports/cli"
 	"goadmin/pkg/logger"
 )
@@ -18,18 +18,18 @@ var (
 )
 
 func main() {
-	lg := logger.New()
+	// Ошибки команд и задач планировщика могут содержать аргументы, поэтому лог проходит через redaction.
+	lg := slog.New(redact.NewHandler(logger.New().Handler(), redact.Default()))
+	slog.SetDefault(lg)
 
 	ctx := context.Background()
-	r := core.NewRegistry()
-	if err := r.Register(ctx, &host.Module{}); err != nil {
-		log.Fatalf("register host module: %v", err)
-	}
-
-	v := buildVersion()
-	root := cli.New(r, v)
-	if err := root.ExecuteContext(ctx); err != nil {
+	if err := cli.Execute(ctx, buildVersion(), os.Args[1:]); err != nil {
 		lg.Error("command failed", "err", err)
+		// Команды модулей возвращают код выхода по error_code ответа.
+		var exitErr *cli.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,338 @@
+// Package alert вычисляет пороговые правила над последними метриками и ведет
+// состояние алертов pending -> firing -> resolved.
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// Значения по умолчанию для правил.
+const (
+	DefaultMetric   = "host"
+	DefaultSeverity = "warning"
+	// TopicAlerts - тема уведомлений об алертах.
+	TopicAlerts = "alerts"
+)
+
+var (
+	errDuplicateRule = errors.New("duplicate alert rule")
+	errStaleMetric   = errors.New("metric is stale")
+)
+
+// Rule - правило алерта над последней записью метрики Metric.
+type Rule struct {
+	Name string
+	// Metric - ключ в metrics (как у коллектора), по умолчанию "host".
+	Metric string
+	// Expr - условие, например "mem_used_pct > 90 for 5m".
+	Expr     string
+	Severity string
+	Summary  string
+	Labels   map[string]string
+}
+
+// Options задает зависимости движка.
+type Options struct {
+	// Notifier получает уведомления о срабатывании и разрешении; nil - без уведомлений.
+	Notifier core.Notifier
+	// Audit фиксирует переходы firing/resolved; nil - без аудита.
+	Audit storage.AuditWriter
+	// StaleAfter - метрика старше считается отсутствующей; 0 - без проверки.
+	StaleAfter time.Duration
+	// Now подменяет часы в тестах.
+	Now func() time.Time
+}
+
+type rule struct {
+	spec  Rule
+	cond  *Condition
+	hold  time.Duration
+	state storage.AlertState
+}
+
+// Engine хранит правила и их состояние.
+type Engine struct {
+	store  storage.Store
+	states storage.AlertStore
+	opts   Options
+
+	mu    sync.Mutex
+	rules []*rule
+}
+
+// NewEngine разбирает правила; состояние сохраняется, если st реализует storage.AlertStore.
+func NewEngine(rules []Rule, st storage.Store, opts Options) (*Engine, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	e := &Engine{store: st, opts: opts}
+	if as, ok := st.(storage.AlertStore); ok {
+		e.states = as
+	}
+	seen := make(map[string]bool, len(rules))
+	for _, spec := range rules {
+		if spec.Name == "" {
+			return nil, fmt.Errorf("alert rule %q: name is required", spec.Expr)
+		}
+		if seen[spec.Name] {
+			return nil, fmt.Errorf("%w: %q", errDuplicateRule, spec.Name)
+		}
+		seen[spec.Name] = true
+		if spec.Metric == "" {
+			spec.Metric = DefaultMetric
+		}
+		if spec.Severity == "" {
+			spec.Severity = DefaultSeverity
+		}
+		cond, hold, err := ParseExpr(spec.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %q: %w", spec.Name, err)
+		}
+		e.rules = append(e.rules, &rule{spec: spec, cond: cond, hold: hold, state: storage.AlertState{
+			Rule:     spec.Name,
+			State:    storage.AlertInactive,
+			Severity: spec.Severity,
+			Expr:     spec.Expr,
+			Summary:  spec.Summary,
+			Labels:   spec.Labels,
+		}})
+	}
+	return e, nil
+}
+
+// Restore загружает сохраненное состояние, чтобы pending продолжал отсчет "for",
+// а firing не уведомлял повторно. Состояние удаленных правил игнорируется.
+func (e *Engine) Restore(ctx context.Context) error {
+	if e.states == nil {
+		return nil
+	}
+	saved, err := e.states.AlertStates(ctx)
+	if err != nil {
+		return fmt.Errorf("load alert state: %w", err)
+	}
+	byRule := make(map[string]storage.AlertState, len(saved))
+	for _, st := range saved {
+		byRule[st.Rule] = st
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range e.rules {
+		st, ok := byRule[r.spec.Name]
+		if !ok {
+			continue
+		}
+		r.state.State = st.State
+		r.state.Value = st.Value
+		r.state.ActiveAt = st.ActiveAt
+		r.state.FiredAt = st.FiredAt
+		r.state.ResolvedAt = st.ResolvedAt
+		r.state.EvaluatedAt = st.EvaluatedAt
+		r.state.LastError = st.LastError
+	}
+	return nil
+}
+
+// Evaluate вычисляет все правила один раз; возвращает объединенные ошибки вычисления.
+func (e *Engine) Evaluate(ctx context.Context) error {
+	now := e.opts.Now()
+	var (
+		errs    []error
+		changed []storage.AlertState
+		toSave  []storage.AlertState
+	)
+	e.mu.Lock()
+	rules := append([]*rule(nil), e.rules...)
+	e.mu.Unlock()
+
+	for _, r := range rules {
+		ok, value, err := e.check(ctx, r, now)
+		e.mu.Lock()
+		fired, resolved := r.advance(now, ok, value, err)
+		st := r.state
+		e.mu.Unlock()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("alert %s: %w", r.spec.Name, err))
+		}
+		if fired || resolved {
+			changed = append(changed, st)
+		}
+		toSave = append(toSave, st)
+	}
+
+	if e.states != nil {
+		for _, st := range toSave {
+			if err := e.states.SaveAlertState(context.WithoutCancel(ctx), st); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, st := range changed {
+		e.announce(ctx, st)
+	}
+	return errors.Join(errs...)
+}
+
+// check вычисляет условие правила по последней записи метрики.
+func (e *Engine) check(ctx context.Context, r *rule, now time.Time) (bool, float64, error) {
+	rec, err := e.store.LatestMetric(ctx, r.spec.Metric)
+	if err != nil {
+		return false, 0, err
+	}
+	if e.opts.StaleAfter > 0 && now.Sub(rec.TS) > e.opts.StaleAfter {
+		return false, 0, fmt.Errorf("%w: %s last sample at %s", errStaleMetric, r.spec.Metric, rec.TS.Format(time.RFC3339))
+	}
+	var payload interface{}
+	if err := json.Unmarshal(rec.Payload, &payload); err != nil {
+		return false, 0, fmt.Errorf("decode %s metric: %w", r.spec.Metric, err)
+	}
+	vars := make(map[string]float64)
+	flatten("", payload, vars)
+	return r.cond.Eval(vars)
+}
+
+// advance применяет результат вычисления; вызывается под e.mu.
+// Ошибка вычисления не меняет состояние: отсутствие данных не разрешает алерт.
+func (r *rule) advance(now time.Time, ok bool, value float64, err error) (fired, resolved bool) {
+	st := &r.state
+	st.EvaluatedAt = now
+	if err != nil {
+		st.LastError = err.Error()
+		return false, false
+	}
+	st.LastError = ""
+	st.Value = value
+	switch {
+	case ok && (st.State == storage.AlertInactive || st.State == storage.AlertResolved || st.State == ""):
+		st.State = storage.AlertPending
+		st.ActiveAt = &now
+		st.FiredAt = nil
+		st.ResolvedAt = nil
+		if r.hold == 0 {
+			st.State = storage.AlertFiring
+			st.FiredAt = &now
+			return true, false
+		}
+	case ok && st.State == storage.AlertPending:
+		if st.ActiveAt == nil {
+			st.ActiveAt = &now
+		}
+		if now.Sub(*st.ActiveAt) >= r.hold {
+			st.State = storage.AlertFiring
+			st.FiredAt = &now
+			return true, false
+		}
+	case !ok && st.State == storage.AlertPending:
+		st.State = storage.AlertInactive
+		st.ActiveAt = nil
+	case !ok && st.State == storage.AlertFiring:
+		st.State = storage.AlertResolved
+		st.ResolvedAt = &now
+		return false, true
+	}
+	return false, false
+}
+
+// announce отправляет уведомление о переходе и аудирует его.
+func (e *Engine) announce(ctx context.Context, st storage.AlertState) {
+	var notifyErr error
+	if e.opts.Notifier != nil {
+		notifyErr = e.opts.Notifier.Notify(ctx, Notification(st))
+		if notifyErr != nil {
+			slog.Warn("alert notification failed", "rule", st.Rule, "state", st.State, "err", notifyErr)
+		}
+	}
+	if e.opts.Audit == nil {
+		return
+	}
+	status := "ok"
+	payload := map[string]interface{}{
+		"rule":     st.Rule,
+		"severity": st.Severity,
+		"expr":     st.Expr,
+		"value":    st.Value,
+	}
+	if notifyErr != nil {
+		status = "error"
+		payload["notify_error"] = notifyErr.Error()
+	}
+	raw, _ := json.Marshal(payload)
+	_ = e.opts.Audit.Write(context.WithoutCancel(ctx), storage.AuditEvent{
+		Subject: st.Rule,
+		Action:  "alert:" + st.State,
+		Source:  "alerts",
+		Status:  status,
+		Payload: raw,
+	})
+}
+
+// Notification формирует уведомление о состоянии алерта.
+func Notification(st storage.AlertState) core.Notification {
+	labels := map[string]string{"alertname": st.Rule, "state": st.State, "severity": st.Severity}
+	for k, v := range st.Labels {
+		if _, reserved := labels[k]; !reserved {
+			labels[k] = v
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s (value %.2f)", st.Rule, st.Expr, st.Value)
+	if st.Summary != "" {
+		b.WriteString("\n")
+		b.WriteString(st.Summary)
+	}
+	n := core.Notification{
+		Topic:    TopicAlerts,
+		Severity: st.Severity,
+		Title:    fmt.Sprintf("[%s] %s", strings.ToUpper(st.State), st.Rule),
+		Text:     b.String(),
+		Labels:   labels,
+	}
+	if st.FiredAt != nil {
+		n.StartsAt = *st.FiredAt
+	}
+	at := n.StartsAt
+	if st.State == storage.AlertResolved && st.ResolvedAt != nil {
+		n.EndsAt = *st.ResolvedAt
+		at = n.EndsAt
+	}
+	n.Key = fmt.Sprintf("alert:%s:%s:%d", st.Rule, st.State, at.UnixNano())
+	return n
+}
+
+// Alerts возвращает pending и firing алерты (all - все правила), сначала firing,
+// затем по имени.
+func (e *Engine) Alerts(all bool) []storage.AlertState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	out := make([]storage.AlertState, 0, len(e.rules))
+	for _, r := range e.rules {
+		if all || r.state.State == storage.AlertPending || r.state.State == storage.AlertFiring {
+			out = append(out, r.state)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		fi, fj := out[i].State == storage.AlertFiring, out[j].State == storage.AlertFiring
+		if fi != fj {
+			return fi
+		}
+		return out[i].Rule < out[j].Rule
+	})
+	return out
+}
+
+// Len возвращает число правил.
+func (e *Engine) Len() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.rules)
+}
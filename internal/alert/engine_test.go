@@ -0,0 +1,143 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+)
+
+type recorder struct {
+	sent []core.Notification
+}
+
+func (r *recorder) Notify(ctx context.Context, n core.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+func saveHost(t *testing.T, st storage.Store, ts time.Time, memPct float64) {
+	t.Helper()
+	payload, _ := json.Marshal(map[string]interface{}{"mem_used_pct": memPct, "load5": 1, "cores": 4})
+	if err := st.SaveMetric(context.Background(), storage.MetricRecord{Module: "host", Payload: payload, TS: ts}); err != nil {
+		t.Fatalf("save metric: %v", err)
+	}
+}
+
+func TestEngineLifecycleAndRestore(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	defer st.Close()
+	clk := &clock{now: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)}
+	notes := &recorder{}
+	rules := []Rule{
+		{Name: "high_memory", Expr: "mem_used_pct > 90 for 5m", Severity: "critical", Labels: map[string]string{"team": "ops"}},
+		{Name: "high_load", Expr: "load5 > cores*2"},
+	}
+	newEngine := func() *Engine {
+		e, err := NewEngine(rules, st, Options{Notifier: notes, Audit: st, StaleAfter: 10 * time.Minute, Now: clk.Now})
+		if err != nil {
+			t.Fatalf("new engine: %v", err)
+		}
+		if err := e.Restore(ctx); err != nil {
+			t.Fatalf("restore: %v", err)
+		}
+		return e
+	}
+	step := func(e *Engine, d time.Duration, memPct float64) {
+		t.Helper()
+		clk.now = clk.now.Add(d)
+		saveHost(t, st, clk.now, memPct)
+		if err := e.Evaluate(ctx); err != nil {
+			t.Fatalf("evaluate: %v", err)
+		}
+	}
+	state := func(e *Engine) string {
+		for _, a := range e.Alerts(true) {
+			if a.Rule == "high_memory" {
+				return a.State
+			}
+		}
+		return ""
+	}
+
+	e := newEngine()
+	step(e, 0, 95)
+	if state(e) != storage.AlertPending || len(notes.sent) != 0 {
+		t.Fatalf("want pending without notification, got %s %d", state(e), len(notes.sent))
+	}
+	step(e, 2*time.Minute, 50)
+	if state(e) != storage.AlertInactive {
+		t.Fatalf("dip below threshold must reset pending, got %s", state(e))
+	}
+	step(e, time.Minute, 95)
+	step(e, 3*time.Minute, 96)
+	if state(e) != storage.AlertPending {
+		t.Fatalf("want pending before 5m, got %s", state(e))
+	}
+
+	// Перезапуск: pending продолжает отсчет с сохраненного active_at.
+	e = newEngine()
+	step(e, 2*time.Minute, 97)
+	if state(e) != storage.AlertFiring || len(notes.sent) != 1 {
+		t.Fatalf("want firing after restart, got %s, %d notifications", state(e), len(notes.sent))
+	}
+	n := notes.sent[0]
+	if n.Topic != TopicAlerts || n.Severity != "critical" || n.Title != "[FIRING] high_memory" || n.Labels["team"] != "ops" || n.Labels["alertname"] != "high_memory" {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+	if active := e.Alerts(false); len(active) != 1 || active[0].Rule != "high_memory" || active[0].Value != 97 {
+		t.Fatalf("active alerts: %+v", active)
+	}
+
+	// Firing после перезапуска не уведомляет повторно.
+	e = newEngine()
+	step(e, time.Minute, 98)
+	if state(e) != storage.AlertFiring || len(notes.sent) != 1 {
+		t.Fatalf("firing must persist without renotify, got %s, %d", state(e), len(notes.sent))
+	}
+
+	// Устаревшая метрика не разрешает алерт.
+	clk.now = clk.now.Add(time.Hour)
+	if err := e.Evaluate(ctx); err == nil {
+		t.Fatal("expected stale metric error")
+	}
+	if state(e) != storage.AlertFiring {
+		t.Fatalf("stale data must not resolve, got %s", state(e))
+	}
+
+	step(e, time.Minute, 40)
+	if state(e) != storage.AlertResolved || len(notes.sent) != 2 || notes.sent[1].Title != "[RESOLVED] high_memory" {
+		t.Fatalf("want resolved notification, got %s %+v", state(e), notes.sent)
+	}
+	if active := e.Alerts(false); len(active) != 0 {
+		t.Fatalf("resolved alert listed as active: %+v", active)
+	}
+
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{Source: "alerts", Limit: 10})
+	if err != nil || len(events) != 2 || events[0].Action != "alert:resolved" || events[1].Action != "alert:firing" {
+		t.Fatalf("audit: %v %+v", err, events)
+	}
+}
+
+func TestEngineRejectsBadRules(t *testing.T) {
+	st := memory.New()
+	defer st.Close()
+	for _, rules := range [][]Rule{
+		{{Name: "a", Expr: "x >"}},
+		{{Expr: "x > 1"}},
+		{{Name: "a", Expr: "x > 1"}, {Name: "a", Expr: "y > 1"}},
+	} {
+		if _, err := NewEngine(rules, st, Options{}); err == nil {
+			t.Fatalf("expected error for %+v", rules)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	errInvalidExpr  = errors.New("invalid alert expression")
+	errMissingField = errors.New("metric field is missing")
+)
+
+// Condition - разобранное условие правила: сравнение двух арифметических выражений
+// над числовыми полями метрики.
+type Condition struct {
+	expr  string
+	op    string
+	left  node
+	right node
+}
+
+// String возвращает исходный текст условия без "for".
+func (c *Condition) String() string { return c.expr }
+
+// Eval вычисляет условие; value - значение левой части (попадает в уведомление).
+func (c *Condition) Eval(vars map[string]float64) (ok bool, value float64, err error) {
+	l, err := c.left(vars)
+	if err != nil {
+		return false, 0, err
+	}
+	r, err := c.right(vars)
+	if err != nil {
+		return false, 0, err
+	}
+	switch c.op {
+	case ">":
+		ok = l > r
+	case ">=":
+		ok = l >= r
+	case "<":
+		ok = l < r
+	case "<=":
+		ok = l <= r
+	case "==":
+		ok = l == r
+	case "!=":
+		ok = l != r
+	}
+	return ok, l, nil
+}
+
+// ParseExpr разбирает "mem_used_pct > 90 for 5m" или "load5 > cores*2".
+// Поддерживаются числа, поля метрики (вложенные через точку), + - * /, скобки
+// и сравнения > >= < <= == !=. Необязательный суффикс "for <duration>" задает,
+// сколько условие должно держаться до срабатывания.
+func ParseExpr(expr string) (*Condition, time.Duration, error) {
+	text := strings.TrimSpace(expr)
+	var hold time.Duration
+	if i := strings.LastIndex(text, " for "); i >= 0 {
+		d, err := time.ParseDuration(strings.TrimSpace(text[i+len(" for "):]))
+		if err != nil || d < 0 {
+			return nil, 0, fmt.Errorf("%w: %q: bad for duration", errInvalidExpr, expr)
+		}
+		hold = d
+		text = strings.TrimSpace(text[:i])
+	}
+	toks, err := tokenize(text)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %q: %v", errInvalidExpr, expr, err)
+	}
+	p := &parser{toks: toks}
+	left, err := p.sum()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %q: %v", errInvalidExpr, expr, err)
+	}
+	op := p.next()
+	switch op {
+	case ">", ">=", "<", "<=", "==", "!=":
+	default:
+		return nil, 0, fmt.Errorf("%w: %q: expected comparison, got %q", errInvalidExpr, expr, op)
+	}
+	right, err := p.sum()
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %q: %v", errInvalidExpr, expr, err)
+	}
+	if p.pos != len(p.toks) {
+		return nil, 0, fmt.Errorf("%w: %q: unexpected %q", errInvalidExpr, expr, p.toks[p.pos])
+	}
+	return &Condition{expr: text, op: op, left: left, right: right}, hold, nil
+}
+
+type node func(vars map[string]float64) (float64, error)
+
+func tokenize(s string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("<>=!", c):
+			if i+1 < len(s) && s[i+1] == '=' {
+				toks = append(toks, s[i:i+2])
+				i += 2
+				continue
+			}
+			if c == '=' || c == '!' {
+				return nil, fmt.Errorf("bad operator at %d", i)
+			}
+			toks = append(toks, string(c))
+			i++
+		case strings.ContainsRune("+-*/()", c):
+			toks = append(toks, string(c))
+			i++
+		case unicode.IsDigit(c) || c == '.' || isIdentStart(c):
+			j := i + 1
+			for j < len(s) && (isIdentPart(rune(s[j])) || s[j] == '.') {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+func isIdentStart(c rune) bool { return c == '_' || unicode.IsLetter(c) }
+func isIdentPart(c rune) bool  { return isIdentStart(c) || unicode.IsDigit(c) }
+
+type parser struct {
+	toks []string
+	pos  int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	if t != "" {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) sum() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "+" || p.peek() == "-" {
+		op := p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "*" || p.peek() == "/" {
+		op := p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = binary(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (node, error) {
+	if p.peek() == "-" {
+		p.next()
+		inner, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(vars map[string]float64) (float64, error) {
+			v, err := inner(vars)
+			return -v, err
+		}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, errors.New("unexpected end of expression")
+	case tok == "(":
+		inner, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, errors.New("missing )")
+		}
+		return inner, nil
+	case isIdentStart(rune(tok[0])):
+		name := tok
+		return func(vars map[string]float64) (float64, error) {
+			v, ok := vars[name]
+			if !ok {
+				return 0, fmt.Errorf("%w: %s", errMissingField, name)
+			}
+			return v, nil
+		}, nil
+	default:
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", tok)
+		}
+		return func(map[string]float64) (float64, error) { return v, nil }, nil
+	}
+}
+
+func binary(op string, left, right node) node {
+	return func(vars map[string]float64) (float64, error) {
+		l, err := left(vars)
+		if err != nil {
+			return 0, err
+		}
+		r, err := right(vars)
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		default:
+			if r == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return l / r, nil
+		}
+	}
+}
+
+// flatten превращает JSON-объект метрики в числовые переменные; вложенные
+// ключи соединяются точкой, bool становится 1/0, нечисловые значения пропускаются.
+func flatten(prefix string, v interface{}, out map[string]float64) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(key, child, out)
+		}
+	case float64:
+		out[prefix] = val
+	case bool:
+		if val {
+			out[prefix] = 1
+		} else {
+			out[prefix] = 0
+		}
+	}
+}
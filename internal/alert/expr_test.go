@@ -0,0 +1,50 @@
+package alert
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseExpr(t *testing.T) {
+	vars := map[string]float64{"mem_used_pct": 93, "load5": 9, "cores": 4, "disk.root.used_pct": 50}
+	cases := []struct {
+		expr string
+		want bool
+		hold time.Duration
+	}{
+		{"mem_used_pct > 90 for 5m", true, 5 * time.Minute},
+		{"mem_used_pct > 95", false, 0},
+		{"load5 > cores*2", true, 0},
+		{"load5 >= (cores + 1) * 2 for 30s", false, 30 * time.Second},
+		{"disk.root.used_pct / 2 == 25", true, 0},
+		{"-load5 < -8.5", true, 0},
+		{"mem_used_pct != 93", false, 0},
+	}
+	for _, tc := range cases {
+		cond, hold, err := ParseExpr(tc.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		got, _, err := cond.Eval(vars)
+		if err != nil || got != tc.want || hold != tc.hold {
+			t.Fatalf("%s: got %v hold %s err %v, want %v hold %s", tc.expr, got, hold, err, tc.want, tc.hold)
+		}
+	}
+
+	cond, _, _ := ParseExpr("load5 > cores*2")
+	if _, value, _ := cond.Eval(vars); value != 9 {
+		t.Fatalf("value = %v, want left side 9", value)
+	}
+	if _, _, err := cond.Eval(map[string]float64{"load5": 1}); !errors.Is(err, errMissingField) {
+		t.Fatalf("missing field: %v", err)
+	}
+}
+
+func TestParseExprInvalid(t *testing.T) {
+	for _, expr := range []string{"", "mem_used_pct", "mem_used_pct > ", "a > 1 for soon", "a = 1", "a > (1", "a > 1 2", "a > 1 ; b"} {
+		if _, _, err := ParseExpr(expr); !errors.Is(err, errInvalidExpr) {
+			t.Fatalf("%q: expected errInvalidExpr, got %v", expr, err)
+		}
+	}
+}
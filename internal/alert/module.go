@@ -0,0 +1,42 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+
+	"goadmin/internal/core"
+)
+
+// Module отдает алерты как модуль ядра: "alerts list" - активные (pending и firing),
+// "alerts all" - состояние всех правил. В чатах доступен как /alerts.
+type Module struct {
+	engine *Engine
+}
+
+// NewModule создает модуль поверх движка.
+func NewModule(e *Engine) *Module {
+	return &Module{engine: e}
+}
+
+func (m *Module) Name() string { return "alerts" }
+
+func (m *Module) Init(ctx context.Context) error { return nil }
+
+func (m *Module) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	switch cmd {
+	case "list":
+		return core.Response{Status: "ok", Data: m.engine.Alerts(false)}, nil
+	case "all":
+		return core.Response{Status: "ok", Data: m.engine.Alerts(true)}, nil
+	default:
+		return core.Response{Status: "error", ErrorCode: "unknown_command"}, fmt.Errorf("command %s not supported", cmd)
+	}
+}
+
+// Commands описывает команды для чат-меню (core.Describer).
+func (m *Module) Commands() []core.CommandInfo {
+	return []core.CommandInfo{
+		{Name: "list", Summary: "Pending and firing alerts"},
+		{Name: "all", Summary: "State of all alert rules"},
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goadmin/internal/alert"
+	"goadmin/internal/collector"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// alertsJob - имя задачи планировщика, вычисляющей правила алертов.
+const alertsJob = "alerts"
+
+// chatAliases - короткие команды чат-транспортов.
+var chatAliases = map[string]string{"alerts": "alerts list"}
+
+// transportNotifier отправляет уведомления через транспорты names (пусто - во все).
+type transportNotifier struct {
+	manager *core.TransportManager
+	names   []string
+}
+
+func (n transportNotifier) Notify(ctx context.Context, msg core.Notification) error {
+	return n.manager.Notify(ctx, n.names, msg)
+}
+
+// buildAlerts создает движок алертов из секции alerts.
+func buildAlerts(cfg config.Config, st storage.Store, notifier core.Notifier, auditSink storage.AuditWriter) (*alert.Engine, error) {
+	rules := make([]alert.Rule, 0, len(cfg.Alerts.Rules))
+	for _, rc := range cfg.Alerts.Rules {
+		rules = append(rules, alert.Rule{
+			Name:     rc.Name,
+			Metric:   rc.Metric,
+			Expr:     rc.Expr,
+			Severity: rc.Severity,
+			Summary:  rc.Summary,
+			Labels:   rc.Labels,
+		})
+	}
+	e, err := alert.NewEngine(rules, st, alert.Options{
+		Notifier:   notifier,
+		Audit:      auditSink,
+		StaleAfter: time.Duration(cfg.Alerts.StaleAfterSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("alerts: %w", err)
+	}
+	return e, nil
+}
+
+// builtinJobs - задачи коллекторов и, если заданы правила, вычисление алертов.
+func builtinJobs(cfg config.Config, collectors *collector.Manager, alerts *alert.Engine) []core.JobSpec {
+	jobs := collectors.Jobs()
+	if len(cfg.Alerts.Rules) == 0 {
+		return jobs
+	}
+	interval := schedulerInterval(cfg)
+	if cfg.Alerts.EvalIntervalSeconds > 0 {
+		interval = time.Duration(cfg.Alerts.EvalIntervalSeconds) * time.Second
+	}
+	return append(jobs, core.JobSpec{
+		Name:     alertsJob,
+		Schedule: core.Every(interval),
+		Timeout:  interval,
+		Run:      alerts.Evaluate,
+	})
+}
@@ -2,54 +2,193 @@ package app
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"goadmin/internal/alert"
+	"goadmin/internal/audit"
+	"goadmin/internal/collector"
 	"goadmin/internal/config"
 	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
 	"goadmin/internal/modules/host"
+	"goadmin/internal/redact"
 	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
 	"goadmin/internal/storage/sqlite"
 	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/grpcapi"
+	"goadmin/internal/transports/mattermost"
 	"goadmin/internal/transports/maxbot"
 	"goadmin/internal/transports/telegram"
 	"goadmin/internal/transports/web"
+	"goadmin/internal/webhook"
 )
 
 // App агрегирует зависимости ядра.
 type App struct {
-	Registry   *core.Registry
-	Transports *core.TransportManager
-	Authorizer core.Authorizer
-	Store      storage.Store
-	Config     config.Config
+	Registry    *core.Registry
+	Transports  *core.TransportManager
+	Authorizer  core.Authorizer
+	Store       storage.Store
+	Audit       *audit.Fanout
+	AuditAsync  *audit.AsyncWriter
+	Redactor    *redact.Redactor
+	Collectors  *collector.Manager
+	Scheduler   *core.Scheduler
+	Schedules   *Schedules
+	Alerts      *alert.Engine
+	Maintenance *maintenance.Manager
+	Webhooks    *webhook.Dispatcher
+	Config      config.Config
 }
 
-// NewApp строит приложение: реестр модулей и хранилище.
-func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
+// NewApp строит приложение: реестр модулей и хранилище. При ошибке
+// закрывается все, что успели открыть.
+func NewApp(ctx context.Context, cfg config.Config) (_ *App, err error) {
 	r := core.NewRegistry()
 	if err := r.Register(ctx, &host.Module{}); err != nil {
 		return nil, fmt.Errorf("register host module: %w", err)
 	}
 
-	st, err := sqlite.Open(cfg.SQLite.Path)
+	a := &App{Registry: r, Config: cfg}
+	defer func() {
+		if err != nil {
+			_ = a.Close()
+		}
+	}()
+	st, err := openBackend(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("open storage: %w", err)
+		return nil, err
+	}
+	a.Store = st
+
+	redactor, err := BuildRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	collectors, err := buildCollectors(cfg, r, st)
+	if err != nil {
+		return nil, err
 	}
 
-	authz := core.NewAllowlistAuthorizer(cfg.Security.AuthAllowlist)
 	transports := core.NewTransportManager()
+	transports.SetRestartPolicy(core.RestartPolicy{
+		Backoff:    time.Duration(cfg.Transports.Restart.BackoffMS) * time.Millisecond,
+		MaxBackoff: time.Duration(cfg.Transports.Restart.MaxBackoffMS) * time.Millisecond,
+	})
 	limiter := common.NewRateLimiter(5, time.Second)
 
-	audit := st
-	tg := telegram.NewAdapter(r, authz, limiter, audit)
-	mx := maxbot.NewAdapter(r, authz, limiter, audit)
+	var primary storage.AuditWriter = st
+	asyncWriter, err := buildAuditAsync(cfg, st)
+	if err != nil {
+		return nil, err
+	}
+	if asyncWriter != nil {
+		a.AuditAsync = asyncWriter
+		primary = asyncWriter
+	}
+	hooks, err := buildWebhooks(cfg)
+	if err != nil {
+		return nil, err
+	}
+	a.Webhooks = hooks
+	auditSink, err := buildAuditFanout(cfg, primary, webhookAuditOutput(cfg, hooks))
+	if err != nil {
+		return nil, err
+	}
+	a.Audit = auditSink
+	mgr := buildMaintenance(st, auditSink)
+	authz := buildAuthorizer(cfg, mgr)
+	alerts, err := buildAlerts(cfg, st, mgr.Filter(notifiers{transportNotifier{manager: transports, names: cfg.Alerts.Notify}, hooks}), auditSink)
+	if err != nil {
+		return nil, err
+	}
+	if err := registerStateModules(ctx, r, alerts, mgr); err != nil {
+		return nil, err
+	}
+	sched, err := buildScheduler(cfg, append(builtinJobs(cfg, collectors, alerts), maintenanceJobSpec(cfg, mgr)))
+	if err != nil {
+		return nil, err
+	}
+	schedules := newSchedules(sched, st, redactor, mgr.Filter(transportNotifier{manager: transports}), hooks)
+
+	tg := telegram.NewAdapter(r, authz, limiter, auditSink)
+	mx := maxbot.NewAdapter(r, authz, limiter, auditSink)
+	tg.RequireAudit(cfg.Audit.FailClosed)
+	mx.RequireAudit(cfg.Audit.FailClosed)
+	tg.SetRedactor(redactor)
+	mx.SetRedactor(redactor)
+	tg.SetAliases(chatAliases)
+	mx.SetAliases(chatAliases)
+	renderer, err := setupRendering(cfg, r, tg, mx)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Transports.Interactive.Enabled {
+		ttl := time.Duration(cfg.Transports.Interactive.CallbackTTLSeconds) * time.Second
+		tg.EnableInteractive(ttl)
+		mx.EnableInteractive(ttl)
+	}
+	if subs, ok := st.(storage.SubscriptionStore); ok {
+		tg.SetSubscriptions(subs, notifyTopics)
+		mx.SetSubscriptions(subs, notifyTopics)
+	}
+	token, err := telegramToken(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		client := telegram.NewClient(cfg.Transports.Telegram.APIURL, token, nil)
+		tg.SetSender(client, outboxConfig(cfg))
+		if cfg.Transports.Telegram.Polling {
+			tg.EnablePolling(client, telegram.PollConfig{
+				Timeout: time.Duration(cfg.Transports.Telegram.PollTimeoutSeconds) * time.Second,
+				MaxAge:  time.Duration(cfg.Transports.Telegram.MaxMessageAgeSeconds) * time.Second,
+			})
+		}
+	}
+	if err := setupMaxBot(cfg, mx); err != nil {
+		return nil, err
+	}
 	if err := transports.Register(tg); err != nil {
 		return nil, fmt.Errorf("register telegram transport: %w", err)
 	}
 	if err := transports.Register(mx); err != nil {
 		return nil, fmt.Errorf("register maxbot transport: %w", err)
 	}
+	mmCfg, mmFormat, mmEnabled, err := mattermostConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if mmEnabled {
+		mm := mattermost.NewAdapter(r, authz, limiter, auditSink, mmCfg, nil)
+		mm.RequireAudit(cfg.Audit.FailClosed)
+		mm.SetRedactor(redactor)
+		mm.SetAliases(chatAliases)
+		mm.SetRenderer(renderer, mmFormat)
+		if err := transports.Register(mm); err != nil {
+			return nil, fmt.Errorf("register mattermost transport: %w", err)
+		}
+	}
+	grpcCfg, grpcEnabled, err := grpcConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if grpcEnabled {
+		grpcCfg.AuditWriter = auditSink
+		grpcCfg.AuditRequired = cfg.Audit.FailClosed
+		grpcCfg.Redactor = redactor
+		grpcCfg.Schedules = schedules
+		if err := transports.Register(grpcapi.NewAdapter(r, authz, st, limiter, grpcCfg)); err != nil {
+			return nil, fmt.Errorf("register grpc transport: %w", err)
+		}
+	}
 	if cfg.Web.Enabled {
 		tokens := make([]web.TokenEntry, 0, len(cfg.Web.Auth.Tokens))
 		for _, token := range cfg.Web.Auth.Tokens {
@@ -75,23 +214,126 @@ func NewApp(ctx context.Context, cfg config.Config) (*App, error) {
 			CORSAllowedOrigins:       cfg.Web.CORS.AllowedOrigins,
 			CORSAllowedMethods:       cfg.Web.CORS.AllowedMethods,
 			CORSAllowedHeaders:       cfg.Web.CORS.AllowedHeaders,
+			AuditWriter:              auditSink,
+			AuditRequired:            cfg.Audit.FailClosed,
+			AuditStats: func() interface{} {
+				stats := map[string]interface{}{"sinks": auditSink.Stats()}
+				if asyncWriter != nil {
+					stats["writer"] = asyncWriter.Stats()
+				}
+				return stats
+			},
+			HealthChecks: auditHealthChecks(asyncWriter),
+			Redactor:     redactor,
+			Collectors:   func() interface{} { return collectors.Stats() },
+			Schedules:    schedules,
+			Alerts:       func(all bool) interface{} { return alerts.Alerts(all) },
+			Maintenance:  mgr,
+			Webhooks:     func() interface{} { return hooks.Stats() },
+			Transports:   func() interface{} { return transports.Status() },
 		})
 		if err := transports.Register(webAdapter); err != nil {
 			return nil, fmt.Errorf("register web transport: %w", err)
 		}
 	}
 
-	return &App{
-		Registry:   r,
-		Transports: transports,
-		Authorizer: authz,
-		Store:      st,
-		Config:     cfg,
-	}, nil
+	a.Transports = transports
+	a.Authorizer = authz
+	a.Redactor = redactor
+	a.Collectors = collectors
+	a.Scheduler = sched
+	a.Schedules = schedules
+	a.Alerts = alerts
+	a.Maintenance = mgr
+	return a, nil
+}
+
+// backendStore - хранилище, которое одновременно служит основным приемником аудита.
+type backendStore interface {
+	storage.Store
+	storage.AuditWriter
+}
+
+// openBackend выбирает хранилище по storage.backend: memory держит состояние
+// только в памяти процесса (эфемерный режим), sqlite - в файле.
+func openBackend(cfg config.Config) (backendStore, error) {
+	switch cfg.Storage.Backend {
+	case "", config.StorageSQLite:
+		return OpenStore(cfg)
+	case config.StorageMemory:
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+// OpenStore открывает SQLite-хранилище с параметрами цепочки аудита из конфига.
+func OpenStore(cfg config.Config) (*sqlite.Store, error) {
+	if cfg.Storage.Backend == config.StorageMemory {
+		return nil, errors.New("storage.backend is memory: there is no database to open")
+	}
+	opts := sqlite.Options{
+		Driver:          cfg.SQLite.Driver,
+		SigningKeyID:    cfg.Audit.SigningKeyID,
+		CheckpointEvery: cfg.Audit.CheckpointEvery,
+	}
+	if cfg.Audit.SigningKeyFile != "" {
+		key, err := storage.LoadAuditSigningKey(cfg.Audit.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load audit signing key: %w", err)
+		}
+		opts.SigningKey = key
+		if opts.SigningKeyID == "" {
+			opts.SigningKeyID = storage.AuditKeyID(key.Public().(ed25519.PublicKey))
+		}
+	}
+	if enc := cfg.SQLite.Encryption; enc.Enabled {
+		keyring, err := storage.LoadKeyring(enc.KeyFile, enc.KeyEnv, enc.ActiveKeyID)
+		if err != nil {
+			return nil, fmt.Errorf("load payload keys: %w", err)
+		}
+		opts.Keyring = keyring
+	}
+	st, err := sqlite.OpenWithOptions(cfg.SQLite.Path, opts)
+	if err != nil {
+		return nil, fmt.Errorf("open storage: %w", err)
+	}
+	return st, nil
+}
+
+// BuildRedactor собирает правила скрытия секретов из секции redaction; nil, если выключено.
+func BuildRedactor(cfg config.Config) (*redact.Redactor, error) {
+	if !cfg.Redaction.Enabled {
+		return nil, nil
+	}
+	rc := redact.Config{Mask: cfg.Redaction.Mask, Fields: cfg.Redaction.Fields}
+	for _, p := range cfg.Redaction.Patterns {
+		rc.Patterns = append(rc.Patterns, redact.Pattern{Name: p.Name, Regex: p.Regex})
+	}
+	for _, c := range cfg.Redaction.Commands {
+		rc.Commands = append(rc.Commands, redact.CommandRule{Command: c.Command, Args: c.Args, Keys: c.Keys})
+	}
+	r, err := redact.New(rc)
+	if err != nil {
+		return nil, fmt.Errorf("build redactor: %w", err)
+	}
+	return r, nil
 }
 
 // Close высвобождает ресурсы приложения.
 func (a *App) Close() error {
+	// Сначала дописывается очередь аудита: fan-out получает события после записи.
+	closeAuditAsync(a.AuditAsync)
+	if a.Audit != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = a.Audit.Close(ctx)
+		cancel()
+	}
+	if a.Webhooks != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = a.Webhooks.Close(ctx)
+		cancel()
+	}
 	if a.Store != nil {
 		return a.Store.Close()
 	}
@@ -100,6 +342,16 @@ func (a *App) Close() error {
 
 // Serve запускает планировщик периодического сбора метрик.
 func (a *App) Serve(ctx context.Context) error {
+	if err := a.Schedules.restore(ctx); err != nil {
+		return err
+	}
+	if err := a.Alerts.Restore(ctx); err != nil {
+		return err
+	}
+	if err := a.Maintenance.Refresh(ctx); err != nil {
+		return err
+	}
+
 	if err := a.Transports.StartAll(ctx); err != nil {
 		return fmt.Errorf("start transports: %w", err)
 	}
@@ -109,27 +361,35 @@ func (a *App) Serve(ctx context.Context) error {
 		_ = a.Transports.StopAll(stopCtx)
 	}()
 
-	interval := time.Duration(a.Config.Scheduler.IntervalSeconds) * time.Second
-	if interval <= 0 {
-		interval = time.Minute
-	}
-	sched := core.NewScheduler(interval)
-
-	sched.Add(func(jobCtx context.Context) error {
-		runCtx, cancel := context.WithTimeout(jobCtx, 3*time.Second)
-		defer cancel()
-
-		resp, err := a.Registry.Execute(runCtx, "host", "status", nil)
-		if err != nil {
-			return fmt.Errorf("host status: %w", err)
-		}
-		payload, err := sqlite.MarshalPayload(resp.Data)
-		if err != nil {
-			return err
+	if a.Config.SQLite.Encryption.BackgroundRekey {
+		if st, ok := a.Store.(*sqlite.Store); ok {
+			go backgroundRekey(ctx, st, a.Audit)
 		}
-		return a.Store.SaveMetric(jobCtx, storage.MetricRecord{Module: "host", Payload: payload})
-	})
+	}
 
-	sched.Start(ctx)
+	go a.Schedules.sync(ctx)
+	a.Scheduler.Start(ctx)
 	return ctx.Err()
 }
+
+// backgroundRekey перешифровывает старые строки активным ключом и аудирует
+// результат через общий writer аудита.
+func backgroundRekey(ctx context.Context, st *sqlite.Store, auditSink storage.AuditWriter) {
+	result, err := st.Rekey(ctx, sqlite.RekeyOptions{Pause: 100 * time.Millisecond})
+	status := "ok"
+	payload := map[string]interface{}{"tables": result}
+	if err != nil {
+		status = "error"
+		payload["error"] = err.Error()
+	}
+	raw, _ := json.Marshal(payload)
+	if err := auditSink.Write(context.WithoutCancel(ctx), storage.AuditEvent{
+		Subject: "system",
+		Action:  "db:rekey",
+		Source:  "scheduler",
+		Status:  status,
+		Payload: raw,
+	}); err != nil {
+		slog.Warn("db rekey audit failed", "err", err)
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goadmin/internal/audit"
+	"goadmin/internal/config"
+	"goadmin/internal/storage"
+)
+
+// buildAuditAsync включает асинхронную запись аудита со spool; nil, если выключено.
+func buildAuditAsync(cfg config.Config, primary storage.AuditWriter) (*audit.AsyncWriter, error) {
+	if !cfg.Audit.Async.Enabled {
+		return nil, nil
+	}
+	var spool *audit.Spool
+	if cfg.Audit.Async.SpoolDir != "" {
+		sp, err := audit.OpenSpool(cfg.Audit.Async.SpoolDir, int64(cfg.Audit.Async.SpoolMaxMB)<<20)
+		if err != nil {
+			return nil, fmt.Errorf("open audit spool: %w", err)
+		}
+		spool = sp
+	}
+	return audit.NewAsyncWriter(primary, audit.AsyncConfig{
+		QueueSize:     cfg.Audit.Async.QueueSize,
+		BatchSize:     cfg.Audit.Async.BatchSize,
+		FlushInterval: time.Duration(cfg.Audit.Async.FlushIntervalMS) * time.Millisecond,
+		FailClosed:    cfg.Audit.FailClosed,
+		Spool:         spool,
+	}), nil
+}
+
+func closeAuditAsync(w *audit.AsyncWriter) {
+	if w == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = w.Close(ctx)
+}
+
+func auditHealthChecks(w *audit.AsyncWriter) map[string]func() string {
+	if w == nil {
+		return nil
+	}
+	return map[string]func() string{"audit": w.Health}
+}
+
+// buildAuditFanout собирает fan-out из audit.sinks и extra поверх основного хранилища.
+func buildAuditFanout(cfg config.Config, primary storage.AuditWriter, extra ...audit.OutputConfig) (*audit.Fanout, error) {
+	outputs := make([]audit.OutputConfig, 0, len(cfg.Audit.Sinks)+len(extra))
+	for i, sc := range cfg.Audit.Sinks {
+		name := sc.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", sc.Type, i)
+		}
+		var (
+			dest audit.Destination
+			err  error
+		)
+		switch sc.Type {
+		case "syslog":
+			dest, err = audit.NewSyslog(audit.SyslogConfig{
+				Name:     name,
+				Network:  sc.Syslog.Network,
+				Address:  sc.Syslog.Address,
+				Facility: sc.Syslog.Facility,
+				AppName:  sc.Syslog.AppName,
+			})
+		case "file":
+			dest, err = audit.NewFile(audit.FileConfig{
+				Name:       name,
+				Path:       sc.File.Path,
+				MaxBytes:   int64(sc.File.MaxSizeMB) << 20,
+				MaxBackups: sc.File.MaxBackups,
+			})
+		case "webhook":
+			dest, err = audit.NewWebhook(audit.WebhookConfig{
+				Name:       name,
+				URL:        sc.Webhook.URL,
+				Headers:    sc.Webhook.Headers,
+				Timeout:    time.Duration(sc.Webhook.TimeoutMS) * time.Millisecond,
+				MaxRetries: sc.Webhook.MaxRetries,
+				Backoff:    time.Duration(sc.Webhook.BackoffMS) * time.Millisecond,
+			})
+		default:
+			err = fmt.Errorf("unknown sink type %q", sc.Type)
+		}
+		if err != nil {
+			closeDestinations(outputs)
+			return nil, fmt.Errorf("audit sink %s: %w", name, err)
+		}
+		outputs = append(outputs, audit.OutputConfig{
+			Destination: dest,
+			Buffer:      sc.Buffer,
+			Policy:      audit.Policy(sc.Policy),
+		})
+	}
+	outputs = append(outputs, extra...)
+	fanout, err := audit.NewFanout(primary, outputs...)
+	if err != nil {
+		closeDestinations(outputs)
+		return nil, fmt.Errorf("audit fanout: %w", err)
+	}
+	return fanout, nil
+}
+
+func closeDestinations(outputs []audit.OutputConfig) {
+	for _, out := range outputs {
+		_ = out.Destination.Close()
+	}
+}
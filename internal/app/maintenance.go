@@ -0,0 +1,54 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
+	"goadmin/internal/storage"
+)
+
+// maintenanceJob - задача планировщика, подхватывающая изменения silences и окон.
+const maintenanceJob = "maintenance"
+
+// buildMaintenance создает менеджер silences и окон; без storage.MaintenanceStore
+// состояние живет только в памяти процесса.
+func buildMaintenance(st storage.Store, auditSink storage.AuditWriter) *maintenance.Manager {
+	ms, _ := st.(storage.MaintenanceStore)
+	return maintenance.NewManager(ms, maintenance.Options{Audit: auditSink})
+}
+
+// buildAuthorizer - allowlist и политика maintenance.require_window.
+func buildAuthorizer(cfg config.Config, mgr *maintenance.Manager) core.Authorizer {
+	policies := make([]maintenance.Policy, 0, len(cfg.Maintenance.RequireWindow))
+	for _, p := range cfg.Maintenance.RequireWindow {
+		policies = append(policies, maintenance.Policy{Action: p.Action, Windows: p.Windows})
+	}
+	return maintenance.NewAuthorizer(core.NewAllowlistAuthorizer(cfg.Security.AuthAllowlist), mgr, policies)
+}
+
+func maintenanceJobSpec(cfg config.Config, mgr *maintenance.Manager) core.JobSpec {
+	interval := 15 * time.Second
+	if cfg.Maintenance.SyncIntervalSeconds > 0 {
+		interval = time.Duration(cfg.Maintenance.SyncIntervalSeconds) * time.Second
+	}
+	return core.JobSpec{
+		Name:     maintenanceJob,
+		Schedule: core.Every(interval),
+		Timeout:  interval,
+		Run:      mgr.Tick,
+	}
+}
+
+// Maintenance открывает менеджер silences и окон поверх хранилища CLI и
+// авторизатор с политикой окон; аудит пишется в st.
+func Maintenance(ctx context.Context, cfg config.Config, st storage.Store, auditSink storage.AuditWriter) (*maintenance.Manager, core.Authorizer, error) {
+	mgr := buildMaintenance(st, auditSink)
+	if err := mgr.Refresh(ctx); err != nil {
+		return nil, nil, fmt.Errorf("load maintenance state: %w", err)
+	}
+	return mgr, buildAuthorizer(cfg, mgr), nil
+}
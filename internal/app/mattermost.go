@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"goadmin/internal/config"
+	"goadmin/internal/render"
+	"goadmin/internal/transports/mattermost"
+)
+
+// mattermostConfig собирает параметры приема команд Mattermost/Slack; ok=false -
+// транспорт выключен. Без токена и signing secret запросы нельзя проверить,
+// поэтому транспорт не запускается.
+func mattermostConfig(cfg config.Config) (mattermost.Config, render.Format, bool, error) {
+	mc := cfg.Transports.Mattermost
+	if mc.ListenAddr == "" {
+		return mattermost.Config{}, "", false, nil
+	}
+	format, err := render.ParseFormat(mc.Format)
+	if err != nil || (format != render.Markdown && format != render.Plain) {
+		return mattermost.Config{}, "", false, fmt.Errorf("transports.mattermost.format: unsupported %q", mc.Format)
+	}
+	if mc.ResponseType != mattermost.Ephemeral && mc.ResponseType != mattermost.InChannel {
+		return mattermost.Config{}, "", false, fmt.Errorf("transports.mattermost.response_type: unsupported %q", mc.ResponseType)
+	}
+	tokens, err := readSecret("mattermost tokens", mc.TokenFile, mc.TokenEnv)
+	if err != nil {
+		return mattermost.Config{}, "", false, err
+	}
+	secret, err := readSecret("slack signing secret", mc.SigningSecretFile, mc.SigningSecretEnv)
+	if err != nil {
+		return mattermost.Config{}, "", false, err
+	}
+	out := mattermost.Config{
+		ListenAddr:       mc.ListenAddr,
+		Path:             mc.Path,
+		SigningSecret:    []byte(secret),
+		Command:          mc.Command,
+		Users:            mc.Users,
+		Teams:            mc.Teams,
+		ImmediateTimeout: time.Duration(mc.ImmediateTimeoutMS) * time.Millisecond,
+		ResponseType:     mc.ResponseType,
+		ResponseHosts:    mc.ResponseHosts,
+	}
+	for _, t := range strings.FieldsFunc(tokens, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			out.Tokens = append(out.Tokens, []byte(t))
+		}
+	}
+	if len(out.Tokens) == 0 && secret == "" {
+		return mattermost.Config{}, "", false, fmt.Errorf("mattermost: neither tokens nor signing secret is set")
+	}
+	return out, format, true, nil
+}
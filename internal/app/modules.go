@@ -0,0 +1,77 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goadmin/internal/alert"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
+	"goadmin/internal/modules/host"
+	"goadmin/internal/storage"
+)
+
+// Modules строит реестр модулей и авторизатор, как NewApp, поверх
+// хранилища st, но без коллекторов, планировщика и транспортов: для
+// goadmin exec и подкоманд модулей. Аудит silences и окон пишется в auditSink.
+func Modules(ctx context.Context, cfg config.Config, st storage.Store, auditSink storage.AuditWriter) (*core.Registry, core.Authorizer, error) {
+	r := core.NewRegistry()
+	if err := r.Register(ctx, &host.Module{}); err != nil {
+		return nil, nil, fmt.Errorf("register host module: %w", err)
+	}
+	mgr, authz, err := Maintenance(ctx, cfg, st, auditSink)
+	if err != nil {
+		return nil, nil, err
+	}
+	alerts, err := buildAlerts(cfg, st, nil, auditSink)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := alerts.Restore(ctx); err != nil {
+		return nil, nil, err
+	}
+	if err := registerStateModules(ctx, r, alerts, mgr); err != nil {
+		return nil, nil, err
+	}
+	return r, authz, nil
+}
+
+// OpenAudit строит для команд CLI writer аудита поверх st, как NewApp:
+// события после записи в st уходят в audit.sinks и webhooks. close
+// дописывает их очереди.
+func OpenAudit(cfg config.Config, st storage.AuditWriter) (storage.AuditWriter, func(), error) {
+	hooks, err := buildWebhooks(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	fanout, err := buildAuditFanout(cfg, st, webhookAuditOutput(cfg, hooks))
+	if err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = hooks.Close(ctx)
+		cancel()
+		return nil, nil, err
+	}
+	closeFn := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = fanout.Close(ctx)
+		_ = hooks.Close(ctx)
+	}
+	return fanout, closeFn, nil
+}
+
+// registerStateModules регистрирует модули алертов, silences и окон обслуживания.
+func registerStateModules(ctx context.Context, r *core.Registry, alerts *alert.Engine, mgr *maintenance.Manager) error {
+	if err := r.Register(ctx, alert.NewModule(alerts)); err != nil {
+		return fmt.Errorf("register alerts module: %w", err)
+	}
+	if err := r.Register(ctx, maintenance.NewSilenceModule(mgr)); err != nil {
+		return fmt.Errorf("register silence module: %w", err)
+	}
+	if err := r.Register(ctx, maintenance.NewWindowModule(mgr)); err != nil {
+		return fmt.Errorf("register maintenance module: %w", err)
+	}
+	return nil
+}
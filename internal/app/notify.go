@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"goadmin/internal/alert"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/render"
+	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/maxbot"
+	"goadmin/internal/transports/telegram"
+)
+
+// notifyTopics - темы, на которые чаты могут подписаться.
+var notifyTopics = []string{alert.TopicAlerts, core.TopicJobs}
+
+// outboxConfig переводит секцию notifications в параметры очереди доставки.
+func outboxConfig(cfg config.Config) common.OutboxConfig {
+	n := cfg.Notifications
+	return common.OutboxConfig{
+		QueueSize:   n.QueueSize,
+		MaxAttempts: n.MaxAttempts,
+		Backoff:     time.Duration(n.BackoffMS) * time.Millisecond,
+		MaxBackoff:  time.Duration(n.MaxBackoffMS) * time.Millisecond,
+		DedupWindow: time.Duration(n.DedupWindowSeconds) * time.Second,
+	}
+}
+
+// telegramToken читает токен бота из token_file, иначе из token_env; пусто - отправка выключена.
+func telegramToken(cfg config.Config) (string, error) {
+	tc := cfg.Transports.Telegram
+	return readSecret("telegram token", tc.TokenFile, tc.TokenEnv)
+}
+
+// maxbotToken читает токен MaxBot так же, как telegramToken.
+func maxbotToken(cfg config.Config) (string, error) {
+	mc := cfg.Transports.MaxBot
+	return readSecret("maxbot token", mc.TokenFile, mc.TokenEnv)
+}
+
+// maxbotWebhook собирает параметры входящего webhook; ok=false - webhook
+// выключен. Без секрета и токена webhook не запускается: запросы нельзя
+// проверить, а ответы - отправить.
+func maxbotWebhook(cfg config.Config, token string) (maxbot.WebhookConfig, bool, error) {
+	mc := cfg.Transports.MaxBot
+	if mc.WebhookListenAddr == "" {
+		return maxbot.WebhookConfig{}, false, nil
+	}
+	if token == "" {
+		return maxbot.WebhookConfig{}, false, fmt.Errorf("maxbot webhook: bot token is empty")
+	}
+	secret, err := readSecret("maxbot webhook secret", mc.WebhookSecretFile, mc.WebhookSecretEnv)
+	if err != nil {
+		return maxbot.WebhookConfig{}, false, err
+	}
+	if secret == "" {
+		return maxbot.WebhookConfig{}, false, fmt.Errorf("maxbot webhook: secret is empty")
+	}
+	return maxbot.WebhookConfig{
+		ListenAddr:   mc.WebhookListenAddr,
+		Path:         mc.WebhookPath,
+		Secret:       []byte(secret),
+		ReplayWindow: time.Duration(mc.ReplayWindowSeconds) * time.Second,
+	}, true, nil
+}
+
+// setupRendering задает чат-транспортам шаблоны модулей и разметку ответов;
+// renderer нужен и транспортам, создаваемым позже.
+func setupRendering(cfg config.Config, r *core.Registry, tg *telegram.Adapter, mx *maxbot.Adapter) (*render.Renderer, error) {
+	renderer, err := render.FromRegistry(r)
+	if err != nil {
+		return nil, fmt.Errorf("response templates: %w", err)
+	}
+	tgFormat, err := render.ParseFormat(cfg.Transports.Telegram.ParseMode)
+	if err != nil || tgFormat == render.Markdown {
+		return nil, fmt.Errorf("transports.telegram.parse_mode: unsupported %q", cfg.Transports.Telegram.ParseMode)
+	}
+	mxFormat, err := render.ParseFormat(cfg.Transports.MaxBot.Format)
+	if err != nil || mxFormat == render.MarkdownV2 {
+		return nil, fmt.Errorf("transports.maxbot.format: unsupported %q", cfg.Transports.MaxBot.Format)
+	}
+	tg.SetRenderer(renderer, tgFormat)
+	mx.SetRenderer(renderer, mxFormat)
+	return renderer, nil
+}
+
+// setupMaxBot включает отправку и входящий webhook MaxBot по конфигу.
+func setupMaxBot(cfg config.Config, mx *maxbot.Adapter) error {
+	token, err := maxbotToken(cfg)
+	if err != nil {
+		return err
+	}
+	hook, enabled, err := maxbotWebhook(cfg, token)
+	if err != nil || token == "" {
+		return err
+	}
+	client := maxbot.NewClient(cfg.Transports.MaxBot.APIURL, token, nil)
+	mx.SetSender(client, outboxConfig(cfg))
+	if enabled {
+		mx.EnableWebhook(client, hook)
+	}
+	return nil
+}
+
+// readSecret читает значение из файла, иначе из переменной окружения.
+func readSecret(what, file, env string) (string, error) {
+	if file != "" {
+		raw, err := os.ReadFile(file) // #nosec G304 -- путь задается доверенным оператором.
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", what, err)
+		}
+		return strings.TrimSpace(string(raw)), nil
+	}
+	if env != "" {
+		return strings.TrimSpace(os.Getenv(env)), nil
+	}
+	return "", nil
+}
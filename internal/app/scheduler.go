@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"goadmin/internal/collector"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// buildCollectors создает коллекторы из секции collectors.
+func buildCollectors(cfg config.Config, exec collector.Executor, st storage.Store) (*collector.Manager, error) {
+	specs := make([]collector.Spec, 0, len(cfg.Collectors))
+	for _, cc := range cfg.Collectors {
+		spec := collector.Spec{
+			Name:    cc.Name,
+			Module:  cc.Module,
+			Command: cc.Command,
+			Args:    cc.Args,
+			Metric:  cc.Metric,
+			Jitter:  time.Duration(cc.JitterMS) * time.Millisecond,
+			Timeout: time.Duration(cc.TimeoutMS) * time.Millisecond,
+		}
+		switch {
+		case cc.Schedule != "":
+			sc, err := core.ParseSchedule(cc.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("collector %q: %w", cc.Name, err)
+			}
+			spec.Schedule = sc
+		case cc.IntervalSeconds > 0:
+			spec.Schedule = core.Every(time.Duration(cc.IntervalSeconds) * time.Second)
+		default:
+			spec.Schedule = core.Every(schedulerInterval(cfg))
+		}
+		retention := cc.RetentionDays
+		if retention == 0 {
+			retention = cfg.SQLite.RetentionDays
+		}
+		if retention > 0 {
+			spec.Retention = time.Duration(retention) * 24 * time.Hour
+		}
+		specs = append(specs, spec)
+	}
+	m, err := collector.NewManager(exec, st, specs)
+	if err != nil {
+		return nil, fmt.Errorf("collectors: %w", err)
+	}
+	return m, nil
+}
+
+func schedulerInterval(cfg config.Config) time.Duration {
+	if cfg.Scheduler.IntervalSeconds <= 0 {
+		return time.Minute
+	}
+	return time.Duration(cfg.Scheduler.IntervalSeconds) * time.Second
+}
+
+// buildScheduler регистрирует встроенные задачи с учетом scheduler.jobs из конфига.
+func buildScheduler(cfg config.Config, builtin []core.JobSpec) (*core.Scheduler, error) {
+	interval := schedulerInterval(cfg)
+	sched := core.NewScheduler(interval)
+
+	byName := make(map[string]int, len(builtin))
+	for i, spec := range builtin {
+		if spec.Schedule == nil {
+			builtin[i].Schedule = core.Every(interval)
+		}
+		byName[spec.Name] = i
+	}
+	disabled := make(map[string]bool)
+	for _, jc := range cfg.Scheduler.Jobs {
+		i, ok := byName[jc.Name]
+		if !ok {
+			return nil, fmt.Errorf("scheduler.jobs: unknown job %q", jc.Name)
+		}
+		spec := &builtin[i]
+		if jc.Disabled {
+			disabled[jc.Name] = true
+			continue
+		}
+		if jc.Schedule != "" {
+			sc, err := core.ParseSchedule(jc.Schedule)
+			if err != nil {
+				return nil, fmt.Errorf("scheduler.jobs %s: %w", jc.Name, err)
+			}
+			spec.Schedule = sc
+		}
+		if jc.JitterMS > 0 {
+			spec.Jitter = time.Duration(jc.JitterMS) * time.Millisecond
+		}
+		if jc.TimeoutMS > 0 {
+			spec.Timeout = time.Duration(jc.TimeoutMS) * time.Millisecond
+		}
+		if jc.Overlap != "" {
+			spec.Overlap = core.OverlapPolicy(jc.Overlap)
+		}
+	}
+	for _, spec := range builtin {
+		if disabled[spec.Name] {
+			continue
+		}
+		if err := sched.AddJob(spec); err != nil {
+			return nil, fmt.Errorf("scheduler.jobs: %w", err)
+		}
+	}
+	return sched, nil
+}
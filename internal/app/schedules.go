@@ -0,0 +1,251 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/storage"
+	"goadmin/internal/webhook"
+)
+
+// scheduleSyncInterval - как часто демон применяет паузы и ручные запуски, записанные CLI.
+const scheduleSyncInterval = 5 * time.Second
+
+// Schedules управляет задачами планировщика и сохраняет историю их запусков.
+// Пауза хранится в job_state, поэтому переживает перезапуск; CLI меняет ее
+// в базе, а демон подхватывает изменения в sync.
+type Schedules struct {
+	sched    *core.Scheduler
+	store    storage.JobStore
+	redactor *redact.Redactor
+	notifier core.Notifier
+	events   *webhook.Dispatcher
+}
+
+// newSchedules подключает запись истории, уведомления о завершении задач и
+// событие job.finished; без storage.JobStore история не ведется, nil
+// notifier и events - без уведомлений и событий.
+func newSchedules(sched *core.Scheduler, st storage.Store, redactor *redact.Redactor, notifier core.Notifier, events *webhook.Dispatcher) *Schedules {
+	s := &Schedules{sched: sched, redactor: redactor, notifier: notifier, events: events}
+	if js, ok := st.(storage.JobStore); ok {
+		s.store = js
+	}
+	sched.SetReporter(s.record)
+	return s
+}
+
+// Jobs возвращает состояние задач.
+func (s *Schedules) Jobs() []core.JobStatus {
+	return s.sched.Jobs()
+}
+
+// Pause приостанавливает задачу и сохраняет паузу.
+func (s *Schedules) Pause(ctx context.Context, name, by string) error {
+	return s.setPaused(ctx, name, true, by)
+}
+
+// Resume снимает паузу.
+func (s *Schedules) Resume(ctx context.Context, name, by string) error {
+	return s.setPaused(ctx, name, false, by)
+}
+
+func (s *Schedules) setPaused(ctx context.Context, name string, paused bool, by string) error {
+	if !s.known(name) {
+		return fmt.Errorf("%s: %w", name, core.ErrUnknownJob)
+	}
+	if s.store != nil {
+		if err := s.store.SetJobPaused(ctx, name, paused, by); err != nil {
+			return err
+		}
+	}
+	if paused {
+		return s.sched.Pause(name)
+	}
+	return s.sched.Resume(name)
+}
+
+// RunNow запускает задачу вне расписания.
+func (s *Schedules) RunNow(_ context.Context, name, _ string) error {
+	return s.sched.RunNow(name)
+}
+
+// Runs возвращает последние запуски задачи, новые первыми.
+func (s *Schedules) Runs(ctx context.Context, name string, limit int) ([]storage.JobRun, error) {
+	if !s.known(name) {
+		return nil, fmt.Errorf("%s: %w", name, core.ErrUnknownJob)
+	}
+	if s.store == nil {
+		return nil, nil
+	}
+	return s.store.JobRuns(ctx, storage.JobRunQuery{Job: name, Limit: limit})
+}
+
+func (s *Schedules) known(name string) bool {
+	for _, job := range s.sched.Jobs() {
+		if job.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// record сохраняет завершенный запуск и сообщает о нем подписчикам темы jobs;
+// пропуски по политике перекрытия учитываются только в счетчиках.
+func (s *Schedules) record(res core.JobResult) {
+	if res.Skipped {
+		return
+	}
+	s.announce(res)
+	run := storage.JobRun{
+		Job:        res.Job,
+		Trigger:    res.Trigger,
+		StartedAt:  res.Started,
+		FinishedAt: res.Started.Add(res.Duration),
+		Status:     "ok",
+	}
+	if res.Err != nil {
+		run.Status = "error"
+		run.Error = s.redactor.String(res.Err.Error())
+	}
+	s.publish(run)
+	if s.store == nil {
+		return
+	}
+	if err := s.store.SaveJobRun(context.Background(), run); err != nil {
+		slog.Error("save job run", "job", res.Job, "err", err)
+	}
+}
+
+// announce уведомляет об ошибках задач и о завершении ручных запусков;
+// успешные плановые запуски не шумят.
+func (s *Schedules) announce(res core.JobResult) {
+	if s.notifier == nil || (res.Err == nil && res.Trigger != core.TriggerManual) {
+		return
+	}
+	n := core.Notification{
+		Key:      fmt.Sprintf("job:%s:%d", res.Job, res.Started.UnixNano()),
+		Topic:    core.TopicJobs,
+		Severity: core.SeverityInfo,
+		Title:    "[OK] " + res.Job,
+		Text:     fmt.Sprintf("%s run finished in %s", res.Trigger, res.Duration.Round(time.Millisecond)),
+		Labels:   map[string]string{"job": res.Job, "trigger": res.Trigger},
+	}
+	if res.Err != nil {
+		n.Severity = core.SeverityWarning
+		n.Title = "[ERROR] " + res.Job
+		n.Text += ": " + s.redactor.String(res.Err.Error())
+	}
+	if err := s.notifier.Notify(context.Background(), n); err != nil {
+		slog.Warn("job notification failed", "job", res.Job, "err", err)
+	}
+}
+
+// publish отправляет job.finished о каждом завершенном запуске.
+func (s *Schedules) publish(run storage.JobRun) {
+	if s.events == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"job":         run.Job,
+		"trigger":     run.Trigger,
+		"started_at":  run.StartedAt,
+		"duration_ms": run.FinishedAt.Sub(run.StartedAt).Milliseconds(),
+	}
+	if run.Error != "" {
+		data["error"] = run.Error
+	}
+	s.events.Publish(webhook.Event{
+		Type:    webhook.EventJobFinished,
+		Time:    run.FinishedAt,
+		Source:  "scheduler",
+		Subject: run.Job,
+		Status:  run.Status,
+		Data:    data,
+	})
+}
+
+// restore применяет сохраненные паузы до старта планировщика.
+func (s *Schedules) restore(ctx context.Context) error {
+	if s.store == nil {
+		return nil
+	}
+	states, err := s.store.JobStates(ctx)
+	if err != nil {
+		return fmt.Errorf("load job state: %w", err)
+	}
+	s.apply(states)
+	return nil
+}
+
+// sync периодически применяет паузы и ручные запуски, записанные в базу другими процессами.
+func (s *Schedules) sync(ctx context.Context) {
+	if s.store == nil {
+		return
+	}
+	ticker := time.NewTicker(scheduleSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		states, err := s.store.JobStates(ctx)
+		if err != nil {
+			slog.Error("load job state", "err", err)
+			continue
+		}
+		s.apply(states)
+		names, err := s.store.ClaimJobRunRequests(ctx)
+		if err != nil {
+			slog.Error("claim job run requests", "err", err)
+			continue
+		}
+		for _, name := range names {
+			if err := s.sched.RunNow(name); err != nil {
+				slog.Warn("requested job run rejected", "job", name, "err", err)
+			}
+		}
+	}
+}
+
+func (s *Schedules) apply(states []storage.JobState) {
+	paused := make(map[string]bool, len(states))
+	for _, st := range states {
+		paused[st.Job] = st.Paused
+	}
+	for _, job := range s.sched.Jobs() {
+		want, ok := paused[job.Name]
+		if !ok || want == job.Paused {
+			continue
+		}
+		if want {
+			_ = s.sched.Pause(job.Name)
+		} else {
+			_ = s.sched.Resume(job.Name)
+		}
+	}
+}
+
+// ScheduledJobs возвращает задачи, которые зарегистрирует демон с этим конфигом
+// (без запуска); используется CLI для проверки имен.
+func ScheduledJobs(cfg config.Config) ([]core.JobStatus, error) {
+	collectors, err := buildCollectors(cfg, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	alerts, err := buildAlerts(cfg, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	sched, err := buildScheduler(cfg, append(builtinJobs(cfg, collectors, alerts), maintenanceJobSpec(cfg, buildMaintenance(nil, nil))))
+	if err != nil {
+		return nil, err
+	}
+	return sched.Jobs(), nil
+}
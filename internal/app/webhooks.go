@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"goadmin/internal/audit"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/webhook"
+)
+
+// webhookEndpoints переводит секцию webhooks в получателей. Заданный, но
+// пустой секрет - ошибка: получатель ждет подписанные запросы.
+func webhookEndpoints(cfg config.Config) ([]webhook.EndpointConfig, error) {
+	wc := cfg.Webhooks
+	out := make([]webhook.EndpointConfig, 0, len(wc.Endpoints))
+	for i, ep := range wc.Endpoints {
+		name := ep.Name
+		if name == "" {
+			name = fmt.Sprintf("webhook-%d", i)
+		}
+		secret, err := webhookSecret(ep)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %s: %w", name, err)
+		}
+		ec := webhook.EndpointConfig{
+			Name:        name,
+			URL:         ep.URL,
+			Format:      ep.Format,
+			Events:      ep.Events,
+			Secret:      secret,
+			Headers:     ep.Headers,
+			ExternalURL: ep.ExternalURL,
+			QueueSize:   wc.QueueSize,
+			MaxAttempts: wc.MaxAttempts,
+			Backoff:     time.Duration(wc.BackoffMS) * time.Millisecond,
+			MaxBackoff:  time.Duration(wc.MaxBackoffMS) * time.Millisecond,
+			Timeout:     time.Duration(wc.TimeoutMS) * time.Millisecond,
+		}
+		if ep.MaxAttempts > 0 {
+			ec.MaxAttempts = ep.MaxAttempts
+		}
+		if ep.TimeoutMS > 0 {
+			ec.Timeout = time.Duration(ep.TimeoutMS) * time.Millisecond
+		}
+		out = append(out, ec)
+	}
+	return out, nil
+}
+
+func webhookSecret(ep config.WebhookEndpoint) ([]byte, error) {
+	var secret string
+	switch {
+	case ep.SecretFile != "":
+		raw, err := os.ReadFile(ep.SecretFile) // #nosec G304 -- путь задается доверенным оператором.
+		if err != nil {
+			return nil, fmt.Errorf("read secret: %w", err)
+		}
+		secret = strings.TrimSpace(string(raw))
+	case ep.SecretEnv != "":
+		secret = strings.TrimSpace(os.Getenv(ep.SecretEnv))
+	default:
+		return nil, nil
+	}
+	if secret == "" {
+		return nil, errors.New("secret is empty")
+	}
+	return []byte(secret), nil
+}
+
+func buildWebhooks(cfg config.Config) (*webhook.Dispatcher, error) {
+	endpoints, err := webhookEndpoints(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return webhook.NewDispatcher(endpoints)
+}
+
+// webhookAuditOutput копирует аудит в webhook (command.executed, access.denied).
+func webhookAuditOutput(cfg config.Config, hooks *webhook.Dispatcher) audit.OutputConfig {
+	return audit.OutputConfig{
+		Destination: hooks.AuditDestination(),
+		Buffer:      cfg.Webhooks.QueueSize,
+		Policy:      audit.PolicyDropNewest,
+	}
+}
+
+// notifiers рассылает уведомление всем получателям и объединяет ошибки.
+type notifiers []core.Notifier
+
+func (ns notifiers) Notify(ctx context.Context, n core.Notification) error {
+	var errs []error
+	for _, nt := range ns {
+		if err := nt.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reload перечитывает конфиг (SIGHUP) и применяет секцию webhooks; остальные
+// секции применяются только перезапуском. Результат аудируется как
+// config:reload и публикуется событием config.reloaded.
+func (a *App) Reload(ctx context.Context, path string) error {
+	payload := map[string]interface{}{"path": path}
+	err := a.reloadWebhooks(path)
+	status := "ok"
+	if err != nil {
+		status = "error"
+		payload["error"] = err.Error()
+	} else {
+		payload["applied"] = []string{"webhooks"}
+	}
+	raw, _ := json.Marshal(payload)
+	if a.Audit != nil {
+		_ = a.Audit.Write(context.WithoutCancel(ctx), storage.AuditEvent{
+			Subject: "system",
+			Action:  "config:reload",
+			Source:  "serve",
+			Status:  status,
+			Payload: raw,
+		})
+	}
+	a.Webhooks.Publish(webhook.Event{
+		Type:    webhook.EventConfigReloaded,
+		Source:  "serve",
+		Subject: "system",
+		Status:  status,
+		Data:    json.RawMessage(raw),
+	})
+	return err
+}
+
+func (a *App) reloadWebhooks(path string) error {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	endpoints, err := webhookEndpoints(cfg)
+	if err != nil {
+		return err
+	}
+	if err := a.Webhooks.Update(endpoints); err != nil {
+		return err
+	}
+	a.Config.Webhooks = cfg.Webhooks
+	return nil
+}
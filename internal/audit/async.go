@@ -0,0 +1,408 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+var (
+	errQueueFull    = errors.New("audit queue is full")
+	errWriterClosed = errors.New("audit writer is closed")
+)
+
+// Состояния асинхронного writer для health-проверок.
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded"
+	HealthFailing  = "failing"
+)
+
+// AsyncConfig задает параметры асинхронной записи аудита.
+type AsyncConfig struct {
+	QueueSize     int
+	BatchSize     int
+	FlushInterval time.Duration
+	RetryInterval time.Duration
+	WriteTimeout  time.Duration
+	// FailClosed заставляет Write ждать, пока событие не попадет в хранилище или spool.
+	FailClosed bool
+	// Spool хранит события, которые не удалось записать; nil отключает spool.
+	Spool *Spool
+}
+
+// AsyncStats - счетчики асинхронного writer.
+type AsyncStats struct {
+	Status       string `json:"status"`
+	FailClosed   bool   `json:"fail_closed"`
+	Queued       int    `json:"queued"`
+	Written      uint64 `json:"written"`
+	Spooled      uint64 `json:"spooled"`
+	SpoolPending int    `json:"spool_pending"`
+	// SpoolCorrupt - строки spool, перенесенные в карантин как нечитаемые.
+	SpoolCorrupt int    `json:"spool_corrupt"`
+	Replayed     uint64 `json:"replayed"`
+	Failed       uint64 `json:"failed"`
+	Dropped      uint64 `json:"dropped"`
+	MaxDelayMS   int64  `json:"max_delay_ms"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+type pendingEvent struct {
+	ev       storage.AuditEvent
+	enqueued time.Time
+	done     chan error
+}
+
+// AsyncWriter пишет аудит пачками в фоне. При недоступности хранилища
+// события уходят в spool на диске и дописываются позже.
+type AsyncWriter struct {
+	primary storage.AuditWriter
+	cfg     AsyncConfig
+	queue   chan *pendingEvent
+	stop    chan struct{}
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	written     atomic.Uint64
+	spooled     atomic.Uint64
+	replayed    atomic.Uint64
+	failed      atomic.Uint64
+	dropped     atomic.Uint64
+	maxDelay    atomic.Int64
+	primaryDown atomic.Bool
+	failing     atomic.Bool
+	lastErr     atomic.Value
+	onStored    atomic.Pointer[func([]storage.AuditEvent)]
+	// closeErr - итог остановки run: события, оставшиеся в spool, и ошибка
+	// его закрытия; читается после закрытия done.
+	closeErr error
+}
+
+// NewAsyncWriter создает writer и запускает фоновую запись.
+func NewAsyncWriter(primary storage.AuditWriter, cfg AsyncConfig) *AsyncWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 4096
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 128
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 200 * time.Millisecond
+	}
+	if cfg.RetryInterval <= 0 {
+		cfg.RetryInterval = 5 * time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 5 * time.Second
+	}
+	w := &AsyncWriter{
+		primary: primary,
+		cfg:     cfg,
+		queue:   make(chan *pendingEvent, cfg.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write ставит событие в очередь. При переполнении очереди событие сразу
+// уходит в spool. В режиме FailClosed Write возвращает nil только после того,
+// как событие сохранено в хранилище или spool.
+func (w *AsyncWriter) Write(ctx context.Context, ev storage.AuditEvent) error {
+	if ev.TS.IsZero() {
+		ev.TS = time.Now().UTC()
+	}
+	p := &pendingEvent{ev: ev, enqueued: time.Now()}
+	if w.cfg.FailClosed {
+		p.done = make(chan error, 1)
+	}
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		w.dropped.Add(1)
+		return errWriterClosed
+	}
+	select {
+	case w.queue <- p:
+		w.mu.RUnlock()
+	default:
+		// Под RLock: Close не закроет spool, пока идет запись в него.
+		err := w.overflow(ev)
+		w.mu.RUnlock()
+		return err
+	}
+
+	if p.done == nil {
+		return nil
+	}
+	select {
+	case err := <-p.done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("audit not confirmed: %w", ctx.Err())
+	}
+}
+
+func (w *AsyncWriter) overflow(ev storage.AuditEvent) error {
+	if w.cfg.Spool == nil {
+		w.dropped.Add(1)
+		w.setErr(errQueueFull)
+		return errQueueFull
+	}
+	if err := w.cfg.Spool.Append([]storage.AuditEvent{ev}); err != nil {
+		w.dropped.Add(1)
+		w.failing.Store(true)
+		w.setErr(err)
+		return fmt.Errorf("spool audit: %w", err)
+	}
+	w.spooled.Add(1)
+	return nil
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	flush := time.NewTicker(w.cfg.FlushInterval)
+	defer flush.Stop()
+	retry := time.NewTicker(w.cfg.RetryInterval)
+	defer retry.Stop()
+
+	batch := make([]*pendingEvent, 0, w.cfg.BatchSize)
+	for {
+		select {
+		case p := <-w.queue:
+			batch = append(batch, p)
+			if len(batch) >= w.cfg.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-flush.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-retry.C:
+			w.replay()
+		case <-w.stop:
+			for drained := false; !drained; {
+				select {
+				case p := <-w.queue:
+					batch = append(batch, p)
+				default:
+					drained = true
+				}
+			}
+			if len(batch) > 0 {
+				w.flush(batch)
+			}
+			w.replay()
+			w.closeErr = w.closeSpool()
+			return
+		}
+	}
+}
+
+// flush пишет пачку в хранилище; если в spool есть более ранние события,
+// сначала дописываются они, чтобы сохранить порядок.
+func (w *AsyncWriter) flush(batch []*pendingEvent) {
+	events := make([]storage.AuditEvent, 0, len(batch))
+	for _, p := range batch {
+		events = append(events, p.ev)
+	}
+
+	w.replay()
+	var (
+		written int
+		err     error
+	)
+	if w.cfg.Spool != nil && w.cfg.Spool.Pending() > 0 {
+		err = errors.New("spool replay pending")
+	} else {
+		written, err = w.writePrimary(events)
+	}
+	w.written.Add(uint64(written))
+
+	if err == nil {
+		w.primaryDown.Store(false)
+		w.failing.Store(false)
+		w.ack(batch, nil)
+		return
+	}
+
+	w.primaryDown.Store(true)
+	w.setErr(err)
+	rest := events[written:]
+	if w.cfg.Spool != nil {
+		spoolErr := w.cfg.Spool.Append(rest)
+		if spoolErr == nil {
+			w.spooled.Add(uint64(len(rest)))
+			w.failing.Store(false)
+			w.ack(batch, nil)
+			return
+		}
+		err = fmt.Errorf("%w; spool: %v", err, spoolErr)
+		w.setErr(err)
+	}
+	w.failed.Add(uint64(len(rest)))
+	w.failing.Store(true)
+	w.ack(batch[:written], nil)
+	w.ack(batch[written:], err)
+}
+
+func (w *AsyncWriter) ack(batch []*pendingEvent, err error) {
+	now := time.Now()
+	for _, p := range batch {
+		if d := now.Sub(p.enqueued).Milliseconds(); d > w.maxDelay.Load() {
+			w.maxDelay.Store(d)
+		}
+		if p.done != nil {
+			p.done <- err
+		}
+	}
+}
+
+func (w *AsyncWriter) replay() {
+	if w.cfg.Spool == nil || w.cfg.Spool.Pending() == 0 {
+		return
+	}
+	n, err := w.cfg.Spool.Replay(w.cfg.BatchSize, w.writePrimary)
+	w.replayed.Add(uint64(n))
+	if err != nil {
+		w.primaryDown.Store(true)
+		w.setErr(err)
+		return
+	}
+	w.primaryDown.Store(false)
+}
+
+// OnStored задает функцию, которая получает события после записи в
+// хранилище (с ID и hash, если хранилище - storage.AuditBatchWriter).
+// Вызывается из фоновой горутины writer.
+func (w *AsyncWriter) OnStored(fn func([]storage.AuditEvent)) {
+	w.onStored.Store(&fn)
+}
+
+// writePrimary возвращает число записанных событий: при поштучной записи
+// часть пачки может сохраниться до ошибки.
+func (w *AsyncWriter) writePrimary(events []storage.AuditEvent) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.cfg.WriteTimeout)
+	defer cancel()
+	if bw, ok := w.primary.(storage.AuditBatchWriter); ok {
+		// Копия: хранилище проставляет поля, а events может остаться для spool.
+		stored := append([]storage.AuditEvent(nil), events...)
+		if err := bw.SaveAuditBatch(ctx, stored); err != nil {
+			return 0, err
+		}
+		w.stored(stored)
+		return len(events), nil
+	}
+	for i, ev := range events {
+		if err := w.primary.Write(ctx, ev); err != nil {
+			w.stored(events[:i])
+			return i, err
+		}
+	}
+	w.stored(events)
+	return len(events), nil
+}
+
+func (w *AsyncWriter) stored(events []storage.AuditEvent) {
+	if fn := w.onStored.Load(); fn != nil && len(events) > 0 {
+		(*fn)(events)
+	}
+}
+
+func (w *AsyncWriter) setErr(err error) {
+	w.lastErr.Store(err.Error())
+}
+
+// Check возвращает ошибку, если события сейчас нельзя надежно сохранить.
+func (w *AsyncWriter) Check() error {
+	w.mu.RLock()
+	closed := w.closed
+	w.mu.RUnlock()
+	if closed {
+		return errWriterClosed
+	}
+	if w.failing.Load() {
+		lastErr, _ := w.lastErr.Load().(string)
+		return fmt.Errorf("audit persistence failing: %s", lastErr)
+	}
+	return nil
+}
+
+// Health возвращает ok, degraded (события задерживаются) или failing.
+func (w *AsyncWriter) Health() string {
+	if w.Check() != nil {
+		return HealthFailing
+	}
+	if w.primaryDown.Load() || (w.cfg.Spool != nil && w.cfg.Spool.Pending() > 0) {
+		return HealthDegraded
+	}
+	return HealthOK
+}
+
+// Stats возвращает счетчики writer.
+func (w *AsyncWriter) Stats() AsyncStats {
+	lastErr, _ := w.lastErr.Load().(string)
+	st := AsyncStats{
+		Status:     w.Health(),
+		FailClosed: w.cfg.FailClosed,
+		Queued:     len(w.queue),
+		Written:    w.written.Load(),
+		Spooled:    w.spooled.Load(),
+		Replayed:   w.replayed.Load(),
+		Failed:     w.failed.Load(),
+		Dropped:    w.dropped.Load(),
+		MaxDelayMS: w.maxDelay.Load(),
+		LastError:  lastErr,
+	}
+	if w.cfg.Spool != nil {
+		st.SpoolPending = w.cfg.Spool.Pending()
+		st.SpoolCorrupt = w.cfg.Spool.Quarantined()
+	}
+	return st
+}
+
+// Close дописывает очередь (до дедлайна ctx). Spool закрывает фоновая
+// горутина после последнего replay: по истечении ctx она еще может писать в него.
+func (w *AsyncWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stop)
+	select {
+	case <-w.done:
+		return w.closeErr
+	case <-ctx.Done():
+		return fmt.Errorf("audit writer close: %w", ctx.Err())
+	}
+}
+
+func (w *AsyncWriter) closeSpool() error {
+	if w.cfg.Spool == nil {
+		return nil
+	}
+	var err error
+	if n := w.cfg.Spool.Pending(); n > 0 {
+		err = fmt.Errorf("audit writer close: %d events left in spool", n)
+	}
+	if closeErr := w.cfg.Spool.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,256 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+type flakyStore struct {
+	mu     sync.Mutex
+	down   bool
+	events []storage.AuditEvent
+}
+
+func (s *flakyStore) Write(ctx context.Context, ev storage.AuditEvent) error {
+	return s.SaveAuditBatch(ctx, []storage.AuditEvent{ev})
+}
+
+func (s *flakyStore) SaveAuditBatch(ctx context.Context, events []storage.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("database is locked")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *flakyStore) setDown(down bool) {
+	s.mu.Lock()
+	s.down = down
+	s.mu.Unlock()
+}
+
+func (s *flakyStore) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.events)
+}
+
+func TestAsyncWriterSpoolsAndReplays(t *testing.T) {
+	store := &flakyStore{down: true}
+	spool, err := OpenSpool(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	w := NewAsyncWriter(store, AsyncConfig{
+		FlushInterval: 5 * time.Millisecond,
+		RetryInterval: 10 * time.Millisecond,
+		FailClosed:    true,
+		Spool:         spool,
+	})
+
+	for _, id := range []string{"1", "2", "3"} {
+		if err := w.Write(context.Background(), storage.AuditEvent{RequestID: id}); err != nil {
+			t.Fatalf("write %s: %v", id, err)
+		}
+	}
+	if st := w.Stats(); st.Spooled != 3 || st.SpoolPending != 3 || st.Status != HealthDegraded {
+		t.Fatalf("unexpected stats while down: %#v", st)
+	}
+
+	store.setDown(false)
+	waitFor(t, func() bool { return store.count() == 3 })
+	if err := w.Write(context.Background(), storage.AuditEvent{RequestID: "4"}); err != nil {
+		t.Fatalf("write after recovery: %v", err)
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	for i, want := range []string{"1", "2", "3", "4"} {
+		if store.events[i].RequestID != want {
+			t.Fatalf("event %d = %s, want %s (order must be preserved)", i, store.events[i].RequestID, want)
+		}
+	}
+	if st := w.Stats(); st.Replayed != 3 || st.SpoolPending != 0 {
+		t.Fatalf("unexpected stats after replay: %#v", st)
+	}
+}
+
+func TestAsyncWriterSpoolSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	if err := spool.Append([]storage.AuditEvent{{RequestID: "a"}, {RequestID: "b"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	_ = spool.Close()
+
+	reopened, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen spool: %v", err)
+	}
+	if reopened.Pending() != 2 {
+		t.Fatalf("pending = %d, want 2", reopened.Pending())
+	}
+	store := &flakyStore{}
+	w := NewAsyncWriter(store, AsyncConfig{RetryInterval: 5 * time.Millisecond, Spool: reopened})
+	waitFor(t, func() bool { return store.count() == 2 })
+	_ = w.Close(context.Background())
+}
+
+func TestSpoolQuarantinesCorruptLines(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	if err := spool.Append([]storage.AuditEvent{{RequestID: "a"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	_ = spool.Close()
+	path := filepath.Join(dir, "audit.spool")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		t.Fatalf("open spool file: %v", err)
+	}
+	// Испорченная строка в середине и оборванная последняя строка.
+	_, _ = f.WriteString("{broken\n" + `{"RequestID":"b"}` + "\n" + `{"RequestID":"c`)
+	_ = f.Close()
+
+	reopened, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen spool: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Pending() != 2 || reopened.Quarantined() != 1 {
+		t.Fatalf("pending = %d, quarantined = %d", reopened.Pending(), reopened.Quarantined())
+	}
+	corrupt, err := os.ReadFile(path + ".corrupt")
+	if err != nil || string(corrupt) != "{broken\n" {
+		t.Fatalf("quarantine = %q, %v", corrupt, err)
+	}
+	// Новые события не склеиваются с оборванной строкой.
+	if err := reopened.Append([]storage.AuditEvent{{RequestID: "d"}}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	var got []string
+	if _, err := reopened.Replay(0, func(events []storage.AuditEvent) (int, error) {
+		for _, ev := range events {
+			got = append(got, ev.RequestID)
+		}
+		return len(events), nil
+	}); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if strings.Join(got, ",") != "a,b,d" {
+		t.Fatalf("replayed %v", got)
+	}
+}
+
+func TestAsyncWriterFailClosedWithoutSpool(t *testing.T) {
+	store := &flakyStore{down: true}
+	w := NewAsyncWriter(store, AsyncConfig{FlushInterval: time.Millisecond, FailClosed: true})
+	defer w.Close(context.Background())
+
+	if err := w.Write(context.Background(), storage.AuditEvent{}); err == nil {
+		t.Fatal("expected error when audit cannot be persisted")
+	}
+	if err := w.Check(); err == nil {
+		t.Fatal("expected Check to report failing writer")
+	}
+	if st := w.Stats(); st.Failed != 1 || st.Status != HealthFailing {
+		t.Fatalf("unexpected stats: %#v", st)
+	}
+
+	store.setDown(false)
+	if err := w.Write(context.Background(), storage.AuditEvent{}); err != nil {
+		t.Fatalf("write after recovery: %v", err)
+	}
+	if err := w.Check(); err != nil {
+		t.Fatalf("expected healthy writer, got %v", err)
+	}
+}
+
+func TestAsyncWriterOverflowGoesToSpool(t *testing.T) {
+	store := &flakyStore{}
+	spool, err := OpenSpool(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	w := NewAsyncWriter(store, AsyncConfig{QueueSize: 1, BatchSize: 1000, FlushInterval: time.Hour, RetryInterval: time.Hour, Spool: spool})
+	for i := 0; i < 5; i++ {
+		if err := w.Write(context.Background(), storage.AuditEvent{}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := w.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if store.count() != 5 {
+		t.Fatalf("stored = %d, want 5", store.count())
+	}
+	if st := w.Stats(); st.Dropped != 0 || st.Spooled == 0 {
+		t.Fatalf("unexpected stats: %#v", st)
+	}
+}
+
+type blockingStore struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Write(ctx context.Context, ev storage.AuditEvent) error {
+	return s.SaveAuditBatch(ctx, []storage.AuditEvent{ev})
+}
+
+func (s *blockingStore) SaveAuditBatch(ctx context.Context, events []storage.AuditEvent) error {
+	s.entered <- struct{}{}
+	<-s.release
+	return errors.New("database is locked")
+}
+
+func TestAsyncWriterCloseTimeoutKeepsSpoolOpen(t *testing.T) {
+	dir := t.TempDir()
+	spool, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	store := &blockingStore{entered: make(chan struct{}, 1), release: make(chan struct{})}
+	w := NewAsyncWriter(store, AsyncConfig{FlushInterval: time.Millisecond, RetryInterval: time.Hour, Spool: spool})
+	if err := w.Write(context.Background(), storage.AuditEvent{Action: "a"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	<-store.entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := w.Close(ctx); err == nil {
+		t.Fatal("expected close timeout")
+	}
+	// Хранилище отвечает после Close: событие должно попасть в еще открытый spool.
+	close(store.release)
+	<-w.done
+	if !strings.Contains(w.closeErr.Error(), "1 events left in spool") {
+		t.Fatalf("close error = %v", w.closeErr)
+	}
+	reopened, err := OpenSpool(dir, 0)
+	if err != nil {
+		t.Fatalf("reopen spool: %v", err)
+	}
+	defer reopened.Close()
+	if reopened.Pending() != 1 {
+		t.Fatalf("spool pending = %d, want 1", reopened.Pending())
+	}
+}
@@ -0,0 +1,207 @@
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Форматы экспорта аудита.
+const (
+	ExportNDJSON = "ndjson"
+	ExportCSV    = "csv"
+)
+
+var errUnknownExportFormat = errors.New("unknown export format")
+
+// csvHeader - колонки CSV-экспорта.
+var csvHeader = []string{"id", "ts", "subject", "action", "source", "status", "request_id", "payload", "prev_hash", "hash"}
+
+// ExportOptions задает формат и выборку экспорта.
+type ExportOptions struct {
+	Format string
+	Query  storage.AuditQuery
+	// OnPage вызывается перед чтением каждой страницы (например, чтобы продлить дедлайн записи).
+	OnPage func()
+}
+
+// ExportSummary - итоговая запись экспорта. SHA256 считается по байтам всех
+// строк событий до итоговой (без сжатия), From/To - крайние ts событий.
+type ExportSummary struct {
+	Format   string `json:"format"`
+	Count    int64  `json:"count"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	SHA256   string `json:"sha256"`
+	Complete bool   `json:"complete"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ValidExportFormat сообщает, поддерживается ли формат.
+func ValidExportFormat(format string) bool {
+	return format == ExportNDJSON || format == ExportCSV
+}
+
+// Export постранично выгружает события в w (в порядке /v1/audit: ts DESC, id DESC)
+// и дописывает итоговую запись. Память не зависит от объема выборки: в каждый момент держится одна страница.
+// При ошибке посреди выгрузки итог пишется с complete=false.
+func Export(ctx context.Context, pager storage.AuditPager, w io.Writer, opts ExportOptions) (ExportSummary, error) {
+	if !ValidExportFormat(opts.Format) {
+		return ExportSummary{}, fmt.Errorf("%w: %q", errUnknownExportFormat, opts.Format)
+	}
+	sum := sha256.New()
+	enc := newExportEncoder(opts.Format, io.MultiWriter(w, sum))
+	summary := ExportSummary{Format: opts.Format}
+	var minTS, maxTS time.Time
+
+	err := func() error {
+		if err := enc.header(); err != nil {
+			return err
+		}
+		q := opts.Query
+		q.Limit = storage.MaxAuditPageSize
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if opts.OnPage != nil {
+				opts.OnPage()
+			}
+			page, err := pager.QueryAuditPage(ctx, q)
+			if err != nil {
+				return err
+			}
+			for _, ev := range page.Events {
+				if err := enc.event(ev); err != nil {
+					return err
+				}
+				summary.Count++
+				if minTS.IsZero() || ev.TS.Before(minTS) {
+					minTS = ev.TS
+				}
+				if ev.TS.After(maxTS) {
+					maxTS = ev.TS
+				}
+			}
+			if page.NextCursor == "" {
+				return nil
+			}
+			q.Cursor = page.NextCursor
+		}
+	}()
+	if ferr := enc.flush(); ferr != nil && err == nil {
+		err = ferr
+	}
+
+	if !minTS.IsZero() {
+		summary.From = minTS.UTC().Format(time.RFC3339Nano)
+		summary.To = maxTS.UTC().Format(time.RFC3339Nano)
+	}
+	summary.SHA256 = hex.EncodeToString(sum.Sum(nil))
+	summary.Complete = err == nil
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	// Итоговая запись не входит в контрольную сумму.
+	enc.retarget(w)
+	if werr := enc.summary(summary); werr != nil && err == nil {
+		err = werr
+	}
+	if err != nil {
+		return summary, fmt.Errorf("export audit: %w", err)
+	}
+	return summary, nil
+}
+
+type exportEncoder struct {
+	format string
+	w      io.Writer
+	csv    *csv.Writer
+}
+
+func newExportEncoder(format string, w io.Writer) *exportEncoder {
+	e := &exportEncoder{format: format}
+	e.retarget(w)
+	return e
+}
+
+func (e *exportEncoder) retarget(w io.Writer) {
+	e.w = w
+	if e.format == ExportCSV {
+		e.csv = csv.NewWriter(w)
+	}
+}
+
+func (e *exportEncoder) header() error {
+	if e.csv == nil {
+		return nil
+	}
+	return e.csv.Write(csvHeader)
+}
+
+func (e *exportEncoder) event(ev storage.AuditEvent) error {
+	if e.csv == nil {
+		line, err := NewRecord(ev).Marshal()
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(append(line, '\n'))
+		return err
+	}
+	return e.csv.Write([]string{
+		strconv.FormatInt(ev.ID, 10),
+		ev.TS.UTC().Format(time.RFC3339Nano),
+		ev.Subject, ev.Action, ev.Source, ev.Status, ev.RequestID,
+		string(ev.Payload), ev.PrevHash, ev.Hash,
+	})
+}
+
+func (e *exportEncoder) flush() error {
+	if e.csv == nil {
+		return nil
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
+
+// summary пишет итог: в NDJSON - объект {"summary": ...}, в CSV - строку,
+// начинающуюся с "#summary", с полями key=value.
+func (e *exportEncoder) summary(s ExportSummary) error {
+	if e.csv == nil {
+		line, err := json.Marshal(map[string]ExportSummary{"summary": s})
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(append(line, '\n'))
+		return err
+	}
+	row := []string{
+		"#summary",
+		"count=" + strconv.FormatInt(s.Count, 10),
+		"from=" + s.From,
+		"to=" + s.To,
+		"sha256=" + s.SHA256,
+		"complete=" + strconv.FormatBool(s.Complete),
+	}
+	if s.Error != "" {
+		row = append(row, "error="+s.Error)
+	}
+	// Та же ширина, что у заголовка, чтобы строгие CSV-парсеры читали файл целиком.
+	for len(row) < len(csvHeader) {
+		row = append(row, "")
+	}
+	if err := e.csv.Write(row); err != nil {
+		return err
+	}
+	e.csv.Flush()
+	return e.csv.Error()
+}
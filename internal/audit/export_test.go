@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// pagedEvents отдает события страницами по pageSize, игнорируя Limit запроса.
+type pagedEvents struct {
+	events   []storage.AuditEvent
+	pageSize int
+	failAt   int
+	calls    int
+}
+
+func (p *pagedEvents) QueryAuditPage(ctx context.Context, q storage.AuditQuery) (storage.AuditPage, error) {
+	p.calls++
+	if p.failAt > 0 && p.calls == p.failAt {
+		return storage.AuditPage{}, errors.New("database is locked")
+	}
+	start := 0
+	if q.Cursor != "" {
+		_, id, err := storage.DecodeAuditCursor(q.Cursor)
+		if err != nil {
+			return storage.AuditPage{}, err
+		}
+		start = int(id)
+	}
+	end := start + p.pageSize
+	if end >= len(p.events) {
+		return storage.AuditPage{Events: p.events[start:]}, nil
+	}
+	return storage.AuditPage{
+		Events:     p.events[start:end],
+		NextCursor: storage.EncodeAuditCursor(time.Time{}, int64(end)),
+	}, nil
+}
+
+func exportEvents(n int) []storage.AuditEvent {
+	base := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	events := make([]storage.AuditEvent, n)
+	for i := range events {
+		events[i] = storage.AuditEvent{
+			ID:      int64(n - i),
+			Subject: "u1",
+			Action:  "host:status",
+			Source:  "web",
+			Status:  "ok",
+			Payload: []byte(`{"note":"a,\"b\"\nc"}`),
+			TS:      base.Add(time.Duration(n-i) * time.Minute),
+		}
+	}
+	return events
+}
+
+func splitSummary(t *testing.T, data []byte) ([]byte, []byte) {
+	t.Helper()
+	trimmed := bytes.TrimRight(data, "\n")
+	idx := bytes.LastIndexByte(trimmed, '\n')
+	if idx < 0 {
+		t.Fatalf("no summary line in %q", data)
+	}
+	return data[:idx+1], trimmed[idx+1:]
+}
+
+func TestExportNDJSON(t *testing.T) {
+	pager := &pagedEvents{events: exportEvents(7), pageSize: 3}
+	var buf bytes.Buffer
+	summary, err := Export(context.Background(), pager, &buf, ExportOptions{Format: ExportNDJSON})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if pager.calls != 3 || summary.Count != 7 || !summary.Complete {
+		t.Fatalf("unexpected summary %#v after %d pages", summary, pager.calls)
+	}
+	if summary.From != "2026-05-01T00:01:00Z" || summary.To != "2026-05-01T00:07:00Z" {
+		t.Fatalf("unexpected range: %s..%s", summary.From, summary.To)
+	}
+
+	body, last := splitSummary(t, buf.Bytes())
+	sum := sha256.Sum256(body)
+	if hex.EncodeToString(sum[:]) != summary.SHA256 {
+		t.Fatal("checksum does not match event lines")
+	}
+	var trailer struct {
+		Summary ExportSummary `json:"summary"`
+	}
+	if err := json.Unmarshal(last, &trailer); err != nil || trailer.Summary != summary {
+		t.Fatalf("bad trailer %s: %v", last, err)
+	}
+	if lines := strings.Count(string(body), "\n"); lines != 7 {
+		t.Fatalf("got %d event lines, want 7", lines)
+	}
+}
+
+func TestExportCSV(t *testing.T) {
+	pager := &pagedEvents{events: exportEvents(4), pageSize: 10}
+	var buf bytes.Buffer
+	summary, err := Export(context.Background(), pager, &buf, ExportOptions{Format: ExportCSV})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	// Заголовок, 4 события и итог; у итоговой строки другое число полей.
+	if len(records) != 6 || records[0][0] != "id" || records[5][0] != "#summary" {
+		t.Fatalf("unexpected csv layout: %#v", records)
+	}
+	if records[1][7] != `{"note":"a,\"b\"\nc"}` {
+		t.Fatalf("payload not preserved: %q", records[1][7])
+	}
+	if records[5][4] != "sha256="+summary.SHA256 {
+		t.Fatalf("summary row mismatch: %#v", records[5])
+	}
+}
+
+func TestExportReportsIncompleteOnError(t *testing.T) {
+	pager := &pagedEvents{events: exportEvents(5), pageSize: 2, failAt: 2}
+	var buf bytes.Buffer
+	summary, err := Export(context.Background(), pager, &buf, ExportOptions{Format: ExportNDJSON})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if summary.Complete || summary.Count != 2 || summary.Error == "" {
+		t.Fatalf("unexpected summary: %#v", summary)
+	}
+	if !strings.Contains(buf.String(), `"complete":false`) {
+		t.Fatalf("trailer must mark export incomplete: %s", buf.String())
+	}
+	if _, err := Export(context.Background(), pager, &buf, ExportOptions{Format: "xml"}); err == nil {
+		t.Fatal("expected unknown format error")
+	}
+}
+
+func TestExportNDJSONKeepsPayloadBytesForHash(t *testing.T) {
+	ev := storage.AuditEvent{
+		ID:      1,
+		Subject: "u1",
+		Action:  "host:status",
+		Source:  "web",
+		Status:  "ok",
+		Payload: []byte(`{"cmd": "a<b && c>d"}`),
+		TS:      time.Date(2026, 5, 1, 0, 0, 0, 123, time.UTC),
+	}
+	ev.Hash = storage.AuditEventHash("", ev)
+	var buf bytes.Buffer
+	if _, err := Export(context.Background(), &pagedEvents{events: []storage.AuditEvent{ev}, pageSize: 10}, &buf, ExportOptions{Format: ExportNDJSON}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	body, _ := splitSummary(t, buf.Bytes())
+	var rec Record
+	if err := json.Unmarshal(bytes.TrimSpace(body), &rec); err != nil {
+		t.Fatalf("decode record: %v", err)
+	}
+	got, err := rec.Event()
+	if err != nil {
+		t.Fatalf("record event: %v", err)
+	}
+	if !bytes.Equal(got.Payload, ev.Payload) {
+		t.Fatalf("payload changed: %s", got.Payload)
+	}
+	if storage.AuditEventHash("", got) != ev.Hash {
+		t.Fatal("hash of exported record does not match")
+	}
+}
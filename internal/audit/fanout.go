@@ -0,0 +1,249 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"goadmin/internal/storage"
+)
+
+// Policy определяет поведение при переполнении буфера назначения.
+type Policy string
+
+const (
+	// PolicyBlock ждет освобождения места в буфере (в пределах ctx записи).
+	PolicyBlock Policy = "block"
+	// PolicyDropNewest отбрасывает новое событие.
+	PolicyDropNewest Policy = "drop_newest"
+	// PolicyDropOldest вытесняет самое старое событие из буфера.
+	PolicyDropOldest Policy = "drop_oldest"
+)
+
+var errUnknownPolicy = errors.New("unknown backpressure policy")
+
+// Destination - внешний получатель копии аудита.
+type Destination interface {
+	Name() string
+	Send(ctx context.Context, ev storage.AuditEvent) error
+	Close() error
+}
+
+// OutputConfig задает буфер и политику для одного назначения.
+type OutputConfig struct {
+	Destination Destination
+	Buffer      int
+	Policy      Policy
+}
+
+// OutputStats - счетчики доставки для одного назначения.
+type OutputStats struct {
+	Name      string `json:"name"`
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+	Dropped   uint64 `json:"dropped"`
+	Queued    int    `json:"queued"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type output struct {
+	dest   Destination
+	policy Policy
+	queue  chan storage.AuditEvent
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	lastErr   atomic.Value
+}
+
+// Fanout пишет аудит в основной writer синхронно и копирует сохраненное
+// событие (с ID, TS и hash) во внешние назначения через их собственные
+// ограниченные буферы. Если primary - AsyncWriter, копии уходят после записи
+// пачки в хранилище.
+type Fanout struct {
+	primary storage.AuditWriter
+	outputs []*output
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// mu защищает очереди от закрытия во время постановки события.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewFanout создает fan-out sink; primary может быть nil.
+func NewFanout(primary storage.AuditWriter, outputs ...OutputConfig) (*Fanout, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &Fanout{primary: primary, ctx: ctx, cancel: cancel}
+	for _, cfg := range outputs {
+		if cfg.Destination == nil {
+			cancel()
+			return nil, errors.New("audit destination is nil")
+		}
+		policy := cfg.Policy
+		if policy == "" {
+			policy = PolicyDropOldest
+		}
+		switch policy {
+		case PolicyBlock, PolicyDropNewest, PolicyDropOldest:
+		default:
+			cancel()
+			return nil, fmt.Errorf("%s: %w: %s", cfg.Destination.Name(), errUnknownPolicy, policy)
+		}
+		buffer := cfg.Buffer
+		if buffer <= 0 {
+			buffer = 1024
+		}
+		f.outputs = append(f.outputs, &output{
+			dest:   cfg.Destination,
+			policy: policy,
+			queue:  make(chan storage.AuditEvent, buffer),
+		})
+	}
+	for _, out := range f.outputs {
+		f.wg.Add(1)
+		go f.run(out)
+	}
+	if aw, ok := primary.(*AsyncWriter); ok {
+		aw.OnStored(func(events []storage.AuditEvent) {
+			f.publish(f.ctx, events...)
+		})
+	}
+	return f, nil
+}
+
+// Write сохраняет событие в primary и ставит копии в очереди назначений.
+// Ошибка возвращается только от primary: внешние назначения учитываются в Stats.
+// Если primary не сохранил событие, назначения получают его без ID и hash.
+func (f *Fanout) Write(ctx context.Context, ev storage.AuditEvent) error {
+	switch primary := f.primary.(type) {
+	case nil:
+	case *AsyncWriter:
+		// Копии отправит OnStored после записи в хранилище.
+		return primary.Write(ctx, ev)
+	case storage.AuditBatchWriter:
+		stored := []storage.AuditEvent{ev}
+		err := primary.SaveAuditBatch(ctx, stored)
+		f.publish(ctx, stored[0])
+		return err
+	default:
+		err := primary.Write(ctx, ev)
+		f.publish(ctx, ev)
+		return err
+	}
+	f.publish(ctx, ev)
+	return nil
+}
+
+func (f *Fanout) publish(ctx context.Context, events ...storage.AuditEvent) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.closed {
+		return
+	}
+	for _, ev := range events {
+		for _, out := range f.outputs {
+			out.enqueue(ctx, ev)
+		}
+	}
+}
+
+func (o *output) enqueue(ctx context.Context, ev storage.AuditEvent) {
+	select {
+	case o.queue <- ev:
+		return
+	default:
+	}
+	switch o.policy {
+	case PolicyBlock:
+		select {
+		case o.queue <- ev:
+		case <-ctx.Done():
+			o.dropped.Add(1)
+		}
+	case PolicyDropOldest:
+		for {
+			select {
+			case <-o.queue:
+				o.dropped.Add(1)
+			default:
+			}
+			select {
+			case o.queue <- ev:
+				return
+			default:
+			}
+		}
+	default:
+		o.dropped.Add(1)
+	}
+}
+
+func (f *Fanout) run(out *output) {
+	defer f.wg.Done()
+	for ev := range out.queue {
+		if err := out.dest.Send(f.ctx, ev); err != nil {
+			out.failed.Add(1)
+			out.lastErr.Store(err.Error())
+			continue
+		}
+		out.delivered.Add(1)
+	}
+}
+
+// Stats возвращает счетчики по всем назначениям.
+func (f *Fanout) Stats() []OutputStats {
+	stats := make([]OutputStats, 0, len(f.outputs))
+	for _, out := range f.outputs {
+		lastErr, _ := out.lastErr.Load().(string)
+		stats = append(stats, OutputStats{
+			Name:      out.dest.Name(),
+			Delivered: out.delivered.Load(),
+			Failed:    out.failed.Load(),
+			Dropped:   out.dropped.Load(),
+			Queued:    len(out.queue),
+			LastError: lastErr,
+		})
+	}
+	return stats
+}
+
+// Close дожидается отправки буферов до дедлайна ctx и закрывает назначения.
+func (f *Fanout) Close(ctx context.Context) error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	for _, out := range f.outputs {
+		close(out.queue)
+	}
+	f.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		f.cancel()
+		<-done
+	}
+	f.cancel()
+
+	var errs []error
+	for _, out := range f.outputs {
+		if err := out.dest.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", out.dest.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
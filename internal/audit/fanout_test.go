@@ -0,0 +1,203 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+)
+
+type fakeDestination struct {
+	mu      sync.Mutex
+	name    string
+	sendErr error
+	gate    chan struct{}
+	got     []storage.AuditEvent
+}
+
+func (d *fakeDestination) Name() string { return d.name }
+
+func (d *fakeDestination) Send(ctx context.Context, ev storage.AuditEvent) error {
+	if d.gate != nil {
+		<-d.gate
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.sendErr != nil {
+		return d.sendErr
+	}
+	d.got = append(d.got, ev)
+	return nil
+}
+
+func (d *fakeDestination) Close() error { return nil }
+
+type fakeWriter struct {
+	err   error
+	count int
+}
+
+func (w *fakeWriter) Write(ctx context.Context, ev storage.AuditEvent) error {
+	w.count++
+	return w.err
+}
+
+func TestFanoutDeliversAndCounts(t *testing.T) {
+	primary := &fakeWriter{}
+	ok := &fakeDestination{name: "ok"}
+	bad := &fakeDestination{name: "bad", sendErr: errors.New("boom")}
+	f, err := NewFanout(primary, OutputConfig{Destination: ok}, OutputConfig{Destination: bad})
+	if err != nil {
+		t.Fatalf("new fanout: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := f.Write(context.Background(), storage.AuditEvent{Action: "host:status"}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	if err := f.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if primary.count != 3 {
+		t.Fatalf("primary writes = %d, want 3", primary.count)
+	}
+	stats := f.Stats()
+	if stats[0].Delivered != 3 || stats[1].Failed != 3 || stats[1].LastError == "" {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+}
+
+func TestFanoutReturnsPrimaryError(t *testing.T) {
+	f, err := NewFanout(&fakeWriter{err: errors.New("disk full")})
+	if err != nil {
+		t.Fatalf("new fanout: %v", err)
+	}
+	defer f.Close(context.Background())
+	if err := f.Write(context.Background(), storage.AuditEvent{}); err == nil {
+		t.Fatal("expected primary error")
+	}
+}
+
+func TestFanoutBackpressurePolicies(t *testing.T) {
+	cases := []struct {
+		policy  Policy
+		dropped uint64
+	}{
+		{PolicyDropNewest, 2},
+		{PolicyDropOldest, 2},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			dest := &fakeDestination{name: "slow", gate: make(chan struct{})}
+			f, err := NewFanout(nil, OutputConfig{Destination: dest, Buffer: 1, Policy: tc.policy})
+			if err != nil {
+				t.Fatalf("new fanout: %v", err)
+			}
+			// Первое событие забирает воркер и зависает на gate, второе занимает буфер.
+			_ = f.Write(context.Background(), storage.AuditEvent{RequestID: "1"})
+			waitFor(t, func() bool { return f.Stats()[0].Queued == 0 })
+			for _, id := range []string{"2", "3", "4"} {
+				_ = f.Write(context.Background(), storage.AuditEvent{RequestID: id})
+			}
+			if got := f.Stats()[0].Dropped; got != tc.dropped {
+				t.Fatalf("dropped = %d, want %d", got, tc.dropped)
+			}
+			close(dest.gate)
+			_ = f.Close(context.Background())
+
+			last := dest.got[len(dest.got)-1].RequestID
+			want := "2"
+			if tc.policy == PolicyDropOldest {
+				want = "4"
+			}
+			if last != want {
+				t.Fatalf("last delivered = %s, want %s", last, want)
+			}
+		})
+	}
+}
+
+func TestFanoutBlockPolicyRespectsContext(t *testing.T) {
+	dest := &fakeDestination{name: "slow", gate: make(chan struct{})}
+	f, err := NewFanout(nil, OutputConfig{Destination: dest, Buffer: 1, Policy: PolicyBlock})
+	if err != nil {
+		t.Fatalf("new fanout: %v", err)
+	}
+	_ = f.Write(context.Background(), storage.AuditEvent{})
+	waitFor(t, func() bool { return f.Stats()[0].Queued == 0 })
+	_ = f.Write(context.Background(), storage.AuditEvent{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_ = f.Write(ctx, storage.AuditEvent{})
+	if got := f.Stats()[0].Dropped; got != 1 {
+		t.Fatalf("dropped = %d, want 1", got)
+	}
+	close(dest.gate)
+	_ = f.Close(context.Background())
+}
+
+func TestFanoutSendsStoredEvent(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		async bool
+	}{{"sync", false}, {"async", true}} {
+		t.Run(tc.name, func(t *testing.T) {
+			st := memory.New()
+			var primary storage.AuditWriter = st
+			var aw *AsyncWriter
+			if tc.async {
+				aw = NewAsyncWriter(st, AsyncConfig{FlushInterval: time.Millisecond})
+				primary = aw
+			}
+			dest := &fakeDestination{name: "copy"}
+			f, err := NewFanout(primary, OutputConfig{Destination: dest})
+			if err != nil {
+				t.Fatalf("new fanout: %v", err)
+			}
+			for _, action := range []string{"host:status", "host:uptime"} {
+				if err := f.Write(context.Background(), storage.AuditEvent{Action: action}); err != nil {
+					t.Fatalf("write: %v", err)
+				}
+			}
+			if aw != nil {
+				if err := aw.Close(context.Background()); err != nil {
+					t.Fatalf("close async: %v", err)
+				}
+			}
+			_ = f.Close(context.Background())
+
+			stored, err := st.QueryAudit(context.Background(), storage.AuditQuery{})
+			if err != nil || len(stored) != 2 || len(dest.got) != 2 {
+				t.Fatalf("stored %d (%v), delivered %d", len(stored), err, len(dest.got))
+			}
+			for i, ev := range dest.got {
+				want := stored[len(stored)-1-i]
+				if ev.ID != want.ID || ev.Hash != want.Hash || ev.PrevHash != want.PrevHash || !ev.TS.Equal(want.TS) {
+					t.Fatalf("delivered %#v, stored %#v", ev, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFanoutRejectsUnknownPolicy(t *testing.T) {
+	if _, err := NewFanout(nil, OutputConfig{Destination: &fakeDestination{name: "x"}, Policy: "bogus"}); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"goadmin/internal/storage"
+)
+
+// FileConfig задает NDJSON-файл с ротацией по размеру.
+type FileConfig struct {
+	Name       string
+	Path       string
+	MaxBytes   int64
+	MaxBackups int
+}
+
+// File пишет события построчно в NDJSON и ротирует файл при превышении MaxBytes.
+type File struct {
+	cfg FileConfig
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewFile открывает (или создает) файл назначения.
+func NewFile(cfg FileConfig) (*File, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("audit file path is empty")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "file"
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = 100 << 20
+	}
+	if cfg.MaxBackups < 0 {
+		cfg.MaxBackups = 0
+	}
+	out := &File{cfg: cfg}
+	if err := out.open(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (f *File) Name() string { return f.cfg.Name }
+
+func (f *File) open() error {
+	fh, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- путь задается оператором.
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	st, err := fh.Stat()
+	if err != nil {
+		_ = fh.Close()
+		return fmt.Errorf("stat audit file: %w", err)
+	}
+	f.f = fh
+	f.size = st.Size()
+	return nil
+}
+
+// Send дописывает событие одной строкой JSON.
+func (f *File) Send(ctx context.Context, ev storage.AuditEvent) error {
+	line, err := NewRecord(ev).Marshal()
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		if err := f.open(); err != nil {
+			return err
+		}
+	}
+	if f.size > 0 && f.size+int64(len(line)) > f.cfg.MaxBytes {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.f.Write(line)
+	f.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write audit file: %w", err)
+	}
+	return nil
+}
+
+// rotate сдвигает path.N -> path.N+1 и начинает новый файл.
+func (f *File) rotate() error {
+	if err := f.f.Close(); err != nil {
+		return fmt.Errorf("close audit file: %w", err)
+	}
+	f.f = nil
+	if f.cfg.MaxBackups == 0 {
+		if err := os.Remove(f.cfg.Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove audit file: %w", err)
+		}
+		return f.open()
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", f.cfg.Path, f.cfg.MaxBackups))
+	for i := f.cfg.MaxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", f.cfg.Path, i)
+		if _, err := os.Stat(src); err == nil {
+			if err := os.Rename(src, fmt.Sprintf("%s.%d", f.cfg.Path, i+1)); err != nil {
+				return fmt.Errorf("rotate audit file: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(f.cfg.Path, f.cfg.Path+".1"); err != nil {
+		return fmt.Errorf("rotate audit file: %w", err)
+	}
+	return f.open()
+}
+
+// Close закрывает текущий файл.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.f == nil {
+		return nil
+	}
+	err := f.f.Close()
+	f.f = nil
+	return err
+}
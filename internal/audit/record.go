@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Record - JSON-представление события для внешних назначений.
+type Record struct {
+	ID        int64           `json:"id,omitempty"`
+	TS        string          `json:"ts"`
+	Subject   string          `json:"subject"`
+	Action    string          `json:"action"`
+	Source    string          `json:"source"`
+	Status    string          `json:"status"`
+	RequestID string          `json:"request_id"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	// PayloadRaw - сохраненные байты payload (base64), по которым считается
+	// hash записи; Payload после JSON-кодирования может отличаться.
+	PayloadRaw []byte `json:"payload_raw,omitempty"`
+	PrevHash   string `json:"prev_hash,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+}
+
+// NewRecord переводит событие хранилища в Record.
+func NewRecord(ev storage.AuditEvent) Record {
+	ts := ev.TS
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	rec := Record{
+		ID:        ev.ID,
+		TS:        ts.UTC().Format(time.RFC3339Nano),
+		Subject:   ev.Subject,
+		Action:    ev.Action,
+		Source:    ev.Source,
+		Status:    ev.Status,
+		RequestID: ev.RequestID,
+		PrevHash:  ev.PrevHash,
+		Hash:      ev.Hash,
+	}
+	if len(ev.Payload) > 0 {
+		rec.PayloadRaw = ev.Payload
+		if json.Valid(ev.Payload) {
+			rec.Payload = json.RawMessage(ev.Payload)
+		}
+	}
+	return rec
+}
+
+// Event переводит Record обратно в событие хранилища (для проверки
+// выгрузки); payload берется из PayloadRaw, без него - из Payload.
+func (r Record) Event() (storage.AuditEvent, error) {
+	ts, err := time.Parse(time.RFC3339Nano, r.TS)
+	if err != nil {
+		return storage.AuditEvent{}, err
+	}
+	payload := r.PayloadRaw
+	if len(payload) == 0 {
+		payload = []byte(r.Payload)
+	}
+	return storage.AuditEvent{
+		ID:        r.ID,
+		TS:        ts.UTC(),
+		Subject:   r.Subject,
+		Action:    r.Action,
+		Source:    r.Source,
+		Status:    r.Status,
+		RequestID: r.RequestID,
+		Payload:   payload,
+		PrevHash:  r.PrevHash,
+		Hash:      r.Hash,
+	}, nil
+}
+
+// Marshal возвращает компактный JSON без перевода строки.
+func (r Record) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
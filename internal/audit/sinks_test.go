@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+func testEvent() storage.AuditEvent {
+	return storage.AuditEvent{
+		Subject:   `u"1`,
+		Action:    "host:status",
+		Source:    "telegram",
+		Status:    "denied",
+		RequestID: "r1",
+		Payload:   []byte(`{"module":"host"}`),
+		TS:        time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+func TestSyslogUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer pc.Close()
+
+	s, err := NewSyslog(SyslogConfig{Network: "udp", Address: pc.LocalAddr().String(), Facility: "auth"})
+	if err != nil {
+		t.Fatalf("new syslog: %v", err)
+	}
+	defer s.Close()
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	buf := make([]byte, 2048)
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	msg := string(buf[:n])
+	// auth(4)*8 + warning(4) = 36
+	if !strings.HasPrefix(msg, "<36>1 2026-01-02T03:04:05Z ") {
+		t.Fatalf("unexpected header: %q", msg)
+	}
+	if !strings.Contains(msg, `subject="u\"1"`) || !strings.HasSuffix(msg, `{"module":"host"}`) {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	got := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		prefix, _ := r.ReadString(' ')
+		got <- prefix
+	}()
+
+	s, err := NewSyslog(SyslogConfig{Network: "tcp", Address: ln.Addr().String()})
+	if err != nil {
+		t.Fatalf("new syslog: %v", err)
+	}
+	defer s.Close()
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	select {
+	case prefix := <-got:
+		if strings.TrimSpace(prefix) == "" || strings.HasPrefix(prefix, "<") {
+			t.Fatalf("expected octet count prefix, got %q", prefix)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestFileRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	f, err := NewFile(FileConfig{Path: path, MaxBytes: 200, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := f.Send(context.Background(), testEvent()); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	for _, p := range []string{path, path + ".1", path + ".2"} {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("read %s: %v", p, err)
+		}
+		var rec Record
+		line := strings.SplitN(string(data), "\n", 2)[0]
+		if err := json.Unmarshal([]byte(line), &rec); err != nil || rec.Action != "host:status" {
+			t.Fatalf("bad record in %s: %q", p, line)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected only 2 backups, stat .3: %v", err)
+	}
+}
+
+func TestWebhookRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var rec Record
+		if err := json.NewDecoder(r.Body).Decode(&rec); err != nil || rec.RequestID != "r1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	wh, err := NewWebhook(WebhookConfig{URL: srv.URL, Headers: map[string]string{"X-Token": "t"}, MaxRetries: 3, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("new webhook: %v", err)
+	}
+	if err := wh.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestWebhookDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	wh, err := NewWebhook(WebhookConfig{URL: srv.URL, MaxRetries: 3, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("new webhook: %v", err)
+	}
+	if err := wh.Send(context.Background(), testEvent()); err == nil {
+		t.Fatal("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("calls = %d, want 1", calls.Load())
+	}
+}
@@ -0,0 +1,299 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"goadmin/internal/storage"
+)
+
+var errSpoolFull = errors.New("audit spool is full")
+
+// Spool - append-only NDJSON файл для событий, которые не удалось сразу
+// записать в основное хранилище. Каждая запись синхронизируется на диск.
+// Нечитаемые строки в середине файла переносятся в audit.spool.corrupt;
+// оборванная последняя строка (сбой во время записи) отбрасывается.
+type Spool struct {
+	path     string
+	maxBytes int64
+
+	mu          sync.Mutex
+	f           *os.File
+	size        int64
+	pending     int
+	quarantined int
+}
+
+// OpenSpool открывает spool в каталоге dir и подсчитывает уже накопленные события.
+func OpenSpool(dir string, maxBytes int64) (*Spool, error) {
+	if dir == "" {
+		return nil, errors.New("audit spool dir is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create spool dir: %w", err)
+	}
+	if maxBytes <= 0 {
+		maxBytes = 256 << 20
+	}
+	s := &Spool{path: filepath.Join(dir, "audit.spool"), maxBytes: maxBytes}
+	events, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	s.pending = len(events)
+	if s.f == nil {
+		if err := s.open(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Spool) open() error {
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- путь задается оператором.
+	if err != nil {
+		return fmt.Errorf("open spool: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("stat spool: %w", err)
+	}
+	s.f = f
+	s.size = st.Size()
+	return nil
+}
+
+// Append дописывает события и делает fsync; при превышении лимита возвращает ошибку.
+func (s *Spool) Append(events []storage.AuditEvent) error {
+	var buf []byte
+	for _, ev := range events {
+		line, err := json.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("marshal spool event: %w", err)
+		}
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return errors.New("audit spool is closed")
+	}
+	if s.size+int64(len(buf)) > s.maxBytes {
+		return errSpoolFull
+	}
+	n, err := s.f.Write(buf)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("write spool: %w", err)
+	}
+	if err := s.f.Sync(); err != nil {
+		return fmt.Errorf("sync spool: %w", err)
+	}
+	s.pending += len(events)
+	return nil
+}
+
+// Quarantined возвращает число строк, перенесенных в audit.spool.corrupt.
+func (s *Spool) Quarantined() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.quarantined
+}
+
+// Pending возвращает число событий, ожидающих повторной записи.
+func (s *Spool) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending
+}
+
+// Replay передает накопленные события в write пачками по batch; write
+// возвращает число реально записанных событий. Записанные события удаляются
+// из spool, остальные остаются.
+func (s *Spool) Replay(batch int, write func([]storage.AuditEvent) (int, error)) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pending == 0 || s.f == nil {
+		return 0, nil
+	}
+	events, err := s.load()
+	if err != nil {
+		return 0, err
+	}
+	if batch <= 0 {
+		batch = len(events)
+	}
+
+	written := 0
+	var writeErr error
+	for written < len(events) {
+		end := written + batch
+		if end > len(events) {
+			end = len(events)
+		}
+		var n int
+		n, writeErr = write(events[written:end])
+		written += n
+		if writeErr != nil {
+			break
+		}
+	}
+	if written == 0 {
+		return 0, writeErr
+	}
+	if err := s.rewrite(events[written:]); err != nil {
+		return written, err
+	}
+	return written, writeErr
+}
+
+// rewrite атомарно заменяет spool оставшимися событиями.
+func (s *Spool) rewrite(rest []storage.AuditEvent) error {
+	tmp := s.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 -- путь задается оператором.
+	if err != nil {
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, ev := range rest {
+		if err := enc.Encode(ev); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("rewrite spool: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	if s.f != nil {
+		_ = s.f.Close()
+		s.f = nil
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("rewrite spool: %w", err)
+	}
+	s.pending = len(rest)
+	return s.open()
+}
+
+// load читает spool; если в нем есть нечитаемые строки, файл
+// переписывается без них, а строки из середины файла сохраняются в карантин.
+func (s *Spool) load() ([]storage.AuditEvent, error) {
+	events, corrupt, torn, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(corrupt) == 0 && !torn {
+		return events, nil
+	}
+	if len(corrupt) > 0 {
+		if err := s.quarantine(corrupt); err != nil {
+			return nil, err
+		}
+		slog.Error("audit spool: corrupt events moved to quarantine", "count", len(corrupt), "path", s.path+".corrupt")
+	}
+	if torn {
+		slog.Warn("audit spool: dropped truncated last line", "path", s.path)
+	}
+	if err := s.rewrite(events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// readAll возвращает события, нечитаемые строки из середины файла и
+// признак оборванной последней строки.
+func (s *Spool) readAll() ([]storage.AuditEvent, [][]byte, bool, error) {
+	f, err := os.Open(s.path) // #nosec G304 -- путь задается оператором.
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, false, nil
+		}
+		return nil, nil, false, fmt.Errorf("read spool: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		events  []storage.AuditEvent
+		corrupt [][]byte
+		last    []byte
+	)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64<<10), 16<<20)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if last != nil {
+			corrupt = append(corrupt, last)
+			last = nil
+		}
+		var ev storage.AuditEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			last = bytes.Clone(sc.Bytes())
+			continue
+		}
+		events = append(events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, false, fmt.Errorf("read spool: %w", err)
+	}
+	// Оборванная при сбое последняя строка не должна блокировать остальные события.
+	return events, corrupt, last != nil, nil
+}
+
+// quarantine дописывает нечитаемые строки в audit.spool.corrupt.
+func (s *Spool) quarantine(lines [][]byte) error {
+	f, err := os.OpenFile(s.path+".corrupt", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- путь задается оператором.
+	if err != nil {
+		return fmt.Errorf("quarantine spool: %w", err)
+	}
+	var buf []byte
+	for _, line := range lines {
+		buf = append(buf, line...)
+		buf = append(buf, '\n')
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("quarantine spool: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("quarantine spool: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("quarantine spool: %w", err)
+	}
+	s.quarantined += len(lines)
+	return nil
+}
+
+// Close закрывает файл spool; накопленные события остаются на диске.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.f == nil {
+		return nil
+	}
+	err := s.f.Close()
+	s.f = nil
+	return err
+}
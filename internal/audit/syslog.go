@@ -0,0 +1,168 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SyslogConfig задает назначение RFC 5424 syslog.
+type SyslogConfig struct {
+	Name     string
+	Network  string // udp|tcp|unix
+	Address  string
+	Facility string
+	AppName  string
+	Timeout  time.Duration
+}
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "daemon": 3, "auth": 4, "syslog": 5, "authpriv": 10,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// Syslog отправляет события в syslog в формате RFC 5424.
+type Syslog struct {
+	cfg      SyslogConfig
+	facility int
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog создает syslog-назначение; соединение открывается лениво.
+func NewSyslog(cfg SyslogConfig) (*Syslog, error) {
+	switch cfg.Network {
+	case "udp", "tcp", "unix":
+	case "":
+		cfg.Network = "udp"
+	default:
+		return nil, fmt.Errorf("syslog network %q is not supported", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("syslog address is empty")
+	}
+	if cfg.Facility == "" {
+		cfg.Facility = "authpriv"
+	}
+	facility, ok := syslogFacilities[cfg.Facility]
+	if !ok {
+		return nil, fmt.Errorf("syslog facility %q is not supported", cfg.Facility)
+	}
+	if cfg.AppName == "" {
+		cfg.AppName = "goadmin"
+	}
+	if cfg.Name == "" {
+		cfg.Name = "syslog"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Second
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &Syslog{cfg: cfg, facility: facility, hostname: hostname}, nil
+}
+
+func (s *Syslog) Name() string { return s.cfg.Name }
+
+// Send форматирует и отправляет событие; при ошибке соединение переоткрывается один раз.
+func (s *Syslog) Send(ctx context.Context, ev storage.AuditEvent) error {
+	msg := s.format(ev)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lastErr error
+	for attempt := 0; attempt < 2; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.conn == nil {
+			conn, err := s.dial(ctx)
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			s.conn = conn
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.cfg.Timeout))
+		if _, err := s.conn.Write(s.frame(msg)); err != nil {
+			_ = s.conn.Close()
+			s.conn = nil
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("syslog send: %w", lastErr)
+}
+
+func (s *Syslog) dial(ctx context.Context) (net.Conn, error) {
+	d := net.Dialer{Timeout: s.cfg.Timeout}
+	if s.cfg.Network == "unix" {
+		// /dev/log обычно datagram-сокет; stream используется как запасной вариант.
+		if conn, err := d.DialContext(ctx, "unixgram", s.cfg.Address); err == nil {
+			return conn, nil
+		}
+	}
+	return d.DialContext(ctx, s.cfg.Network, s.cfg.Address)
+}
+
+// frame добавляет octet-counting (RFC 6587) для stream-транспорта.
+func (s *Syslog) frame(msg []byte) []byte {
+	if s.cfg.Network != "tcp" {
+		return msg
+	}
+	return append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+}
+
+func (s *Syslog) format(ev storage.AuditEvent) []byte {
+	rec := NewRecord(ev)
+	body := "-"
+	if len(rec.Payload) > 0 {
+		body = string(rec.Payload)
+	}
+	pri := s.facility*8 + syslogSeverity(ev.Status)
+	sd := fmt.Sprintf(`[goadmin@32473 subject="%s" action="%s" source="%s" status="%s" request_id="%s"]`,
+		escapeSDParam(ev.Subject), escapeSDParam(ev.Action), escapeSDParam(ev.Source),
+		escapeSDParam(ev.Status), escapeSDParam(ev.RequestID))
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d audit %s %s", pri, rec.TS, s.hostname, s.cfg.AppName, os.Getpid(), sd, body)
+	return []byte(msg)
+}
+
+func syslogSeverity(status string) int {
+	switch status {
+	case "ok":
+		return 6 // informational
+	case "denied", "rate_limited":
+		return 4 // warning
+	default:
+		return 3 // error
+	}
+}
+
+func escapeSDParam(v string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+	return r.Replace(v)
+}
+
+// Close закрывает соединение с syslog.
+func (s *Syslog) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
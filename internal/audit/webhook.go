@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// WebhookConfig задает HTTP-назначение с повторами.
+type WebhookConfig struct {
+	Name       string
+	URL        string
+	Headers    map[string]string
+	Timeout    time.Duration
+	MaxRetries int
+	Backoff    time.Duration
+	Client     *http.Client
+}
+
+// Webhook отправляет каждое событие POST-запросом с JSON-телом.
+type Webhook struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhook создает webhook-назначение.
+func NewWebhook(cfg WebhookConfig) (*Webhook, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("audit webhook url is empty")
+	}
+	if cfg.Name == "" {
+		cfg.Name = "webhook"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 500 * time.Millisecond
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	return &Webhook{cfg: cfg, client: client}, nil
+}
+
+func (w *Webhook) Name() string { return w.cfg.Name }
+
+// Send отправляет событие; 5xx, 429 и сетевые ошибки повторяются с экспоненциальной паузой.
+func (w *Webhook) Send(ctx context.Context, ev storage.AuditEvent) error {
+	body, err := NewRecord(ev).Marshal()
+	if err != nil {
+		return err
+	}
+	backoff := w.cfg.Backoff
+	var lastErr error
+	for attempt := 0; attempt <= w.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		retry, err := w.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return fmt.Errorf("audit webhook: %w", lastErr)
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// Close у webhook ничего не держит.
+func (w *Webhook) Close() error {
+	w.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,222 @@
+// Package collector периодически выполняет команды модулей и сохраняет
+// результат в хранилище метрик.
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// pruneEvery ограничивает частоту очистки старых метрик одного коллектора.
+const pruneEvery = time.Hour
+
+var errDuplicate = errors.New("duplicate collector")
+
+// Executor выполняет команду модуля (реализуется core.Registry).
+type Executor interface {
+	Execute(ctx context.Context, module, cmd string, args []string) (core.Response, error)
+}
+
+// Spec описывает коллектор.
+type Spec struct {
+	Name    string
+	Module  string
+	Command string
+	Args    []string
+	// Metric - ключ записи в metrics (по умолчанию Module), по нему читает /v1/metrics/latest.
+	Metric   string
+	Schedule core.Schedule
+	Jitter   time.Duration
+	Timeout  time.Duration
+	// Retention - сколько хранить метрики коллектора; 0 - без очистки.
+	Retention time.Duration
+}
+
+// Stats - счетчики коллектора для /v1/collectors.
+type Stats struct {
+	Name           string     `json:"name"`
+	Module         string     `json:"module"`
+	Command        string     `json:"command"`
+	Metric         string     `json:"metric"`
+	Schedule       string     `json:"schedule"`
+	RetentionHours int64      `json:"retention_hours,omitempty"`
+	Runs           int64      `json:"runs"`
+	Failures       int64      `json:"failures"`
+	LastRun        *time.Time `json:"last_run,omitempty"`
+	LastSuccess    *time.Time `json:"last_success,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	LastDurationMS int64      `json:"last_duration_ms"`
+	MaxDurationMS  int64      `json:"max_duration_ms"`
+	AvgDurationMS  int64      `json:"avg_duration_ms"`
+	Pruned         int64      `json:"pruned"`
+}
+
+type collector struct {
+	spec       Spec
+	stats      Stats
+	total      time.Duration
+	lastPruned time.Time
+}
+
+// Manager хранит коллекторы и их статистику.
+type Manager struct {
+	exec  Executor
+	store storage.Store
+
+	mu         sync.Mutex
+	collectors []*collector
+	byName     map[string]*collector
+}
+
+// NewManager проверяет спецификации и создает менеджер.
+func NewManager(exec Executor, st storage.Store, specs []Spec) (*Manager, error) {
+	m := &Manager{exec: exec, store: st, byName: make(map[string]*collector, len(specs))}
+	metrics := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		if spec.Module == "" || spec.Command == "" {
+			return nil, fmt.Errorf("collector %q: module and command are required", spec.Name)
+		}
+		if spec.Name == "" {
+			spec.Name = spec.Module + "_" + spec.Command
+		}
+		if spec.Metric == "" {
+			spec.Metric = spec.Module
+		}
+		if spec.Schedule == nil {
+			return nil, fmt.Errorf("collector %q: schedule is required", spec.Name)
+		}
+		if _, ok := m.byName[spec.Name]; ok {
+			return nil, fmt.Errorf("%w: name %q", errDuplicate, spec.Name)
+		}
+		if other, ok := metrics[spec.Metric]; ok {
+			return nil, fmt.Errorf("%w: %q and %q both write metric %q, set metric explicitly", errDuplicate, other, spec.Name, spec.Metric)
+		}
+		metrics[spec.Metric] = spec.Name
+		c := &collector{spec: spec, stats: Stats{
+			Name:           spec.Name,
+			Module:         spec.Module,
+			Command:        spec.Command,
+			Metric:         spec.Metric,
+			Schedule:       fmt.Sprint(spec.Schedule),
+			RetentionHours: int64(spec.Retention / time.Hour),
+		}}
+		m.collectors = append(m.collectors, c)
+		m.byName[spec.Name] = c
+	}
+	return m, nil
+}
+
+// Jobs возвращает задачи планировщика для всех коллекторов.
+func (m *Manager) Jobs() []core.JobSpec {
+	jobs := make([]core.JobSpec, 0, len(m.collectors))
+	for _, c := range m.collectors {
+		name := c.spec.Name
+		jobs = append(jobs, core.JobSpec{
+			Name:     name,
+			Schedule: c.spec.Schedule,
+			Jitter:   c.spec.Jitter,
+			Timeout:  c.spec.Timeout,
+			Run:      func(ctx context.Context) error { return m.Collect(ctx, name) },
+		})
+	}
+	return jobs
+}
+
+// Collect выполняет коллектор один раз и сохраняет результат.
+func (m *Manager) Collect(ctx context.Context, name string) error {
+	c, ok := m.byName[name]
+	if !ok {
+		return fmt.Errorf("unknown collector %q", name)
+	}
+	started := time.Now()
+	err := m.collect(ctx, c.spec)
+	m.record(c, started, time.Since(started), err)
+	if err != nil {
+		return err
+	}
+	m.prune(ctx, c, started)
+	return nil
+}
+
+func (m *Manager) collect(ctx context.Context, spec Spec) error {
+	resp, err := m.exec.Execute(ctx, spec.Module, spec.Command, spec.Args)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", spec.Module, spec.Command, err)
+	}
+	if resp.Status != "" && resp.Status != "ok" {
+		return fmt.Errorf("%s %s: status %s %s", spec.Module, spec.Command, resp.Status, resp.ErrorCode)
+	}
+	payload, err := json.Marshal(resp.Data)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", spec.Name, err)
+	}
+	// Сохранение не должно обрываться таймаутом сбора.
+	if err := m.store.SaveMetric(context.WithoutCancel(ctx), storage.MetricRecord{Module: spec.Metric, Payload: payload}); err != nil {
+		return fmt.Errorf("save %s metric: %w", spec.Name, err)
+	}
+	return nil
+}
+
+func (m *Manager) record(c *collector, started time.Time, d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &c.stats
+	s.Runs++
+	s.LastRun = &started
+	s.LastDurationMS = d.Milliseconds()
+	if s.LastDurationMS > s.MaxDurationMS {
+		s.MaxDurationMS = s.LastDurationMS
+	}
+	c.total += d
+	s.AvgDurationMS = (c.total / time.Duration(s.Runs)).Milliseconds()
+	if err != nil {
+		s.Failures++
+		s.LastError = err.Error()
+		return
+	}
+	s.LastError = ""
+	s.LastSuccess = &started
+}
+
+// prune удаляет метрики старше Retention не чаще раза в pruneEvery.
+func (m *Manager) prune(ctx context.Context, c *collector, now time.Time) {
+	pruner, ok := m.store.(storage.MetricPruner)
+	if !ok || c.spec.Retention <= 0 {
+		return
+	}
+	m.mu.Lock()
+	due := now.Sub(c.lastPruned) >= pruneEvery
+	if due {
+		c.lastPruned = now
+	}
+	m.mu.Unlock()
+	if !due {
+		return
+	}
+	n, err := pruner.PruneMetrics(context.WithoutCancel(ctx), c.spec.Metric, now.Add(-c.spec.Retention))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		c.stats.LastError = fmt.Sprintf("prune: %v", err)
+		return
+	}
+	c.stats.Pruned += n
+}
+
+// Stats возвращает счетчики коллекторов в порядке конфигурации.
+func (m *Manager) Stats() []Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Stats, 0, len(m.collectors))
+	for _, c := range m.collectors {
+		out = append(out, c.stats)
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+)
+
+type fakeExec struct {
+	calls []string
+	err   error
+	resp  core.Response
+}
+
+func (f *fakeExec) Execute(_ context.Context, module, cmd string, args []string) (core.Response, error) {
+	f.calls = append(f.calls, module+" "+cmd)
+	return f.resp, f.err
+}
+
+func TestCollectSavesMetricAndStats(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	exec := &fakeExec{resp: core.Response{Status: "ok", Data: map[string]int{"load": 1}}}
+	m, err := NewManager(exec, st, []Spec{
+		{Module: "host", Command: "status", Schedule: core.Every(time.Minute), Retention: time.Hour},
+		{Name: "disk", Module: "host", Command: "disk", Metric: "host.disk", Schedule: core.Every(time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	jobs := m.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != "host_status" || jobs[1].Name != "disk" {
+		t.Fatalf("unexpected jobs: %#v", jobs)
+	}
+
+	if err := st.SaveMetric(ctx, storage.MetricRecord{Module: "host", Payload: []byte(`{}`), TS: time.Now().Add(-2 * time.Hour)}); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if err := jobs[0].Run(ctx); err != nil {
+		t.Fatalf("collect: %v", err)
+	}
+	rec, err := st.LatestMetric(ctx, "host")
+	if err != nil || string(rec.Payload) != `{"load":1}` {
+		t.Fatalf("latest: %v %s", err, rec.Payload)
+	}
+
+	exec.err = errors.New("boom")
+	if err := m.Collect(ctx, "disk"); err == nil {
+		t.Fatal("expected collect error")
+	}
+	exec.err = nil
+	exec.resp = core.Response{Status: "error", ErrorCode: "unsupported"}
+	if err := m.Collect(ctx, "disk"); err == nil {
+		t.Fatal("expected error for non-ok response")
+	}
+
+	stats := m.Stats()
+	if s := stats[0]; s.Runs != 1 || s.Failures != 0 || s.Pruned != 1 || s.LastSuccess == nil || s.Metric != "host" || s.RetentionHours != 1 {
+		t.Fatalf("unexpected host stats: %#v", s)
+	}
+	if s := stats[1]; s.Runs != 2 || s.Failures != 2 || s.LastError == "" || s.LastSuccess != nil {
+		t.Fatalf("unexpected disk stats: %#v", s)
+	}
+}
+
+func TestNewManagerValidates(t *testing.T) {
+	every := core.Every(time.Minute)
+	for name, specs := range map[string][]Spec{
+		"no command":       {{Module: "host", Schedule: every}},
+		"no schedule":      {{Module: "host", Command: "status"}},
+		"duplicate name":   {{Name: "a", Module: "host", Command: "status", Metric: "x", Schedule: every}, {Name: "a", Module: "host", Command: "disk", Metric: "y", Schedule: every}},
+		"duplicate metric": {{Module: "host", Command: "status", Schedule: every}, {Module: "host", Command: "disk", Schedule: every}},
+	} {
+		if _, err := NewManager(&fakeExec{}, memory.New(), specs); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -7,6 +7,12 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// Значения storage.backend.
+const (
+	StorageSQLite = "sqlite"
+	StorageMemory = "memory"
+)
+
 // Config описывает основные параметры агента.
 type Config struct {
 	Agent struct {
@@ -17,13 +23,214 @@ type Config struct {
 		ExecAllowlist []string            `yaml:"exec_allowlist"`
 		AuthAllowlist map[string][]string `yaml:"auth_allowlist"`
 	} `yaml:"security"`
+	Storage struct {
+		Backend string `yaml:"backend"`
+	} `yaml:"storage"`
 	SQLite struct {
 		Path          string `yaml:"path"`
+		Driver        string `yaml:"driver"`
 		RetentionDays int    `yaml:"retention_days"`
+		Encryption    struct {
+			Enabled     bool   `yaml:"enabled"`
+			KeyFile     string `yaml:"key_file"`
+			KeyEnv      string `yaml:"key_env"`
+			ActiveKeyID string `yaml:"active_key_id"`
+			// BackgroundRekey перешифровывает старые строки активным ключом после старта.
+			BackgroundRekey bool `yaml:"background_rekey"`
+		} `yaml:"encryption"`
 	} `yaml:"sqlite"`
+	Audit struct {
+		SigningKeyFile  string `yaml:"signing_key_file"`
+		SigningKeyID    string `yaml:"signing_key_id"`
+		CheckpointEvery int    `yaml:"checkpoint_every"`
+		FailClosed      bool   `yaml:"fail_closed"`
+		Async           struct {
+			Enabled         bool   `yaml:"enabled"`
+			QueueSize       int    `yaml:"queue_size"`
+			BatchSize       int    `yaml:"batch_size"`
+			FlushIntervalMS int    `yaml:"flush_interval_ms"`
+			SpoolDir        string `yaml:"spool_dir"`
+			SpoolMaxMB      int    `yaml:"spool_max_mb"`
+		} `yaml:"async"`
+		Sinks []struct {
+			Name   string `yaml:"name"`
+			Type   string `yaml:"type"`
+			Buffer int    `yaml:"buffer"`
+			Policy string `yaml:"policy"`
+			Syslog struct {
+				Network  string `yaml:"network"`
+				Address  string `yaml:"address"`
+				Facility string `yaml:"facility"`
+				AppName  string `yaml:"app_name"`
+			} `yaml:"syslog"`
+			File struct {
+				Path       string `yaml:"path"`
+				MaxSizeMB  int    `yaml:"max_size_mb"`
+				MaxBackups int    `yaml:"max_backups"`
+			} `yaml:"file"`
+			Webhook struct {
+				URL        string            `yaml:"url"`
+				Headers    map[string]string `yaml:"headers"`
+				TimeoutMS  int               `yaml:"timeout_ms"`
+				MaxRetries int               `yaml:"max_retries"`
+				BackoffMS  int               `yaml:"backoff_ms"`
+			} `yaml:"webhook"`
+		} `yaml:"sinks"`
+	} `yaml:"audit"`
+	Redaction struct {
+		Enabled  bool     `yaml:"enabled"`
+		Mask     string   `yaml:"mask"`
+		Fields   []string `yaml:"fields"`
+		Patterns []struct {
+			Name  string `yaml:"name"`
+			Regex string `yaml:"regex"`
+		} `yaml:"patterns"`
+		Commands []struct {
+			Command string   `yaml:"command"`
+			Args    []int    `yaml:"args"`
+			Keys    []string `yaml:"keys"`
+		} `yaml:"commands"`
+	} `yaml:"redaction"`
 	Scheduler struct {
 		IntervalSeconds int `yaml:"interval_seconds"`
+		// Jobs переопределяет расписание встроенных задач по имени.
+		Jobs []struct {
+			Name      string `yaml:"name"`
+			Schedule  string `yaml:"schedule"`
+			JitterMS  int    `yaml:"jitter_ms"`
+			TimeoutMS int    `yaml:"timeout_ms"`
+			Overlap   string `yaml:"overlap"`
+			Disabled  bool   `yaml:"disabled"`
+		} `yaml:"jobs"`
 	} `yaml:"scheduler"`
+	Collectors []Collector `yaml:"collectors"`
+	Alerts     struct {
+		EvalIntervalSeconds int `yaml:"eval_interval_seconds"`
+		// StaleAfterSeconds - метрика старше не вычисляется (алерт не разрешается по устаревшим данным).
+		StaleAfterSeconds int `yaml:"stale_after_seconds"`
+		// Notify - транспорты для уведомлений; пустой список - все, которые умеют отправлять.
+		Notify []string    `yaml:"notify"`
+		Rules  []AlertRule `yaml:"rules"`
+	} `yaml:"alerts"`
+	Transports struct {
+		Telegram struct {
+			// Токен бота читается из token_file или переменной token_env; без токена отправка выключена.
+			TokenFile string `yaml:"token_file"`
+			TokenEnv  string `yaml:"token_env"`
+			APIURL    string `yaml:"api_url"`
+			// Polling - прием команд через getUpdates (long polling); нужен токен.
+			Polling            bool `yaml:"polling"`
+			PollTimeoutSeconds int  `yaml:"poll_timeout_seconds"`
+			// MaxMessageAgeSeconds - сообщения старше не исполняются (0 - без ограничения).
+			MaxMessageAgeSeconds int `yaml:"max_message_age_seconds"`
+			// ParseMode - разметка ответов: HTML, MarkdownV2 или plain.
+			ParseMode string `yaml:"parse_mode"`
+		} `yaml:"telegram"`
+		MaxBot struct {
+			// Токен бота читается из token_file или token_env; без токена отправка выключена.
+			TokenFile string `yaml:"token_file"`
+			TokenEnv  string `yaml:"token_env"`
+			APIURL    string `yaml:"api_url"`
+			// WebhookListenAddr - адрес входящего webhook; пусто - прием команд выключен.
+			WebhookListenAddr string `yaml:"webhook_listen_addr"`
+			WebhookPath       string `yaml:"webhook_path"`
+			// Секрет подписки (заголовок X-Max-Bot-Api-Secret) обязателен для webhook.
+			WebhookSecretFile string `yaml:"webhook_secret_file"`
+			WebhookSecretEnv  string `yaml:"webhook_secret_env"`
+			// ReplayWindowSeconds - допустимый возраст события и срок памяти о повторах.
+			ReplayWindowSeconds int `yaml:"replay_window_seconds"`
+			// Format - разметка ответов: html, markdown или plain.
+			Format string `yaml:"format"`
+		} `yaml:"maxbot"`
+		// Mattermost - slash-команды и outgoing webhooks Mattermost/Slack.
+		Mattermost struct {
+			// ListenAddr - адрес приема команд; пусто - транспорт выключен.
+			ListenAddr string `yaml:"listen_addr"`
+			Path       string `yaml:"path"`
+			// Токены slash-команд и outgoing webhooks (через запятую или по строке в файле).
+			TokenFile string `yaml:"token_file"`
+			TokenEnv  string `yaml:"token_env"`
+			// Signing secret Slack; нужен токен или секрет.
+			SigningSecretFile string `yaml:"signing_secret_file"`
+			SigningSecretEnv  string `yaml:"signing_secret_env"`
+			// Command - slash-команда вида "/goadmin host status".
+			Command string `yaml:"command"`
+			// Users - ID пользователя рабочего пространства -> субъект auth_allowlist.mattermost.
+			Users map[string]string `yaml:"users"`
+			// Teams ограничивает team_id; пусто - любые.
+			Teams []string `yaml:"teams"`
+			// ImmediateTimeoutMS - дольше ответ отправляется в response_url.
+			ImmediateTimeoutMS int    `yaml:"immediate_timeout_ms"`
+			ResponseType       string `yaml:"response_type"`
+			// ResponseHosts ограничивает хосты response_url; пусто - любые.
+			ResponseHosts []string `yaml:"response_hosts"`
+			// Format - разметка ответов: markdown или plain.
+			Format string `yaml:"format"`
+		} `yaml:"mattermost"`
+		// GRPC - gRPC API goadmin.v1.Admin с health и reflection; bearer-токены
+		// берутся из web.auth.tokens.
+		GRPC struct {
+			// ListenAddr - адрес сервера; пусто - транспорт выключен.
+			ListenAddr string `yaml:"listen_addr"`
+			// TLS - сертификат сервера; client_ca_file включает проверку
+			// клиентских сертификатов (mTLS).
+			TLS struct {
+				CertFile     string `yaml:"cert_file"`
+				KeyFile      string `yaml:"key_file"`
+				ClientCAFile string `yaml:"client_ca_file"`
+				// RequireClientCert - без сертификата соединение отклоняется
+				// (иначе достаточно bearer-токена).
+				RequireClientCert bool `yaml:"require_client_cert"`
+			} `yaml:"tls"`
+			// ClientSubjects - CN клиентского сертификата -> субъект auth_allowlist.grpc.
+			ClientSubjects   map[string]string `yaml:"client_subjects"`
+			RequestTimeoutMS int               `yaml:"request_timeout_ms"`
+			// StreamIntervalMS - период опроса состояния задачи в StreamJob.
+			StreamIntervalMS int  `yaml:"stream_interval_ms"`
+			MaxRecvBytes     int  `yaml:"max_recv_bytes"`
+			Reflection       bool `yaml:"reflection"`
+		} `yaml:"grpc"`
+		// Restart - паузы перезапуска упавшего транспорта (удваиваются до max).
+		Restart struct {
+			BackoffMS    int `yaml:"backoff_ms"`
+			MaxBackoffMS int `yaml:"max_backoff_ms"`
+		} `yaml:"restart"`
+		// Interactive - /menu, выбор аргументов кнопками и подтверждение
+		// изменяющих команд в Telegram и MaxBot.
+		Interactive struct {
+			Enabled bool `yaml:"enabled"`
+			// CallbackTTLSeconds - срок жизни кнопок.
+			CallbackTTLSeconds int `yaml:"callback_ttl_seconds"`
+		} `yaml:"interactive"`
+	} `yaml:"transports"`
+	// Notifications - доставка уведомлений подписанным чатам.
+	Notifications struct {
+		QueueSize          int `yaml:"queue_size"`
+		MaxAttempts        int `yaml:"max_attempts"`
+		BackoffMS          int `yaml:"backoff_ms"`
+		MaxBackoffMS       int `yaml:"max_backoff_ms"`
+		DedupWindowSeconds int `yaml:"dedup_window_seconds"`
+	} `yaml:"notifications"`
+	Maintenance struct {
+		// SyncIntervalSeconds - как часто демон подхватывает изменения из CLI и аудирует истечение.
+		SyncIntervalSeconds int `yaml:"sync_interval_seconds"`
+		// RequireWindow - действия, разрешенные только внутри окна обслуживания.
+		RequireWindow []struct {
+			Action  string   `yaml:"action"`
+			Windows []string `yaml:"windows"`
+		} `yaml:"require_window"`
+	} `yaml:"maintenance"`
+	// Webhooks - исходящие HTTP-события агента и алерты в формате Alertmanager.
+	// Параметры доставки общие, max_attempts и timeout_ms переопределяются в endpoint.
+	Webhooks struct {
+		QueueSize    int               `yaml:"queue_size"`
+		MaxAttempts  int               `yaml:"max_attempts"`
+		BackoffMS    int               `yaml:"backoff_ms"`
+		MaxBackoffMS int               `yaml:"max_backoff_ms"`
+		TimeoutMS    int               `yaml:"timeout_ms"`
+		Endpoints    []WebhookEndpoint `yaml:"endpoints"`
+	} `yaml:"webhooks"`
+
 	Web struct {
 		Enabled          bool   `yaml:"enabled"`
 		ListenAddr       string `yaml:"listen_addr"`
@@ -56,14 +263,107 @@ type Config struct {
 	} `yaml:"llm"`
 }
 
+// Collector - команда модуля, периодически сохраняемая в metrics.
+// Пустые interval_seconds и retention_days берутся из scheduler и sqlite.
+type Collector struct {
+	Name            string   `yaml:"name"`
+	Module          string   `yaml:"module"`
+	Command         string   `yaml:"command"`
+	Args            []string `yaml:"args"`
+	Metric          string   `yaml:"metric"`
+	IntervalSeconds int      `yaml:"interval_seconds"`
+	Schedule        string   `yaml:"schedule"`
+	JitterMS        int      `yaml:"jitter_ms"`
+	TimeoutMS       int      `yaml:"timeout_ms"`
+	RetentionDays   int      `yaml:"retention_days"`
+}
+
+// AlertRule - пороговое правило над последней записью метрики, например
+// expr: "mem_used_pct > 90 for 5m".
+type AlertRule struct {
+	Name     string            `yaml:"name"`
+	Metric   string            `yaml:"metric"`
+	Expr     string            `yaml:"expr"`
+	Severity string            `yaml:"severity"`
+	Summary  string            `yaml:"summary"`
+	Labels   map[string]string `yaml:"labels"`
+}
+
+// WebhookEndpoint - получатель webhook. Format: generic (события по шаблонам
+// events, например "job.*") или alertmanager (только алерты). Секрет подписи
+// HMAC читается из secret_file или переменной secret_env.
+type WebhookEndpoint struct {
+	Name        string            `yaml:"name"`
+	URL         string            `yaml:"url"`
+	Format      string            `yaml:"format"`
+	Events      []string          `yaml:"events"`
+	SecretFile  string            `yaml:"secret_file"`
+	SecretEnv   string            `yaml:"secret_env"`
+	Headers     map[string]string `yaml:"headers"`
+	ExternalURL string            `yaml:"external_url"`
+	MaxAttempts int               `yaml:"max_attempts"`
+	TimeoutMS   int               `yaml:"timeout_ms"`
+}
+
 // Default возвращает конфигурацию по умолчанию.
 func Default() Config {
 	var cfg Config
 	cfg.Agent.Mode = "cli"
 	cfg.Agent.LogLevel = "info"
+	cfg.Storage.Backend = StorageSQLite
 	cfg.SQLite.Path = "/var/lib/goadmin/state.db"
+	cfg.SQLite.Driver = "auto"
 	cfg.SQLite.RetentionDays = 30
+	cfg.SQLite.Encryption.KeyEnv = "GOADMIN_DB_KEYS"
+	cfg.Audit.CheckpointEvery = 100
+	cfg.Audit.Async.QueueSize = 4096
+	cfg.Audit.Async.BatchSize = 128
+	cfg.Audit.Async.FlushIntervalMS = 200
+	cfg.Audit.Async.SpoolDir = "/var/lib/goadmin/audit-spool"
+	cfg.Audit.Async.SpoolMaxMB = 256
+	cfg.Redaction.Enabled = true
 	cfg.Scheduler.IntervalSeconds = 60
+	cfg.Collectors = []Collector{{Name: "host_status", Module: "host", Command: "status", Metric: "host", TimeoutMS: 3000}}
+	cfg.Alerts.EvalIntervalSeconds = 60
+	cfg.Alerts.StaleAfterSeconds = 300
+	cfg.Transports.Telegram.TokenEnv = "GOADMIN_TELEGRAM_TOKEN"
+	cfg.Transports.Telegram.APIURL = "https://api.telegram.org"
+	cfg.Transports.Telegram.Polling = true
+	cfg.Transports.Telegram.PollTimeoutSeconds = 25
+	cfg.Transports.Telegram.MaxMessageAgeSeconds = 300
+	cfg.Transports.Telegram.ParseMode = "HTML"
+	cfg.Transports.MaxBot.TokenEnv = "GOADMIN_MAXBOT_TOKEN"
+	cfg.Transports.MaxBot.APIURL = "https://platform-api.max.ru"
+	cfg.Transports.MaxBot.WebhookPath = "/maxbot/webhook"
+	cfg.Transports.MaxBot.WebhookSecretEnv = "GOADMIN_MAXBOT_WEBHOOK_SECRET"
+	cfg.Transports.MaxBot.ReplayWindowSeconds = 300
+	cfg.Transports.MaxBot.Format = "html"
+	cfg.Transports.Mattermost.Path = "/mattermost/command"
+	cfg.Transports.Mattermost.TokenEnv = "GOADMIN_MATTERMOST_TOKENS"
+	cfg.Transports.Mattermost.SigningSecretEnv = "GOADMIN_SLACK_SIGNING_SECRET"
+	cfg.Transports.Mattermost.Command = "/goadmin"
+	cfg.Transports.Mattermost.ImmediateTimeoutMS = 2000
+	cfg.Transports.Mattermost.ResponseType = "ephemeral"
+	cfg.Transports.Mattermost.Format = "markdown"
+	cfg.Transports.GRPC.RequestTimeoutMS = 3000
+	cfg.Transports.GRPC.StreamIntervalMS = 1000
+	cfg.Transports.GRPC.MaxRecvBytes = 1 << 20
+	cfg.Transports.GRPC.Reflection = true
+	cfg.Transports.Restart.BackoffMS = 1000
+	cfg.Transports.Restart.MaxBackoffMS = 60000
+	cfg.Transports.Interactive.Enabled = true
+	cfg.Transports.Interactive.CallbackTTLSeconds = 300
+	cfg.Notifications.QueueSize = 256
+	cfg.Notifications.MaxAttempts = 5
+	cfg.Notifications.BackoffMS = 1000
+	cfg.Notifications.MaxBackoffMS = 60000
+	cfg.Notifications.DedupWindowSeconds = 3600
+	cfg.Maintenance.SyncIntervalSeconds = 15
+	cfg.Webhooks.QueueSize = 256
+	cfg.Webhooks.MaxAttempts = 5
+	cfg.Webhooks.BackoffMS = 1000
+	cfg.Webhooks.MaxBackoffMS = 60000
+	cfg.Webhooks.TimeoutMS = 5000
 	cfg.Web.Enabled = false
 	cfg.Web.ListenAddr = "127.0.0.1:8080"
 	cfg.Web.ReadTimeoutMS = 2000
@@ -73,11 +373,11 @@ func Default() Config {
 	cfg.Web.MaxBodyBytes = 1 << 20
 	cfg.Web.Auth.Mode = "bearer"
 	cfg.Web.Auth.AllowLegacySubjectHeader = true
-	cfg.Web.CORS.AllowedMethods = []string{"GET", "POST", "OPTIONS"}
+	cfg.Web.CORS.AllowedMethods = []string{"GET", "POST", "DELETE", "OPTIONS"}
 	cfg.Web.CORS.AllowedHeaders = []string{"Authorization", "Content-Type", "X-Request-ID"}
 	cfg.LLM.ProviderOrder = []string{"local", "cloud"}
 	cfg.LLM.TimeoutMS = 2000
-	cfg.Security.AuthAllowlist = map[string][]string{"telegram": {}, "maxbot": {}, "web": {}}
+	cfg.Security.AuthAllowlist = map[string][]string{"telegram": {}, "maxbot": {}, "mattermost": {}, "grpc": {}, "web": {}, "cli": {}}
 	return cfg
 }
 
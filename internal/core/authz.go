@@ -1,6 +1,9 @@
 package core
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // Subject описывает источник команды и его идентификатор.
 type Subject struct {
@@ -14,6 +17,20 @@ type Action struct {
 	Command string
 }
 
+type subjectKey struct{}
+
+// WithSubject сохраняет инициатора команды в контексте: модули узнают из него,
+// кто создал объект.
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext возвращает инициатора, сохраненного WithSubject.
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	s, ok := ctx.Value(subjectKey{}).(Subject)
+	return s, ok
+}
+
 // Authorizer отвечает за решение доступа к действию.
 type Authorizer interface {
 	Authorize(subject Subject, action Action) error
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleNext(t *testing.T) {
+	// 2026-03-02 - понедельник.
+	from := time.Date(2026, 3, 2, 10, 7, 30, 0, time.UTC)
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 2, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 3, 2, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2026, 3, 3, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 jan,jul *", time.Date(2026, 7, 1, 12, 0, 0, 0, time.UTC)},
+		// Ограничены и день месяца, и день недели: срабатывает любое совпадение.
+		{"0 0 13 * fri", time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tc := range cases {
+		sc, err := ParseSchedule(tc.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.expr, err)
+		}
+		if got := sc.Next(from); !got.Equal(tc.want) {
+			t.Fatalf("%q: next = %s, want %s", tc.expr, got, tc.want)
+		}
+	}
+
+	impossible, err := ParseSchedule("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if next := impossible.Next(from); !next.IsZero() {
+		t.Fatalf("expected no next run for Feb 30, got %s", next)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every -1s", "@every soon"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Fatalf("%q: expected error", expr)
+		}
+	}
+}
@@ -58,3 +58,48 @@ func (r *Registry) Providers() []string {
 	}
 	return names
 }
+
+// Templates собирает шаблоны ответов модулей-Templater по ключу "module:command".
+func (r *Registry) Templates() map[string]string {
+	out := map[string]string{}
+	for name, prov := range r.providers {
+		t, ok := prov.(Templater)
+		if !ok {
+			continue
+		}
+		for cmd, text := range t.Templates() {
+			out[name+":"+cmd] = text
+		}
+	}
+	return out
+}
+
+// Describe возвращает команды модуля-Describer; ok=false - модуль без описания.
+func (r *Registry) Describe(module string) ([]CommandInfo, bool) {
+	d, ok := r.providers[module].(Describer)
+	if !ok {
+		return nil, false
+	}
+	return d.Commands(), true
+}
+
+// Command ищет описание команды модуля.
+func (r *Registry) Command(module, command string) (CommandInfo, bool) {
+	cmds, _ := r.Describe(module)
+	for _, c := range cmds {
+		if c.Name == command {
+			return c, true
+		}
+	}
+	return CommandInfo{}, false
+}
+
+// Complete возвращает варианты следующего аргумента команды; модуль без
+// Completer вариантов не дает.
+func (r *Registry) Complete(ctx context.Context, module, command string, args []string) ([]string, error) {
+	c, ok := r.providers[module].(Completer)
+	if !ok {
+		return nil, nil
+	}
+	return c.Complete(ctx, command, args)
+}
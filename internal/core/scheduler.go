@@ -2,6 +2,10 @@ package core
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
 	"sync"
 	"time"
 )
@@ -9,41 +13,363 @@ import (
 // Job описывает периодическую задачу.
 type Job func(ctx context.Context) error
 
-// Scheduler запускает задачи с фиксированным интервалом.
+// OverlapPolicy задает поведение, когда срабатывание приходит во время предыдущего запуска.
+type OverlapPolicy string
+
+const (
+	// OverlapSkip пропускает срабатывание (по умолчанию).
+	OverlapSkip OverlapPolicy = "skip"
+	// OverlapQueue откладывает срабатывание до завершения текущего запуска;
+	// несколько отложенных срабатываний схлопываются в одно.
+	OverlapQueue OverlapPolicy = "queue"
+	// OverlapReplace отменяет текущий запуск и начинает новый после его выхода.
+	OverlapReplace OverlapPolicy = "replace"
+)
+
+// Источник запуска задачи.
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	errJobExists = errors.New("job already registered")
+	// ErrUnknownJob - задача с таким именем не зарегистрирована.
+	ErrUnknownJob = errors.New("unknown job")
+	// ErrJobRunning - ручной запуск отклонен: задача уже выполняется, а политика OverlapSkip.
+	ErrJobRunning = errors.New("job is already running")
+	// ErrSchedulerStopped - ручной запуск до Start или после остановки планировщика.
+	ErrSchedulerStopped = errors.New("scheduler is not running")
+)
+
+// JobSpec описывает именованную задачу планировщика.
+type JobSpec struct {
+	Name     string
+	Schedule Schedule
+	// Jitter добавляет к каждому срабатыванию случайную задержку [0, Jitter).
+	Jitter time.Duration
+	// Timeout ограничивает один запуск; 0 - без ограничения.
+	Timeout time.Duration
+	Overlap OverlapPolicy
+	Run     Job
+}
+
+// JobResult описывает завершенный или пропущенный запуск.
+type JobResult struct {
+	Job      string
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+	// Trigger - TriggerSchedule или TriggerManual.
+	Trigger string
+	// Skipped - срабатывание пропущено политикой OverlapSkip.
+	Skipped bool
+}
+
+// JobStatus - текущее состояние задачи.
+type JobStatus struct {
+	Name      string        `json:"name"`
+	Schedule  string        `json:"schedule"`
+	Overlap   OverlapPolicy `json:"overlap"`
+	Running   bool          `json:"running"`
+	Paused    bool          `json:"paused"`
+	Runs      int64         `json:"runs"`
+	Failures  int64         `json:"failures"`
+	Skips     int64         `json:"skips"`
+	LastRun   *time.Time    `json:"last_run,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+	NextRun   *time.Time    `json:"next_run,omitempty"`
+}
+
+// Scheduler запускает именованные задачи по cron-выражениям или интервалам.
 type Scheduler struct {
 	interval time.Duration
-	jobs     []Job
+
+	mu       sync.Mutex
+	jobs     map[string]*scheduledJob
+	order    []string
+	ctx      context.Context
+	logger   *slog.Logger
+	reporter func(JobResult)
 	wg       sync.WaitGroup
 }
 
-// NewScheduler создает scheduler с заданным интервалом.
+type scheduledJob struct {
+	spec    JobSpec
+	running bool
+	paused  bool
+	pending bool
+	// pendingTrigger - источник отложенного запуска.
+	pendingTrigger string
+	cancel         context.CancelFunc
+	status         JobStatus
+}
+
+// NewScheduler создает scheduler; interval используется задачами, добавленными через Add.
 func NewScheduler(interval time.Duration) *Scheduler {
-	return &Scheduler{interval: interval}
+	return &Scheduler{interval: interval, jobs: make(map[string]*scheduledJob)}
+}
+
+// SetLogger задает логгер ошибок задач; по умолчанию slog.Default().
+func (s *Scheduler) SetLogger(l *slog.Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logger = l
+}
+
+// SetReporter задает получатель результатов запусков (например, для истории).
+func (s *Scheduler) SetReporter(fn func(JobResult)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reporter = fn
 }
 
-// Add добавляет задачу в расписание.
+// Add добавляет задачу с интервалом планировщика и политикой OverlapSkip.
 func (s *Scheduler) Add(job Job) {
-	s.jobs = append(s.jobs, job)
+	s.mu.Lock()
+	name := fmt.Sprintf("job-%d", len(s.order)+1)
+	s.mu.Unlock()
+	_ = s.AddJob(JobSpec{Name: name, Schedule: Every(s.interval), Run: job})
+}
+
+// AddJob регистрирует именованную задачу. Задача, добавленная после Start, начинает работу сразу.
+func (s *Scheduler) AddJob(spec JobSpec) error {
+	if spec.Name == "" || spec.Run == nil || spec.Schedule == nil {
+		return fmt.Errorf("job requires name, schedule and run: %w", errInvalidArguments)
+	}
+	if iv, ok := spec.Schedule.(intervalSchedule); ok && iv <= 0 {
+		return fmt.Errorf("job %s: %w: interval must be positive", spec.Name, errInvalidSchedule)
+	}
+	switch spec.Overlap {
+	case "":
+		spec.Overlap = OverlapSkip
+	case OverlapSkip, OverlapQueue, OverlapReplace:
+	default:
+		return fmt.Errorf("job %s: unknown overlap policy %q: %w", spec.Name, spec.Overlap, errInvalidArguments)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[spec.Name]; exists {
+		return fmt.Errorf("%s: %w", spec.Name, errJobExists)
+	}
+	j := &scheduledJob{spec: spec, status: JobStatus{Name: spec.Name, Schedule: scheduleString(spec.Schedule), Overlap: spec.Overlap}}
+	s.jobs[spec.Name] = j
+	s.order = append(s.order, spec.Name)
+	if s.ctx != nil {
+		s.wg.Add(1)
+		go s.loop(s.ctx, j)
+	}
+	return nil
+}
+
+// Jobs возвращает состояние задач в порядке регистрации.
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]JobStatus, 0, len(s.order))
+	for _, name := range s.order {
+		out = append(out, s.jobs[name].status)
+	}
+	return out
+}
+
+// Pause приостанавливает плановые срабатывания задачи; текущий запуск не прерывается.
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume возобновляет плановые срабатывания задачи.
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[name]
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownJob)
+	}
+	j.paused = paused
+	j.status.Paused = paused
+	return nil
 }
 
-// Start запускает scheduler до отмены контекста.
+// RunNow запускает задачу вне расписания, в том числе приостановленную.
+// Если задача уже выполняется, применяется ее политика перекрытия; при OverlapSkip
+// возвращается ErrJobRunning.
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	ctx := s.ctx
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", name, ErrUnknownJob)
+	}
+	if ctx == nil || ctx.Err() != nil {
+		return ErrSchedulerStopped
+	}
+	if !s.fire(ctx, j, TriggerManual) {
+		return fmt.Errorf("%s: %w", name, ErrJobRunning)
+	}
+	return nil
+}
+
+// Start запускает задачи и блокируется до отмены контекста; затем ждет завершения текущих запусков.
 func (s *Scheduler) Start(ctx context.Context) {
-	ticker := time.NewTicker(s.interval)
+	s.mu.Lock()
+	s.ctx = ctx
+	jobs := make([]*scheduledJob, 0, len(s.order))
+	for _, name := range s.order {
+		jobs = append(jobs, s.jobs[name])
+	}
+	s.wg.Add(len(jobs))
+	s.mu.Unlock()
+
+	for _, j := range jobs {
+		go s.loop(ctx, j)
+	}
+	<-ctx.Done()
+	s.wg.Wait()
+}
+
+// loop ждет очередного срабатывания задачи и передает его в fire.
+func (s *Scheduler) loop(ctx context.Context, j *scheduledJob) {
+	defer s.wg.Done()
 	for {
+		next := j.spec.Schedule.Next(time.Now())
+		if next.IsZero() {
+			s.log().Error("job schedule has no future runs", "job", j.spec.Name)
+			return
+		}
+		if j.spec.Jitter > 0 {
+			next = next.Add(rand.N(j.spec.Jitter))
+		}
+		s.mu.Lock()
+		j.status.NextRun = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
 		select {
 		case <-ctx.Done():
-			ticker.Stop()
-			s.wg.Wait()
+			timer.Stop()
 			return
-		case <-ticker.C:
-			for _, job := range s.jobs {
-				job := job
-				s.wg.Add(1)
-				go func() {
-					defer s.wg.Done()
-					_ = job(ctx)
-				}()
+		case <-timer.C:
+			s.mu.Lock()
+			paused := j.paused
+			s.mu.Unlock()
+			if !paused {
+				s.fire(ctx, j, TriggerSchedule)
 			}
 		}
 	}
 }
+
+// fire применяет политику перекрытия и запускает задачу в отдельной горутине.
+// Возвращает false, если срабатывание пропущено.
+func (s *Scheduler) fire(ctx context.Context, j *scheduledJob, trigger string) bool {
+	s.mu.Lock()
+	if j.running {
+		switch j.spec.Overlap {
+		case OverlapQueue:
+			j.pending = true
+			j.pendingTrigger = trigger
+		case OverlapReplace:
+			j.pending = true
+			j.pendingTrigger = trigger
+			j.cancel()
+		default:
+			j.status.Skips++
+			s.mu.Unlock()
+			s.report(JobResult{Job: j.spec.Name, Started: time.Now(), Trigger: trigger, Skipped: true})
+			return false
+		}
+		s.mu.Unlock()
+		return true
+	}
+	s.startLocked(ctx, j, trigger)
+	s.mu.Unlock()
+	return true
+}
+
+// startLocked запускает задачу; вызывается под s.mu.
+func (s *Scheduler) startLocked(ctx context.Context, j *scheduledJob, trigger string) {
+	var (
+		runCtx context.Context
+		cancel context.CancelFunc
+	)
+	if j.spec.Timeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, j.spec.Timeout)
+	} else {
+		runCtx, cancel = context.WithCancel(ctx)
+	}
+	j.running = true
+	j.cancel = cancel
+	j.status.Running = true
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		started := time.Now()
+		err := runJob(runCtx, j.spec.Run)
+		cancel()
+		res := JobResult{Job: j.spec.Name, Started: started, Duration: time.Since(started), Err: err, Trigger: trigger}
+
+		s.mu.Lock()
+		j.running = false
+		j.status.Running = false
+		j.status.Runs++
+		j.status.LastRun = &started
+		j.status.LastError = ""
+		if err != nil {
+			j.status.Failures++
+			j.status.LastError = err.Error()
+		}
+		if j.pending && ctx.Err() == nil {
+			j.pending = false
+			s.startLocked(ctx, j, j.pendingTrigger)
+		}
+		s.mu.Unlock()
+		s.report(res)
+	}()
+}
+
+// runJob выполняет задачу, превращая панику в ошибку.
+func runJob(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panic: %v", r)
+		}
+	}()
+	return job(ctx)
+}
+
+func (s *Scheduler) report(res JobResult) {
+	s.mu.Lock()
+	reporter := s.reporter
+	s.mu.Unlock()
+	if res.Err != nil {
+		s.log().Error("scheduled job failed", "job", res.Job, "duration", res.Duration, "err", res.Err)
+	} else if res.Skipped {
+		s.log().Warn("scheduled job skipped: previous run still in progress", "job", res.Job)
+	}
+	if reporter != nil {
+		reporter(res)
+	}
+}
+
+func (s *Scheduler) log() *slog.Logger {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.logger != nil {
+		return s.logger
+	}
+	return slog.Default()
+}
+
+func scheduleString(sc Schedule) string {
+	if str, ok := sc.(fmt.Stringer); ok {
+		return str.String()
+	}
+	return fmt.Sprintf("%T", sc)
+}
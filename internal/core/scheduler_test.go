@@ -2,6 +2,8 @@ package core
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -23,3 +25,246 @@ func TestSchedulerRunsJobs(t *testing.T) {
 		t.Fatalf("expected jobs to run, got %d", c)
 	}
 }
+
+// resultLog собирает результаты запусков для проверок.
+type resultLog struct {
+	mu      sync.Mutex
+	results []JobResult
+}
+
+func (l *resultLog) add(r JobResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, r)
+}
+
+func (l *resultLog) count(pred func(JobResult) bool) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	n := 0
+	for _, r := range l.results {
+		if pred(r) {
+			n++
+		}
+	}
+	return n
+}
+
+func runScheduler(t *testing.T, d time.Duration, specs ...JobSpec) (*Scheduler, *resultLog) {
+	t.Helper()
+	sched := NewScheduler(time.Second)
+	log := &resultLog{}
+	sched.SetReporter(log.add)
+	for _, spec := range specs {
+		if err := sched.AddJob(spec); err != nil {
+			t.Fatalf("add job: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	sched.Start(ctx)
+	return sched, log
+}
+
+func TestSchedulerOverlapSkip(t *testing.T) {
+	var running, maxRunning int32
+	sched, log := runScheduler(t, 120*time.Millisecond, JobSpec{
+		Name:     "slow",
+		Schedule: Every(10 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			if n > atomic.LoadInt32(&maxRunning) {
+				atomic.StoreInt32(&maxRunning, n)
+			}
+			select {
+			case <-time.After(50 * time.Millisecond):
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	})
+	if maxRunning != 1 {
+		t.Fatalf("expected no overlapping runs, max concurrent = %d", maxRunning)
+	}
+	skipped := log.count(func(r JobResult) bool { return r.Skipped })
+	if skipped == 0 {
+		t.Fatal("expected skipped ticks")
+	}
+	if st := sched.Jobs()[0]; st.Skips != int64(skipped) || st.Overlap != OverlapSkip {
+		t.Fatalf("unexpected status: %#v", st)
+	}
+}
+
+func TestSchedulerOverlapQueueCoalesces(t *testing.T) {
+	var runs int32
+	_, log := runScheduler(t, 130*time.Millisecond, JobSpec{
+		Name:     "queued",
+		Schedule: Every(10 * time.Millisecond),
+		Overlap:  OverlapQueue,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			time.Sleep(40 * time.Millisecond)
+			return nil
+		},
+	})
+	// Без очереди было бы ~3 запуска, без схлопывания - ~12.
+	if r := atomic.LoadInt32(&runs); r < 3 || r > 5 {
+		t.Fatalf("queued runs = %d, want 3..5", r)
+	}
+	if log.count(func(r JobResult) bool { return r.Skipped }) != 0 {
+		t.Fatal("queue policy must not skip")
+	}
+}
+
+func TestSchedulerOverlapReplaceCancelsRunning(t *testing.T) {
+	var canceled int32
+	_, log := runScheduler(t, 100*time.Millisecond, JobSpec{
+		Name:     "replaced",
+		Schedule: Every(20 * time.Millisecond),
+		Overlap:  OverlapReplace,
+		Run: func(ctx context.Context) error {
+			select {
+			case <-time.After(time.Second):
+				return nil
+			case <-ctx.Done():
+				atomic.AddInt32(&canceled, 1)
+				return ctx.Err()
+			}
+		},
+	})
+	if atomic.LoadInt32(&canceled) < 2 {
+		t.Fatalf("expected replaced runs to be canceled, got %d", canceled)
+	}
+	if log.count(func(r JobResult) bool { return errors.Is(r.Err, context.Canceled) }) < 2 {
+		t.Fatal("expected canceled results to be reported")
+	}
+}
+
+func TestSchedulerTimeoutErrorsAndPanics(t *testing.T) {
+	boom := errors.New("boom")
+	sched, log := runScheduler(t, 80*time.Millisecond,
+		JobSpec{
+			Name:     "timeout",
+			Schedule: Every(20 * time.Millisecond),
+			Timeout:  5 * time.Millisecond,
+			Run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		JobSpec{Name: "fails", Schedule: Every(20 * time.Millisecond), Run: func(context.Context) error { return boom }},
+		JobSpec{Name: "panics", Schedule: Every(20 * time.Millisecond), Run: func(context.Context) error { panic("bad") }},
+	)
+	if log.count(func(r JobResult) bool { return r.Job == "timeout" && errors.Is(r.Err, context.DeadlineExceeded) }) == 0 {
+		t.Fatal("expected per-job timeout")
+	}
+	if log.count(func(r JobResult) bool { return r.Job == "fails" && errors.Is(r.Err, boom) }) == 0 {
+		t.Fatal("expected job error to be reported")
+	}
+	if log.count(func(r JobResult) bool { return r.Job == "panics" && r.Err != nil }) == 0 {
+		t.Fatal("expected panic to be reported as error")
+	}
+	for _, st := range sched.Jobs() {
+		if st.Failures == 0 || st.LastError == "" || st.Failures != st.Runs {
+			t.Fatalf("unexpected status: %#v", st)
+		}
+	}
+}
+
+func TestSchedulerJitterAndValidation(t *testing.T) {
+	var first atomic.Int64
+	start := time.Now()
+	runScheduler(t, 80*time.Millisecond, JobSpec{
+		Name:     "jittered",
+		Schedule: Every(time.Millisecond),
+		Jitter:   30 * time.Millisecond,
+		Run: func(context.Context) error {
+			first.CompareAndSwap(0, int64(time.Since(start)))
+			return nil
+		},
+	})
+	if first.Load() == 0 {
+		t.Fatal("jittered job did not run")
+	}
+
+	sched := NewScheduler(time.Second)
+	noop := func(context.Context) error { return nil }
+	if err := sched.AddJob(JobSpec{Name: "a", Schedule: Every(time.Second), Run: noop}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := sched.AddJob(JobSpec{Name: "a", Schedule: Every(time.Second), Run: noop}); !errors.Is(err, errJobExists) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if err := sched.AddJob(JobSpec{Name: "b", Schedule: Every(time.Second), Overlap: "parallel", Run: noop}); err == nil {
+		t.Fatal("expected unknown overlap policy error")
+	}
+	if err := sched.AddJob(JobSpec{Name: "c", Schedule: Every(0), Run: noop}); err == nil {
+		t.Fatal("expected non-positive interval error")
+	}
+}
+
+func TestSchedulerPauseResumeRunNow(t *testing.T) {
+	sched := NewScheduler(time.Second)
+	log := &resultLog{}
+	sched.SetReporter(log.add)
+	release := make(chan struct{})
+	if err := sched.AddJob(JobSpec{
+		Name:     "paused",
+		Schedule: Every(5 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			select {
+			case <-release:
+			case <-ctx.Done():
+			}
+			return nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sched.RunNow("paused"); !errors.Is(err, ErrSchedulerStopped) {
+		t.Fatalf("run before start: %v", err)
+	}
+	if err := sched.Pause("missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Fatalf("pause unknown: %v", err)
+	}
+	if err := sched.Pause("paused"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sched.Start(ctx)
+		close(done)
+	}()
+	time.Sleep(40 * time.Millisecond)
+	if st := sched.Jobs()[0]; st.Runs != 0 || st.Running || !st.Paused {
+		t.Fatalf("paused job ran: %+v", st)
+	}
+
+	if err := sched.RunNow("paused"); err != nil {
+		t.Fatalf("run now: %v", err)
+	}
+	if err := sched.RunNow("paused"); !errors.Is(err, ErrJobRunning) {
+		t.Fatalf("second run now: %v", err)
+	}
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+	if err := sched.Resume("paused"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	cancel()
+	<-done
+
+	if n := log.count(func(r JobResult) bool { return r.Trigger == TriggerManual && !r.Skipped }); n != 1 {
+		t.Fatalf("manual runs = %d, want 1", n)
+	}
+	if n := log.count(func(r JobResult) bool { return r.Trigger == TriggerSchedule && !r.Skipped }); n == 0 {
+		t.Fatal("resumed job did not run on schedule")
+	}
+	if st := sched.Jobs()[0]; st.Paused {
+		t.Fatalf("job still paused: %+v", st)
+	}
+}
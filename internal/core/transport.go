@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
+	"time"
 )
 
 var (
 	errTransportExists  = errors.New("transport already registered")
 	errUnknownTransport = errors.New("unknown transport")
+	// ErrNotifyUnsupported - транспорт не умеет отправлять уведомления.
+	ErrNotifyUnsupported = errors.New("transport does not support notifications")
 )
 
 // TransportAdapter определяет жизненный цикл входного транспорта.
@@ -19,15 +23,84 @@ type TransportAdapter interface {
 	Stop(ctx context.Context) error
 }
 
-// TransportManager управляет запуском и остановкой транспортов.
+// FailureNotifier - необязательный интерфейс транспорта, который может
+// перестать работать после успешного Start (например, упал HTTP-сервер).
+// Транспорт отправляет ошибку в канал Failed, менеджер перезапускает его:
+// Stop, пауза, Start.
+type FailureNotifier interface {
+	Failed() <-chan error
+}
+
+// Состояния транспорта.
+const (
+	TransportStarting = "starting"
+	TransportRunning  = "running"
+	TransportFailed   = "failed"
+	TransportStopped  = "stopped"
+)
+
+// TransportStatus - текущее состояние транспорта.
+type TransportStatus struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Since - время перехода в State.
+	Since       *time.Time `json:"since,omitempty"`
+	Restarts    int64      `json:"restarts"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// RestartPolicy - паузы между перезапусками упавшего транспорта: Backoff,
+// удваиваясь до MaxBackoff; после MaxBackoff стабильной работы пауза
+// сбрасывается.
+type RestartPolicy struct {
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// transportStopTimeout ограничивает Stop при откате запуска и перезапуске.
+const transportStopTimeout = 10 * time.Second
+
+type managedTransport struct {
+	adapter TransportAdapter
+	status  TransportStatus
+	// running - время последнего успешного Start (для сброса паузы).
+	running time.Time
+	// stop останавливает наблюдение, done закрывается по его завершении.
+	stop chan struct{}
+	done chan struct{}
+}
+
+// TransportManager запускает транспорты в порядке регистрации, перезапускает
+// упавшие (FailureNotifier) и останавливает все параллельно.
 type TransportManager struct {
 	mu         sync.Mutex
-	transports map[string]TransportAdapter
+	transports map[string]*managedTransport
+	order      []string
+	policy     RestartPolicy
+	now        func() time.Time
 }
 
 // NewTransportManager создает пустой менеджер транспортов.
 func NewTransportManager() *TransportManager {
-	return &TransportManager{transports: make(map[string]TransportAdapter)}
+	return &TransportManager{
+		transports: make(map[string]*managedTransport),
+		policy:     RestartPolicy{Backoff: time.Second, MaxBackoff: time.Minute},
+		now:        time.Now,
+	}
+}
+
+// SetRestartPolicy задает паузы перезапуска; нулевые поля не меняются.
+func (m *TransportManager) SetRestartPolicy(p RestartPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p.Backoff > 0 {
+		m.policy.Backoff = p.Backoff
+	}
+	if p.MaxBackoff > 0 {
+		m.policy.MaxBackoff = p.MaxBackoff
+	}
+	m.policy.MaxBackoff = max(m.policy.MaxBackoff, m.policy.Backoff)
 }
 
 // Register добавляет транспорт; имена должны быть уникальны.
@@ -45,42 +118,157 @@ func (m *TransportManager) Register(adapter TransportAdapter) error {
 	if _, exists := m.transports[name]; exists {
 		return fmt.Errorf("%s: %w", name, errTransportExists)
 	}
-	m.transports[name] = adapter
+	m.transports[name] = &managedTransport{adapter: adapter, status: TransportStatus{Name: name, State: TransportStopped}}
+	m.order = append(m.order, name)
 	return nil
 }
 
-// StartAll запускает все зарегистрированные транспорты.
-func (m *TransportManager) StartAll(ctx context.Context) error {
+// Status возвращает состояние транспортов в порядке регистрации.
+func (m *TransportManager) Status() []TransportStatus {
 	m.mu.Lock()
-	list := make([]TransportAdapter, 0, len(m.transports))
-	for _, tr := range m.transports {
-		list = append(list, tr)
+	defer m.mu.Unlock()
+	out := make([]TransportStatus, 0, len(m.order))
+	for _, name := range m.order {
+		out = append(out, m.transports[name].status)
 	}
-	m.mu.Unlock()
+	return out
+}
 
+// StartAll запускает транспорты в порядке регистрации. Если один не
+// запустился, уже запущенные останавливаются в обратном порядке.
+func (m *TransportManager) StartAll(ctx context.Context) error {
+	list := m.list()
+	started := make([]*managedTransport, 0, len(list))
 	for _, tr := range list {
-		if err := tr.Start(ctx); err != nil {
-			return fmt.Errorf("start transport %s: %w", tr.Name(), err)
+		m.setState(tr, TransportStarting, nil)
+		if err := tr.adapter.Start(ctx); err != nil {
+			m.setState(tr, TransportFailed, err)
+			err = fmt.Errorf("start transport %s: %w", tr.status.Name, err)
+			return errors.Join(err, m.rollback(ctx, started))
 		}
+		m.setState(tr, TransportRunning, nil)
+		started = append(started, tr)
+	}
+	for _, tr := range started {
+		m.supervise(ctx, tr)
 	}
 	return nil
 }
 
-// StopAll останавливает все зарегистрированные транспорты.
-func (m *TransportManager) StopAll(ctx context.Context) error {
-	m.mu.Lock()
-	list := make([]TransportAdapter, 0, len(m.transports))
-	for _, tr := range m.transports {
-		list = append(list, tr)
+// rollback останавливает запущенные транспорты в обратном порядке.
+func (m *TransportManager) rollback(ctx context.Context, started []*managedTransport) error {
+	stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), transportStopTimeout)
+	defer cancel()
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := m.stopTransport(stopCtx, started[i]); err != nil {
+			errs = append(errs, fmt.Errorf("rollback: %w", err))
+		}
 	}
+	return errors.Join(errs...)
+}
+
+// supervise перезапускает транспорт после ошибки из Failed до StopAll/StopOne
+// или отмены ctx.
+func (m *TransportManager) supervise(ctx context.Context, tr *managedTransport) {
+	fn, ok := tr.adapter.(FailureNotifier)
+	if !ok {
+		return
+	}
+	stop, done := make(chan struct{}), make(chan struct{})
+	m.mu.Lock()
+	tr.stop, tr.done = stop, done
+	policy := m.policy
 	m.mu.Unlock()
+	go func() {
+		defer close(done)
+		backoff := policy.Backoff
+		for {
+			var err error
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case err = <-fn.Failed():
+			}
+			m.mu.Lock()
+			if m.now().Sub(tr.running) >= policy.MaxBackoff {
+				backoff = policy.Backoff
+			}
+			m.mu.Unlock()
+			for err != nil {
+				m.setState(tr, TransportFailed, err)
+				slog.Error("transport failed", "transport", tr.status.Name, "err", err, "restart_in", backoff)
+				stopCtx, cancel := context.WithTimeout(ctx, transportStopTimeout)
+				_ = tr.adapter.Stop(stopCtx)
+				cancel()
+				select {
+				case <-ctx.Done():
+					return
+				case <-stop:
+					return
+				case <-time.After(backoff):
+				}
+				backoff = min(backoff*2, policy.MaxBackoff)
+				drain(fn.Failed())
+				m.mu.Lock()
+				tr.status.Restarts++
+				m.mu.Unlock()
+				m.setState(tr, TransportStarting, nil)
+				if err = tr.adapter.Start(ctx); err == nil {
+					m.setState(tr, TransportRunning, nil)
+				}
+			}
+		}
+	}()
+}
 
+// drain отбрасывает ошибки, пришедшие до перезапуска.
+func drain(ch <-chan error) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+// StopAll параллельно останавливает все транспорты и ждет их не дольше ctx;
+// ошибки объединяются.
+func (m *TransportManager) StopAll(ctx context.Context) error {
+	list := m.list()
+	type result struct {
+		name string
+		err  error
+	}
+	results := make(chan result, len(list))
 	for _, tr := range list {
-		if err := tr.Stop(ctx); err != nil {
-			return fmt.Errorf("stop transport %s: %w", tr.Name(), err)
+		go func(tr *managedTransport) {
+			results <- result{name: tr.adapter.Name(), err: m.stopTransport(ctx, tr)}
+		}(tr)
+	}
+	pending := make(map[string]bool, len(list))
+	for _, tr := range list {
+		pending[tr.adapter.Name()] = true
+	}
+	var errs []error
+	for len(pending) > 0 {
+		select {
+		case r := <-results:
+			delete(pending, r.name)
+			if r.err != nil {
+				errs = append(errs, r.err)
+			}
+		case <-ctx.Done():
+			for _, name := range m.orderOf(pending) {
+				errs = append(errs, fmt.Errorf("stop transport %s: %w", name, ctx.Err()))
+			}
+			return errors.Join(errs...)
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 // StopOne останавливает конкретный транспорт по имени.
@@ -91,8 +279,148 @@ func (m *TransportManager) StopOne(ctx context.Context, name string) error {
 	if !ok {
 		return fmt.Errorf("%s: %w", name, errUnknownTransport)
 	}
-	if err := tr.Stop(ctx); err != nil {
-		return fmt.Errorf("stop transport %s: %w", tr.Name(), err)
+	return m.stopTransport(ctx, tr)
+}
+
+// stopTransport завершает наблюдение и останавливает транспорт.
+func (m *TransportManager) stopTransport(ctx context.Context, tr *managedTransport) error {
+	m.mu.Lock()
+	stop, done := tr.stop, tr.done
+	tr.stop, tr.done = nil, nil
+	m.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("stop transport %s: %w", tr.adapter.Name(), ctx.Err())
+		}
+	}
+	if err := tr.adapter.Stop(ctx); err != nil {
+		m.setState(tr, TransportFailed, err)
+		return fmt.Errorf("stop transport %s: %w", tr.adapter.Name(), err)
 	}
+	m.setState(tr, TransportStopped, nil)
 	return nil
 }
+
+func (m *TransportManager) list() []*managedTransport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]*managedTransport, 0, len(m.order))
+	for _, name := range m.order {
+		out = append(out, m.transports[name])
+	}
+	return out
+}
+
+// orderOf возвращает имена из set в порядке регистрации.
+func (m *TransportManager) orderOf(set map[string]bool) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for _, name := range m.order {
+		if set[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// setState меняет состояние; err != nil запоминается как последняя ошибка.
+func (m *TransportManager) setState(tr *managedTransport, state string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	if tr.status.State != state {
+		tr.status.State = state
+		tr.status.Since = &now
+	}
+	if state == TransportRunning {
+		tr.running = now
+	}
+	if err != nil {
+		tr.status.LastError = err.Error()
+		tr.status.LastErrorAt = &now
+	}
+}
+
+// TopicJobs - тема уведомлений о заданиях (алерты - alert.TopicAlerts).
+const TopicJobs = "jobs"
+
+// Уровни важности уведомлений по возрастанию.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// SeverityRank возвращает порядок важности; неизвестный уровень - -1.
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityInfo:
+		return 0
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// Notification - исходящее сообщение транспорта (например, об алерте).
+type Notification struct {
+	// Topic - тема для маршрутизации и подписок, например "alerts".
+	Topic    string
+	Severity string
+	Title    string
+	Text     string
+	Labels   map[string]string
+	// Key идентифицирует событие для дедупликации доставки; пусто - по содержимому.
+	Key string
+	// StartsAt и EndsAt - интервал события (для алертов: срабатывание и разрешение).
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+// Notifier - необязательная возможность транспорта отправлять уведомления.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Notify отправляет уведомление в транспорты names; пустой список - во все,
+// которые реализуют Notifier. Ошибки отдельных транспортов объединяются.
+func (m *TransportManager) Notify(ctx context.Context, names []string, n Notification) error {
+	m.mu.Lock()
+	targets := make(map[string]Notifier)
+	var errs []error
+	if len(names) == 0 {
+		for name, tr := range m.transports {
+			if nt, ok := tr.adapter.(Notifier); ok {
+				targets[name] = nt
+			}
+		}
+	}
+	for _, name := range names {
+		tr, ok := m.transports[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %w", name, errUnknownTransport))
+			continue
+		}
+		nt, ok := tr.adapter.(Notifier)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %w", name, ErrNotifyUnsupported))
+			continue
+		}
+		targets[name] = nt
+	}
+	m.mu.Unlock()
+
+	for name, nt := range targets {
+		if err := nt.Notify(ctx, n); err != nil {
+			errs = append(errs, fmt.Errorf("notify %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -3,7 +3,11 @@ package core
 import (
 	"context"
 	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type fakeTransport struct {
@@ -63,3 +67,175 @@ func TestTransportManagerStopOneUnknown(t *testing.T) {
 		t.Fatalf("expected errUnknownTransport, got: %v", err)
 	}
 }
+
+type notifyingTransport struct {
+	fakeTransport
+	sent []Notification
+	err  error
+}
+
+func (n *notifyingTransport) Notify(ctx context.Context, msg Notification) error {
+	n.sent = append(n.sent, msg)
+	return n.err
+}
+
+func TestTransportManagerNotify(t *testing.T) {
+	mgr := NewTransportManager()
+	chat := &notifyingTransport{fakeTransport: fakeTransport{name: "chat"}}
+	broken := &notifyingTransport{fakeTransport: fakeTransport{name: "broken"}, err: errors.New("down")}
+	for _, tr := range []TransportAdapter{chat, broken, &fakeTransport{name: "web"}} {
+		if err := mgr.Register(tr); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	msg := Notification{Topic: "alerts", Text: "hi"}
+
+	if err := mgr.Notify(context.Background(), nil, msg); err == nil || len(chat.sent) != 1 || len(broken.sent) != 1 {
+		t.Fatalf("broadcast: err=%v chat=%d broken=%d", err, len(chat.sent), len(broken.sent))
+	}
+	if err := mgr.Notify(context.Background(), []string{"chat"}, msg); err != nil || len(chat.sent) != 2 {
+		t.Fatalf("targeted: err=%v chat=%d", err, len(chat.sent))
+	}
+	err := mgr.Notify(context.Background(), []string{"chat", "web", "missing"}, msg)
+	if !errors.Is(err, ErrNotifyUnsupported) || !errors.Is(err, errUnknownTransport) || len(chat.sent) != 3 {
+		t.Fatalf("mixed: err=%v chat=%d", err, len(chat.sent))
+	}
+}
+
+// supervisedTransport записывает вызовы в общий журнал и может упасть после Start.
+type supervisedTransport struct {
+	name     string
+	log      *callLog
+	startErr error
+	stopWait bool
+	failed   chan error
+}
+
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, s)
+}
+
+func (l *callLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+func (s *supervisedTransport) Name() string { return s.name }
+
+func (s *supervisedTransport) Start(ctx context.Context) error {
+	s.log.add("start " + s.name)
+	return s.startErr
+}
+
+func (s *supervisedTransport) Stop(ctx context.Context) error {
+	s.log.add("stop " + s.name)
+	if s.stopWait {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return nil
+}
+
+func (s *supervisedTransport) Failed() <-chan error { return s.failed }
+
+func TestTransportManagerStartRollback(t *testing.T) {
+	log := &callLog{}
+	mgr := NewTransportManager()
+	for _, tr := range []*supervisedTransport{
+		{name: "a", log: log},
+		{name: "b", log: log},
+		{name: "c", log: log, startErr: errors.New("bind: address in use")},
+		{name: "d", log: log},
+	} {
+		if err := mgr.Register(tr); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	err := mgr.StartAll(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start transport c") {
+		t.Fatalf("expected start error, got %v", err)
+	}
+	want := []string{"start a", "start b", "start c", "stop b", "stop a"}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	st := mgr.Status()
+	if st[0].State != TransportStopped || st[2].State != TransportFailed || st[2].LastError != "bind: address in use" || st[3].State != TransportStopped {
+		t.Fatalf("status = %+v", st)
+	}
+}
+
+func TestTransportManagerRestartsFailed(t *testing.T) {
+	log := &callLog{}
+	tr := &supervisedTransport{name: "web", log: log, failed: make(chan error, 1)}
+	mgr := NewTransportManager()
+	mgr.SetRestartPolicy(RestartPolicy{Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	if err := mgr.Register(tr); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := mgr.StartAll(ctx); err != nil {
+		t.Fatalf("start all: %v", err)
+	}
+	tr.failed <- errors.New("serve: listener closed")
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		st := mgr.Status()[0]
+		if st.State == TransportRunning && st.Restarts == 1 {
+			if st.LastError != "serve: listener closed" || st.LastErrorAt == nil {
+				t.Fatalf("status = %+v", st)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("transport not restarted: %+v", st)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := mgr.StopAll(ctx); err != nil {
+		t.Fatalf("stop all: %v", err)
+	}
+	want := []string{"start web", "stop web", "start web", "stop web"}
+	if got := log.get(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("calls = %v, want %v", got, want)
+	}
+	if st := mgr.Status()[0]; st.State != TransportStopped {
+		t.Fatalf("state after stop = %s", st.State)
+	}
+}
+
+func TestTransportManagerStopAllDeadline(t *testing.T) {
+	log := &callLog{}
+	mgr := NewTransportManager()
+	for _, tr := range []TransportAdapter{
+		&supervisedTransport{name: "stuck", log: log, stopWait: true},
+		&fakeTransport{name: "broken", stopErr: errors.New("close failed")},
+		&supervisedTransport{name: "ok", log: log},
+	} {
+		if err := mgr.Register(tr); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	err := mgr.StopAll(ctx)
+	if time.Since(started) > time.Second {
+		t.Fatalf("StopAll must respect the deadline")
+	}
+	if err == nil || !strings.Contains(err.Error(), "stop transport stuck") || !strings.Contains(err.Error(), "close failed") {
+		t.Fatalf("expected aggregated errors, got %v", err)
+	}
+	if st := mgr.Status(); st[2].State != TransportStopped || st[1].State != TransportFailed {
+		t.Fatalf("status = %+v", st)
+	}
+}
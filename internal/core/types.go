@@ -15,3 +15,63 @@ type CommandProvider interface {
 	Init(ctx context.Context) error
 	Execute(ctx context.Context, cmd string, args []string) (Response, error)
 }
+
+// CommandOptions - именованные аргументы команды, разобранные транспортом:
+// key=value в Params, --name и --name=value в Flags. Сами аргументы модуль
+// по-прежнему получает в args целиком.
+type CommandOptions struct {
+	Params map[string]string
+	Flags  map[string]string
+}
+
+type optionsKey struct{}
+
+// WithCommandOptions сохраняет именованные аргументы команды в контексте.
+func WithCommandOptions(ctx context.Context, opts CommandOptions) context.Context {
+	return context.WithValue(ctx, optionsKey{}, opts)
+}
+
+// CommandOptionsFromContext возвращает аргументы, сохраненные WithCommandOptions.
+func CommandOptionsFromContext(ctx context.Context) (CommandOptions, bool) {
+	opts, ok := ctx.Value(optionsKey{}).(CommandOptions)
+	return opts, ok
+}
+
+// Templater - необязательный интерфейс модуля: шаблоны текста ответа по
+// именам команд (text/template, функции см. internal/render).
+type Templater interface {
+	Templates() map[string]string
+}
+
+// CommandInfo описывает команду модуля для чат-меню.
+type CommandInfo struct {
+	Name    string
+	Summary string
+	// Usage - аргументы для ручного ввода, например "<duration> <matcher>...".
+	Usage string
+	// Mutating - команда меняет состояние; в чате исполняется после подтверждения.
+	Mutating bool
+	// Args - позиционные аргументы. Команда собирается кнопками, если у
+	// каждого обязательного аргумента есть Choices или Dynamic.
+	Args []ArgInfo
+}
+
+// ArgInfo - аргумент команды для выбора кнопками.
+type ArgInfo struct {
+	Name     string
+	Choices  []string
+	Optional bool
+	// Dynamic - варианты отдает Completer модуля.
+	Dynamic bool
+}
+
+// Describer - необязательный интерфейс модуля: список команд для меню.
+type Describer interface {
+	Commands() []CommandInfo
+}
+
+// Completer - необязательный интерфейс модуля: варианты следующего
+// аргумента команды (например, id активных объектов).
+type Completer interface {
+	Complete(ctx context.Context, command string, args []string) ([]string, error)
+}
@@ -0,0 +1,58 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"goadmin/internal/core"
+)
+
+// ErrOutsideWindow - действие разрешено только внутри окна обслуживания.
+var ErrOutsideWindow = errors.New("action is allowed only inside a maintenance window")
+
+// Policy ограничивает действие Action ("module:command", "*" в любой части,
+// "module" - все команды модуля) окнами Windows; пустой список - любое активное окно.
+type Policy struct {
+	Action  string
+	Windows []string
+}
+
+func (p Policy) matches(action core.Action) bool {
+	module, command, ok := strings.Cut(p.Action, ":")
+	if !ok {
+		command = "*"
+	}
+	return (module == "*" || module == action.Module) && (command == "*" || command == action.Command)
+}
+
+// Authorizer дополняет next политикой окон обслуживания.
+type Authorizer struct {
+	next     core.Authorizer
+	manager  *Manager
+	policies []Policy
+}
+
+// NewAuthorizer оборачивает next; без политик решения next не меняются.
+func NewAuthorizer(next core.Authorizer, m *Manager, policies []Policy) *Authorizer {
+	return &Authorizer{next: next, manager: m, policies: policies}
+}
+
+// Authorize сначала спрашивает next, затем требует активное окно для действий из политик.
+func (a *Authorizer) Authorize(subject core.Subject, action core.Action) error {
+	if err := a.next.Authorize(subject, action); err != nil {
+		return err
+	}
+	for _, p := range a.policies {
+		if !p.matches(action) {
+			continue
+		}
+		if _, ok := a.manager.ActiveWindow(p.Windows); !ok {
+			if len(p.Windows) == 0 {
+				return fmt.Errorf("%s:%s: %w", action.Module, action.Command, ErrOutsideWindow)
+			}
+			return fmt.Errorf("%s:%s: %w (%s)", action.Module, action.Command, ErrOutsideWindow, strings.Join(p.Windows, ", "))
+		}
+	}
+	return nil
+}
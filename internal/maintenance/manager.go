@@ -0,0 +1,632 @@
+// Package maintenance ведет silences и окна обслуживания: они подавляют
+// уведомления по меткам, а окна еще и открывают действия, разрешенные
+// политикой только во время обслуживания.
+package maintenance
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// Source - источник событий аудита об истечении.
+const Source = "maintenance"
+
+// Состояния silence и окна в списках.
+const (
+	StatePending = "pending"
+	StateActive  = "active"
+	StateExpired = "expired"
+)
+
+var (
+	errInvalidSilence = errors.New("invalid silence")
+	errInvalidWindow  = errors.New("invalid maintenance window")
+	windowName        = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// IsInvalid сообщает, что ошибка вызвана неверными параметрами silence или окна.
+func IsInvalid(err error) bool {
+	return errors.Is(err, errInvalidSilence) || errors.Is(err, errInvalidWindow) || errors.Is(err, errInvalidMatcher)
+}
+
+// Options задает зависимости менеджера.
+type Options struct {
+	// Audit фиксирует создание, снятие и истечение; nil - без аудита.
+	Audit storage.AuditWriter
+	// Now подменяет часы в тестах.
+	Now func() time.Time
+}
+
+type silence struct {
+	storage.Silence
+	matchers []matcher
+}
+
+type window struct {
+	storage.MaintenanceWindow
+	sched    core.Schedule
+	matchers []matcher
+}
+
+// Manager держит silences и окна в памяти; источник истины - хранилище,
+// изменения других процессов (CLI) подхватываются в Refresh.
+type Manager struct {
+	store storage.MaintenanceStore
+	opts  Options
+
+	mu         sync.RWMutex
+	silences   []silence
+	windows    []window
+	suppressed uint64
+	// lastTick - граница, после которой Tick аудирует завершившиеся
+	// повторения окон по расписанию.
+	lastTick time.Time
+}
+
+// NewManager создает менеджер; без хранилища (nil) состояние живет только в памяти.
+func NewManager(st storage.MaintenanceStore, opts Options) *Manager {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Manager{store: st, opts: opts, lastTick: opts.Now()}
+}
+
+// Refresh перечитывает silences и окна из хранилища.
+func (m *Manager) Refresh(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	sils, err := m.store.Silences(ctx)
+	if err != nil {
+		return fmt.Errorf("load silences: %w", err)
+	}
+	wins, err := m.store.MaintenanceWindows(ctx)
+	if err != nil {
+		return fmt.Errorf("load maintenance windows: %w", err)
+	}
+	silences := make([]silence, 0, len(sils))
+	for _, s := range sils {
+		c, err := newSilence(s)
+		if err != nil {
+			slog.Warn("skip invalid silence", "id", s.ID, "err", err)
+			continue
+		}
+		silences = append(silences, c)
+	}
+	windows := make([]window, 0, len(wins))
+	for _, w := range wins {
+		c, err := newWindow(w)
+		if err != nil {
+			slog.Warn("skip invalid maintenance window", "name", w.Name, "err", err)
+			continue
+		}
+		windows = append(windows, c)
+	}
+	m.mu.Lock()
+	m.silences, m.windows = silences, windows
+	m.mu.Unlock()
+	return nil
+}
+
+// Tick перечитывает состояние и аудирует истекшие silences и окна, в том
+// числе каждое завершившееся повторение окна по расписанию; выполняется
+// задачей планировщика.
+func (m *Manager) Tick(ctx context.Context) error {
+	if err := m.Refresh(ctx); err != nil {
+		return err
+	}
+	now := m.opts.Now()
+	m.mu.Lock()
+	var (
+		sils []storage.Silence
+		wins []storage.MaintenanceWindow
+		occs []windowOccurrence
+	)
+	for i := range m.silences {
+		s := &m.silences[i]
+		if !s.ExpiryAudited && !now.Before(s.EndsAt) {
+			s.ExpiryAudited = true
+			sils = append(sils, s.Silence)
+		}
+	}
+	for i := range m.windows {
+		w := &m.windows[i]
+		if !w.ExpiryAudited && w.EndsAt != nil && !now.Before(*w.EndsAt) {
+			w.ExpiryAudited = true
+			wins = append(wins, w.MaintenanceWindow)
+		}
+		occs = append(occs, w.endedBetween(m.lastTick, now)...)
+	}
+	m.lastTick = now
+	m.mu.Unlock()
+
+	var errs []error
+	for _, s := range sils {
+		m.audit(ctx, core.Subject{Source: Source, ID: s.ID}, "silence:expired", s)
+		if m.store != nil {
+			if err := m.store.SaveSilence(ctx, s); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for _, o := range occs {
+		m.audit(ctx, core.Subject{Source: Source, ID: o.Name}, "maintenance:expired", o)
+	}
+	for _, w := range wins {
+		m.audit(ctx, core.Subject{Source: Source, ID: w.Name}, "maintenance:expired", w)
+		if m.store != nil {
+			if err := m.store.SaveMaintenanceWindow(ctx, w); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SilenceSpec - параметры нового silence. Пустой StartsAt - сейчас; конец
+// задается EndsAt или Duration.
+type SilenceSpec struct {
+	Matchers []string
+	StartsAt time.Time
+	EndsAt   time.Time
+	Duration time.Duration
+	Comment  string
+}
+
+// CreateSilence создает silence и аудирует его как silence:create.
+func (m *Manager) CreateSilence(ctx context.Context, spec SilenceSpec, by core.Subject) (storage.Silence, error) {
+	now := m.opts.Now()
+	matchers, err := ParseMatchers(spec.Matchers)
+	if err != nil {
+		return storage.Silence{}, err
+	}
+	if len(matchers) == 0 {
+		return storage.Silence{}, fmt.Errorf("%w: at least one matcher is required", errInvalidSilence)
+	}
+	s := storage.Silence{
+		ID:        newID(),
+		Matchers:  matchers,
+		StartsAt:  spec.StartsAt,
+		EndsAt:    spec.EndsAt,
+		Comment:   spec.Comment,
+		CreatedBy: by.ID,
+		CreatedAt: now,
+	}
+	if s.StartsAt.IsZero() {
+		s.StartsAt = now
+	}
+	if s.EndsAt.IsZero() && spec.Duration > 0 {
+		s.EndsAt = s.StartsAt.Add(spec.Duration)
+	}
+	if !s.EndsAt.After(s.StartsAt) || !s.EndsAt.After(now) {
+		return storage.Silence{}, fmt.Errorf("%w: ends_at must be in the future and after starts_at", errInvalidSilence)
+	}
+	c, err := newSilence(s)
+	if err != nil {
+		return storage.Silence{}, err
+	}
+	if m.store != nil {
+		if err := m.store.SaveSilence(ctx, s); err != nil {
+			return storage.Silence{}, err
+		}
+	}
+	m.mu.Lock()
+	m.silences = append([]silence{c}, m.silences...)
+	m.mu.Unlock()
+	m.audit(ctx, by, "silence:create", s)
+	return s, nil
+}
+
+// ExpireSilence досрочно завершает silence (silence:expire).
+func (m *Manager) ExpireSilence(ctx context.Context, id string, by core.Subject) (storage.Silence, error) {
+	now := m.opts.Now()
+	m.mu.Lock()
+	idx := -1
+	for i, s := range m.silences {
+		if s.ID == id {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		m.mu.Unlock()
+		return storage.Silence{}, fmt.Errorf("silence %s: %w", id, storage.ErrNotFound)
+	}
+	s := m.silences[idx].Silence
+	if !now.Before(s.EndsAt) {
+		m.mu.Unlock()
+		return s, nil
+	}
+	s.EndsAt = now
+	if s.StartsAt.After(now) {
+		s.StartsAt = now
+	}
+	s.ExpiryAudited = true
+	m.silences[idx].Silence = s
+	m.mu.Unlock()
+
+	if m.store != nil {
+		if err := m.store.SaveSilence(ctx, s); err != nil {
+			return storage.Silence{}, err
+		}
+	}
+	m.audit(ctx, by, "silence:expire", s)
+	return s, nil
+}
+
+// SilenceStatus - silence с текущим состоянием.
+type SilenceStatus struct {
+	storage.Silence
+	State string `json:"state"`
+}
+
+// Silences возвращает действующие и будущие silences (all - и истекшие), новые первыми.
+func (m *Manager) Silences(all bool) []SilenceStatus {
+	now := m.opts.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]SilenceStatus, 0, len(m.silences))
+	for _, s := range m.silences {
+		st := SilenceStatus{Silence: s.Silence, State: s.state(now)}
+		if all || st.State != StateExpired {
+			out = append(out, st)
+		}
+	}
+	return out
+}
+
+// WindowSpec - параметры окна обслуживания. Разовое окно: StartsAt (по
+// умолчанию сейчас) и EndsAt или Duration. Повторяющееся: cron Schedule и Duration.
+type WindowSpec struct {
+	Name     string
+	Schedule string
+	Duration time.Duration
+	StartsAt *time.Time
+	EndsAt   *time.Time
+	Matchers []string
+	Comment  string
+}
+
+// CreateWindow создает или заменяет окно (maintenance:create).
+func (m *Manager) CreateWindow(ctx context.Context, spec WindowSpec, by core.Subject) (WindowStatus, error) {
+	now := m.opts.Now()
+	if !windowName.MatchString(spec.Name) {
+		return WindowStatus{}, fmt.Errorf("%w: name %q must match %s", errInvalidWindow, spec.Name, windowName)
+	}
+	matchers, err := ParseMatchers(spec.Matchers)
+	if err != nil {
+		return WindowStatus{}, err
+	}
+	w := storage.MaintenanceWindow{
+		Name:      spec.Name,
+		Schedule:  strings.TrimSpace(spec.Schedule),
+		Duration:  spec.Duration,
+		StartsAt:  spec.StartsAt,
+		EndsAt:    spec.EndsAt,
+		Matchers:  matchers,
+		Comment:   spec.Comment,
+		CreatedBy: by.ID,
+		CreatedAt: now,
+	}
+	if w.Schedule == "" {
+		if w.StartsAt == nil {
+			w.StartsAt = &now
+		}
+		if w.EndsAt == nil && w.Duration > 0 {
+			end := w.StartsAt.Add(w.Duration)
+			w.EndsAt = &end
+		}
+		if w.EndsAt == nil || !w.EndsAt.After(*w.StartsAt) || !w.EndsAt.After(now) {
+			return WindowStatus{}, fmt.Errorf("%w: one-off window needs ends_at in the future and after starts_at", errInvalidWindow)
+		}
+		w.Duration = w.EndsAt.Sub(*w.StartsAt)
+	}
+	c, err := newWindow(w)
+	if err != nil {
+		return WindowStatus{}, err
+	}
+	if m.store != nil {
+		if err := m.store.SaveMaintenanceWindow(ctx, w); err != nil {
+			return WindowStatus{}, err
+		}
+	}
+	m.mu.Lock()
+	replaced := false
+	for i := range m.windows {
+		if m.windows[i].Name == w.Name {
+			m.windows[i], replaced = c, true
+		}
+	}
+	if !replaced {
+		m.windows = append(m.windows, c)
+		sort.Slice(m.windows, func(i, j int) bool { return m.windows[i].Name < m.windows[j].Name })
+	}
+	m.mu.Unlock()
+	st := c.status(now)
+	m.audit(ctx, by, "maintenance:create", st)
+	return st, nil
+}
+
+// DeleteWindow удаляет окно (maintenance:delete).
+func (m *Manager) DeleteWindow(ctx context.Context, name string, by core.Subject) error {
+	m.mu.Lock()
+	idx := -1
+	for i := range m.windows {
+		if m.windows[i].Name == name {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		m.mu.Unlock()
+		return fmt.Errorf("maintenance window %s: %w", name, storage.ErrNotFound)
+	}
+	w := m.windows[idx].MaintenanceWindow
+	m.windows = append(m.windows[:idx:idx], m.windows[idx+1:]...)
+	m.mu.Unlock()
+
+	if m.store != nil {
+		if err := m.store.DeleteMaintenanceWindow(ctx, name); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	m.audit(ctx, by, "maintenance:delete", w)
+	return nil
+}
+
+// WindowStatus - окно с признаком активности и ближайшим началом.
+type WindowStatus struct {
+	storage.MaintenanceWindow
+	DurationMS int64      `json:"duration_ms"`
+	State      string     `json:"state"`
+	NextStart  *time.Time `json:"next_start,omitempty"`
+}
+
+// Windows возвращает окна по имени.
+func (m *Manager) Windows() []WindowStatus {
+	now := m.opts.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	out := make([]WindowStatus, 0, len(m.windows))
+	for _, w := range m.windows {
+		out = append(out, w.status(now))
+	}
+	return out
+}
+
+func (w window) status(now time.Time) WindowStatus {
+	st := WindowStatus{MaintenanceWindow: w.MaintenanceWindow, DurationMS: w.Duration.Milliseconds(), State: StatePending}
+	switch {
+	case w.activeAt(now):
+		st.State = StateActive
+	case w.EndsAt != nil && !now.Before(*w.EndsAt):
+		st.State = StateExpired
+	}
+	st.NextStart = w.nextStart(now)
+	return st
+}
+
+// ActiveWindow возвращает имя активного окна из names (пустой список - любое).
+func (m *Manager) ActiveWindow(names []string) (string, bool) {
+	now := m.opts.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, w := range m.windows {
+		if (len(names) == 0 || contains(names, w.Name)) && w.activeAt(now) {
+			return w.Name, true
+		}
+	}
+	return "", false
+}
+
+// Silenced сообщает, подавлено ли уведомление с метками labels, и чем:
+// "silence:<id>" или "maintenance:<name>".
+func (m *Manager) Silenced(labels map[string]string) (string, bool) {
+	now := m.opts.Now()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, s := range m.silences {
+		if s.state(now) == StateActive && matchAll(s.matchers, labels) {
+			return "silence:" + s.ID, true
+		}
+	}
+	for _, w := range m.windows {
+		if w.activeAt(now) && matchAll(w.matchers, labels) {
+			return "maintenance:" + w.Name, true
+		}
+	}
+	return "", false
+}
+
+// Suppressed возвращает число подавленных уведомлений.
+func (m *Manager) Suppressed() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.suppressed
+}
+
+// Filter оборачивает notifier: уведомления под действующим silence или окном
+// не отправляются. К меткам добавляется topic.
+func (m *Manager) Filter(next core.Notifier) core.Notifier {
+	return &filter{m: m, next: next}
+}
+
+type filter struct {
+	m    *Manager
+	next core.Notifier
+}
+
+func (f *filter) Notify(ctx context.Context, n core.Notification) error {
+	labels := make(map[string]string, len(n.Labels)+1)
+	for k, v := range n.Labels {
+		labels[k] = v
+	}
+	if _, ok := labels["topic"]; !ok {
+		labels["topic"] = n.Topic
+	}
+	if by, ok := f.m.Silenced(labels); ok {
+		f.m.mu.Lock()
+		f.m.suppressed++
+		f.m.mu.Unlock()
+		slog.Info("notification silenced", "topic", n.Topic, "title", n.Title, "by", by)
+		return nil
+	}
+	return f.next.Notify(ctx, n)
+}
+
+func (m *Manager) audit(ctx context.Context, by core.Subject, action string, payload interface{}) {
+	if m.opts.Audit == nil {
+		return
+	}
+	raw, _ := json.Marshal(payload)
+	if err := m.opts.Audit.Write(context.WithoutCancel(ctx), storage.AuditEvent{
+		Subject: by.ID,
+		Action:  action,
+		Source:  by.Source,
+		Status:  "ok",
+		Payload: raw,
+	}); err != nil {
+		slog.Warn("maintenance audit failed", "action", action, "err", err)
+	}
+}
+
+func newSilence(s storage.Silence) (silence, error) {
+	ms, err := compileMatchers(s.Matchers)
+	if err != nil {
+		return silence{}, err
+	}
+	return silence{Silence: s, matchers: ms}, nil
+}
+
+func (s silence) state(now time.Time) string {
+	switch {
+	case now.Before(s.StartsAt):
+		return StatePending
+	case now.Before(s.EndsAt):
+		return StateActive
+	default:
+		return StateExpired
+	}
+}
+
+func newWindow(w storage.MaintenanceWindow) (window, error) {
+	ms, err := compileMatchers(w.Matchers)
+	if err != nil {
+		return window{}, err
+	}
+	c := window{MaintenanceWindow: w, matchers: ms}
+	if w.Schedule == "" {
+		if w.StartsAt == nil || w.EndsAt == nil {
+			return window{}, fmt.Errorf("%w: %s: one-off window needs starts_at and ends_at", errInvalidWindow, w.Name)
+		}
+		return c, nil
+	}
+	if strings.HasPrefix(w.Schedule, "@every") {
+		return window{}, fmt.Errorf("%w: %s: use a cron expression, not @every", errInvalidWindow, w.Name)
+	}
+	if w.Duration <= 0 {
+		return window{}, fmt.Errorf("%w: %s: recurring window needs a positive duration", errInvalidWindow, w.Name)
+	}
+	sched, err := core.ParseSchedule(w.Schedule)
+	if err != nil {
+		return window{}, fmt.Errorf("%w: %s: %v", errInvalidWindow, w.Name, err)
+	}
+	c.sched = sched
+	return c, nil
+}
+
+// activeAt: разовое окно - [StartsAt, EndsAt); повторяющееся - последний старт
+// по расписанию не раньше t-Duration.
+func (w window) activeAt(t time.Time) bool {
+	if w.StartsAt != nil && t.Before(*w.StartsAt) {
+		return false
+	}
+	if w.EndsAt != nil && !t.Before(*w.EndsAt) {
+		return false
+	}
+	if w.sched == nil {
+		return true
+	}
+	return !w.sched.Next(t.Add(-w.Duration)).After(t)
+}
+
+// windowOccurrence - завершившееся повторение окна по расписанию (payload аудита).
+type windowOccurrence struct {
+	storage.MaintenanceWindow
+	OccurrenceStart time.Time `json:"occurrence_start"`
+	OccurrenceEnd   time.Time `json:"occurrence_end"`
+}
+
+// maxOccurrencesPerTick ограничивает аудит после долгого перерыва между Tick.
+const maxOccurrencesPerTick = 100
+
+// endedBetween возвращает повторения окна, завершившиеся в (from, to].
+func (w window) endedBetween(from, to time.Time) []windowOccurrence {
+	if w.sched == nil || !from.Before(to) {
+		return nil
+	}
+	var out []windowOccurrence
+	for start := w.sched.Next(from.Add(-w.Duration)); !start.IsZero() && !start.Add(w.Duration).After(to); start = w.sched.Next(start) {
+		// Повторение, которое обрезает EndsAt, аудируется как истечение окна.
+		if w.EndsAt != nil && start.Add(w.Duration).After(*w.EndsAt) {
+			break
+		}
+		if w.StartsAt != nil && start.Before(*w.StartsAt) {
+			continue
+		}
+		out = append(out, windowOccurrence{MaintenanceWindow: w.MaintenanceWindow, OccurrenceStart: start, OccurrenceEnd: start.Add(w.Duration)})
+		if len(out) == maxOccurrencesPerTick {
+			break
+		}
+	}
+	return out
+}
+
+func (w window) nextStart(t time.Time) *time.Time {
+	var next time.Time
+	switch {
+	case w.sched == nil:
+		if !t.Before(*w.StartsAt) {
+			return nil
+		}
+		next = *w.StartsAt
+	default:
+		from := t
+		if w.StartsAt != nil && w.StartsAt.After(t) {
+			from = w.StartsAt.Add(-time.Nanosecond)
+		}
+		next = w.sched.Next(from)
+		if next.IsZero() || (w.EndsAt != nil && !next.Before(*w.EndsAt)) {
+			return nil
+		}
+	}
+	return &next
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,222 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+)
+
+type recorder struct {
+	sent []core.Notification
+}
+
+func (r *recorder) Notify(ctx context.Context, n core.Notification) error {
+	r.sent = append(r.sent, n)
+	return nil
+}
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time { return c.now }
+
+var alice = core.Subject{Source: "web", ID: "alice"}
+
+func TestParseMatcher(t *testing.T) {
+	for in, want := range map[string]storage.LabelMatcher{
+		"alertname=high_memory": {Name: "alertname", Op: "=", Value: "high_memory"},
+		"team!=ops":             {Name: "team", Op: "!=", Value: "ops"},
+		"severity=~crit.*":      {Name: "severity", Op: "=~", Value: "crit.*"},
+		"job!~host_.*":          {Name: "job", Op: "!~", Value: "host_.*"},
+	} {
+		got, err := ParseMatcher(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMatcher(%q) = %+v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "alertname", "=x", "a!x", "a=~("} {
+		if _, err := ParseMatcher(in); !IsInvalid(err) {
+			t.Fatalf("ParseMatcher(%q) must fail, got %v", in, err)
+		}
+	}
+}
+
+func TestSilenceSuppressesAndExpires(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	clk := &clock{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	m := NewManager(st, Options{Audit: st, Now: clk.Now})
+	notes := &recorder{}
+	notifier := m.Filter(notes)
+
+	if _, err := m.CreateSilence(ctx, SilenceSpec{Duration: time.Hour}, alice); !IsInvalid(err) {
+		t.Fatalf("silence without matchers must be rejected, got %v", err)
+	}
+	sil, err := m.CreateSilence(ctx, SilenceSpec{Matchers: []string{"alertname=high_memory", "severity=~critical|warning"}, Duration: time.Hour, Comment: "swap"}, alice)
+	if err != nil {
+		t.Fatalf("create silence: %v", err)
+	}
+	firing := core.Notification{Topic: "alerts", Labels: map[string]string{"alertname": "high_memory", "severity": "critical"}}
+	other := core.Notification{Topic: "alerts", Labels: map[string]string{"alertname": "high_load", "severity": "critical"}}
+	_ = notifier.Notify(ctx, firing)
+	_ = notifier.Notify(ctx, other)
+	if len(notes.sent) != 1 || notes.sent[0].Labels["alertname"] != "high_load" || m.Suppressed() != 1 {
+		t.Fatalf("unexpected deliveries: %+v suppressed=%d", notes.sent, m.Suppressed())
+	}
+
+	// Другой процесс (CLI) видит silence после Refresh.
+	cli := NewManager(st, Options{Now: clk.Now})
+	if err := cli.Refresh(ctx); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if by, ok := cli.Silenced(firing.Labels); !ok || by != "silence:"+sil.ID {
+		t.Fatalf("refreshed manager must see silence, got %q %v", by, ok)
+	}
+
+	clk.now = clk.now.Add(2 * time.Hour)
+	if err := m.Tick(ctx); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	_ = m.Tick(ctx)
+	_ = notifier.Notify(ctx, firing)
+	if len(notes.sent) != 2 {
+		t.Fatalf("expired silence must not suppress, got %d", len(notes.sent))
+	}
+	if got := m.Silences(false); len(got) != 0 {
+		t.Fatalf("expired silence listed as active: %+v", got)
+	}
+	if got := m.Silences(true); len(got) != 1 || got[0].State != StateExpired {
+		t.Fatalf("unexpected all silences: %+v", got)
+	}
+
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{Limit: 10})
+	if err != nil {
+		t.Fatalf("query audit: %v", err)
+	}
+	actions := map[string]int{}
+	for _, ev := range events {
+		actions[ev.Action]++
+	}
+	if actions["silence:create"] != 1 || actions["silence:expired"] != 1 {
+		t.Fatalf("unexpected audit: %v", actions)
+	}
+}
+
+func TestExpireSilence(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	clk := &clock{now: time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)}
+	m := NewManager(st, Options{Audit: st, Now: clk.Now})
+	sil, err := m.CreateSilence(ctx, SilenceSpec{Matchers: []string{"topic=jobs"}, Duration: time.Hour}, alice)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := m.ExpireSilence(ctx, "missing", alice); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	got, err := m.ExpireSilence(ctx, sil.ID, alice)
+	if err != nil || !got.EndsAt.Equal(clk.now) {
+		t.Fatalf("expire: %+v %v", got, err)
+	}
+	if _, ok := m.Silenced(map[string]string{"topic": "jobs"}); ok {
+		t.Fatal("expired silence still active")
+	}
+	// Ручное снятие уже в аудите: Tick не пишет silence:expired.
+	_ = m.Tick(ctx)
+	events, _ := st.QueryAudit(ctx, storage.AuditQuery{Limit: 10})
+	for _, ev := range events {
+		if ev.Action == "silence:expired" {
+			t.Fatalf("manual expiry audited twice: %+v", events)
+		}
+	}
+}
+
+func TestWindowsAndPolicy(t *testing.T) {
+	ctx := context.Background()
+	st := memory.New()
+	// Понедельник 01:30 UTC.
+	clk := &clock{now: time.Date(2026, 3, 2, 1, 30, 0, 0, time.UTC)}
+	m := NewManager(st, Options{Audit: st, Now: clk.Now})
+	if _, err := m.CreateWindow(ctx, WindowSpec{Name: "nightly", Schedule: "0 2 * * *", Duration: time.Hour}, alice); err != nil {
+		t.Fatalf("create recurring: %v", err)
+	}
+	if _, err := m.CreateWindow(ctx, WindowSpec{Name: "bad", Schedule: "@every 1h", Duration: time.Hour}, alice); !IsInvalid(err) {
+		t.Fatalf("@every window must be rejected, got %v", err)
+	}
+	if _, err := m.CreateWindow(ctx, WindowSpec{Name: "bad name"}, alice); !IsInvalid(err) {
+		t.Fatalf("bad name must be rejected, got %v", err)
+	}
+
+	authz := NewAuthorizer(core.NewAllowlistAuthorizer(map[string][]string{"web": {"alice"}}), m, []Policy{
+		{Action: "schedules:run", Windows: []string{"nightly"}},
+		{Action: "exec"},
+	})
+	run := core.Action{Module: "schedules", Command: "run"}
+	if err := authz.Authorize(alice, run); !errors.Is(err, ErrOutsideWindow) {
+		t.Fatalf("expected ErrOutsideWindow, got %v", err)
+	}
+	if err := authz.Authorize(alice, core.Action{Module: "schedules", Command: "read"}); err != nil {
+		t.Fatalf("unrestricted action denied: %v", err)
+	}
+	if err := authz.Authorize(core.Subject{Source: "web", ID: "bob"}, core.Action{Module: "alerts", Command: "list"}); err == nil || errors.Is(err, ErrOutsideWindow) {
+		t.Fatalf("allowlist must still apply, got %v", err)
+	}
+	if w := m.Windows(); len(w) != 1 || w[0].State != StatePending || w[0].NextStart == nil || w[0].NextStart.Hour() != 2 {
+		t.Fatalf("unexpected windows: %+v", w)
+	}
+
+	clk.now = clk.now.Add(45 * time.Minute) // 02:15
+	if err := authz.Authorize(alice, run); err != nil {
+		t.Fatalf("inside window: %v", err)
+	}
+	if err := authz.Authorize(alice, core.Action{Module: "exec", Command: "run"}); err != nil {
+		t.Fatalf("any window must satisfy policy without names: %v", err)
+	}
+	if by, ok := m.Silenced(map[string]string{"topic": "jobs"}); !ok || by != "maintenance:nightly" {
+		t.Fatalf("window without matchers must silence everything, got %q %v", by, ok)
+	}
+	clk.now = clk.now.Add(time.Hour) // 03:15
+	if err := authz.Authorize(alice, run); !errors.Is(err, ErrOutsideWindow) {
+		t.Fatalf("window is over, got %v", err)
+	}
+
+	// Разовое окно с matchers подавляет только подходящие уведомления и истекает с аудитом.
+	if _, err := m.CreateWindow(ctx, WindowSpec{Name: "db-upgrade", Duration: 30 * time.Minute, Matchers: []string{"team=dba"}}, alice); err != nil {
+		t.Fatalf("create one-off: %v", err)
+	}
+	if _, ok := m.Silenced(map[string]string{"team": "ops"}); ok {
+		t.Fatal("window matchers ignored")
+	}
+	if _, ok := m.Silenced(map[string]string{"team": "dba"}); !ok {
+		t.Fatal("one-off window must silence matching labels")
+	}
+	clk.now = clk.now.Add(time.Hour)
+	if err := m.Tick(ctx); err != nil {
+		t.Fatalf("tick: %v", err)
+	}
+	// Повторение 02:00-03:00 уже в аудите: следующий Tick его не повторяет.
+	_ = m.Tick(ctx)
+	if err := m.DeleteWindow(ctx, "nightly", alice); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := m.DeleteWindow(ctx, "nightly", alice); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	events, _ := st.QueryAudit(ctx, storage.AuditQuery{Limit: 20})
+	actions := map[string]int{}
+	for _, ev := range events {
+		actions[ev.Action]++
+		if ev.Action == "maintenance:expired" && ev.Subject == "nightly" &&
+			!strings.Contains(string(ev.Payload), `"occurrence_end":"2026-03-02T03:00:00Z"`) {
+			t.Fatalf("unexpected occurrence payload: %s", ev.Payload)
+		}
+	}
+	if actions["maintenance:create"] != 2 || actions["maintenance:expired"] != 2 || actions["maintenance:delete"] != 1 {
+		t.Fatalf("unexpected audit: %v", actions)
+	}
+}
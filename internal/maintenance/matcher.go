@@ -0,0 +1,113 @@
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"goadmin/internal/storage"
+)
+
+var errInvalidMatcher = errors.New("invalid label matcher")
+
+// ParseMatcher разбирает "name=value", "name!=value", "name=~regex" или "name!~regex".
+// Регулярное выражение должно совпадать со всем значением метки.
+func ParseMatcher(s string) (storage.LabelMatcher, error) {
+	i := strings.IndexAny(s, "=!")
+	if i <= 0 {
+		return storage.LabelMatcher{}, fmt.Errorf("%w: %q: expected name=value", errInvalidMatcher, s)
+	}
+	m := storage.LabelMatcher{Name: strings.TrimSpace(s[:i])}
+	rest := s[i:]
+	for _, op := range []string{storage.MatchNotRegexp, storage.MatchRegexp, storage.MatchNotEqual, storage.MatchEqual} {
+		if strings.HasPrefix(rest, op) {
+			m.Op = op
+			m.Value = strings.TrimSpace(rest[len(op):])
+			break
+		}
+	}
+	if m.Op == "" || m.Name == "" {
+		return storage.LabelMatcher{}, fmt.Errorf("%w: %q", errInvalidMatcher, s)
+	}
+	if _, err := compileMatcher(m); err != nil {
+		return storage.LabelMatcher{}, err
+	}
+	return m, nil
+}
+
+// ParseMatchers разбирает список matchers.
+func ParseMatchers(list []string) ([]storage.LabelMatcher, error) {
+	out := make([]storage.LabelMatcher, 0, len(list))
+	for _, s := range list {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+	}
+	return out, nil
+}
+
+// FormatMatcher возвращает matcher в текстовом виде.
+func FormatMatcher(m storage.LabelMatcher) string {
+	return m.Name + m.Op + m.Value
+}
+
+type matcher struct {
+	storage.LabelMatcher
+	re *regexp.Regexp
+}
+
+func compileMatcher(m storage.LabelMatcher) (matcher, error) {
+	out := matcher{LabelMatcher: m}
+	switch m.Op {
+	case storage.MatchEqual, storage.MatchNotEqual:
+	case storage.MatchRegexp, storage.MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + m.Value + ")$")
+		if err != nil {
+			return matcher{}, fmt.Errorf("%w: %s: %v", errInvalidMatcher, FormatMatcher(m), err)
+		}
+		out.re = re
+	default:
+		return matcher{}, fmt.Errorf("%w: unknown operator %q", errInvalidMatcher, m.Op)
+	}
+	return out, nil
+}
+
+func compileMatchers(list []storage.LabelMatcher) ([]matcher, error) {
+	out := make([]matcher, 0, len(list))
+	for _, m := range list {
+		c, err := compileMatcher(m)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// matches проверяет метку; отсутствующая метка считается пустой строкой.
+func (m matcher) matches(labels map[string]string) bool {
+	v := labels[m.Name]
+	switch m.Op {
+	case storage.MatchEqual:
+		return v == m.Value
+	case storage.MatchNotEqual:
+		return v != m.Value
+	case storage.MatchRegexp:
+		return m.re.MatchString(v)
+	default:
+		return !m.re.MatchString(v)
+	}
+}
+
+// matchAll возвращает true, если подходят все matchers (пустой список подходит всегда).
+func matchAll(list []matcher, labels map[string]string) bool {
+	for _, m := range list {
+		if !m.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
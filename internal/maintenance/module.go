@@ -0,0 +1,177 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// SilenceModule отдает silences как модуль ядра (в чатах /silence ...):
+//
+//	silence list [all]
+//	silence add <duration> <matcher>... [-- комментарий]
+//	silence expire <id>
+type SilenceModule struct{ m *Manager }
+
+// NewSilenceModule создает модуль silence.
+func NewSilenceModule(m *Manager) *SilenceModule { return &SilenceModule{m: m} }
+
+func (s *SilenceModule) Name() string { return "silence" }
+
+func (s *SilenceModule) Init(ctx context.Context) error { return nil }
+
+func (s *SilenceModule) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	by := subjectOf(ctx)
+	switch cmd {
+	case "list":
+		return core.Response{Status: "ok", Data: s.m.Silences(len(args) > 0 && args[0] == "all")}, nil
+	case "add":
+		args, comment := splitComment(args)
+		if len(args) < 2 {
+			return badArgs("usage: silence add <duration> <matcher>... [-- comment]")
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil || d <= 0 {
+			return badArgs("bad duration " + args[0])
+		}
+		sil, err := s.m.CreateSilence(ctx, SilenceSpec{Matchers: args[1:], Duration: d, Comment: comment}, by)
+		return result(sil, err)
+	case "expire":
+		if len(args) != 1 {
+			return badArgs("usage: silence expire <id>")
+		}
+		sil, err := s.m.ExpireSilence(ctx, args[0], by)
+		return result(sil, err)
+	default:
+		return core.Response{Status: "error", ErrorCode: "unknown_command"}, fmt.Errorf("command %s not supported", cmd)
+	}
+}
+
+// Commands описывает команды для чат-меню (core.Describer).
+func (s *SilenceModule) Commands() []core.CommandInfo {
+	return []core.CommandInfo{
+		{Name: "list", Summary: "Active silences", Args: []core.ArgInfo{{Name: "all", Choices: []string{"all"}, Optional: true}}},
+		{Name: "add", Summary: "Create a silence", Usage: "<duration> <matcher>... [-- comment]", Mutating: true,
+			Args: []core.ArgInfo{{Name: "duration"}, {Name: "matcher"}}},
+		{Name: "expire", Summary: "Expire a silence", Usage: "<id>", Mutating: true, Args: []core.ArgInfo{{Name: "id", Dynamic: true}}},
+	}
+}
+
+// Complete предлагает id активных silences для expire (core.Completer).
+func (s *SilenceModule) Complete(ctx context.Context, command string, args []string) ([]string, error) {
+	if command != "expire" || len(args) != 0 {
+		return nil, nil
+	}
+	var ids []string
+	for _, st := range s.m.Silences(false) {
+		ids = append(ids, st.ID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// WindowModule управляет окнами обслуживания из чата (/maintenance ...):
+//
+//	maintenance list
+//	maintenance start <name> <duration> [matcher...] [-- комментарий]
+//	maintenance delete <name>
+//
+// Повторяющиеся окна задаются через API или CLI.
+type WindowModule struct{ m *Manager }
+
+// NewWindowModule создает модуль maintenance.
+func NewWindowModule(m *Manager) *WindowModule { return &WindowModule{m: m} }
+
+func (w *WindowModule) Name() string { return "maintenance" }
+
+func (w *WindowModule) Init(ctx context.Context) error { return nil }
+
+func (w *WindowModule) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	by := subjectOf(ctx)
+	switch cmd {
+	case "list":
+		return core.Response{Status: "ok", Data: w.m.Windows()}, nil
+	case "start":
+		args, comment := splitComment(args)
+		if len(args) < 2 {
+			return badArgs("usage: maintenance start <name> <duration> [matcher...] [-- comment]")
+		}
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return badArgs("bad duration " + args[1])
+		}
+		win, err := w.m.CreateWindow(ctx, WindowSpec{Name: args[0], Duration: d, Matchers: args[2:], Comment: comment}, by)
+		return result(win, err)
+	case "delete":
+		if len(args) != 1 {
+			return badArgs("usage: maintenance delete <name>")
+		}
+		err := w.m.DeleteWindow(ctx, args[0], by)
+		return result(map[string]string{"deleted": args[0]}, err)
+	default:
+		return core.Response{Status: "error", ErrorCode: "unknown_command"}, fmt.Errorf("command %s not supported", cmd)
+	}
+}
+
+// Commands описывает команды для чат-меню (core.Describer).
+func (w *WindowModule) Commands() []core.CommandInfo {
+	return []core.CommandInfo{
+		{Name: "list", Summary: "Maintenance windows"},
+		{Name: "start", Summary: "Start a maintenance window", Usage: "<name> <duration> [matcher...] [-- comment]", Mutating: true,
+			Args: []core.ArgInfo{{Name: "name"}, {Name: "duration"}}},
+		{Name: "delete", Summary: "Delete a maintenance window", Usage: "<name>", Mutating: true, Args: []core.ArgInfo{{Name: "name", Dynamic: true}}},
+	}
+}
+
+// Complete предлагает имена окон для delete (core.Completer).
+func (w *WindowModule) Complete(ctx context.Context, command string, args []string) ([]string, error) {
+	if command != "delete" || len(args) != 0 {
+		return nil, nil
+	}
+	var names []string
+	for _, st := range w.m.Windows() {
+		names = append(names, st.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func subjectOf(ctx context.Context) core.Subject {
+	if s, ok := core.SubjectFromContext(ctx); ok {
+		return s
+	}
+	return core.Subject{Source: "module", ID: "unknown"}
+}
+
+// splitComment отделяет комментарий после "--".
+func splitComment(args []string) ([]string, string) {
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], strings.Join(args[i+1:], " ")
+		}
+	}
+	return args, ""
+}
+
+func badArgs(msg string) (core.Response, error) {
+	return core.Response{Status: "error", ErrorCode: "bad_args"}, errors.New(msg)
+}
+
+func result(data interface{}, err error) (core.Response, error) {
+	switch {
+	case err == nil:
+		return core.Response{Status: "ok", Data: data}, nil
+	case errors.Is(err, storage.ErrNotFound):
+		return core.Response{Status: "error", ErrorCode: "not_found"}, err
+	case IsInvalid(err):
+		return core.Response{Status: "error", ErrorCode: "bad_args"}, err
+	default:
+		return core.Response{Status: "error", ErrorCode: "internal_error"}, err
+	}
+}
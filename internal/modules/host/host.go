@@ -3,6 +3,7 @@ package host
 import (
 	"context"
 	"fmt"
+	"runtime"
 	"time"
 
 	"github.com/shirou/gopsutil/v3/host"
@@ -56,6 +57,23 @@ func (m *Module) status(ctx context.Context) (core.Response, error) {
 		"load1":        ld.Load1,
 		"load5":        ld.Load5,
 		"load15":       ld.Load15,
+		"cores":        runtime.NumCPU(),
 	}
 	return core.Response{Status: "ok", Data: resp}, nil
 }
+
+// statusTemplate - текст ответа host status в чатах и CLI.
+const statusTemplate = `{{bold .Data.hostname}} ({{.Data.platform}} {{.Data.platformVer}}, kernel {{.Data.kernel}})
+uptime: {{duration .Data.uptime_sec}}, boot {{.Data.boot_time}}
+load: {{.Data.load1}} / {{.Data.load5}} / {{.Data.load15}} on {{.Data.cores}} cores
+memory: {{bytes .Data.mem_used}} of {{bytes .Data.mem_total}} ({{pct .Data.mem_used_pct}})`
+
+// Templates задает шаблоны ответов (core.Templater).
+func (m *Module) Templates() map[string]string {
+	return map[string]string{"status": statusTemplate}
+}
+
+// Commands описывает команды для чат-меню (core.Describer).
+func (m *Module) Commands() []core.CommandInfo {
+	return []core.CommandInfo{{Name: "status", Summary: "Host status"}}
+}
@@ -3,6 +3,9 @@ package host
 import (
 	"context"
 	"testing"
+
+	"goadmin/internal/core"
+	"goadmin/internal/render"
 )
 
 func TestUnknownCommand(t *testing.T) {
@@ -13,3 +16,24 @@ func TestUnknownCommand(t *testing.T) {
 		t.Fatalf("expected error for unknown command")
 	}
 }
+
+func TestStatusTemplate(t *testing.T) {
+	r, err := render.New(map[string]string{"host:status": (&Module{}).Templates()["status"]})
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	resp := core.Response{Status: "ok", Data: map[string]interface{}{
+		"hostname": "db_1", "platform": "debian", "platformVer": "12", "kernel": "6.1",
+		"uptime_sec": 90000, "boot_time": "2026-01-01T00:00:00Z",
+		"mem_total": 4 << 30, "mem_used": 1 << 30, "mem_used_pct": 25.0,
+		"load1": 0.5, "load5": 0.25, "load15": 0.1, "cores": 4,
+	}}
+	got := r.Render(render.Target{Format: render.MarkdownV2}, "host", "status", resp, nil)
+	want := "*db\\_1* \\(debian 12, kernel 6\\.1\\)\n" +
+		"uptime: 1d 1h, boot 2026\\-01\\-01T00:00:00Z\n" +
+		"load: 0\\.5 / 0\\.25 / 0\\.1 on 4 cores\n" +
+		"memory: 1\\.0 GiB of 4\\.0 GiB \\(25\\.0%\\)"
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,397 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultMask подставляется вместо скрытых значений.
+const DefaultMask = "[REDACTED]"
+
+// DefaultFields - имена полей и ключей, значения которых всегда скрываются.
+var DefaultFields = []string{
+	"password", "passwd", "pwd", "secret", "token", "apikey", "api_key",
+	"authorization", "private_key", "access_key", "credentials",
+}
+
+// DefaultPatterns - выражения для секретов в свободном тексте. Если в выражении
+// есть группа, скрывается только первая группа, иначе все совпадение.
+var DefaultPatterns = []Pattern{
+	{Name: "private_key", Regex: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{Name: "bearer", Regex: `(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{8,})`},
+	{Name: "jwt", Regex: `\beyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]{5,}`},
+	{Name: "aws_access_key", Regex: `\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "url_credentials", Regex: `://[^/\s:@]+:([^/\s@]+)@`},
+}
+
+// Pattern - именованное регулярное выражение.
+type Pattern struct {
+	Name  string
+	Regex string
+}
+
+// CommandRule помечает чувствительные аргументы команды module:command.
+// Args - позиции (с нуля), Keys - имена для key=value и --key value.
+type CommandRule struct {
+	Command string
+	Args    []int
+	Keys    []string
+}
+
+// Config задает правила редактирования.
+type Config struct {
+	Mask     string
+	Fields   []string
+	Patterns []Pattern
+	Commands []CommandRule
+}
+
+// Decision объясняет, что произошло с одним значением.
+type Decision struct {
+	Index    int      `json:"index"`
+	Input    string   `json:"input"`
+	Output   string   `json:"output"`
+	Redacted bool     `json:"redacted"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+type compiledPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+type commandRule struct {
+	args map[int]struct{}
+	keys []string
+}
+
+// Redactor скрывает секреты в аргументах, тексте и структурах.
+// Нулевой *Redactor ничего не меняет.
+type Redactor struct {
+	mask     string
+	fields   []string
+	patterns []compiledPattern
+	commands map[string]commandRule
+}
+
+// New компилирует правила; пустые Fields/Patterns заменяются значениями по умолчанию.
+func New(cfg Config) (*Redactor, error) {
+	r := &Redactor{mask: cfg.Mask, commands: make(map[string]commandRule, len(cfg.Commands))}
+	if r.mask == "" {
+		r.mask = DefaultMask
+	}
+	fields := append([]string(nil), cfg.Fields...)
+	if len(fields) == 0 {
+		fields = append(fields, DefaultFields...)
+	}
+	for _, f := range fields {
+		if n := normalizeKey(f); n != "" {
+			r.fields = append(r.fields, n)
+		}
+	}
+	// Длинные имена первыми, чтобы "api_key" не срезался до "key" в key=value.
+	sort.Slice(fields, func(i, j int) bool { return len(fields[i]) > len(fields[j]) })
+
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = DefaultPatterns
+	}
+	for i, p := range patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", p.Name, err)
+		}
+		name := p.Name
+		if name == "" {
+			name = fmt.Sprintf("pattern-%d", i)
+		}
+		r.patterns = append(r.patterns, compiledPattern{name: name, re: re})
+	}
+	if len(fields) > 0 {
+		quoted := make([]string, 0, len(fields))
+		for _, f := range fields {
+			quoted = append(quoted, regexp.QuoteMeta(f))
+		}
+		re, err := regexp.Compile(`(?i)(?:` + strings.Join(quoted, "|") + `)"?\s*[=:]\s*"?([^\s"&,;]+)`)
+		if err != nil {
+			return nil, fmt.Errorf("redaction fields: %w", err)
+		}
+		r.patterns = append(r.patterns, compiledPattern{name: "field_assignment", re: re})
+	}
+
+	for _, rule := range cfg.Commands {
+		if !strings.Contains(rule.Command, ":") {
+			return nil, fmt.Errorf("redaction command %q: expected module:command", rule.Command)
+		}
+		cr := commandRule{args: make(map[int]struct{}, len(rule.Args))}
+		for _, idx := range rule.Args {
+			cr.args[idx] = struct{}{}
+		}
+		for _, k := range rule.Keys {
+			cr.keys = append(cr.keys, normalizeKey(k))
+		}
+		r.commands[rule.Command] = cr
+	}
+	return r, nil
+}
+
+// Default возвращает Redactor со встроенными правилами.
+func Default() *Redactor {
+	r, err := New(Config{})
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Mask возвращает строку-заменитель.
+func (r *Redactor) Mask() string {
+	if r == nil {
+		return DefaultMask
+	}
+	return r.mask
+}
+
+// String скрывает секреты в произвольном тексте.
+func (r *Redactor) String(s string) string {
+	out, _ := r.explainString(s)
+	return out
+}
+
+func (r *Redactor) explainString(s string) (string, []string) {
+	if r == nil || s == "" {
+		return s, nil
+	}
+	var reasons []string
+	for _, p := range r.patterns {
+		matches := p.re.FindAllStringSubmatchIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var b strings.Builder
+		last, replaced := 0, false
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			if s[start:end] == r.mask {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(r.mask)
+			last, replaced = end, true
+		}
+		if !replaced {
+			continue
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+		reasons = append(reasons, "pattern:"+p.name)
+	}
+	return s, reasons
+}
+
+// IsSensitiveField сообщает, скрывается ли значение поля с таким именем.
+func (r *Redactor) IsSensitiveField(name string) bool {
+	if r == nil {
+		return false
+	}
+	return matchField(r.fields, normalizeKey(name))
+}
+
+// Args возвращает копию аргументов команды со скрытыми секретами.
+func (r *Redactor) Args(module, command string, args []string) []string {
+	if r == nil || len(args) == 0 {
+		return args
+	}
+	decisions := r.ExplainArgs(module, command, args)
+	out := make([]string, len(decisions))
+	for i, d := range decisions {
+		out[i] = d.Output
+	}
+	return out
+}
+
+// ExplainArgs применяет правила к аргументам и объясняет каждое решение.
+func (r *Redactor) ExplainArgs(module, command string, args []string) []Decision {
+	decisions := make([]Decision, len(args))
+	var rule commandRule
+	if r != nil {
+		rule = r.commands[module+":"+command]
+	}
+	maskNext := ""
+	for i, arg := range args {
+		d := Decision{Index: i, Input: arg, Output: arg}
+		if r == nil {
+			decisions[i] = d
+			continue
+		}
+		switch {
+		case hasIndex(rule.args, i):
+			d.Output, d.Reasons = r.mask, []string{"command_arg"}
+		case maskNext != "":
+			d.Output, d.Reasons = r.mask, []string{maskNext}
+		default:
+			if key, _, ok := splitKeyValue(arg); ok && r.sensitiveKey(rule, key) {
+				d.Output = arg[:strings.Index(arg, "=")+1] + r.mask
+				d.Reasons = []string{"field:" + normalizeKey(key)}
+			} else {
+				d.Output, d.Reasons = r.explainString(arg)
+			}
+		}
+		maskNext = ""
+		if flag, ok := bareFlag(arg); ok && r.sensitiveKey(rule, flag) {
+			maskNext = "flag:" + normalizeKey(flag)
+		}
+		d.Redacted = d.Output != d.Input
+		if !d.Redacted {
+			d.Reasons = nil
+		}
+		decisions[i] = d
+	}
+	return decisions
+}
+
+func (r *Redactor) sensitiveKey(rule commandRule, key string) bool {
+	n := normalizeKey(key)
+	return matchField(r.fields, n) || matchField(rule.keys, n)
+}
+
+// Value скрывает секреты в значении, сериализуемом в JSON: поля с
+// чувствительными именами и совпадения шаблонов в строках. Если скрывать
+// нечего, возвращается исходное значение без изменений.
+func (r *Redactor) Value(v interface{}) interface{} {
+	if r == nil || v == nil {
+		return v
+	}
+	if s, ok := v.(string); ok {
+		return r.String(s)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	tree, changed, err := r.redactJSON(raw)
+	if err != nil || !changed {
+		return v
+	}
+	return tree
+}
+
+// JSON скрывает секреты в JSON-документе; невалидный JSON обрабатывается как текст.
+func (r *Redactor) JSON(raw []byte) []byte {
+	if r == nil || len(raw) == 0 {
+		return raw
+	}
+	tree, changed, err := r.redactJSON(raw)
+	if err != nil {
+		return []byte(r.String(string(raw)))
+	}
+	if !changed {
+		return raw
+	}
+	out, err := json.Marshal(tree)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+func (r *Redactor) redactJSON(raw []byte) (interface{}, bool, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, false, err
+	}
+	tree, changed := r.walk(tree)
+	return tree, changed, nil
+}
+
+func (r *Redactor) walk(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		changed := false
+		for k, item := range val {
+			if r.IsSensitiveField(k) {
+				if s, ok := item.(string); ok && s == r.mask {
+					continue
+				}
+				if item != nil {
+					val[k] = r.mask
+					changed = true
+				}
+				continue
+			}
+			next, c := r.walk(item)
+			val[k] = next
+			changed = changed || c
+		}
+		return val, changed
+	case []interface{}:
+		changed := false
+		for i, item := range val {
+			next, c := r.walk(item)
+			val[i] = next
+			changed = changed || c
+		}
+		return val, changed
+	case string:
+		out := r.String(val)
+		return out, out != val
+	default:
+		return v, false
+	}
+}
+
+// normalizeKey приводит имя к виду без регистра, дефисов и подчеркиваний.
+func normalizeKey(k string) string {
+	k = strings.TrimLeft(k, "-")
+	k = strings.ToLower(k)
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(k)
+}
+
+// matchField: имя совпадает с полем или оканчивается на него (db_password, github_token).
+func matchField(fields []string, name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, f := range fields {
+		if name == f || strings.HasSuffix(name, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasIndex(set map[int]struct{}, i int) bool {
+	_, ok := set[i]
+	return ok
+}
+
+// splitKeyValue разбирает key=value и --key=value.
+func splitKeyValue(arg string) (string, string, bool) {
+	idx := strings.Index(arg, "=")
+	if idx <= 0 {
+		return "", "", false
+	}
+	key := strings.TrimLeft(arg[:idx], "-")
+	if key == "" || strings.ContainsAny(key, " \t") {
+		return "", "", false
+	}
+	return key, arg[idx+1:], true
+}
+
+// bareFlag распознает флаг без значения (--password), за которым идет значение.
+func bareFlag(arg string) (string, bool) {
+	if !strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+		return "", false
+	}
+	name := strings.TrimLeft(arg, "-")
+	return name, name != ""
+}
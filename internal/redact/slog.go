@@ -0,0 +1,70 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler скрывает секреты в сообщении и атрибутах записей slog.
+type Handler struct {
+	next slog.Handler
+	r    *Redactor
+}
+
+// NewHandler оборачивает next; nil-Redactor оставляет записи без изменений.
+func NewHandler(next slog.Handler, r *Redactor) *Handler {
+	return &Handler{next: next, r: r}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, rec slog.Record) error {
+	out := slog.NewRecord(rec.Time, rec.Level, h.r.String(rec.Message), rec.PC)
+	rec.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, h.attr(a))
+	}
+	return &Handler{next: h.next.WithAttrs(redacted), r: h.r}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), r: h.r}
+}
+
+func (h *Handler) attr(a slog.Attr) slog.Attr {
+	if h.r == nil {
+		return a
+	}
+	v := a.Value.Resolve()
+	if h.r.IsSensitiveField(a.Key) && v.Kind() != slog.KindGroup {
+		return slog.String(a.Key, h.r.Mask())
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, h.r.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			redacted = append(redacted, h.attr(ga))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, h.r.String(err.Error()))
+		}
+		return slog.Any(a.Key, h.r.Value(v.Any()))
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
+}
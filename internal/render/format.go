@@ -0,0 +1,109 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Format - разметка текста транспорта.
+type Format string
+
+const (
+	// Plain - текст без разметки (CLI, fallback).
+	Plain Format = "plain"
+	// HTML - подмножество HTML Telegram и MaxBot: <b>, <code>, <pre>.
+	HTML Format = "html"
+	// MarkdownV2 - разметка Telegram MarkdownV2.
+	MarkdownV2 Format = "markdownv2"
+	// Markdown - разметка MaxBot и Mattermost.
+	Markdown Format = "markdown"
+)
+
+// ParseFormat разбирает имя разметки из конфига; пусто, text и plain - Plain.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case "", "text", Plain:
+		return Plain, nil
+	case HTML, MarkdownV2, Markdown:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown render format %q", s)
+	}
+}
+
+// Markup - уже экранированный фрагмент; шаблон выводит его без изменений.
+type Markup string
+
+// mdV2Special - символы, экранируемые в тексте MarkdownV2.
+const mdV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// mdSpecial - символы, экранируемые в тексте Markdown MaxBot.
+const mdSpecial = "_*[]()~`\\"
+
+func backslash(s, special string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Escape экранирует обычный текст для разметки f.
+func Escape(f Format, s string) string {
+	switch f {
+	case HTML:
+		return html.EscapeString(s)
+	case MarkdownV2:
+		return backslash(s, mdV2Special)
+	case Markdown:
+		return backslash(s, mdSpecial)
+	default:
+		return s
+	}
+}
+
+// Bold - выделенный текст.
+func Bold(f Format, s string) Markup {
+	switch f {
+	case HTML:
+		return Markup("<b>" + html.EscapeString(s) + "</b>")
+	case MarkdownV2:
+		return Markup("*" + Escape(f, s) + "*")
+	case Markdown:
+		return Markup("**" + Escape(f, s) + "**")
+	default:
+		return Markup(s)
+	}
+}
+
+// Code - моноширинный фрагмент в строке.
+func Code(f Format, s string) Markup {
+	switch f {
+	case HTML:
+		return Markup("<code>" + html.EscapeString(s) + "</code>")
+	case MarkdownV2:
+		return Markup("`" + backslash(s, "`\\") + "`")
+	case Markdown:
+		return Markup("`" + strings.ReplaceAll(s, "`", "'") + "`")
+	default:
+		return Markup(s)
+	}
+}
+
+// Pre - моноширинный блок (таблицы).
+func Pre(f Format, s string) Markup {
+	switch f {
+	case HTML:
+		return Markup("<pre>" + html.EscapeString(s) + "</pre>")
+	case MarkdownV2:
+		return Markup("```\n" + backslash(s, "`\\") + "\n```")
+	case Markdown:
+		return Markup("```\n" + strings.ReplaceAll(s, "```", "'''") + "\n```")
+	default:
+		return Markup(s)
+	}
+}
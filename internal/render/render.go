@@ -0,0 +1,237 @@
+// Package render превращает core.Response в читаемый текст транспорта:
+// разметка и экранирование по платформе, единицы измерения, таблицы и
+// обрезка до лимита сообщения. Модули задают свои шаблоны через
+// core.Templater.
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"goadmin/internal/core"
+)
+
+// Target - разметка и лимит длины сообщения (UTF-16 символы, 0 - без лимита).
+type Target struct {
+	Format Format
+	Limit  int
+}
+
+// Renderer хранит шаблоны модулей; nil Renderer рендерит по умолчанию.
+type Renderer struct {
+	// templates[format]["module:command"]
+	templates map[Format]map[string]*template.Template
+}
+
+// View - данные шаблона; Data приведен к JSON-виду (map, []interface{},
+// json.Number, string, bool).
+type View struct {
+	Module  string
+	Command string
+	Status  string
+	Data    interface{}
+}
+
+// New разбирает шаблоны "module:command" -> text/template. Литеральный
+// текст шаблона и вывод действий экранируются автоматически; функции bold,
+// code, pre, table, bytes, pct, duration, ms и value возвращают Markup.
+func New(templates map[string]string) (*Renderer, error) {
+	r := &Renderer{templates: map[Format]map[string]*template.Template{}}
+	for _, f := range []Format{Plain, HTML, MarkdownV2, Markdown} {
+		r.templates[f] = map[string]*template.Template{}
+		for key, text := range templates {
+			t, err := parseTemplate(f, key, text)
+			if err != nil {
+				return nil, fmt.Errorf("template %s: %w", key, err)
+			}
+			r.templates[f][key] = t
+		}
+	}
+	return r, nil
+}
+
+// FromRegistry собирает шаблоны модулей реестра.
+func FromRegistry(reg *core.Registry) (*Renderer, error) {
+	return New(reg.Templates())
+}
+
+// Render возвращает текст ответа на команду module command; ошибка
+// показывается кодом и текстом. Текст обрезается до t.Limit.
+func (r *Renderer) Render(t Target, module, command string, resp core.Response, err error) string {
+	f := t.Format
+	if f == "" {
+		f = Plain
+	}
+	var out string
+	switch {
+	case err != nil || resp.Status == "error":
+		out = renderError(f, resp, err)
+	default:
+		out = r.renderData(f, module, command, resp)
+	}
+	return Truncate(f, out, t.Limit)
+}
+
+func (r *Renderer) renderData(f Format, module, command string, resp core.Response) string {
+	view := View{Module: module, Command: command, Status: resp.Status, Data: normalize(resp.Data)}
+	if r != nil {
+		if tmpl, ok := r.templates[f][module+":"+command]; ok {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, view); err == nil {
+				return strings.TrimSpace(buf.String())
+			}
+		}
+	}
+	return renderDefault(f, view)
+}
+
+func renderError(f Format, resp core.Response, err error) string {
+	code := resp.ErrorCode
+	if code == "" {
+		code = "error"
+	}
+	if err == nil {
+		return string(Bold(f, code))
+	}
+	return string(Bold(f, code)) + "\n" + Escape(f, err.Error())
+}
+
+func funcs(f Format) template.FuncMap {
+	return template.FuncMap{
+		"_esc":     func(v interface{}) Markup { return escapeValue(f, v) },
+		"bold":     func(v interface{}) Markup { return Bold(f, plainValue(v)) },
+		"code":     func(v interface{}) Markup { return Code(f, plainValue(v)) },
+		"pre":      func(v interface{}) Markup { return Pre(f, plainValue(v)) },
+		"table":    func(rows interface{}, cols ...string) Markup { return Pre(f, Table(rows, cols)) },
+		"bytes":    func(v interface{}) Markup { return Markup(Escape(f, Bytes(v))) },
+		"pct":      func(v interface{}) Markup { return Markup(Escape(f, Percent(v))) },
+		"duration": func(v interface{}) Markup { return Markup(Escape(f, Seconds(v))) },
+		"ms":       func(v interface{}) Markup { return Markup(Escape(f, Millis(v))) },
+		"value":    func(v interface{}) Markup { return Markup(Escape(f, plainValue(v))) },
+	}
+}
+
+func escapeValue(f Format, v interface{}) Markup {
+	if m, ok := v.(Markup); ok {
+		return m
+	}
+	return Markup(Escape(f, plainValue(v)))
+}
+
+// parseTemplate разбирает шаблон и дописывает экранирование: литералы
+// экранируются сразу, к каждому выводящему действию добавляется _esc.
+func parseTemplate(f Format, name, text string) (*template.Template, error) {
+	t, err := template.New(name).Option("missingkey=zero").Funcs(funcs(f)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil {
+			escapeNode(f, tt.Tree, tt.Tree.Root)
+		}
+	}
+	return t, nil
+}
+
+func escapeNode(f Format, tree *parse.Tree, n parse.Node) {
+	switch n := n.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			escapeNode(f, tree, c)
+		}
+	case *parse.TextNode:
+		n.Text = []byte(Escape(f, string(n.Text)))
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) > 0 {
+			return
+		}
+		n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      n.Pos,
+			Args:     []parse.Node{parse.NewIdentifier("_esc").SetTree(tree).SetPos(n.Pos)},
+		})
+	case *parse.IfNode:
+		escapeNode(f, tree, n.List)
+		escapeNode(f, tree, n.ElseList)
+	case *parse.RangeNode:
+		escapeNode(f, tree, n.List)
+		escapeNode(f, tree, n.ElseList)
+	case *parse.WithNode:
+		escapeNode(f, tree, n.List)
+		escapeNode(f, tree, n.ElseList)
+	}
+}
+
+// renderDefault - заголовок "module command" и поля "key: value" с
+// единицами по суффиксу ключа; списки объектов - таблицей.
+func renderDefault(f Format, v View) string {
+	var b strings.Builder
+	b.WriteString(string(Bold(f, strings.TrimSpace(v.Module+" "+v.Command))))
+	switch data := v.Data.(type) {
+	case nil:
+		b.WriteString("\n" + Escape(f, "ok"))
+	case map[string]interface{}:
+		writeFields(f, &b, data, "")
+	case []interface{}:
+		b.WriteString("\n" + listValue(f, data))
+	default:
+		b.WriteString("\n" + Escape(f, plainValue(data)))
+	}
+	return b.String()
+}
+
+func writeFields(f Format, b *strings.Builder, m map[string]interface{}, indent string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b.WriteString("\n" + indent + Escape(f, k+":"))
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			if len(v) == 0 {
+				b.WriteString(" -")
+				continue
+			}
+			writeFields(f, b, v, indent+"  ")
+		case []interface{}:
+			if isTable(v) {
+				b.WriteString("\n" + listValue(f, v))
+				continue
+			}
+			b.WriteString(" " + Escape(f, plainValue(v)))
+		default:
+			b.WriteString(" " + Escape(f, Keyed(k, v)))
+		}
+	}
+}
+
+func listValue(f Format, items []interface{}) string {
+	if len(items) == 0 {
+		return Escape(f, "(empty)")
+	}
+	if isTable(items) {
+		return string(Pre(f, Table(items, nil)))
+	}
+	return Escape(f, plainValue(items))
+}
+
+func isTable(items []interface{}) bool {
+	if len(items) == 0 {
+		return false
+	}
+	for _, it := range items {
+		if _, ok := it.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package render
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"goadmin/internal/core"
+)
+
+func TestEscapeFormats(t *testing.T) {
+	cases := []struct {
+		f    Format
+		want string
+	}{
+		{Plain, "a_b <c> 1.5 (x)"},
+		{HTML, "a_b &lt;c&gt; 1.5 (x)"},
+		{MarkdownV2, `a\_b <c\> 1\.5 \(x\)`},
+		{Markdown, `a\_b <c> 1.5 \(x\)`},
+	}
+	for _, tc := range cases {
+		if got := Escape(tc.f, "a_b <c> 1.5 (x)"); got != tc.want {
+			t.Errorf("%s: %q, want %q", tc.f, got, tc.want)
+		}
+	}
+	if got := Escape(MarkdownV2, "v1.2-rc!"); got != `v1\.2\-rc\!` {
+		t.Fatalf("markdownv2 = %q", got)
+	}
+}
+
+func TestTemplateAutoEscape(t *testing.T) {
+	r, err := New(map[string]string{
+		"disk:usage": `{{bold .Data.mount}} used {{bytes .Data.used}} ({{pct .Data.used_pct}}) - {{.Data.note}}`,
+	})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	resp := core.Response{Status: "ok", Data: map[string]interface{}{
+		"mount": "/var_log", "used": 3 << 30, "used_pct": 71.25, "note": "<ok> & 1.0",
+	}}
+	cases := map[Format]string{
+		Plain:      "/var_log used 3.0 GiB (71.2%) - <ok> & 1.0",
+		HTML:       "<b>/var_log</b> used 3.0 GiB (71.2%) - &lt;ok&gt; &amp; 1.0",
+		MarkdownV2: `*/var\_log* used 3\.0 GiB \(71\.2%\) \- <ok\> & 1\.0`,
+	}
+	for f, want := range cases {
+		if got := r.Render(Target{Format: f}, "disk", "usage", resp, nil); got != want {
+			t.Errorf("%s:\n got %q\nwant %q", f, got, want)
+		}
+	}
+	if _, err := New(map[string]string{"x:y": "{{"}); err == nil {
+		t.Fatal("broken template accepted")
+	}
+}
+
+func TestDefaultRendering(t *testing.T) {
+	var r *Renderer
+	resp := core.Response{Status: "ok", Data: map[string]interface{}{
+		"uptime_sec": 93784,
+		"disk_bytes": 1536,
+		"load_pct":   12.345,
+		"jobs": []map[string]interface{}{
+			{"name": "backup", "last_ms": 1500},
+			{"name": "rotate-logs", "last_ms": 20},
+		},
+	}}
+	got := r.Render(Target{Format: HTML}, "host", "info", resp, nil)
+	for _, want := range []string{
+		"<b>host info</b>",
+		"disk_bytes: 1.5 KiB",
+		"load_pct: 12.3%",
+		"uptime_sec: 1d 2h",
+		"<pre>last_ms  name\n1.5s     backup\n20ms     rotate-logs</pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	if got := r.Render(Target{}, "alerts", "list", core.Response{Status: "ok", Data: []string{}}, nil); got != "alerts list\n(empty)" {
+		t.Fatalf("empty list = %q", got)
+	}
+	errText := r.Render(Target{Format: MarkdownV2}, "host", "status", core.Response{Status: "error", ErrorCode: "access_denied"}, errors.New("subject not allowed."))
+	if errText != "*access\\_denied*\nsubject not allowed\\." {
+		t.Fatalf("error = %q", errText)
+	}
+}
+
+func TestTruncateClosesBlocks(t *testing.T) {
+	rows := make([]map[string]interface{}, 200)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"name": "job-<" + strings.Repeat("x", 20) + ">"}
+	}
+	var r *Renderer
+	for _, f := range []Format{HTML, MarkdownV2} {
+		got := r.Render(Target{Format: f, Limit: 1000}, "jobs", "list", core.Response{Status: "ok", Data: rows}, nil)
+		if n := utf16Len(got); n > 1000 {
+			t.Fatalf("%s: %d units", f, n)
+		}
+		if !strings.HasSuffix(got, Escape(f, truncatedMark)) {
+			t.Fatalf("%s: no truncation mark: %q", f, got[len(got)-40:])
+		}
+		if f == HTML && strings.Count(got, "<pre>") != strings.Count(got, "</pre>") {
+			t.Fatalf("unbalanced pre: %q", got[len(got)-60:])
+		}
+		if f == MarkdownV2 && strings.Count(got, "```")%2 != 0 {
+			t.Fatalf("unclosed code block: %q", got[len(got)-60:])
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": Plain, "HTML": HTML, "MarkdownV2": MarkdownV2, "markdown": Markdown} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("%q = %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseFormat("bbcode"); err == nil {
+		t.Fatal("unknown format accepted")
+	}
+}
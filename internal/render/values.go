@@ -0,0 +1,333 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// normalize приводит данные модуля к JSON-виду: структуры превращаются в
+// map по json-тегам, числа - в json.Number.
+func normalize(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return fmt.Sprint(v)
+	}
+	return out
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// plainValue - текст значения без единиц: списки через запятую, объекты -
+// "k=v" по ключам.
+func plainValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "-"
+	case Markup:
+		return string(x)
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, 0, len(x))
+		for _, it := range x {
+			parts = append(parts, plainValue(it))
+		}
+		return strings.Join(parts, ", ")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, k := range keys {
+			parts = append(parts, k+"="+plainValue(x[k]))
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// Keyed форматирует значение по суффиксу ключа: _pct/_percent - проценты,
+// _bytes - байты, _sec/_seconds - длительность, _ms - миллисекунды.
+func Keyed(key string, v interface{}) string {
+	if _, ok := toFloat(v); ok {
+		if _, isString := v.(string); !isString {
+			switch {
+			case strings.HasSuffix(key, "_pct"), strings.HasSuffix(key, "_percent"):
+				return Percent(v)
+			case strings.HasSuffix(key, "_bytes"):
+				return Bytes(v)
+			case strings.HasSuffix(key, "_sec"), strings.HasSuffix(key, "_seconds"):
+				return Seconds(v)
+			case strings.HasSuffix(key, "_ms"):
+				return Millis(v)
+			}
+		}
+	}
+	return plainValue(v)
+}
+
+// Bytes - размер в двоичных единицах: 512 B, 1.5 KiB, 7.8 GiB.
+func Bytes(v interface{}) string {
+	n, ok := toFloat(v)
+	if !ok {
+		return plainValue(v)
+	}
+	const unit = 1024
+	if math.Abs(n) < unit {
+		return fmt.Sprintf("%.0f B", n)
+	}
+	exp := 0
+	for math.Abs(n) >= unit && exp < 6 {
+		n /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", n, "KMGTPE"[exp-1])
+}
+
+// Percent - проценты с одним знаком: 42.5%.
+func Percent(v interface{}) string {
+	n, ok := toFloat(v)
+	if !ok {
+		return plainValue(v)
+	}
+	return strconv.FormatFloat(n, 'f', 1, 64) + "%"
+}
+
+// Seconds - длительность из секунд: 3d 4h, 5m 12s.
+func Seconds(v interface{}) string {
+	n, ok := toFloat(v)
+	if !ok {
+		return plainValue(v)
+	}
+	return humanDuration(time.Duration(n * float64(time.Second)))
+}
+
+// Millis - длительность из миллисекунд.
+func Millis(v interface{}) string {
+	n, ok := toFloat(v)
+	if !ok {
+		return plainValue(v)
+	}
+	return humanDuration(time.Duration(n * float64(time.Millisecond)))
+}
+
+// humanDuration показывает две старшие единицы.
+func humanDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + humanDuration(-d)
+	}
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	if d < time.Minute {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	units := []struct {
+		d    time.Duration
+		name string
+	}{{24 * time.Hour, "d"}, {time.Hour, "h"}, {time.Minute, "m"}, {time.Second, "s"}}
+	var parts []string
+	for _, u := range units {
+		if d >= u.d {
+			parts = append(parts, fmt.Sprintf("%d%s", d/u.d, u.name))
+			d %= u.d
+		}
+		if len(parts) == 2 {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Table - таблица с выравниванием по колонкам; rows - список объектов,
+// пустой cols - все ключи по алфавиту.
+func Table(rows interface{}, cols []string) string {
+	items, _ := normalize(rows).([]interface{})
+	if len(cols) == 0 {
+		seen := map[string]bool{}
+		for _, it := range items {
+			if m, ok := it.(map[string]interface{}); ok {
+				for k := range m {
+					if !seen[k] {
+						seen[k] = true
+						cols = append(cols, k)
+					}
+				}
+			}
+		}
+		sort.Strings(cols)
+	}
+	cells := [][]string{cols}
+	for _, it := range items {
+		m, _ := it.(map[string]interface{})
+		row := make([]string, len(cols))
+		for i, c := range cols {
+			row[i] = Keyed(c, m[c])
+		}
+		cells = append(cells, row)
+	}
+	widths := make([]int, len(cols))
+	for _, row := range cells {
+		for i, c := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(c))
+		}
+	}
+	lines := make([]string, 0, len(cells))
+	for _, row := range cells {
+		var b strings.Builder
+		for i, c := range row {
+			b.WriteString(c)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(c)+2))
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// truncatedMark дописывается к обрезанному тексту.
+const truncatedMark = "… (truncated)"
+
+// Truncate обрезает размеченный текст до limit UTF-16 символов по границе
+// строки, закрывая открытые блоки разметки; limit <= 0 - без обрезки.
+func Truncate(f Format, s string, limit int) string {
+	if limit <= 0 || utf16Len(s) <= limit {
+		return s
+	}
+	// Запас на метку и закрывающие теги.
+	budget := limit - utf16Len(truncatedMark) - 32
+	if budget <= 0 {
+		return Escape(f, truncatedMark)
+	}
+	cut, units := len(s), 0
+	for i, r := range s {
+		n := 1
+		if r >= 0x10000 {
+			n = 2
+		}
+		if units+n > budget {
+			cut = i
+			break
+		}
+		units += n
+	}
+	head := s[:cut]
+	if i := strings.LastIndexByte(head, '\n'); i > 0 {
+		head = head[:i]
+	}
+	head = trimPartialEscape(f, head)
+	return head + closeBlocks(f, head) + "\n" + Escape(f, truncatedMark)
+}
+
+// trimPartialEscape убирает оборванную HTML-сущность или одиночный "\".
+func trimPartialEscape(f Format, s string) string {
+	switch f {
+	case HTML:
+		if amp := strings.LastIndexByte(s, '&'); amp >= 0 && !strings.Contains(s[amp:], ";") {
+			s = s[:amp]
+		}
+		if lt := strings.LastIndexByte(s, '<'); lt >= 0 && !strings.Contains(s[lt:], ">") {
+			s = s[:lt]
+		}
+	case MarkdownV2, Markdown:
+		trailing := len(s) - len(strings.TrimRight(s, "\\"))
+		if trailing%2 == 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	return s
+}
+
+// closeBlocks возвращает закрытие блоков, открытых в s.
+func closeBlocks(f Format, s string) string {
+	switch f {
+	case HTML:
+		var stack []string
+		for rest := s; ; {
+			lt := strings.IndexByte(rest, '<')
+			if lt < 0 {
+				break
+			}
+			gt := strings.IndexByte(rest[lt:], '>')
+			if gt < 0 {
+				break
+			}
+			tag := rest[lt+1 : lt+gt]
+			rest = rest[lt+gt+1:]
+			if strings.HasPrefix(tag, "/") {
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+				continue
+			}
+			name, _, _ := strings.Cut(tag, " ")
+			stack = append(stack, name)
+		}
+		var b strings.Builder
+		for i := len(stack) - 1; i >= 0; i-- {
+			b.WriteString("</" + stack[i] + ">")
+		}
+		return b.String()
+	case MarkdownV2, Markdown:
+		if strings.Count(s, "```")%2 == 1 {
+			return "\n```"
+		}
+	}
+	return ""
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
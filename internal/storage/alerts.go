@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Состояния алерта.
+const (
+	AlertInactive = "inactive"
+	AlertPending  = "pending"
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// AlertState - состояние правила алерта; сохраняется, чтобы pending/firing
+// переживали перезапуск без повторных уведомлений.
+type AlertState struct {
+	Rule        string            `json:"rule"`
+	State       string            `json:"state"`
+	Severity    string            `json:"severity"`
+	Expr        string            `json:"expr"`
+	Summary     string            `json:"summary,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Value       float64           `json:"value"`
+	ActiveAt    *time.Time        `json:"active_at,omitempty"`
+	FiredAt     *time.Time        `json:"fired_at,omitempty"`
+	ResolvedAt  *time.Time        `json:"resolved_at,omitempty"`
+	EvaluatedAt time.Time         `json:"evaluated_at"`
+	LastError   string            `json:"last_error,omitempty"`
+}
+
+// AlertStore хранит состояние правил алертов.
+type AlertStore interface {
+	SaveAlertState(ctx context.Context, st AlertState) error
+	AlertStates(ctx context.Context) ([]AlertState, error)
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditCheckpoint фиксирует подпись хвоста цепочки аудита на момент EventID.
+type AuditCheckpoint struct {
+	EventID   int64
+	Hash      string
+	KeyID     string
+	Signature []byte
+	TS        time.Time
+}
+
+// AuditChainReport описывает результат проверки цепочки аудита.
+type AuditChainReport struct {
+	Checked     int64  `json:"checked"`
+	Checkpoints int64  `json:"checkpoints"`
+	OK          bool   `json:"ok"`
+	BrokenAt    int64  `json:"broken_at,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	LastID      int64  `json:"last_id,omitempty"`
+	LastHash    string `json:"last_hash,omitempty"`
+}
+
+// AuditChainStore реализуется хранилищами с hash-цепочкой аудита.
+type AuditChainStore interface {
+	VerifyAuditChain(ctx context.Context, pub ed25519.PublicKey) (AuditChainReport, error)
+	AuditCheckpoints(ctx context.Context, fromID, toID int64) ([]AuditCheckpoint, error)
+}
+
+// AuditEventHash считает hash записи аудита, связанный с hash предыдущей записи.
+// Время нормализуется в UTC, а пустой payload приравнивается к NULL,
+// чтобы hash не зависел от представления в БД.
+func AuditEventHash(prevHash string, ev AuditEvent) string {
+	payload := ev.Payload
+	if len(payload) == 0 {
+		payload = nil
+	}
+	canonical, _ := json.Marshal(struct {
+		Prev      string `json:"prev"`
+		TS        string `json:"ts"`
+		Subject   string `json:"subject"`
+		Action    string `json:"action"`
+		Source    string `json:"source"`
+		Status    string `json:"status"`
+		RequestID string `json:"request_id"`
+		Payload   []byte `json:"payload"`
+	}{
+		Prev:      prevHash,
+		TS:        ev.TS.UTC().Format(time.RFC3339Nano),
+		Subject:   ev.Subject,
+		Action:    ev.Action,
+		Source:    ev.Source,
+		Status:    ev.Status,
+		RequestID: ev.RequestID,
+		Payload:   payload,
+	})
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckpointMessage возвращает подписываемое содержимое контрольной точки.
+func CheckpointMessage(eventID int64, hash string) []byte {
+	return []byte(fmt.Sprintf("goadmin-audit:%d:%s", eventID, hash))
+}
+
+// ChainVerifier последовательно проверяет записи аудита в порядке ID.
+type ChainVerifier struct {
+	report      AuditChainReport
+	prevHash    string
+	started     bool
+	checkpoints []AuditCheckpoint
+	pub         ed25519.PublicKey
+}
+
+// NewChainVerifier создает verifier; anchor задает ожидаемый prev_hash первой записи.
+// Для полной цепочки anchor пустой, для фрагмента экспорта - prev_hash первой записи.
+// Контрольные точки должны быть отсортированы по EventID; pub == nil отключает проверку подписей.
+func NewChainVerifier(anchor string, checkpoints []AuditCheckpoint, pub ed25519.PublicKey) *ChainVerifier {
+	return &ChainVerifier{
+		report:      AuditChainReport{OK: true},
+		prevHash:    anchor,
+		checkpoints: checkpoints,
+		pub:         pub,
+	}
+}
+
+// Add проверяет очередную запись; возвращает false на первом разрыве.
+func (v *ChainVerifier) Add(ev AuditEvent) bool {
+	if !v.report.OK {
+		return false
+	}
+	v.report.Checked++
+	switch {
+	case v.started && ev.ID <= v.report.LastID:
+		return v.fail(ev.ID, "events are not ordered by id")
+	case ev.PrevHash != v.prevHash:
+		return v.fail(ev.ID, "prev_hash does not match previous record")
+	case ev.Hash != AuditEventHash(ev.PrevHash, ev):
+		return v.fail(ev.ID, "record hash mismatch")
+	}
+	v.started = true
+	v.prevHash = ev.Hash
+	v.report.LastID = ev.ID
+	v.report.LastHash = ev.Hash
+
+	for len(v.checkpoints) > 0 && v.checkpoints[0].EventID <= ev.ID {
+		cp := v.checkpoints[0]
+		v.checkpoints = v.checkpoints[1:]
+		if cp.EventID != ev.ID {
+			return v.fail(cp.EventID, "checkpoint references missing record")
+		}
+		if !v.checkCheckpoint(cp, ev.Hash) {
+			return false
+		}
+	}
+	return true
+}
+
+// Finish завершает проверку и возвращает итог.
+// Контрольные точки после последней записи означают удаление хвоста цепочки.
+func (v *ChainVerifier) Finish() AuditChainReport {
+	if v.report.OK && len(v.checkpoints) > 0 {
+		v.fail(v.checkpoints[0].EventID, "checkpoint references missing record")
+	}
+	return v.report
+}
+
+func (v *ChainVerifier) checkCheckpoint(cp AuditCheckpoint, hash string) bool {
+	v.report.Checkpoints++
+	if cp.Hash != hash {
+		return v.fail(cp.EventID, "checkpoint hash does not match record")
+	}
+	if v.pub != nil && !ed25519.Verify(v.pub, CheckpointMessage(cp.EventID, cp.Hash), cp.Signature) {
+		return v.fail(cp.EventID, "checkpoint signature is invalid")
+	}
+	return true
+}
+
+func (v *ChainVerifier) fail(id int64, reason string) bool {
+	v.report.OK = false
+	v.report.BrokenAt = id
+	v.report.Reason = reason
+	return false
+}
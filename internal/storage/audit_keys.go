@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GenerateAuditKey создает новую пару ключей для подписи цепочки аудита
+// и возвращает их в формате base64, который понимают Load*-функции.
+func GenerateAuditKey() (privB64, pubB64 string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generate ed25519 key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(priv.Seed()), base64.StdEncoding.EncodeToString(pub), nil
+}
+
+// LoadAuditSigningKey читает ed25519 ключ (seed или полный ключ в base64) из файла.
+func LoadAuditSigningKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := readBase64File(path)
+	if err != nil {
+		return nil, err
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, fmt.Errorf("audit signing key %s: unexpected size %d", path, len(raw))
+	}
+}
+
+// LoadAuditPublicKey читает публичный ed25519 ключ в base64 из файла.
+func LoadAuditPublicKey(path string) (ed25519.PublicKey, error) {
+	raw, err := readBase64File(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("audit public key %s: unexpected size %d", path, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// AuditKeyID возвращает короткий идентификатор публичного ключа.
+func AuditKeyID(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:4])
+}
+
+func readBase64File(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- путь к ключу задается доверенным оператором.
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("decode key file %s: %w", path, err)
+	}
+	return raw, nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ограничения размера страницы аудита.
+const (
+	DefaultAuditPageSize = 50
+	MaxAuditPageSize     = 1000
+)
+
+var (
+	// ErrInvalidCursor возвращается для поврежденного или чужого курсора.
+	ErrInvalidCursor = errors.New("invalid audit cursor")
+	// ErrInvalidGroupBy возвращается для неизвестного поля группировки.
+	ErrInvalidGroupBy = errors.New("invalid audit group_by")
+)
+
+// AuditPage - страница аудита; пустой NextCursor означает конец выборки.
+type AuditPage struct {
+	Events     []AuditEvent
+	NextCursor string
+}
+
+// AuditPager - хранилище с курсорной пагинацией аудита (ts DESC, id DESC).
+type AuditPager interface {
+	QueryAuditPage(ctx context.Context, q AuditQuery) (AuditPage, error)
+}
+
+// AuditStatsQuery задает агрегацию: фильтры AuditQuery (Limit и Cursor
+// игнорируются), размер временного окна (0 - весь диапазон) и поля группировки.
+type AuditStatsQuery struct {
+	AuditQuery
+	Bucket  time.Duration
+	GroupBy []string
+}
+
+// AuditStatsRow - число событий в окне для комбинации полей группировки.
+// Поля, не входящие в GroupBy, остаются пустыми.
+type AuditStatsRow struct {
+	Bucket  time.Time
+	Subject string
+	Action  string
+	Source  string
+	Status  string
+	Count   int64
+}
+
+// AuditAggregator - хранилище с агрегацией аудита.
+type AuditAggregator interface {
+	AggregateAudit(ctx context.Context, q AuditStatsQuery) ([]AuditStatsRow, error)
+}
+
+// AuditGroupFields - допустимые поля группировки.
+var AuditGroupFields = []string{"subject", "action", "source", "status"}
+
+// ValidateAuditGroupBy проверяет поля группировки и убирает дубликаты.
+func ValidateAuditGroupBy(fields []string) ([]string, error) {
+	seen := make(map[string]struct{}, len(fields))
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		valid := false
+		for _, allowed := range AuditGroupFields {
+			if f == allowed {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidGroupBy, f)
+		}
+		if _, dup := seen[f]; dup {
+			continue
+		}
+		seen[f] = struct{}{}
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// NormalizeAuditLimit приводит размер страницы к допустимому диапазону.
+func NormalizeAuditLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultAuditPageSize
+	}
+	if limit > MaxAuditPageSize {
+		return MaxAuditPageSize
+	}
+	return limit
+}
+
+const auditCursorVersion = "a1"
+
+// EncodeAuditCursor кодирует позицию последнего события страницы.
+func EncodeAuditCursor(ts time.Time, id int64) string {
+	raw := fmt.Sprintf("%s:%d:%d", auditCursorVersion, ts.UTC().UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeAuditCursor возвращает позицию, закодированную EncodeAuditCursor.
+func DecodeAuditCursor(cursor string) (time.Time, int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 || parts[0] != auditCursorVersion {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || id <= 0 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.Unix(0, nanos).UTC(), id, nil
+}
@@ -6,3 +6,9 @@ import "context"
 type AuditWriter interface {
 	Write(ctx context.Context, ev AuditEvent) error
 }
+
+// AuditBatchWriter позволяет сохранить пачку событий одной транзакцией.
+// При успехе реализация проставляет в events присвоенные ID, TS, PrevHash и Hash.
+type AuditBatchWriter interface {
+	SaveAuditBatch(ctx context.Context, events []AuditEvent) error
+}
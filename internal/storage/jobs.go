@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// MaxJobRunsPerJob - сколько последних запусков задачи хранится в истории.
+const MaxJobRunsPerJob = 1000
+
+// JobRun - завершенный запуск задачи планировщика.
+type JobRun struct {
+	ID         int64     `json:"id"`
+	Job        string    `json:"job"`
+	Trigger    string    `json:"trigger"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobRunQuery выбирает историю запусков (новые первыми). Пустой Job - все задачи.
+type JobRunQuery struct {
+	Job   string
+	Limit int
+}
+
+// JobState - управляемое состояние задачи: пауза и запрос ручного запуска.
+// Через него CLI управляет задачами работающего демона.
+type JobState struct {
+	Job          string    `json:"job"`
+	Paused       bool      `json:"paused"`
+	RunRequested bool      `json:"run_requested"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UpdatedBy    string    `json:"updated_by,omitempty"`
+}
+
+// JobStore хранит историю запусков и состояние задач планировщика.
+type JobStore interface {
+	// SaveJobRun добавляет запуск и вытесняет записи сверх MaxJobRunsPerJob.
+	SaveJobRun(ctx context.Context, run JobRun) error
+	JobRuns(ctx context.Context, q JobRunQuery) ([]JobRun, error)
+	JobStates(ctx context.Context) ([]JobState, error)
+	SetJobPaused(ctx context.Context, job string, paused bool, by string) error
+	RequestJobRun(ctx context.Context, job, by string) error
+	// ClaimJobRunRequests снимает запросы ручного запуска и возвращает имена задач.
+	ClaimJobRunRequests(ctx context.Context) ([]string, error)
+}
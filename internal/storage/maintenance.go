@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Операторы LabelMatcher.
+const (
+	MatchEqual     = "="
+	MatchNotEqual  = "!="
+	MatchRegexp    = "=~"
+	MatchNotRegexp = "!~"
+)
+
+// LabelMatcher - условие на метку уведомления, например alertname=high_memory.
+type LabelMatcher struct {
+	Name  string `json:"name"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
+}
+
+// Silence подавляет уведомления, метки которых подходят под все Matchers,
+// в интервале [StartsAt, EndsAt).
+type Silence struct {
+	ID        string         `json:"id"`
+	Matchers  []LabelMatcher `json:"matchers"`
+	StartsAt  time.Time      `json:"starts_at"`
+	EndsAt    time.Time      `json:"ends_at"`
+	Comment   string         `json:"comment,omitempty"`
+	CreatedBy string         `json:"created_by,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	// ExpiryAudited - истечение уже зафиксировано в аудите.
+	ExpiryAudited bool `json:"-"`
+}
+
+// MaintenanceWindow - именованное окно обслуживания. Разовое окно задается
+// StartsAt/EndsAt; повторяющееся - cron Schedule и Duration, а StartsAt/EndsAt
+// (необязательные) ограничивают срок его действия. Пустые Matchers подавляют
+// все уведомления внутри окна.
+type MaintenanceWindow struct {
+	Name          string         `json:"name"`
+	Schedule      string         `json:"schedule,omitempty"`
+	Duration      time.Duration  `json:"-"`
+	StartsAt      *time.Time     `json:"starts_at,omitempty"`
+	EndsAt        *time.Time     `json:"ends_at,omitempty"`
+	Matchers      []LabelMatcher `json:"matchers"`
+	Comment       string         `json:"comment,omitempty"`
+	CreatedBy     string         `json:"created_by,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	ExpiryAudited bool           `json:"-"`
+}
+
+// MaintenanceStore хранит silences и окна обслуживания.
+type MaintenanceStore interface {
+	// SaveSilence создает или обновляет silence по ID.
+	SaveSilence(ctx context.Context, s Silence) error
+	// Silences возвращает все silences, новые первыми.
+	Silences(ctx context.Context) ([]Silence, error)
+	// SaveMaintenanceWindow создает или заменяет окно по имени.
+	SaveMaintenanceWindow(ctx context.Context, w MaintenanceWindow) error
+	// DeleteMaintenanceWindow удаляет окно; ErrNotFound, если его нет.
+	DeleteMaintenanceWindow(ctx context.Context, name string) error
+	// MaintenanceWindows возвращает окна, отсортированные по имени.
+	MaintenanceWindows(ctx context.Context) ([]MaintenanceWindow, error)
+}
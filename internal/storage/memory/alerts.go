@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"goadmin/internal/storage"
+)
+
+// SaveAlertState сохраняет состояние правила алерта.
+func (s *Store) SaveAlertState(_ context.Context, st storage.AlertState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.alerts[st.Rule] = cloneAlert(st)
+	return nil
+}
+
+// AlertStates возвращает состояние всех правил, отсортированное по имени.
+func (s *Store) AlertStates(context.Context) ([]storage.AlertState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	out := make([]storage.AlertState, 0, len(s.alerts))
+	for _, st := range s.alerts {
+		out = append(out, cloneAlert(st))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Rule < out[j].Rule })
+	return out, nil
+}
+
+func cloneAlert(st storage.AlertState) storage.AlertState {
+	if st.Labels != nil {
+		labels := make(map[string]string, len(st.Labels))
+		for k, v := range st.Labels {
+			labels[k] = v
+		}
+		st.Labels = labels
+	}
+	return st
+}
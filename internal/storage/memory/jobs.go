@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SaveJobRun добавляет запуск задачи и удаляет записи сверх storage.MaxJobRunsPerJob.
+func (s *Store) SaveJobRun(_ context.Context, run storage.JobRun) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.nextRun++
+	run.ID = s.nextRun
+	s.jobRuns = append(s.jobRuns, run)
+
+	// Записи добавляются по одной, поэтому сверх лимита может оказаться только самая старая.
+	count, oldest := 0, -1
+	for i, r := range s.jobRuns {
+		if r.Job == run.Job {
+			if oldest < 0 {
+				oldest = i
+			}
+			count++
+		}
+	}
+	if count > storage.MaxJobRunsPerJob {
+		s.jobRuns = append(s.jobRuns[:oldest], s.jobRuns[oldest+1:]...)
+	}
+	return nil
+}
+
+// JobRuns возвращает историю запусков, новые первыми.
+func (s *Store) JobRuns(_ context.Context, q storage.JobRunQuery) ([]storage.JobRun, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	limit := q.Limit
+	if limit <= 0 || limit > storage.MaxJobRunsPerJob {
+		limit = storage.MaxJobRunsPerJob
+	}
+	var out []storage.JobRun
+	for i := len(s.jobRuns) - 1; i >= 0 && len(out) < limit; i-- {
+		if q.Job == "" || s.jobRuns[i].Job == q.Job {
+			out = append(out, s.jobRuns[i])
+		}
+	}
+	return out, nil
+}
+
+// JobStates возвращает состояние задач, отсортированное по имени.
+func (s *Store) JobStates(context.Context) ([]storage.JobState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	out := make([]storage.JobState, 0, len(s.jobs))
+	for _, st := range s.jobs {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Job < out[j].Job })
+	return out, nil
+}
+
+// SetJobPaused сохраняет паузу задачи.
+func (s *Store) SetJobPaused(_ context.Context, job string, paused bool, by string) error {
+	return s.updateJob(job, by, func(st *storage.JobState) { st.Paused = paused })
+}
+
+// RequestJobRun ставит запрос ручного запуска.
+func (s *Store) RequestJobRun(_ context.Context, job, by string) error {
+	return s.updateJob(job, by, func(st *storage.JobState) { st.RunRequested = true })
+}
+
+// ClaimJobRunRequests снимает все запросы ручного запуска.
+func (s *Store) ClaimJobRunRequests(context.Context) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	var jobs []string
+	for name, st := range s.jobs {
+		if st.RunRequested {
+			st.RunRequested = false
+			s.jobs[name] = st
+			jobs = append(jobs, name)
+		}
+	}
+	sort.Strings(jobs)
+	return jobs, nil
+}
+
+func (s *Store) updateJob(job, by string, fn func(*storage.JobState)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	st := s.jobs[job]
+	st.Job = job
+	fn(&st)
+	st.UpdatedAt = time.Now().UTC()
+	st.UpdatedBy = by
+	s.jobs[job] = st
+	return nil
+}
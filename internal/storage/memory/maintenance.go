@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SaveSilence создает или обновляет silence по ID.
+func (s *Store) SaveSilence(_ context.Context, sil storage.Silence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	sil.Matchers = append([]storage.LabelMatcher(nil), sil.Matchers...)
+	for i, cur := range s.silences {
+		if cur.ID == sil.ID {
+			sil.CreatedBy, sil.CreatedAt = cur.CreatedBy, cur.CreatedAt
+			s.silences[i] = sil
+			return nil
+		}
+	}
+	s.silences = append(s.silences, sil)
+	return nil
+}
+
+// Silences возвращает все silences, новые первыми.
+func (s *Store) Silences(context.Context) ([]storage.Silence, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	out := make([]storage.Silence, 0, len(s.silences))
+	for _, sil := range s.silences {
+		sil.Matchers = append([]storage.LabelMatcher(nil), sil.Matchers...)
+		out = append(out, sil)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].CreatedAt.Equal(out[j].CreatedAt) {
+			return out[i].CreatedAt.After(out[j].CreatedAt)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// SaveMaintenanceWindow создает или заменяет окно обслуживания по имени.
+func (s *Store) SaveMaintenanceWindow(_ context.Context, w storage.MaintenanceWindow) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.windows[w.Name] = cloneWindow(w)
+	return nil
+}
+
+// DeleteMaintenanceWindow удаляет окно обслуживания.
+func (s *Store) DeleteMaintenanceWindow(_ context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if _, ok := s.windows[name]; !ok {
+		return fmt.Errorf("maintenance window %s: %w", name, storage.ErrNotFound)
+	}
+	delete(s.windows, name)
+	return nil
+}
+
+// MaintenanceWindows возвращает окна обслуживания по имени.
+func (s *Store) MaintenanceWindows(context.Context) ([]storage.MaintenanceWindow, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	out := make([]storage.MaintenanceWindow, 0, len(s.windows))
+	for _, w := range s.windows {
+		out = append(out, cloneWindow(w))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func cloneWindow(w storage.MaintenanceWindow) storage.MaintenanceWindow {
+	w.Matchers = append([]storage.LabelMatcher(nil), w.Matchers...)
+	for _, t := range []**time.Time{&w.StartsAt, &w.EndsAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+	return w
+}
@@ -0,0 +1,346 @@
+// Package memory реализует storage.Store в памяти процесса: для тестов и
+// эфемерного режима агента, когда на диске не должно оставаться состояния.
+package memory
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// DefaultMetricsPerModule - сколько последних метрик модуля хранится по умолчанию.
+const DefaultMetricsPerModule = 1000
+
+// ErrClosed возвращается после Close.
+var ErrClosed = errors.New("memory store is closed")
+
+// Options задает лимиты хранилища.
+type Options struct {
+	// MetricsPerModule ограничивает историю метрик модуля; старые записи вытесняются.
+	MetricsPerModule int
+}
+
+// Store хранит метрики и hash-цепочку аудита в памяти.
+type Store struct {
+	mu       sync.RWMutex
+	opts     Options
+	metrics  map[string][]storage.MetricRecord
+	audit    []storage.AuditEvent
+	jobRuns  []storage.JobRun
+	jobs     map[string]storage.JobState
+	alerts   map[string]storage.AlertState
+	subs     []storage.Subscription
+	silences []storage.Silence
+	windows  map[string]storage.MaintenanceWindow
+	nextRun  int64
+	closed   bool
+}
+
+// New создает пустое хранилище с лимитами по умолчанию.
+func New() *Store {
+	return NewWithOptions(Options{})
+}
+
+// NewWithOptions создает пустое хранилище.
+func NewWithOptions(opts Options) *Store {
+	if opts.MetricsPerModule <= 0 {
+		opts.MetricsPerModule = DefaultMetricsPerModule
+	}
+	return &Store{opts: opts, metrics: make(map[string][]storage.MetricRecord), jobs: make(map[string]storage.JobState),
+		alerts: make(map[string]storage.AlertState), windows: make(map[string]storage.MaintenanceWindow)}
+}
+
+// SaveMetric сохраняет метрику.
+func (s *Store) SaveMetric(_ context.Context, rec storage.MetricRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if rec.TS.IsZero() {
+		rec.TS = time.Now()
+	}
+	rec.TS = rec.TS.UTC()
+	rec.Payload = cloneBytes(rec.Payload)
+	list := append(s.metrics[rec.Module], rec)
+	if over := len(list) - s.opts.MetricsPerModule; over > 0 {
+		list = append(list[:0:0], list[over:]...)
+	}
+	s.metrics[rec.Module] = list
+	return nil
+}
+
+// LatestMetric возвращает метрику модуля с наибольшим ts.
+func (s *Store) LatestMetric(_ context.Context, module string) (storage.MetricRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return storage.MetricRecord{}, ErrClosed
+	}
+	var (
+		latest storage.MetricRecord
+		found  bool
+	)
+	for _, rec := range s.metrics[module] {
+		if !found || !rec.TS.Before(latest.TS) {
+			latest, found = rec, true
+		}
+	}
+	if !found {
+		return storage.MetricRecord{}, fmt.Errorf("latest metric %q: %w", module, storage.ErrNotFound)
+	}
+	latest.Payload = cloneBytes(latest.Payload)
+	return latest, nil
+}
+
+// PruneMetrics удаляет метрики модуля старше before.
+func (s *Store) PruneMetrics(_ context.Context, module string, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	list := s.metrics[module]
+	kept := list[:0]
+	for _, rec := range list {
+		if !rec.TS.Before(before) {
+			kept = append(kept, rec)
+		}
+	}
+	s.metrics[module] = kept
+	return int64(len(list) - len(kept)), nil
+}
+
+// SaveAudit сохраняет событие аудита.
+func (s *Store) SaveAudit(ctx context.Context, ev storage.AuditEvent) error {
+	return s.SaveAuditBatch(ctx, []storage.AuditEvent{ev})
+}
+
+// Write реализует storage.AuditWriter.
+func (s *Store) Write(ctx context.Context, ev storage.AuditEvent) error {
+	return s.SaveAudit(ctx, ev)
+}
+
+// SaveAuditBatch сохраняет события, продолжая hash-цепочку, и проставляет
+// в events ID, TS, PrevHash и Hash.
+func (s *Store) SaveAuditBatch(_ context.Context, events []storage.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	prevHash := ""
+	if n := len(s.audit); n > 0 {
+		prevHash = s.audit[n-1].Hash
+	}
+	for i, ev := range events {
+		if ev.TS.IsZero() {
+			ev.TS = time.Now()
+		}
+		ev.TS = ev.TS.UTC()
+		ev.ID = int64(len(s.audit)) + 1
+		ev.PrevHash = prevHash
+		ev.Hash = storage.AuditEventHash(prevHash, ev)
+		events[i] = ev
+		ev.Payload = cloneBytes(ev.Payload)
+		s.audit = append(s.audit, ev)
+		prevHash = ev.Hash
+	}
+	return nil
+}
+
+// QueryAudit возвращает одну страницу аудита; для продолжения используйте QueryAuditPage.
+func (s *Store) QueryAudit(ctx context.Context, q storage.AuditQuery) ([]storage.AuditEvent, error) {
+	page, err := s.QueryAuditPage(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return page.Events, nil
+}
+
+// QueryAuditPage возвращает страницу аудита в порядке ts DESC, id DESC.
+func (s *Store) QueryAuditPage(_ context.Context, q storage.AuditQuery) (storage.AuditPage, error) {
+	limit := storage.NormalizeAuditLimit(q.Limit)
+	var (
+		cursorTS time.Time
+		cursorID int64
+	)
+	if q.Cursor != "" {
+		ts, id, err := storage.DecodeAuditCursor(q.Cursor)
+		if err != nil {
+			return storage.AuditPage{}, err
+		}
+		cursorTS, cursorID = ts, id
+	}
+
+	matched, err := s.filterAudit(q)
+	if err != nil {
+		return storage.AuditPage{}, err
+	}
+	sort.Slice(matched, func(i, j int) bool { return auditAfter(matched[i], matched[j]) })
+
+	page := storage.AuditPage{Events: make([]storage.AuditEvent, 0, limit)}
+	for _, ev := range matched {
+		if cursorID != 0 && !auditAfter(storage.AuditEvent{TS: cursorTS, ID: cursorID}, ev) {
+			continue
+		}
+		if len(page.Events) == limit {
+			last := page.Events[limit-1]
+			page.NextCursor = storage.EncodeAuditCursor(last.TS, last.ID)
+			break
+		}
+		ev.Payload = cloneBytes(ev.Payload)
+		page.Events = append(page.Events, ev)
+	}
+	return page, nil
+}
+
+// AggregateAudit считает события по окнам времени и полям группировки.
+func (s *Store) AggregateAudit(_ context.Context, q storage.AuditStatsQuery) ([]storage.AuditStatsRow, error) {
+	groupBy, err := storage.ValidateAuditGroupBy(q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	matched, err := s.filterAudit(q.AuditQuery)
+	if err != nil {
+		return nil, err
+	}
+	bucket := int64(q.Bucket / time.Second)
+	counts := make(map[storage.AuditStatsRow]int64)
+	for _, ev := range matched {
+		var key storage.AuditStatsRow
+		if bucket > 0 {
+			key.Bucket = time.Unix(ev.TS.Unix()/bucket*bucket, 0).UTC()
+		}
+		for _, f := range groupBy {
+			switch f {
+			case "subject":
+				key.Subject = ev.Subject
+			case "action":
+				key.Action = ev.Action
+			case "source":
+				key.Source = ev.Source
+			case "status":
+				key.Status = ev.Status
+			}
+		}
+		counts[key]++
+	}
+	out := make([]storage.AuditStatsRow, 0, len(counts))
+	for key, n := range counts {
+		key.Count = n
+		out = append(out, key)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if !a.Bucket.Equal(b.Bucket) {
+			return a.Bucket.Before(b.Bucket)
+		}
+		for _, f := range groupBy {
+			av, bv := statsField(a, f), statsField(b, f)
+			if av != bv {
+				return av < bv
+			}
+		}
+		return false
+	})
+	return out, nil
+}
+
+// VerifyAuditChain проверяет hash-цепочку. Контрольные точки в памяти не подписываются.
+func (s *Store) VerifyAuditChain(_ context.Context, pub ed25519.PublicKey) (storage.AuditChainReport, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return storage.AuditChainReport{}, ErrClosed
+	}
+	v := storage.NewChainVerifier("", nil, pub)
+	for _, ev := range s.audit {
+		if !v.Add(ev) {
+			break
+		}
+	}
+	return v.Finish(), nil
+}
+
+// AuditCheckpoints всегда пуст: хранилище в памяти не подписывает цепочку.
+func (s *Store) AuditCheckpoints(context.Context, int64, int64) ([]storage.AuditCheckpoint, error) {
+	return nil, nil
+}
+
+// Close освобождает данные; последующие вызовы возвращают ErrClosed.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	s.metrics = nil
+	s.audit = nil
+	s.jobRuns = nil
+	s.jobs = nil
+	s.alerts = nil
+	s.subs = nil
+	s.silences = nil
+	s.windows = nil
+	return nil
+}
+
+func (s *Store) filterAudit(q storage.AuditQuery) ([]storage.AuditEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	var out []storage.AuditEvent
+	for _, ev := range s.audit {
+		if !q.From.IsZero() && ev.TS.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && ev.TS.After(q.To) {
+			continue
+		}
+		if (q.Subject != "" && ev.Subject != q.Subject) ||
+			(q.Action != "" && ev.Action != q.Action) ||
+			(q.Source != "" && ev.Source != q.Source) ||
+			(q.Status != "" && ev.Status != q.Status) ||
+			(q.RequestID != "" && ev.RequestID != q.RequestID) {
+			continue
+		}
+		out = append(out, ev)
+	}
+	return out, nil
+}
+
+// auditAfter сообщает, идет ли a раньше b в порядке ts DESC, id DESC.
+func auditAfter(a, b storage.AuditEvent) bool {
+	if !a.TS.Equal(b.TS) {
+		return a.TS.After(b.TS)
+	}
+	return a.ID > b.ID
+}
+
+func statsField(row storage.AuditStatsRow, field string) string {
+	switch field {
+	case "subject":
+		return row.Subject
+	case "action":
+		return row.Action
+	case "source":
+		return row.Source
+	case "status":
+		return row.Status
+	}
+	return ""
+}
+
+func cloneBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte(nil), b...)
+}
@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Store { return New() })
+}
+
+func TestMetricsPerModuleLimit(t *testing.T) {
+	st := NewWithOptions(Options{MetricsPerModule: 3})
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		if err := st.SaveMetric(ctx, storage.MetricRecord{Module: "host", Payload: []byte{byte('0' + i)}}); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+	if n := len(st.metrics["host"]); n != 3 {
+		t.Fatalf("kept %d metrics, want 3", n)
+	}
+	rec, err := st.LatestMetric(ctx, "host")
+	if err != nil || string(rec.Payload) != "4" {
+		t.Fatalf("latest: %v %q", err, rec.Payload)
+	}
+	_ = st.Close()
+	if err := st.SaveMetric(ctx, storage.MetricRecord{Module: "host"}); err != ErrClosed {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
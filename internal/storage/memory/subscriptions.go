@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Subscribe создает подписку или обновляет MinSeverity существующей.
+func (s *Store) Subscribe(_ context.Context, sub storage.Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	for i, cur := range s.subs {
+		if cur.Transport == sub.Transport && cur.ChatID == sub.ChatID && cur.Topic == sub.Topic {
+			s.subs[i].MinSeverity = sub.MinSeverity
+			return nil
+		}
+	}
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	s.subs = append(s.subs, sub)
+	sort.Slice(s.subs, func(i, j int) bool {
+		a, b := s.subs[i], s.subs[j]
+		if a.Transport != b.Transport {
+			return a.Transport < b.Transport
+		}
+		if a.ChatID != b.ChatID {
+			return a.ChatID < b.ChatID
+		}
+		return a.Topic < b.Topic
+	})
+	return nil
+}
+
+// Unsubscribe удаляет подписки чата на topic; пустой topic - на все темы.
+func (s *Store) Unsubscribe(_ context.Context, transport, chatID, topic string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, ErrClosed
+	}
+	kept := s.subs[:0]
+	var n int64
+	for _, sub := range s.subs {
+		if sub.Transport == transport && sub.ChatID == chatID && (topic == "" || sub.Topic == topic) {
+			n++
+			continue
+		}
+		kept = append(kept, sub)
+	}
+	s.subs = kept
+	return n, nil
+}
+
+// Subscriptions возвращает подписки по фильтру.
+func (s *Store) Subscriptions(_ context.Context, q storage.SubscriptionQuery) ([]storage.Subscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	var out []storage.Subscription
+	for _, sub := range s.subs {
+		if (q.Transport == "" || sub.Transport == q.Transport) && (q.ChatID == "" || sub.ChatID == q.ChatID) && (q.Topic == "" || sub.Topic == q.Topic) {
+			out = append(out, sub)
+		}
+	}
+	return out, nil
+}
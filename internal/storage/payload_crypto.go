@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// payloadEnvelopeVersion - первый байт зашифрованного payload.
+const payloadEnvelopeVersion byte = 1
+
+const (
+	dataKeySize = 32
+	nonceSize   = 12
+	// wrappedKeySize = nonce + зашифрованный DEK + GCM tag.
+	wrappedKeySize = nonceSize + dataKeySize + 16
+)
+
+var (
+	// ErrUnknownPayloadKey - строка зашифрована ключом, которого нет в keyring.
+	ErrUnknownPayloadKey = errors.New("unknown payload encryption key")
+	errBadEnvelope       = errors.New("malformed payload envelope")
+)
+
+// Keyring хранит мастер-ключи (KEK) шифрования payload по идентификаторам.
+// Новые записи шифруются активным ключом, старые читаются ключом из своей строки.
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+	order  []string
+}
+
+// NewKeyring создает keyring; active должен быть среди keys.
+func NewKeyring(keys map[string][]byte, order []string, active string) (*Keyring, error) {
+	k := &Keyring{active: active, keys: make(map[string]cipher.AEAD, len(keys))}
+	for _, id := range order {
+		raw, ok := keys[id]
+		if !ok {
+			return nil, fmt.Errorf("payload key %q: not found", id)
+		}
+		if len(raw) != dataKeySize {
+			return nil, fmt.Errorf("payload key %q: expected %d bytes, got %d", id, dataKeySize, len(raw))
+		}
+		aead, err := newGCM(raw)
+		if err != nil {
+			return nil, fmt.Errorf("payload key %q: %w", id, err)
+		}
+		k.keys[id] = aead
+		k.order = append(k.order, id)
+	}
+	if len(k.keys) == 0 {
+		return nil, errors.New("payload keyring is empty")
+	}
+	if k.active == "" {
+		k.active = k.order[len(k.order)-1]
+	}
+	if _, ok := k.keys[k.active]; !ok {
+		return nil, fmt.Errorf("active payload key %q: %w", k.active, ErrUnknownPayloadKey)
+	}
+	return k, nil
+}
+
+// LoadKeyring читает ключи из файла и/или переменной окружения в формате
+// "key_id:base64" (по одному на строку или через запятую). Без active активным
+// становится последний прочитанный ключ: сначала файл, затем окружение.
+func LoadKeyring(path, envVar, active string) (*Keyring, error) {
+	keys := make(map[string][]byte)
+	var order []string
+	add := func(src, entry string) error {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			return nil
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return fmt.Errorf("%s: expected key_id:base64", src)
+		}
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return fmt.Errorf("%s: key %q: %w", src, id, err)
+		}
+		if _, dup := keys[id]; dup {
+			return fmt.Errorf("%s: duplicate key %q", src, id)
+		}
+		keys[id] = raw
+		order = append(order, id)
+		return nil
+	}
+
+	if path != "" {
+		f, err := os.Open(path) // #nosec G304 -- путь задается оператором.
+		if err != nil {
+			return nil, fmt.Errorf("open payload key file: %w", err)
+		}
+		defer f.Close()
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			if err := add(path, sc.Text()); err != nil {
+				return nil, err
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("read payload key file: %w", err)
+		}
+	}
+	if envVar != "" {
+		for _, entry := range strings.FieldsFunc(os.Getenv(envVar), func(r rune) bool { return r == ',' || r == '\n' }) {
+			if err := add("$"+envVar, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return NewKeyring(keys, order, active)
+}
+
+// GeneratePayloadKey возвращает строку "key_id:base64" с новым случайным ключом.
+func GeneratePayloadKey(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, ":, \n") {
+		return "", fmt.Errorf("invalid key id %q", id)
+	}
+	raw := make([]byte, dataKeySize)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// ActiveKeyID возвращает идентификатор ключа для новых записей.
+func (k *Keyring) ActiveKeyID() string { return k.active }
+
+// Seal шифрует payload по схеме envelope: случайный DEK шифрует данные,
+// активный KEK шифрует DEK. aad привязывает шифротекст к месту хранения.
+func (k *Keyring) Seal(plain, aad []byte) ([]byte, string, error) {
+	dek := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, "", fmt.Errorf("generate data key: %w", err)
+	}
+	kek := k.keys[k.active]
+
+	out := make([]byte, 1, 1+wrappedKeySize+nonceSize+len(plain)+16)
+	out[0] = payloadEnvelopeVersion
+	wrapNonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, wrapNonce); err != nil {
+		return nil, "", fmt.Errorf("generate nonce: %w", err)
+	}
+	out = append(out, wrapNonce...)
+	out = kek.Seal(out, wrapNonce, dek, []byte(k.active))
+
+	dataAEAD, err := newGCM(dek)
+	if err != nil {
+		return nil, "", err
+	}
+	dataNonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(rand.Reader, dataNonce); err != nil {
+		return nil, "", fmt.Errorf("generate nonce: %w", err)
+	}
+	out = append(out, dataNonce...)
+	out = dataAEAD.Seal(out, dataNonce, plain, aad)
+	return out, k.active, nil
+}
+
+// Open расшифровывает payload, зашифрованный Seal ключом keyID.
+func (k *Keyring) Open(keyID string, sealed, aad []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownPayloadKey, keyID)
+	}
+	if len(sealed) < 1+wrappedKeySize+nonceSize || sealed[0] != payloadEnvelopeVersion {
+		return nil, errBadEnvelope
+	}
+	wrapped := sealed[1 : 1+wrappedKeySize]
+	dek, err := kek.Open(nil, wrapped[:nonceSize], wrapped[nonceSize:], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key: %w", err)
+	}
+	dataAEAD, err := newGCM(dek)
+	if err != nil {
+		return nil, err
+	}
+	rest := sealed[1+wrappedKeySize:]
+	plain, err := dataAEAD.Open(nil, rest[:nonceSize], rest[nonceSize:], aad)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt payload: %w", err)
+	}
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SaveAlertState сохраняет состояние правила алерта.
+func (s *Store) SaveAlertState(ctx context.Context, st storage.AlertState) error {
+	labels, err := json.Marshal(st.Labels)
+	if err != nil {
+		return fmt.Errorf("marshal alert labels: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO alert_state(rule, state, severity, expr, summary, labels, value, active_at, fired_at, resolved_at, evaluated_at, last_error)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(rule) DO UPDATE SET state = excluded.state, severity = excluded.severity, expr = excluded.expr,
+			summary = excluded.summary, labels = excluded.labels, value = excluded.value, active_at = excluded.active_at,
+			fired_at = excluded.fired_at, resolved_at = excluded.resolved_at, evaluated_at = excluded.evaluated_at,
+			last_error = excluded.last_error`,
+		st.Rule, st.State, st.Severity, st.Expr, st.Summary, string(labels), st.Value,
+		nullTime(st.ActiveAt), nullTime(st.FiredAt), nullTime(st.ResolvedAt), st.EvaluatedAt.UTC(), st.LastError)
+	if err != nil {
+		return fmt.Errorf("save alert state: %w", err)
+	}
+	return nil
+}
+
+// AlertStates возвращает состояние всех правил, отсортированное по имени.
+func (s *Store) AlertStates(ctx context.Context) ([]storage.AlertState, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT rule, state, severity, expr, summary, labels, value, active_at, fired_at, resolved_at, evaluated_at, last_error
+		FROM alert_state ORDER BY rule`)
+	if err != nil {
+		return nil, fmt.Errorf("query alert state: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.AlertState
+	for rows.Next() {
+		var (
+			st                      storage.AlertState
+			labels, evaluated       string
+			active, fired, resolved sql.NullString
+		)
+		if err := rows.Scan(&st.Rule, &st.State, &st.Severity, &st.Expr, &st.Summary, &labels, &st.Value,
+			&active, &fired, &resolved, &evaluated, &st.LastError); err != nil {
+			return nil, fmt.Errorf("scan alert state: %w", err)
+		}
+		if err := json.Unmarshal([]byte(labels), &st.Labels); err != nil {
+			return nil, fmt.Errorf("decode alert labels: %w", err)
+		}
+		if st.EvaluatedAt, err = parseSQLiteTS(evaluated); err != nil {
+			return nil, fmt.Errorf("parse alert time: %w", err)
+		}
+		for _, f := range []struct {
+			src sql.NullString
+			dst **time.Time
+		}{{active, &st.ActiveAt}, {fired, &st.FiredAt}, {resolved, &st.ResolvedAt}} {
+			if !f.src.Valid {
+				continue
+			}
+			ts, err := parseSQLiteTS(f.src.String)
+			if err != nil {
+				return nil, fmt.Errorf("parse alert time: %w", err)
+			}
+			*f.dst = &ts
+		}
+		out = append(out, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate alert state: %w", err)
+	}
+	return out, nil
+}
+
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
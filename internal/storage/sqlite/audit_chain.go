@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/ed25519"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+func (s *Store) insertCheckpoint(ctx context.Context, tx *sql.Tx, eventID int64, hash string) error {
+	sig := ed25519.Sign(s.opts.SigningKey, storage.CheckpointMessage(eventID, hash))
+	_, err := tx.ExecContext(ctx, `INSERT INTO audit_checkpoints(event_id, hash, key_id, signature, ts) VALUES(?,?,?,?,?)`,
+		eventID, hash, s.opts.SigningKeyID, sig, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("insert audit checkpoint: %w", err)
+	}
+	return nil
+}
+
+// AuditCheckpoints возвращает подписи цепочки для записей с ID в диапазоне [fromID, toID].
+// toID <= 0 означает без верхней границы.
+func (s *Store) AuditCheckpoints(ctx context.Context, fromID, toID int64) ([]storage.AuditCheckpoint, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT event_id, hash, key_id, signature, ts
+FROM audit_checkpoints
+WHERE event_id >= ? AND (? <= 0 OR event_id <= ?)
+ORDER BY event_id`, fromID, toID, toID)
+	if err != nil {
+		return nil, fmt.Errorf("query audit checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var out []storage.AuditCheckpoint
+	for rows.Next() {
+		var cp storage.AuditCheckpoint
+		var ts string
+		if err := rows.Scan(&cp.EventID, &cp.Hash, &cp.KeyID, &cp.Signature, &ts); err != nil {
+			return nil, fmt.Errorf("scan audit checkpoint: %w", err)
+		}
+		parsedTS, err := parseSQLiteTS(ts)
+		if err != nil {
+			return nil, fmt.Errorf("parse checkpoint timestamp: %w", err)
+		}
+		cp.TS = parsedTS
+		out = append(out, cp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit checkpoints: %w", err)
+	}
+	return out, nil
+}
+
+// VerifyAuditChain проходит всю цепочку аудита и сообщает о первом разрыве.
+// Если pub не задан, используется публичный ключ из Options (если он есть).
+func (s *Store) VerifyAuditChain(ctx context.Context, pub ed25519.PublicKey) (storage.AuditChainReport, error) {
+	if pub == nil && s.opts.SigningKey != nil {
+		pub = s.opts.SigningKey.Public().(ed25519.PublicKey)
+	}
+	checkpoints, err := s.AuditCheckpoints(ctx, 0, 0)
+	if err != nil {
+		return storage.AuditChainReport{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+auditReadColumns+` FROM audit_events ORDER BY id`)
+	if err != nil {
+		return storage.AuditChainReport{}, fmt.Errorf("query audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	v := storage.NewChainVerifier("", checkpoints, pub)
+	for rows.Next() {
+		ev, err := s.scanAudit(rows)
+		if err != nil {
+			return storage.AuditChainReport{}, err
+		}
+		if !v.Add(ev) {
+			return v.Finish(), nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return storage.AuditChainReport{}, fmt.Errorf("iterate audit chain: %w", err)
+	}
+	return v.Finish(), nil
+}
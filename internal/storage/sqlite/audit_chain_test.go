@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"goadmin/internal/storage"
+)
+
+func openTestStore(t *testing.T, opts Options) *Store {
+	t.Helper()
+	st, err := OpenWithOptions(filepath.Join(t.TempDir(), "state.db"), opts)
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
+
+func TestAuditChainVerifyDetectsTampering(t *testing.T) {
+	ctx := context.Background()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	st := openTestStore(t, Options{SigningKey: priv, SigningKeyID: "k1", CheckpointEvery: 2})
+
+	for i := 0; i < 5; i++ {
+		if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: "u1", Action: "host:status", Source: "cli", Status: "ok", Payload: []byte(`{"n":1}`)}); err != nil {
+			t.Fatalf("save audit: %v", err)
+		}
+	}
+
+	report, err := st.VerifyAuditChain(ctx, nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !report.OK || report.Checked != 5 || report.Checkpoints != 2 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+
+	if _, err := st.db.Exec(`UPDATE audit_events SET status = 'denied' WHERE id = 3`); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	report, err = st.VerifyAuditChain(ctx, nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if report.OK || report.BrokenAt != 3 {
+		t.Fatalf("expected break at 3, got %#v", report)
+	}
+}
+
+func TestAuditChainVerifyDetectsDeletedTail(t *testing.T) {
+	ctx := context.Background()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	st := openTestStore(t, Options{SigningKey: priv, CheckpointEvery: 2})
+	for i := 0; i < 4; i++ {
+		if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: "u1", Action: "web:me", Source: "web", Status: "ok"}); err != nil {
+			t.Fatalf("save audit: %v", err)
+		}
+	}
+	if _, err := st.db.Exec(`DELETE FROM audit_events WHERE id >= 3`); err != nil {
+		t.Fatalf("tamper: %v", err)
+	}
+	report, err := st.VerifyAuditChain(ctx, nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if report.OK || report.BrokenAt != 4 {
+		t.Fatalf("expected missing checkpoint record 4, got %#v", report)
+	}
+}
+
+func TestMigrationBackfillsLegacyAuditChain(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	drv, err := resolveDriver(DriverAuto)
+	if err != nil {
+		t.Fatalf("driver: %v", err)
+	}
+	legacy, err := sql.Open(drv.sqlName, drv.dsn(path))
+	if err != nil {
+		t.Fatalf("open legacy: %v", err)
+	}
+	for _, stmt := range migrations[0].stmts {
+		if _, err := legacy.Exec(stmt); err != nil {
+			t.Fatalf("legacy schema: %v", err)
+		}
+	}
+	if _, err := legacy.Exec(`INSERT INTO audit_events(subject, action, source, status, request_id, payload) VALUES('u1','host:status','telegram','ok','r1',NULL)`); err != nil {
+		t.Fatalf("legacy insert: %v", err)
+	}
+	_ = legacy.Close()
+
+	st, err := Open(path)
+	if err != nil {
+		t.Fatalf("open migrated: %v", err)
+	}
+	defer st.Close()
+	if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: "u2", Action: "web:me", Source: "web", Status: "ok"}); err != nil {
+		t.Fatalf("save audit: %v", err)
+	}
+	report, err := st.VerifyAuditChain(ctx, nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !report.OK || report.Checked != 2 {
+		t.Fatalf("unexpected report: %#v", report)
+	}
+}
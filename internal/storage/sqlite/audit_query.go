@@ -0,0 +1,165 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// auditFilter собирает WHERE для фильтров AuditQuery.
+func auditFilter(q storage.AuditQuery) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+	if !q.From.IsZero() {
+		conds = append(conds, "ts >= ?")
+		args = append(args, q.From.UTC())
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "ts <= ?")
+		args = append(args, q.To.UTC())
+	}
+	for _, f := range []struct {
+		column string
+		value  string
+	}{
+		{"subject", q.Subject},
+		{"action", q.Action},
+		{"source", q.Source},
+		{"status", q.Status},
+		{"request_id", q.RequestID},
+	} {
+		if f.value != "" {
+			conds = append(conds, f.column+" = ?")
+			args = append(args, f.value)
+		}
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// QueryAuditPage возвращает страницу аудита в порядке ts DESC, id DESC.
+func (s *Store) QueryAuditPage(ctx context.Context, q storage.AuditQuery) (storage.AuditPage, error) {
+	limit := storage.NormalizeAuditLimit(q.Limit)
+	where, args := auditFilter(q)
+	if q.Cursor != "" {
+		ts, id, err := storage.DecodeAuditCursor(q.Cursor)
+		if err != nil {
+			return storage.AuditPage{}, err
+		}
+		cond := "(ts < ? OR (ts = ? AND id < ?))"
+		if where == "" {
+			where = " WHERE " + cond
+		} else {
+			where += " AND " + cond
+		}
+		args = append(args, ts, ts, id)
+	}
+	// Лишняя строка показывает, есть ли следующая страница.
+	args = append(args, limit+1)
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+auditReadColumns+` FROM audit_events`+where+` ORDER BY ts DESC, id DESC LIMIT ?`, args...)
+	if err != nil {
+		return storage.AuditPage{}, fmt.Errorf("query audit: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]storage.AuditEvent, 0, limit)
+	for rows.Next() {
+		ev, err := s.scanAudit(rows)
+		if err != nil {
+			return storage.AuditPage{}, err
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return storage.AuditPage{}, fmt.Errorf("iterate audit: %w", err)
+	}
+
+	page := storage.AuditPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		last := page.Events[limit-1]
+		page.NextCursor = storage.EncodeAuditCursor(last.TS, last.ID)
+	}
+	return page, nil
+}
+
+// maxAuditStatsRows ограничивает размер ответа агрегации.
+const maxAuditStatsRows = 10000
+
+// AggregateAudit считает события по окнам времени и полям группировки.
+func (s *Store) AggregateAudit(ctx context.Context, q storage.AuditStatsQuery) ([]storage.AuditStatsRow, error) {
+	groupBy, err := storage.ValidateAuditGroupBy(q.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	bucket := int64(q.Bucket / time.Second)
+	where, args := auditFilter(q.AuditQuery)
+
+	// ts хранится в UTC; первые 19 символов - "YYYY-MM-DD HH:MM:SS" в любом формате записи.
+	bucketExpr := "0"
+	var selectArgs []interface{}
+	if bucket > 0 {
+		bucketExpr = "(CAST(strftime('%s', substr(ts, 1, 19)) AS INTEGER) / ?) * ?"
+		selectArgs = append(selectArgs, bucket, bucket)
+	}
+	cols := []string{bucketExpr + " AS bucket"}
+	group := []string{"bucket"}
+	for _, f := range groupBy {
+		cols = append(cols, "COALESCE("+f+", '')")
+		group = append(group, "COALESCE("+f+", '')")
+	}
+	query := `SELECT ` + strings.Join(cols, ", ") + `, COUNT(*) FROM audit_events` + where +
+		` GROUP BY ` + strings.Join(group, ", ") + ` ORDER BY ` + strings.Join(group, ", ") + ` LIMIT ?`
+	args = append(append(selectArgs, args...), maxAuditStatsRows)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("aggregate audit: %w", err)
+	}
+	defer rows.Close()
+
+	var out []storage.AuditStatsRow
+	for rows.Next() {
+		var (
+			row    storage.AuditStatsRow
+			bucket int64
+			values = make([]string, len(groupBy))
+		)
+		dest := []interface{}{&bucket}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &row.Count)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan audit stats: %w", err)
+		}
+		if bucket > 0 {
+			row.Bucket = time.Unix(bucket, 0).UTC()
+		}
+		for i, f := range groupBy {
+			switch f {
+			case "subject":
+				row.Subject = values[i]
+			case "action":
+				row.Action = values[i]
+			case "source":
+				row.Source = values[i]
+			case "status":
+				row.Status = values[i]
+			}
+		}
+		out = append(out, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate audit stats: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+func seedAudit(t *testing.T, st *Store) time.Time {
+	t.Helper()
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	var events []storage.AuditEvent
+	for i := 0; i < 25; i++ {
+		status := "ok"
+		if i%5 == 0 {
+			status = "denied"
+		}
+		subject := "u1"
+		if i%2 == 1 {
+			subject = "u2"
+		}
+		events = append(events, storage.AuditEvent{
+			Subject:   subject,
+			Action:    "host:status",
+			Source:    "telegram",
+			Status:    status,
+			RequestID: "r" + string(rune('a'+i)),
+			// Каждые 10 событий - в новом часе; внутри часа пары с одинаковым ts.
+			TS: base.Add(time.Duration(i/10)*time.Hour + time.Duration(i/2)*time.Second),
+		})
+	}
+	if err := st.SaveAuditBatch(context.Background(), events); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return base
+}
+
+func TestQueryAuditPageWalksAllEvents(t *testing.T) {
+	st := openTestStore(t, Options{})
+	seedAudit(t, st)
+
+	seen := map[int64]bool{}
+	var prev storage.AuditEvent
+	cursor := ""
+	pages := 0
+	for {
+		page, err := st.QueryAuditPage(context.Background(), storage.AuditQuery{Limit: 7, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		pages++
+		for _, ev := range page.Events {
+			if seen[ev.ID] {
+				t.Fatalf("event %d returned twice", ev.ID)
+			}
+			seen[ev.ID] = true
+			if prev.ID != 0 && (ev.TS.After(prev.TS) || (ev.TS.Equal(prev.TS) && ev.ID > prev.ID)) {
+				t.Fatalf("order broken: %d after %d", ev.ID, prev.ID)
+			}
+			prev = ev
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 25 || pages != 4 {
+		t.Fatalf("seen %d events in %d pages, want 25 in 4", len(seen), pages)
+	}
+}
+
+func TestQueryAuditFilters(t *testing.T) {
+	st := openTestStore(t, Options{})
+	base := seedAudit(t, st)
+	ctx := context.Background()
+
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{Status: "denied", Subject: "u1"})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	// denied: i=0,5,10,15,20; из них u1 (четные): 0,10,20.
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	events, err = st.QueryAudit(ctx, storage.AuditQuery{RequestID: "rc"})
+	if err != nil || len(events) != 1 || events[0].RequestID != "rc" {
+		t.Fatalf("request_id filter: %v %#v", err, events)
+	}
+	events, err = st.QueryAudit(ctx, storage.AuditQuery{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)})
+	if err != nil || len(events) != 10 {
+		t.Fatalf("time range: %v, got %d events, want 10", err, len(events))
+	}
+	if _, err := st.QueryAuditPage(ctx, storage.AuditQuery{Cursor: "garbage"}); !errors.Is(err, storage.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestAggregateAudit(t *testing.T) {
+	st := openTestStore(t, Options{})
+	base := seedAudit(t, st)
+
+	rows, err := st.AggregateAudit(context.Background(), storage.AuditStatsQuery{
+		Bucket:  time.Hour,
+		GroupBy: []string{"status"},
+	})
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	counts := map[string]int64{}
+	var total int64
+	for _, row := range rows {
+		counts[row.Bucket.Format("15")+"/"+row.Status] = row.Count
+		total += row.Count
+		if row.Subject != "" {
+			t.Fatalf("subject must be empty when not grouped: %#v", row)
+		}
+	}
+	if total != 25 {
+		t.Fatalf("total = %d, want 25", total)
+	}
+	if counts["10/denied"] != 2 || counts["10/ok"] != 8 || counts["12/denied"] != 1 || counts["12/ok"] != 4 {
+		t.Fatalf("unexpected buckets: %#v", counts)
+	}
+	if !rows[0].Bucket.Equal(base) {
+		t.Fatalf("first bucket = %s, want %s", rows[0].Bucket, base)
+	}
+
+	if _, err := st.AggregateAudit(context.Background(), storage.AuditStatsQuery{GroupBy: []string{"payload"}}); !errors.Is(err, storage.ErrInvalidGroupBy) {
+		t.Fatalf("expected ErrInvalidGroupBy, got %v", err)
+	}
+}
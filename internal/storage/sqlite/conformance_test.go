@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/storagetest"
+)
+
+func TestConformance(t *testing.T) {
+	for _, name := range Drivers() {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			storagetest.Run(t, func(t *testing.T) storage.Store {
+				return openTestStore(t, Options{Driver: name})
+			})
+		})
+		t.Run(name+"/encrypted", func(t *testing.T) {
+			storagetest.Run(t, func(t *testing.T) storage.Store {
+				keyring := testKeyring(t, testKeys(t, "k1"), "", "k1")
+				return openTestStore(t, Options{Driver: name, Keyring: keyring})
+			})
+		})
+	}
+}
+
+func TestDriversShareDatabaseFormat(t *testing.T) {
+	if len(Drivers()) < 2 {
+		t.Skip("only one sqlite driver in this build")
+	}
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	ts := time.Date(2026, 3, 1, 10, 0, 0, 123456789, time.UTC)
+
+	// Пишут оба драйвера по очереди; цепочка и порядок ts должны сохраниться.
+	for i, name := range []string{DriverCGO, DriverPureGo, DriverCGO} {
+		st := openAt(t, path, Options{Driver: name})
+		if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: name, Action: "a", TS: ts.Add(time.Duration(i) * time.Second)}); err != nil {
+			t.Fatalf("%s save: %v", name, err)
+		}
+		if err := st.Close(); err != nil {
+			t.Fatalf("%s close: %v", name, err)
+		}
+	}
+	for _, name := range Drivers() {
+		st := openAt(t, path, Options{Driver: name})
+		events, err := st.QueryAudit(ctx, storage.AuditQuery{From: ts.Add(time.Second)})
+		if err != nil || len(events) != 2 || !events[1].TS.Equal(ts.Add(time.Second)) {
+			t.Fatalf("%s query: %v %#v", name, err, events)
+		}
+		report, err := st.VerifyAuditChain(ctx, nil)
+		if err != nil || !report.OK || report.Checked != 3 {
+			t.Fatalf("%s verify: %v %#v", name, err, report)
+		}
+	}
+}
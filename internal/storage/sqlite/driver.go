@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Имена драйверов для Options.Driver и sqlite.driver в конфиге.
+const (
+	DriverAuto   = "auto"
+	DriverCGO    = "cgo"
+	DriverPureGo = "purego"
+)
+
+// driver связывает имя database/sql драйвера с форматом DSN.
+type driver struct {
+	sqlName string
+	dsn     func(path string) string
+}
+
+// drivers заполняется в init файлов driver_*.go в зависимости от build-тегов.
+var drivers = map[string]driver{}
+
+// Drivers возвращает драйверы, собранные в бинарь.
+func Drivers() []string {
+	out := make([]string, 0, len(drivers))
+	for name := range drivers {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// resolveDriver выбирает драйвер: auto предпочитает cgo, если он собран.
+func resolveDriver(name string) (driver, error) {
+	if name == "" || name == DriverAuto {
+		if d, ok := drivers[DriverCGO]; ok {
+			return d, nil
+		}
+		name = DriverPureGo
+	}
+	d, ok := drivers[name]
+	if !ok {
+		return driver{}, fmt.Errorf("sqlite driver %q is not available in this build (have %v)", name, Drivers())
+	}
+	return d, nil
+}
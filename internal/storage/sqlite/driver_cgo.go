@@ -0,0 +1,18 @@
+//go:build cgo && !purego
+
+package sqlite
+
+import (
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3" // sqlite driver (CGO)
+)
+
+func init() {
+	drivers[DriverCGO] = driver{
+		sqlName: "sqlite3",
+		dsn: func(path string) string {
+			return fmt.Sprintf("file:%s?_journal=WAL&_busy_timeout=5000&_txlock=immediate", path)
+		},
+	}
+}
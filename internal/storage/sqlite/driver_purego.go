@@ -0,0 +1,18 @@
+package sqlite
+
+import (
+	"fmt"
+
+	_ "modernc.org/sqlite" // sqlite driver (pure Go)
+)
+
+func init() {
+	drivers[DriverPureGo] = driver{
+		sqlName: "sqlite",
+		dsn: func(path string) string {
+			// _time_format=sqlite пишет time.Time в том же формате, что и mattn/go-sqlite3,
+			// поэтому базы совместимы между драйверами, а ts сравнивается лексически.
+			return fmt.Sprintf("file:%s?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate&_time_format=sqlite", path)
+		},
+	}
+}
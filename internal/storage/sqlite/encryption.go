@@ -0,0 +1,196 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	tableMetrics = "metrics"
+	tableAudit   = "audit_events"
+)
+
+// ErrEncryptionDisabled - операция требует Options.Keyring.
+var ErrEncryptionDisabled = errors.New("payload encryption is not configured")
+
+// payloadAAD привязывает шифротекст к таблице, чтобы его нельзя было перенести в другую.
+func payloadAAD(table string) []byte {
+	return []byte("goadmin:" + table + ":payload")
+}
+
+// sealPayload шифрует payload активным ключом. Пустой payload и хранилище без
+// keyring возвращают данные как есть с пустым key_id.
+func (s *Store) sealPayload(table string, plain []byte) ([]byte, string, error) {
+	if s.opts.Keyring == nil || plain == nil {
+		return plain, "", nil
+	}
+	sealed, keyID, err := s.opts.Keyring.Seal(plain, payloadAAD(table))
+	if err != nil {
+		return nil, "", fmt.Errorf("encrypt %s payload: %w", table, err)
+	}
+	return sealed, keyID, nil
+}
+
+// openPayload расшифровывает payload строки; пустой key_id означает открытый текст.
+func (s *Store) openPayload(table, keyID string, data []byte) ([]byte, error) {
+	if keyID == "" {
+		return data, nil
+	}
+	if s.opts.Keyring == nil {
+		return nil, fmt.Errorf("%s payload encrypted with key %q: %w", table, keyID, ErrEncryptionDisabled)
+	}
+	plain, err := s.opts.Keyring.Open(keyID, data, payloadAAD(table))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt %s payload: %w", table, err)
+	}
+	return plain, nil
+}
+
+// RekeyOptions управляет фоновым перешифрованием.
+type RekeyOptions struct {
+	// BatchSize - строк в одной транзакции (по умолчанию 500).
+	BatchSize int
+	// Pause - задержка между пачками, чтобы не мешать основной нагрузке.
+	Pause time.Duration
+	// Progress вызывается после каждой пачки.
+	Progress func(RekeyProgress)
+}
+
+// RekeyProgress - состояние перешифрования одной таблицы.
+type RekeyProgress struct {
+	Table     string `json:"table"`
+	Scanned   int64  `json:"scanned"`
+	Rewritten int64  `json:"rewritten"`
+	LastID    int64  `json:"last_id"`
+	Done      bool   `json:"done"`
+}
+
+// Rekey перешифровывает активным ключом все payload, записанные другим ключом
+// или открытым текстом. Работает небольшими транзакциями и может идти параллельно
+// с записью; новые строки уже пишутся активным ключом. Прерывание безопасно:
+// повторный запуск продолжит с необработанных строк.
+func (s *Store) Rekey(ctx context.Context, opts RekeyOptions) ([]RekeyProgress, error) {
+	if s.opts.Keyring == nil {
+		return nil, ErrEncryptionDisabled
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	var out []RekeyProgress
+	for _, table := range []string{tableMetrics, tableAudit} {
+		p, err := s.rekeyTable(ctx, table, opts)
+		out = append(out, p)
+		if err != nil {
+			return out, err
+		}
+	}
+	return out, nil
+}
+
+type rekeyRow struct {
+	id      int64
+	payload []byte
+	keyID   string
+}
+
+func (s *Store) rekeyTable(ctx context.Context, table string, opts RekeyOptions) (RekeyProgress, error) {
+	p := RekeyProgress{Table: table}
+	active := s.opts.Keyring.ActiveKeyID()
+	for {
+		if err := ctx.Err(); err != nil {
+			return p, err
+		}
+		rows, err := s.selectRekeyBatch(ctx, table, active, p.LastID, opts.BatchSize)
+		if err != nil {
+			return p, err
+		}
+		if len(rows) == 0 {
+			p.Done = true
+			if opts.Progress != nil {
+				opts.Progress(p)
+			}
+			return p, nil
+		}
+		n, err := s.rewriteBatch(ctx, table, rows)
+		if err != nil {
+			return p, err
+		}
+		p.Scanned += int64(len(rows))
+		p.Rewritten += n
+		p.LastID = rows[len(rows)-1].id
+		if opts.Progress != nil {
+			opts.Progress(p)
+		}
+		if opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return p, ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+	}
+}
+
+// selectRekeyBatch читает следующую пачку строк, зашифрованных не активным ключом.
+// Таблица берется только из констант tableMetrics/tableAudit.
+func (s *Store) selectRekeyBatch(ctx context.Context, table, active string, afterID int64, limit int) ([]rekeyRow, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, payload, payload_key_id FROM `+table+
+		` WHERE payload_key_id != ? AND id > ? ORDER BY id LIMIT ?`, active, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("select %s for rekey: %w", table, err)
+	}
+	defer rows.Close()
+	var out []rekeyRow
+	for rows.Next() {
+		var r rekeyRow
+		if err := rows.Scan(&r.id, &r.payload, &r.keyID); err != nil {
+			return nil, fmt.Errorf("scan %s for rekey: %w", table, err)
+		}
+		out = append(out, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate %s for rekey: %w", table, err)
+	}
+	return out, nil
+}
+
+// rewriteBatch перешифровывает пачку одной транзакцией. Условие на старый key_id
+// защищает от гонки с параллельным rekey.
+func (s *Store) rewriteBatch(ctx context.Context, table string, rows []rekeyRow) (int64, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin rekey tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var rewritten int64
+	for _, r := range rows {
+		if r.payload == nil {
+			continue
+		}
+		plain, err := s.openPayload(table, r.keyID, r.payload)
+		if err != nil {
+			return 0, fmt.Errorf("row %d: %w", r.id, err)
+		}
+		sealed, keyID, err := s.sealPayload(table, plain)
+		if err != nil {
+			return 0, err
+		}
+		res, err := tx.ExecContext(ctx, `UPDATE `+table+` SET payload = ?, payload_key_id = ? WHERE id = ? AND payload_key_id = ?`,
+			sealed, keyID, r.id, r.keyID)
+		if err != nil {
+			return 0, fmt.Errorf("rekey %s row %d: %w", table, r.id, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		rewritten += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit rekey: %w", err)
+	}
+	return rewritten, nil
+}
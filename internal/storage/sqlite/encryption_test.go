@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"goadmin/internal/storage"
+)
+
+func testKeys(t *testing.T, ids ...string) map[string][]byte {
+	t.Helper()
+	keys := make(map[string][]byte, len(ids))
+	for _, id := range ids {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatalf("rand: %v", err)
+		}
+		keys[id] = key
+	}
+	return keys
+}
+
+func testKeyring(t *testing.T, keys map[string][]byte, active string, ids ...string) *storage.Keyring {
+	t.Helper()
+	k, err := storage.NewKeyring(keys, ids, active)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	return k
+}
+
+func TestPayloadEncryptedAtRest(t *testing.T) {
+	ctx := context.Background()
+	st := openTestStore(t, Options{Keyring: testKeyring(t, testKeys(t, "k1"), "", "k1")})
+	secret := []byte(`{"token":"s3cr3t"}`)
+
+	if err := st.SaveMetric(ctx, storage.MetricRecord{Module: "host", Payload: secret}); err != nil {
+		t.Fatalf("save metric: %v", err)
+	}
+	if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: "u1", Action: "host:status", Status: "ok", Payload: secret}); err != nil {
+		t.Fatalf("save audit: %v", err)
+	}
+
+	for _, table := range []string{tableMetrics, tableAudit} {
+		var raw []byte
+		var keyID string
+		if err := st.db.QueryRow(`SELECT payload, payload_key_id FROM `+table).Scan(&raw, &keyID); err != nil {
+			t.Fatalf("raw %s: %v", table, err)
+		}
+		if keyID != "k1" || bytes.Contains(raw, []byte("s3cr3t")) {
+			t.Fatalf("%s payload stored in clear (key_id=%q)", table, keyID)
+		}
+	}
+
+	rec, err := st.LatestMetric(ctx, "host")
+	if err != nil || !bytes.Equal(rec.Payload, secret) {
+		t.Fatalf("latest metric: %v %q", err, rec.Payload)
+	}
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{})
+	if err != nil || len(events) != 1 || !bytes.Equal(events[0].Payload, secret) {
+		t.Fatalf("query audit: %v %#v", err, events)
+	}
+	report, err := st.VerifyAuditChain(ctx, nil)
+	if err != nil || !report.OK {
+		t.Fatalf("verify: %v %#v", err, report)
+	}
+
+	// Шифротекст привязан к таблице: перенос в другую таблицу не расшифруется.
+	if _, err := st.db.Exec(`UPDATE audit_events SET payload = (SELECT payload FROM metrics)`); err != nil {
+		t.Fatalf("swap: %v", err)
+	}
+	if _, err := st.QueryAudit(ctx, storage.AuditQuery{}); err == nil {
+		t.Fatal("expected decrypt error for moved ciphertext")
+	}
+}
+
+func TestRekeyRotatesAndEncryptsLegacyRows(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	payload := []byte(`{"n":1}`)
+	save := func(st *Store, n int, payload []byte) {
+		t.Helper()
+		for i := 0; i < n; i++ {
+			if err := st.SaveAudit(ctx, storage.AuditEvent{Subject: "u1", Action: "a", Payload: payload}); err != nil {
+				t.Fatalf("save: %v", err)
+			}
+		}
+	}
+
+	// Строки до включения шифрования остаются открытым текстом.
+	plain, err := OpenWithOptions(path, Options{})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	save(plain, 2, payload)
+	if _, err := plain.Rekey(ctx, RekeyOptions{}); !errors.Is(err, ErrEncryptionDisabled) {
+		t.Fatalf("expected ErrEncryptionDisabled, got %v", err)
+	}
+	_ = plain.Close()
+
+	keys := testKeys(t, "k1", "k2")
+	st, err := OpenWithOptions(path, Options{Keyring: testKeyring(t, keys, "k1", "k1", "k2")})
+	if err != nil {
+		t.Fatalf("open k1: %v", err)
+	}
+	save(st, 3, payload)
+	save(st, 1, nil)
+	_ = st.Close()
+
+	st, err = OpenWithOptions(path, Options{Keyring: testKeyring(t, keys, "k2", "k1", "k2")})
+	if err != nil {
+		t.Fatalf("open k2: %v", err)
+	}
+	defer st.Close()
+	var batches int
+	result, err := st.Rekey(ctx, RekeyOptions{BatchSize: 2, Progress: func(RekeyProgress) { batches++ }})
+	if err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	audit := result[1]
+	if audit.Table != tableAudit || !audit.Done || audit.Scanned != 6 || audit.Rewritten != 5 {
+		t.Fatalf("unexpected audit progress: %#v", audit)
+	}
+	if batches < 4 {
+		t.Fatalf("expected batched progress, got %d callbacks", batches)
+	}
+
+	var stale int
+	if err := st.db.QueryRow(`SELECT COUNT(*) FROM audit_events WHERE payload IS NOT NULL AND payload_key_id != 'k2'`).Scan(&stale); err != nil || stale != 0 {
+		t.Fatalf("stale rows after rekey: %d %v", stale, err)
+	}
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{})
+	if err != nil || len(events) != 6 {
+		t.Fatalf("query: %v, %d events", err, len(events))
+	}
+	for _, ev := range events {
+		if ev.Payload != nil && !bytes.Equal(ev.Payload, payload) {
+			t.Fatalf("event %d payload = %q", ev.ID, ev.Payload)
+		}
+	}
+	report, err := st.VerifyAuditChain(ctx, nil)
+	if err != nil || !report.OK || report.Checked != 6 {
+		t.Fatalf("verify after rekey: %v %#v", err, report)
+	}
+
+	// Повторный запуск видит только строку без payload и ничего не переписывает.
+	again, err := st.Rekey(ctx, RekeyOptions{})
+	if err != nil || again[1].Scanned != 1 || again[1].Rewritten != 0 {
+		t.Fatalf("second rekey must only see the nil payload row: %v %#v", err, again)
+	}
+}
+
+func TestKeyringRejectsUnknownKey(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	st, err := OpenWithOptions(path, Options{Keyring: testKeyring(t, testKeys(t, "k1"), "", "k1")})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := st.SaveMetric(ctx, storage.MetricRecord{Module: "host", Payload: []byte(`{}`)}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	_ = st.Close()
+
+	st = openAt(t, path, Options{Keyring: testKeyring(t, testKeys(t, "k9"), "", "k9")})
+	if _, err := st.LatestMetric(ctx, "host"); !errors.Is(err, storage.ErrUnknownPayloadKey) {
+		t.Fatalf("expected ErrUnknownPayloadKey, got %v", err)
+	}
+	st = openAt(t, path, Options{})
+	if _, err := st.LatestMetric(ctx, "host"); !errors.Is(err, ErrEncryptionDisabled) {
+		t.Fatalf("expected ErrEncryptionDisabled, got %v", err)
+	}
+}
+
+func openAt(t *testing.T, path string, opts Options) *Store {
+	t.Helper()
+	st, err := OpenWithOptions(path, opts)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	t.Cleanup(func() { _ = st.Close() })
+	return st
+}
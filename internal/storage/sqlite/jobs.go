@@ -0,0 +1,161 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SaveJobRun добавляет запуск задачи и удаляет записи сверх storage.MaxJobRunsPerJob.
+func (s *Store) SaveJobRun(ctx context.Context, run storage.JobRun) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin job run tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `INSERT INTO job_runs(job, trigger, started_at, finished_at, status, error) VALUES(?,?,?,?,?,?)`,
+		run.Job, run.Trigger, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.Status, run.Error); err != nil {
+		return fmt.Errorf("insert job run: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM job_runs WHERE job = ? AND id <= (
+		SELECT id FROM job_runs WHERE job = ? ORDER BY id DESC LIMIT 1 OFFSET ?)`,
+		run.Job, run.Job, storage.MaxJobRunsPerJob); err != nil {
+		return fmt.Errorf("trim job runs: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit job run: %w", err)
+	}
+	return nil
+}
+
+// JobRuns возвращает историю запусков, новые первыми.
+func (s *Store) JobRuns(ctx context.Context, q storage.JobRunQuery) ([]storage.JobRun, error) {
+	limit := q.Limit
+	if limit <= 0 || limit > storage.MaxJobRunsPerJob {
+		limit = storage.MaxJobRunsPerJob
+	}
+	query := `SELECT id, job, trigger, started_at, finished_at, status, error FROM job_runs`
+	args := []interface{}{}
+	if q.Job != "" {
+		query += ` WHERE job = ?`
+		args = append(args, q.Job)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query job runs: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.JobRun
+	for rows.Next() {
+		var (
+			run               storage.JobRun
+			started, finished string
+		)
+		if err := rows.Scan(&run.ID, &run.Job, &run.Trigger, &started, &finished, &run.Status, &run.Error); err != nil {
+			return nil, fmt.Errorf("scan job run: %w", err)
+		}
+		if run.StartedAt, err = parseSQLiteTS(started); err != nil {
+			return nil, fmt.Errorf("parse job run start: %w", err)
+		}
+		if run.FinishedAt, err = parseSQLiteTS(finished); err != nil {
+			return nil, fmt.Errorf("parse job run finish: %w", err)
+		}
+		out = append(out, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate job runs: %w", err)
+	}
+	return out, nil
+}
+
+// JobStates возвращает сохраненное состояние задач.
+func (s *Store) JobStates(ctx context.Context) ([]storage.JobState, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT job, paused, run_requested, updated_at, updated_by FROM job_state ORDER BY job`)
+	if err != nil {
+		return nil, fmt.Errorf("query job state: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.JobState
+	for rows.Next() {
+		var (
+			st      storage.JobState
+			updated string
+		)
+		if err := rows.Scan(&st.Job, &st.Paused, &st.RunRequested, &updated, &st.UpdatedBy); err != nil {
+			return nil, fmt.Errorf("scan job state: %w", err)
+		}
+		if st.UpdatedAt, err = parseSQLiteTS(updated); err != nil {
+			return nil, fmt.Errorf("parse job state time: %w", err)
+		}
+		out = append(out, st)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate job state: %w", err)
+	}
+	return out, nil
+}
+
+// SetJobPaused сохраняет паузу задачи.
+func (s *Store) SetJobPaused(ctx context.Context, job string, paused bool, by string) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO job_state(job, paused, updated_at, updated_by) VALUES(?,?,?,?)
+		ON CONFLICT(job) DO UPDATE SET paused = excluded.paused, updated_at = excluded.updated_at, updated_by = excluded.updated_by`,
+		job, paused, time.Now().UTC(), by)
+	if err != nil {
+		return fmt.Errorf("save job state: %w", err)
+	}
+	return nil
+}
+
+// RequestJobRun ставит запрос ручного запуска; демон снимает его через ClaimJobRunRequests.
+func (s *Store) RequestJobRun(ctx context.Context, job, by string) error {
+	_, err := s.db.ExecContext(ctx, `INSERT INTO job_state(job, run_requested, updated_at, updated_by) VALUES(?,1,?,?)
+		ON CONFLICT(job) DO UPDATE SET run_requested = 1, updated_at = excluded.updated_at, updated_by = excluded.updated_by`,
+		job, time.Now().UTC(), by)
+	if err != nil {
+		return fmt.Errorf("request job run: %w", err)
+	}
+	return nil
+}
+
+// ClaimJobRunRequests атомарно снимает все запросы ручного запуска.
+func (s *Store) ClaimJobRunRequests(ctx context.Context) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin claim tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `SELECT job FROM job_state WHERE run_requested = 1 ORDER BY job`)
+	if err != nil {
+		return nil, fmt.Errorf("query job run requests: %w", err)
+	}
+	var jobs []string
+	for rows.Next() {
+		var job string
+		if err := rows.Scan(&job); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan job run request: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate job run requests: %w", err)
+	}
+	if len(jobs) == 0 {
+		return nil, nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE job_state SET run_requested = 0 WHERE run_requested = 1`); err != nil {
+		return nil, fmt.Errorf("clear job run requests: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit claim: %w", err)
+	}
+	return jobs, nil
+}
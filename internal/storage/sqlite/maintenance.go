@@ -0,0 +1,146 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// SaveSilence создает или обновляет silence по id.
+func (s *Store) SaveSilence(ctx context.Context, sil storage.Silence) error {
+	matchers, err := json.Marshal(sil.Matchers)
+	if err != nil {
+		return fmt.Errorf("marshal silence matchers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO silences(id, matchers, starts_at, ends_at, comment, created_by, created_at, expiry_audited)
+		VALUES(?,?,?,?,?,?,?,?)
+		ON CONFLICT(id) DO UPDATE SET matchers = excluded.matchers, starts_at = excluded.starts_at, ends_at = excluded.ends_at,
+			comment = excluded.comment, expiry_audited = excluded.expiry_audited`,
+		sil.ID, string(matchers), sil.StartsAt.UTC(), sil.EndsAt.UTC(), sil.Comment, sil.CreatedBy, sil.CreatedAt.UTC(), sil.ExpiryAudited)
+	if err != nil {
+		return fmt.Errorf("save silence: %w", err)
+	}
+	return nil
+}
+
+// Silences возвращает все silences, новые первыми.
+func (s *Store) Silences(ctx context.Context) ([]storage.Silence, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, matchers, starts_at, ends_at, comment, created_by, created_at, expiry_audited
+		FROM silences ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("query silences: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.Silence
+	for rows.Next() {
+		var (
+			sil                        storage.Silence
+			matchers, starts, ends, at string
+		)
+		if err := rows.Scan(&sil.ID, &matchers, &starts, &ends, &sil.Comment, &sil.CreatedBy, &at, &sil.ExpiryAudited); err != nil {
+			return nil, fmt.Errorf("scan silence: %w", err)
+		}
+		if err := json.Unmarshal([]byte(matchers), &sil.Matchers); err != nil {
+			return nil, fmt.Errorf("decode silence matchers: %w", err)
+		}
+		for _, f := range []struct {
+			src string
+			dst *time.Time
+		}{{starts, &sil.StartsAt}, {ends, &sil.EndsAt}, {at, &sil.CreatedAt}} {
+			if *f.dst, err = parseSQLiteTS(f.src); err != nil {
+				return nil, fmt.Errorf("parse silence time: %w", err)
+			}
+		}
+		out = append(out, sil)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate silences: %w", err)
+	}
+	return out, nil
+}
+
+// SaveMaintenanceWindow создает или заменяет окно обслуживания по имени.
+func (s *Store) SaveMaintenanceWindow(ctx context.Context, w storage.MaintenanceWindow) error {
+	matchers, err := json.Marshal(w.Matchers)
+	if err != nil {
+		return fmt.Errorf("marshal window matchers: %w", err)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO maintenance_windows(name, schedule, duration_ms, starts_at, ends_at, matchers, comment, created_by, created_at, expiry_audited)
+		VALUES(?,?,?,?,?,?,?,?,?,?)
+		ON CONFLICT(name) DO UPDATE SET schedule = excluded.schedule, duration_ms = excluded.duration_ms,
+			starts_at = excluded.starts_at, ends_at = excluded.ends_at, matchers = excluded.matchers, comment = excluded.comment,
+			created_by = excluded.created_by, created_at = excluded.created_at, expiry_audited = excluded.expiry_audited`,
+		w.Name, w.Schedule, w.Duration.Milliseconds(), nullTime(w.StartsAt), nullTime(w.EndsAt), string(matchers),
+		w.Comment, w.CreatedBy, w.CreatedAt.UTC(), w.ExpiryAudited)
+	if err != nil {
+		return fmt.Errorf("save maintenance window: %w", err)
+	}
+	return nil
+}
+
+// DeleteMaintenanceWindow удаляет окно обслуживания.
+func (s *Store) DeleteMaintenanceWindow(ctx context.Context, name string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM maintenance_windows WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("delete maintenance window: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete maintenance window: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("maintenance window %s: %w", name, storage.ErrNotFound)
+	}
+	return nil
+}
+
+// MaintenanceWindows возвращает окна обслуживания по имени.
+func (s *Store) MaintenanceWindows(ctx context.Context) ([]storage.MaintenanceWindow, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT name, schedule, duration_ms, starts_at, ends_at, matchers, comment, created_by, created_at, expiry_audited
+		FROM maintenance_windows ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("query maintenance windows: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.MaintenanceWindow
+	for rows.Next() {
+		var (
+			w            storage.MaintenanceWindow
+			durationMS   int64
+			starts, ends sql.NullString
+			matchers, at string
+		)
+		if err := rows.Scan(&w.Name, &w.Schedule, &durationMS, &starts, &ends, &matchers, &w.Comment, &w.CreatedBy, &at, &w.ExpiryAudited); err != nil {
+			return nil, fmt.Errorf("scan maintenance window: %w", err)
+		}
+		w.Duration = time.Duration(durationMS) * time.Millisecond
+		if err := json.Unmarshal([]byte(matchers), &w.Matchers); err != nil {
+			return nil, fmt.Errorf("decode window matchers: %w", err)
+		}
+		if w.CreatedAt, err = parseSQLiteTS(at); err != nil {
+			return nil, fmt.Errorf("parse window time: %w", err)
+		}
+		for _, f := range []struct {
+			src sql.NullString
+			dst **time.Time
+		}{{starts, &w.StartsAt}, {ends, &w.EndsAt}} {
+			if !f.src.Valid {
+				continue
+			}
+			ts, err := parseSQLiteTS(f.src.String)
+			if err != nil {
+				return nil, fmt.Errorf("parse window time: %w", err)
+			}
+			*f.dst = &ts
+		}
+		out = append(out, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate maintenance windows: %w", err)
+	}
+	return out, nil
+}
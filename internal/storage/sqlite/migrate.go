@@ -0,0 +1,233 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+
+	"goadmin/internal/storage"
+)
+
+// migration описывает шаг схемы; версия хранится в PRAGMA user_version.
+type migration struct {
+	version int
+	stmts   []string
+	apply   func(tx *sql.Tx) error
+}
+
+var migrations = []migration{
+	{
+		version: 1,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS metrics (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				ts DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				module TEXT NOT NULL,
+				payload BLOB NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_metrics_ts ON metrics(ts);`,
+			`CREATE INDEX IF NOT EXISTS idx_metrics_module_ts ON metrics(module, ts);`,
+			`CREATE TABLE IF NOT EXISTS audit_events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				ts DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				subject TEXT,
+				action TEXT,
+				source TEXT,
+				status TEXT,
+				request_id TEXT,
+				payload BLOB
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_ts ON audit_events(ts);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_subject_ts ON audit_events(subject, ts);`,
+		},
+	},
+	{
+		version: 2,
+		stmts: []string{
+			`ALTER TABLE audit_events ADD COLUMN prev_hash TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE audit_events ADD COLUMN hash TEXT NOT NULL DEFAULT '';`,
+			`CREATE TABLE IF NOT EXISTS audit_checkpoints (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				ts DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				event_id INTEGER NOT NULL,
+				hash TEXT NOT NULL,
+				key_id TEXT NOT NULL,
+				signature BLOB NOT NULL
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_event ON audit_checkpoints(event_id);`,
+		},
+		apply: backfillAuditChain,
+	},
+	{
+		version: 3,
+		stmts: []string{
+			// Порядок страниц аудита (ts DESC, id DESC) и фильтры /v1/audit.
+			`CREATE INDEX IF NOT EXISTS idx_audit_ts_id ON audit_events(ts, id);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_action_ts ON audit_events(action, ts);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_source_ts ON audit_events(source, ts);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_status_ts ON audit_events(status, ts);`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_request_id ON audit_events(request_id);`,
+			`DROP INDEX IF EXISTS idx_audit_ts;`,
+		},
+	},
+	{
+		version: 4,
+		stmts: []string{
+			// Идентификатор KEK, которым зашифрован payload; пустая строка - открытый текст.
+			`ALTER TABLE metrics ADD COLUMN payload_key_id TEXT NOT NULL DEFAULT '';`,
+			`ALTER TABLE audit_events ADD COLUMN payload_key_id TEXT NOT NULL DEFAULT '';`,
+		},
+	},
+	{
+		version: 5,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS job_runs (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				job TEXT NOT NULL,
+				trigger TEXT NOT NULL,
+				started_at DATETIME NOT NULL,
+				finished_at DATETIME NOT NULL,
+				status TEXT NOT NULL,
+				error TEXT NOT NULL DEFAULT ''
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_job_runs_job_id ON job_runs(job, id);`,
+			// Пауза и запрос ручного запуска; CLI пишет сюда, демон применяет.
+			`CREATE TABLE IF NOT EXISTS job_state (
+				job TEXT PRIMARY KEY,
+				paused INTEGER NOT NULL DEFAULT 0,
+				run_requested INTEGER NOT NULL DEFAULT 0,
+				updated_at DATETIME NOT NULL,
+				updated_by TEXT NOT NULL DEFAULT ''
+			);`,
+		},
+	},
+	{
+		version: 6,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS alert_state (
+				rule TEXT PRIMARY KEY,
+				state TEXT NOT NULL,
+				severity TEXT NOT NULL,
+				expr TEXT NOT NULL,
+				summary TEXT NOT NULL DEFAULT '',
+				labels TEXT NOT NULL DEFAULT '{}',
+				value REAL NOT NULL DEFAULT 0,
+				active_at DATETIME,
+				fired_at DATETIME,
+				resolved_at DATETIME,
+				evaluated_at DATETIME NOT NULL,
+				last_error TEXT NOT NULL DEFAULT ''
+			);`,
+		},
+	},
+	{
+		version: 7,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS subscriptions (
+				transport TEXT NOT NULL,
+				chat_id TEXT NOT NULL,
+				topic TEXT NOT NULL,
+				min_severity TEXT NOT NULL DEFAULT '',
+				created_by TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				PRIMARY KEY (transport, chat_id, topic)
+			);`,
+			`CREATE INDEX IF NOT EXISTS idx_subscriptions_topic ON subscriptions(transport, topic);`,
+		},
+	},
+	{
+		version: 8,
+		stmts: []string{
+			`CREATE TABLE IF NOT EXISTS silences (
+				id TEXT PRIMARY KEY,
+				matchers TEXT NOT NULL,
+				starts_at DATETIME NOT NULL,
+				ends_at DATETIME NOT NULL,
+				comment TEXT NOT NULL DEFAULT '',
+				created_by TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				expiry_audited INTEGER NOT NULL DEFAULT 0
+			);`,
+			`CREATE TABLE IF NOT EXISTS maintenance_windows (
+				name TEXT PRIMARY KEY,
+				schedule TEXT NOT NULL DEFAULT '',
+				duration_ms INTEGER NOT NULL DEFAULT 0,
+				starts_at DATETIME,
+				ends_at DATETIME,
+				matchers TEXT NOT NULL,
+				comment TEXT NOT NULL DEFAULT '',
+				created_by TEXT NOT NULL DEFAULT '',
+				created_at DATETIME NOT NULL,
+				expiry_audited INTEGER NOT NULL DEFAULT 0
+			);`,
+		},
+	},
+}
+
+func migrate(db *sql.DB) error {
+	var current int
+	if err := db.QueryRow(`PRAGMA user_version`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(db, m); err != nil {
+			return fmt.Errorf("migration %d failed: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, stmt := range m.stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	if m.apply != nil {
+		if err := m.apply(tx); err != nil {
+			return err
+		}
+	}
+	// PRAGMA не поддерживает плейсхолдеры; версия берется из статического списка.
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, m.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backfillAuditChain связывает в цепочку записи, созданные до появления hash-колонок.
+func backfillAuditChain(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT ` + auditColumns + ` FROM audit_events ORDER BY id`)
+	if err != nil {
+		return err
+	}
+	var events []storage.AuditEvent
+	for rows.Next() {
+		ev, err := scanAuditEvent(rows)
+		if err != nil {
+			_ = rows.Close()
+			return err
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+
+	prev := ""
+	for _, ev := range events {
+		ev.Hash = storage.AuditEventHash(prev, ev)
+		if _, err := tx.Exec(`UPDATE audit_events SET prev_hash = ?, hash = ? WHERE id = ?`, prev, ev.Hash, ev.ID); err != nil {
+			return err
+		}
+		prev = ev.Hash
+	}
+	return nil
+}
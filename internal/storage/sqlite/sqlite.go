@@ -2,26 +2,58 @@ package sqlite
 
 import (
 	"context"
+	"crypto/ed25519"
 	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3" // sqlite driver
-
 	"goadmin/internal/storage"
 )
 
+// Options задает необязательные параметры хранилища.
+type Options struct {
+	// Driver выбирает драйвер: auto (по умолчанию), cgo или purego.
+	Driver string
+	// SigningKey включает подпись контрольных точек цепочки аудита.
+	SigningKey ed25519.PrivateKey
+	// SigningKeyID сохраняется рядом с подписью для ротации ключей.
+	SigningKeyID string
+	// CheckpointEvery задает, через сколько записей аудита ставится подпись.
+	CheckpointEvery int
+	// Keyring включает шифрование колонок payload; без него payload пишется как есть.
+	Keyring *storage.Keyring
+}
+
 // Store реализует storage.Store поверх SQLite.
 type Store struct {
-	db *sql.DB
+	db   *sql.DB
+	opts Options
+
+	// auditMu сериализует вставки аудита, чтобы prev_hash не разветвлялся.
+	auditMu sync.Mutex
 }
 
 // Open инициализирует соединение и выполняет миграции.
 func Open(path string) (*Store, error) {
-	dsn := fmt.Sprintf("file:%s?_journal=WAL&_busy_timeout=5000", path)
-	db, err := sql.Open("sqlite3", dsn)
+	return OpenWithOptions(path, Options{})
+}
+
+// OpenWithOptions инициализирует соединение с дополнительными параметрами.
+func OpenWithOptions(path string, opts Options) (*Store, error) {
+	if opts.SigningKey != nil && len(opts.SigningKey) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid audit signing key size")
+	}
+	if opts.CheckpointEvery <= 0 {
+		opts.CheckpointEvery = 100
+	}
+	drv, err := resolveDriver(opts.Driver)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(drv.sqlName, drv.dsn(path))
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
 	}
@@ -29,38 +61,7 @@ func Open(path string) (*Store, error) {
 		_ = db.Close()
 		return nil, err
 	}
-	return &Store{db: db}, nil
-}
-
-func migrate(db *sql.DB) error {
-	schema := []string{
-		`CREATE TABLE IF NOT EXISTS metrics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			ts DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			module TEXT NOT NULL,
-			payload BLOB NOT NULL
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_metrics_ts ON metrics(ts);`,
-		`CREATE INDEX IF NOT EXISTS idx_metrics_module_ts ON metrics(module, ts);`,
-		`CREATE TABLE IF NOT EXISTS audit_events (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			ts DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			subject TEXT,
-			action TEXT,
-			source TEXT,
-			status TEXT,
-			request_id TEXT,
-			payload BLOB
-		);`,
-		`CREATE INDEX IF NOT EXISTS idx_audit_ts ON audit_events(ts);`,
-		`CREATE INDEX IF NOT EXISTS idx_audit_subject_ts ON audit_events(subject, ts);`,
-	}
-	for _, stmt := range schema {
-		if _, err := db.Exec(stmt); err != nil {
-			return fmt.Errorf("migration failed: %w", err)
-		}
-	}
-	return nil
+	return &Store{db: db, opts: opts}, nil
 }
 
 // SaveMetric сохраняет метрику.
@@ -69,35 +70,90 @@ func (s *Store) SaveMetric(ctx context.Context, rec storage.MetricRecord) error
 	if ts.IsZero() {
 		ts = time.Now().UTC()
 	}
-	_, err := s.db.ExecContext(ctx, `INSERT INTO metrics(module, payload, ts) VALUES(?,?,?)`, rec.Module, rec.Payload, ts)
+	payload, keyID, err := s.sealPayload(tableMetrics, rec.Payload)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT INTO metrics(module, payload, ts, payload_key_id) VALUES(?,?,?,?)`, rec.Module, payload, ts, keyID)
 	if err != nil {
 		return fmt.Errorf("insert metric: %w", err)
 	}
 	return nil
 }
 
-// SaveAudit сохраняет аудиторное событие.
+// SaveAudit сохраняет аудиторное событие и связывает его с предыдущим по hash.
 func (s *Store) SaveAudit(ctx context.Context, ev storage.AuditEvent) error {
-	ts := ev.TS
-	if ts.IsZero() {
-		ts = time.Now().UTC()
+	return s.SaveAuditBatch(ctx, []storage.AuditEvent{ev})
+}
+
+// SaveAuditBatch сохраняет события одной транзакцией, продолжая hash-цепочку.
+// После успешной записи в events проставлены ID, TS, PrevHash и Hash.
+func (s *Store) SaveAuditBatch(ctx context.Context, events []storage.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
 	}
-	_, err := s.db.ExecContext(ctx, `INSERT INTO audit_events(subject, action, source, status, request_id, payload, ts) VALUES(?,?,?,?,?,?,?)`,
-		ev.Subject, ev.Action, ev.Source, ev.Status, ev.RequestID, ev.Payload, ts)
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("insert audit: %w", err)
+		return fmt.Errorf("begin audit tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var prevHash string
+	err = tx.QueryRowContext(ctx, `SELECT hash FROM audit_events ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read audit chain tail: %w", err)
+	}
+
+	stored := make([]storage.AuditEvent, 0, len(events))
+	for _, ev := range events {
+		if ev.TS.IsZero() {
+			ev.TS = time.Now()
+		}
+		ev.TS = ev.TS.UTC()
+		ev.PrevHash = prevHash
+		// Hash считается по открытому payload, поэтому rekey не ломает цепочку.
+		ev.Hash = storage.AuditEventHash(prevHash, ev)
+		payload, keyID, err := s.sealPayload(tableAudit, ev.Payload)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `INSERT INTO audit_events(subject, action, source, status, request_id, payload, ts, prev_hash, hash, payload_key_id) VALUES(?,?,?,?,?,?,?,?,?,?)`,
+			ev.Subject, ev.Action, ev.Source, ev.Status, ev.RequestID, payload, ev.TS, ev.PrevHash, ev.Hash, keyID)
+		if err != nil {
+			return fmt.Errorf("insert audit: %w", err)
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("audit id: %w", err)
+		}
+		ev.ID = id
+		stored = append(stored, ev)
+		if s.opts.SigningKey != nil && id%int64(s.opts.CheckpointEvery) == 0 {
+			if err := s.insertCheckpoint(ctx, tx, id, ev.Hash); err != nil {
+				return err
+			}
+		}
+		prevHash = ev.Hash
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit audit: %w", err)
 	}
+	copy(events, stored)
 	return nil
 }
 
 // LatestMetric возвращает последнюю метрику по модулю.
 func (s *Store) LatestMetric(ctx context.Context, module string) (storage.MetricRecord, error) {
-	row := s.db.QueryRowContext(ctx, `SELECT module, payload, ts FROM metrics WHERE module = ? ORDER BY ts DESC LIMIT 1`, module)
+	row := s.db.QueryRowContext(ctx, `SELECT module, payload, ts, payload_key_id FROM metrics WHERE module = ? ORDER BY ts DESC LIMIT 1`, module)
 	var rec storage.MetricRecord
-	var ts string
-	if err := row.Scan(&rec.Module, &rec.Payload, &ts); err != nil {
+	var ts, keyID string
+	if err := row.Scan(&rec.Module, &rec.Payload, &ts, &keyID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return storage.MetricRecord{}, fmt.Errorf("latest metric not found: %w", err)
+			return storage.MetricRecord{}, fmt.Errorf("latest metric %q: %w", module, storage.ErrNotFound)
 		}
 		return storage.MetricRecord{}, fmt.Errorf("query latest metric: %w", err)
 	}
@@ -106,57 +162,77 @@ func (s *Store) LatestMetric(ctx context.Context, module string) (storage.Metric
 		return storage.MetricRecord{}, fmt.Errorf("parse metric timestamp: %w", err)
 	}
 	rec.TS = parsedTS
+	if rec.Payload, err = s.openPayload(tableMetrics, keyID, rec.Payload); err != nil {
+		return storage.MetricRecord{}, err
+	}
 	return rec, nil
 }
 
-// QueryAudit возвращает аудит по фильтрам.
-func (s *Store) QueryAudit(ctx context.Context, q storage.AuditQuery) ([]storage.AuditEvent, error) {
-	limit := q.Limit
-	if limit <= 0 {
-		limit = 50
+// PruneMetrics удаляет метрики модуля старше before.
+func (s *Store) PruneMetrics(ctx context.Context, module string, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM metrics WHERE module = ? AND ts < ?`, module, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("prune metrics: %w", err)
 	}
-	if limit > 200 {
-		limit = 200
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("prune metrics: %w", err)
 	}
+	return n, nil
+}
 
-	from := q.From
-	if from.IsZero() {
-		from = time.Unix(0, 0).UTC()
-	}
-	to := q.To
-	if to.IsZero() {
-		to = time.Now().UTC()
+// QueryAudit возвращает одну страницу аудита; для продолжения используйте QueryAuditPage.
+func (s *Store) QueryAudit(ctx context.Context, q storage.AuditQuery) ([]storage.AuditEvent, error) {
+	page, err := s.QueryAuditPage(ctx, q)
+	if err != nil {
+		return nil, err
 	}
+	return page.Events, nil
+}
+
+// auditColumns - колонки схемы v2; на них опирается backfill миграции.
+const auditColumns = `id, subject, action, source, status, request_id, payload, ts, prev_hash, hash`
+
+// auditReadColumns дополняет auditColumns ключом шифрования payload (схема v4).
+const auditReadColumns = auditColumns + `, payload_key_id`
 
-	rows, err := s.db.QueryContext(ctx, `
-SELECT subject, action, source, status, request_id, payload, ts
-FROM audit_events
-WHERE ts >= ? AND ts <= ? AND (? = '' OR subject = ?)
-ORDER BY ts DESC
-LIMIT ?`, from, to, q.Subject, q.Subject, limit)
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAudit читает строку auditReadColumns и расшифровывает payload.
+func (s *Store) scanAudit(row rowScanner) (storage.AuditEvent, error) {
+	var keyID string
+	ev, err := scanAuditEvent(row, &keyID)
 	if err != nil {
-		return nil, fmt.Errorf("query audit: %w", err)
+		return storage.AuditEvent{}, err
+	}
+	if ev.Payload, err = s.openPayload(tableAudit, keyID, ev.Payload); err != nil {
+		return storage.AuditEvent{}, fmt.Errorf("audit %d: %w", ev.ID, err)
 	}
-	defer rows.Close()
+	return ev, nil
+}
 
-	events := make([]storage.AuditEvent, 0, limit)
-	for rows.Next() {
-		var ev storage.AuditEvent
-		var ts string
-		if err := rows.Scan(&ev.Subject, &ev.Action, &ev.Source, &ev.Status, &ev.RequestID, &ev.Payload, &ts); err != nil {
-			return nil, fmt.Errorf("scan audit: %w", err)
-		}
-		parsedTS, err := parseSQLiteTS(ts)
-		if err != nil {
-			return nil, fmt.Errorf("parse audit timestamp: %w", err)
-		}
-		ev.TS = parsedTS
-		events = append(events, ev)
+// scanAuditEvent читает auditColumns; extra получает дополнительные колонки после них.
+func scanAuditEvent(row rowScanner, extra ...interface{}) (storage.AuditEvent, error) {
+	var ev storage.AuditEvent
+	var subject, action, source, status, requestID sql.NullString
+	var ts string
+	dest := append([]interface{}{&ev.ID, &subject, &action, &source, &status, &requestID, &ev.Payload, &ts, &ev.PrevHash, &ev.Hash}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return storage.AuditEvent{}, fmt.Errorf("scan audit: %w", err)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate audit: %w", err)
+	ev.Subject = subject.String
+	ev.Action = action.String
+	ev.Source = source.String
+	ev.Status = status.String
+	ev.RequestID = requestID.String
+	parsedTS, err := parseSQLiteTS(ts)
+	if err != nil {
+		return storage.AuditEvent{}, fmt.Errorf("parse audit timestamp: %w", err)
 	}
-	return events, nil
+	ev.TS = parsedTS
+	return ev, nil
 }
 
 func parseSQLiteTS(v string) (time.Time, error) {
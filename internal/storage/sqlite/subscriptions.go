@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Subscribe создает подписку или обновляет min_severity существующей.
+func (s *Store) Subscribe(ctx context.Context, sub storage.Subscription) error {
+	if sub.CreatedAt.IsZero() {
+		sub.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO subscriptions(transport, chat_id, topic, min_severity, created_by, created_at) VALUES(?,?,?,?,?,?)
+		ON CONFLICT(transport, chat_id, topic) DO UPDATE SET min_severity = excluded.min_severity`,
+		sub.Transport, sub.ChatID, sub.Topic, sub.MinSeverity, sub.CreatedBy, sub.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("save subscription: %w", err)
+	}
+	return nil
+}
+
+// Unsubscribe удаляет подписки чата на topic; пустой topic - на все темы.
+func (s *Store) Unsubscribe(ctx context.Context, transport, chatID, topic string) (int64, error) {
+	query := `DELETE FROM subscriptions WHERE transport = ? AND chat_id = ?`
+	args := []interface{}{transport, chatID}
+	if topic != "" {
+		query += ` AND topic = ?`
+		args = append(args, topic)
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete subscription: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete subscription: %w", err)
+	}
+	return n, nil
+}
+
+// Subscriptions возвращает подписки по фильтру.
+func (s *Store) Subscriptions(ctx context.Context, q storage.SubscriptionQuery) ([]storage.Subscription, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	for _, f := range []struct{ col, val string }{{"transport", q.Transport}, {"chat_id", q.ChatID}, {"topic", q.Topic}} {
+		if f.val != "" {
+			where = append(where, f.col+" = ?")
+			args = append(args, f.val)
+		}
+	}
+	query := `SELECT transport, chat_id, topic, min_severity, created_by, created_at FROM subscriptions`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY transport, chat_id, topic`
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query subscriptions: %w", err)
+	}
+	defer rows.Close()
+	var out []storage.Subscription
+	for rows.Next() {
+		var (
+			sub     storage.Subscription
+			created string
+		)
+		if err := rows.Scan(&sub.Transport, &sub.ChatID, &sub.Topic, &sub.MinSeverity, &sub.CreatedBy, &created); err != nil {
+			return nil, fmt.Errorf("scan subscription: %w", err)
+		}
+		if sub.CreatedAt, err = parseSQLiteTS(created); err != nil {
+			return nil, fmt.Errorf("parse subscription time: %w", err)
+		}
+		out = append(out, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subscriptions: %w", err)
+	}
+	return out, nil
+}
@@ -2,9 +2,13 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrNotFound возвращается, когда запрошенной записи нет.
+var ErrNotFound = errors.New("not found")
+
 // MetricRecord сохраняет метрики модуля.
 type MetricRecord struct {
 	Module  string
@@ -13,7 +17,9 @@ type MetricRecord struct {
 }
 
 // AuditEvent фиксирует действия пользователей/транспорта.
+// ID, PrevHash и Hash заполняет хранилище при записи и используются для проверки цепочки.
 type AuditEvent struct {
+	ID        int64
 	Subject   string
 	Action    string
 	Source    string
@@ -21,14 +27,22 @@ type AuditEvent struct {
 	RequestID string
 	Payload   []byte
 	TS        time.Time
+	PrevHash  string
+	Hash      string
 }
 
-// AuditQuery задает фильтры выборки аудита.
+// AuditQuery задает фильтры выборки аудита. Пустые поля не фильтруют.
+// Cursor продолжает выборку с места, где остановилась предыдущая страница.
 type AuditQuery struct {
-	From    time.Time
-	To      time.Time
-	Subject string
-	Limit   int
+	From      time.Time
+	To        time.Time
+	Subject   string
+	Action    string
+	Source    string
+	Status    string
+	RequestID string
+	Limit     int
+	Cursor    string
 }
 
 // Store описывает операции хранилища.
@@ -39,3 +53,8 @@ type Store interface {
 	QueryAudit(ctx context.Context, q AuditQuery) ([]AuditEvent, error)
 	Close() error
 }
+
+// MetricPruner удаляет метрики модуля старше before; возвращает число удаленных записей.
+type MetricPruner interface {
+	PruneMetrics(ctx context.Context, module string, before time.Time) (int64, error)
+}
@@ -0,0 +1,577 @@
+// Package storagetest содержит общий набор проверок для реализаций storage.Store.
+// Необязательные возможности (AuditPager, AuditBatchWriter, AuditAggregator,
+// AuditChainStore, JobStore, AlertStore, SubscriptionStore, MaintenanceStore) проверяются, только если хранилище их реализует.
+package storagetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Factory открывает пустое хранилище; закрытие остается за Run.
+type Factory func(t *testing.T) storage.Store
+
+var base = time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// Run запускает набор проверок для хранилища из open.
+func Run(t *testing.T, open Factory) {
+	t.Helper()
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, st storage.Store)
+	}{
+		{"LatestMetric", testLatestMetric},
+		{"PruneMetrics", testPruneMetrics},
+		{"AuditQueryOrderAndFilters", testAuditQuery},
+		{"AuditChain", testAuditChain},
+		{"AuditPager", testAuditPager},
+		{"AuditBatch", testAuditBatch},
+		{"AuditAggregate", testAuditAggregate},
+		{"ConcurrentAudit", testConcurrentAudit},
+		{"JobRuns", testJobRuns},
+		{"JobState", testJobState},
+		{"AlertState", testAlertState},
+		{"Subscriptions", testSubscriptions},
+		{"Maintenance", testMaintenance},
+	}
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			st := open(t)
+			t.Cleanup(func() { _ = st.Close() })
+			tc.fn(t, st)
+		})
+	}
+}
+
+func testLatestMetric(t *testing.T, st storage.Store) {
+	ctx := context.Background()
+	for _, rec := range []storage.MetricRecord{
+		{Module: "host", Payload: []byte(`{"n":1}`), TS: base},
+		{Module: "host", Payload: []byte(`{"n":3}`), TS: base.Add(2 * time.Second)},
+		{Module: "host", Payload: []byte(`{"n":2}`), TS: base.Add(time.Second)},
+		{Module: "disk", Payload: []byte(`{"n":9}`), TS: base.Add(time.Hour)},
+	} {
+		if err := st.SaveMetric(ctx, rec); err != nil {
+			t.Fatalf("save metric: %v", err)
+		}
+	}
+	rec, err := st.LatestMetric(ctx, "host")
+	if err != nil {
+		t.Fatalf("latest metric: %v", err)
+	}
+	if rec.Module != "host" || string(rec.Payload) != `{"n":3}` || !rec.TS.Equal(base.Add(2*time.Second)) {
+		t.Fatalf("unexpected latest metric: %s %s %s", rec.Module, rec.Payload, rec.TS)
+	}
+	if _, err := st.LatestMetric(ctx, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected storage.ErrNotFound, got %v", err)
+	}
+}
+
+func testPruneMetrics(t *testing.T, st storage.Store) {
+	pruner, ok := st.(storage.MetricPruner)
+	if !ok {
+		t.Skip("store does not implement storage.MetricPruner")
+	}
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		for _, module := range []string{"host", "disk"} {
+			rec := storage.MetricRecord{Module: module, Payload: []byte(`{}`), TS: base.Add(time.Duration(i) * time.Hour)}
+			if err := st.SaveMetric(ctx, rec); err != nil {
+				t.Fatalf("save metric: %v", err)
+			}
+		}
+	}
+	n, err := pruner.PruneMetrics(ctx, "host", base.Add(2*time.Hour))
+	if err != nil || n != 2 {
+		t.Fatalf("prune: %v, removed %d, want 2", err, n)
+	}
+	if n, err := pruner.PruneMetrics(ctx, "host", base.Add(2*time.Hour)); err != nil || n != 0 {
+		t.Fatalf("second prune: %v, removed %d", err, n)
+	}
+	// Чужой модуль не затронут, последняя метрика на месте.
+	for _, module := range []string{"host", "disk"} {
+		rec, err := st.LatestMetric(ctx, module)
+		if err != nil || !rec.TS.Equal(base.Add(3*time.Hour)) {
+			t.Fatalf("%s latest after prune: %v %s", module, err, rec.TS)
+		}
+	}
+	if n, err := pruner.PruneMetrics(ctx, "disk", base.Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("disk prune: %v, removed %d, want 1", err, n)
+	}
+}
+
+func testJobRuns(t *testing.T, st storage.Store) {
+	jobs, ok := st.(storage.JobStore)
+	if !ok {
+		t.Skip("store does not implement storage.JobStore")
+	}
+	ctx := context.Background()
+	extra := 5
+	for i := 0; i < storage.MaxJobRunsPerJob+extra; i++ {
+		run := storage.JobRun{
+			Job:        "collect",
+			Trigger:    "schedule",
+			StartedAt:  base.Add(time.Duration(i) * time.Minute),
+			FinishedAt: base.Add(time.Duration(i)*time.Minute + time.Second),
+			Status:     "ok",
+		}
+		if i%2 == 0 {
+			run.Status, run.Error = "error", fmt.Sprintf("fail %d", i)
+		}
+		if err := jobs.SaveJobRun(ctx, run); err != nil {
+			t.Fatalf("save job run: %v", err)
+		}
+	}
+	if err := jobs.SaveJobRun(ctx, storage.JobRun{Job: "other", Trigger: "manual", StartedAt: base, FinishedAt: base, Status: "ok"}); err != nil {
+		t.Fatalf("save job run: %v", err)
+	}
+
+	all, err := jobs.JobRuns(ctx, storage.JobRunQuery{Job: "collect"})
+	if err != nil {
+		t.Fatalf("job runs: %v", err)
+	}
+	if len(all) != storage.MaxJobRunsPerJob {
+		t.Fatalf("history not trimmed: %d runs", len(all))
+	}
+	last := storage.MaxJobRunsPerJob + extra - 1
+	if !all[0].StartedAt.Equal(base.Add(time.Duration(last)*time.Minute)) || !all[len(all)-1].StartedAt.Equal(base.Add(time.Duration(extra)*time.Minute)) {
+		t.Fatalf("unexpected order or trimming: first %s last %s", all[0].StartedAt, all[len(all)-1].StartedAt)
+	}
+	recent, err := jobs.JobRuns(ctx, storage.JobRunQuery{Job: "collect", Limit: 3})
+	if err != nil || len(recent) != 3 || recent[0].ID <= recent[1].ID {
+		t.Fatalf("limited runs: %v %+v", err, recent)
+	}
+	if r := recent[0]; r.Status != "error" || r.Error != fmt.Sprintf("fail %d", last) || !r.FinishedAt.Equal(r.StartedAt.Add(time.Second)) {
+		t.Fatalf("unexpected run: %+v", r)
+	}
+	other, err := jobs.JobRuns(ctx, storage.JobRunQuery{Limit: 1})
+	if err != nil || len(other) != 1 || other[0].Job != "other" || other[0].Trigger != "manual" {
+		t.Fatalf("unfiltered runs: %v %+v", err, other)
+	}
+}
+
+func testJobState(t *testing.T, st storage.Store) {
+	jobs, ok := st.(storage.JobStore)
+	if !ok {
+		t.Skip("store does not implement storage.JobStore")
+	}
+	ctx := context.Background()
+	if err := jobs.SetJobPaused(ctx, "b", true, "alice"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+	if err := jobs.RequestJobRun(ctx, "a", "bob"); err != nil {
+		t.Fatalf("request run: %v", err)
+	}
+	if err := jobs.RequestJobRun(ctx, "b", "bob"); err != nil {
+		t.Fatalf("request run: %v", err)
+	}
+	states, err := jobs.JobStates(ctx)
+	if err != nil || len(states) != 2 {
+		t.Fatalf("job states: %v %+v", err, states)
+	}
+	if a, b := states[0], states[1]; a.Job != "a" || a.Paused || !a.RunRequested || b.Job != "b" || !b.Paused || b.UpdatedBy != "bob" || b.UpdatedAt.IsZero() {
+		t.Fatalf("unexpected states: %+v", states)
+	}
+
+	claimed, err := jobs.ClaimJobRunRequests(ctx)
+	if err != nil || len(claimed) != 2 || claimed[0] != "a" || claimed[1] != "b" {
+		t.Fatalf("claim: %v %v", err, claimed)
+	}
+	if claimed, err := jobs.ClaimJobRunRequests(ctx); err != nil || len(claimed) != 0 {
+		t.Fatalf("second claim: %v %v", err, claimed)
+	}
+	if err := jobs.SetJobPaused(ctx, "b", false, "alice"); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	states, err = jobs.JobStates(ctx)
+	if err != nil || states[1].Paused || states[1].RunRequested {
+		t.Fatalf("state after resume: %v %+v", err, states)
+	}
+}
+
+func testAlertState(t *testing.T, st storage.Store) {
+	alerts, ok := st.(storage.AlertStore)
+	if !ok {
+		t.Skip("store does not implement storage.AlertStore")
+	}
+	ctx := context.Background()
+	active := base.Add(-5 * time.Minute)
+	firing := storage.AlertState{
+		Rule:        "mem",
+		State:       storage.AlertFiring,
+		Severity:    "critical",
+		Expr:        "mem_used_pct > 90",
+		Labels:      map[string]string{"team": "ops"},
+		Value:       93.5,
+		ActiveAt:    &active,
+		FiredAt:     &base,
+		EvaluatedAt: base,
+	}
+	pending := storage.AlertState{Rule: "load", State: storage.AlertPending, Severity: "warning", Expr: "load5 > 4", ActiveAt: &base, EvaluatedAt: base}
+	for _, s := range []storage.AlertState{firing, pending} {
+		if err := alerts.SaveAlertState(ctx, s); err != nil {
+			t.Fatalf("save alert state: %v", err)
+		}
+	}
+	resolved := base.Add(time.Minute)
+	pending.State, pending.ActiveAt, pending.ResolvedAt, pending.LastError = storage.AlertResolved, nil, &resolved, "stale"
+	if err := alerts.SaveAlertState(ctx, pending); err != nil {
+		t.Fatalf("update alert state: %v", err)
+	}
+
+	got, err := alerts.AlertStates(ctx)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("alert states: %v %+v", err, got)
+	}
+	load, mem := got[0], got[1]
+	if load.Rule != "load" || load.State != storage.AlertResolved || load.ActiveAt != nil || load.ResolvedAt == nil || !load.ResolvedAt.Equal(resolved) || load.LastError != "stale" {
+		t.Fatalf("unexpected updated state: %+v", load)
+	}
+	if mem.Value != 93.5 || mem.Labels["team"] != "ops" || mem.ActiveAt == nil || !mem.ActiveAt.Equal(active) || mem.FiredAt == nil || !mem.FiredAt.Equal(base) || !mem.EvaluatedAt.Equal(base) {
+		t.Fatalf("unexpected firing state: %+v", mem)
+	}
+}
+
+func testSubscriptions(t *testing.T, st storage.Store) {
+	subs, ok := st.(storage.SubscriptionStore)
+	if !ok {
+		t.Skip("store does not implement storage.SubscriptionStore")
+	}
+	ctx := context.Background()
+	for _, sub := range []storage.Subscription{
+		{Transport: "telegram", ChatID: "100", Topic: "alerts", MinSeverity: "warning", CreatedBy: "1", CreatedAt: base},
+		{Transport: "telegram", ChatID: "100", Topic: "jobs", CreatedAt: base},
+		{Transport: "telegram", ChatID: "200", Topic: "alerts", CreatedAt: base},
+		{Transport: "maxbot", ChatID: "100", Topic: "alerts", CreatedAt: base},
+		// Повторная подписка меняет только порог.
+		{Transport: "telegram", ChatID: "100", Topic: "alerts", MinSeverity: "critical", CreatedBy: "2", CreatedAt: base.Add(time.Hour)},
+	} {
+		if err := subs.Subscribe(ctx, sub); err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+	}
+	got, err := subs.Subscriptions(ctx, storage.SubscriptionQuery{Transport: "telegram", Topic: "alerts"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("subscriptions: %v %+v", err, got)
+	}
+	if got[0].ChatID != "100" || got[0].MinSeverity != "critical" || got[0].CreatedBy != "1" || !got[0].CreatedAt.Equal(base) || got[1].ChatID != "200" {
+		t.Fatalf("unexpected subscriptions: %+v", got)
+	}
+	if n, err := subs.Unsubscribe(ctx, "telegram", "100", "jobs"); err != nil || n != 1 {
+		t.Fatalf("unsubscribe topic: %v %d", err, n)
+	}
+	if n, err := subs.Unsubscribe(ctx, "telegram", "200", ""); err != nil || n != 1 {
+		t.Fatalf("unsubscribe all: %v %d", err, n)
+	}
+	all, err := subs.Subscriptions(ctx, storage.SubscriptionQuery{})
+	if err != nil || len(all) != 2 || all[0].Transport != "maxbot" || all[1].Topic != "alerts" {
+		t.Fatalf("remaining subscriptions: %v %+v", err, all)
+	}
+	chat, err := subs.Subscriptions(ctx, storage.SubscriptionQuery{ChatID: "100"})
+	if err != nil || len(chat) != 2 {
+		t.Fatalf("chat filter: %v %+v", err, chat)
+	}
+}
+
+func testMaintenance(t *testing.T, st storage.Store) {
+	ms, ok := st.(storage.MaintenanceStore)
+	if !ok {
+		t.Skip("store does not implement storage.MaintenanceStore")
+	}
+	ctx := context.Background()
+	matchers := []storage.LabelMatcher{{Name: "alertname", Op: storage.MatchEqual, Value: "high_memory"}, {Name: "team", Op: storage.MatchRegexp, Value: "ops|dba"}}
+	for i, id := range []string{"s1", "s2"} {
+		if err := ms.SaveSilence(ctx, storage.Silence{
+			ID: id, Matchers: matchers, StartsAt: base, EndsAt: base.Add(time.Hour),
+			Comment: "upgrade", CreatedBy: "alice", CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}); err != nil {
+			t.Fatalf("save silence: %v", err)
+		}
+	}
+	// Истечение: обновляется конец и отметка аудита, автор сохраняется.
+	if err := ms.SaveSilence(ctx, storage.Silence{ID: "s1", Matchers: matchers, StartsAt: base, EndsAt: base.Add(time.Minute), ExpiryAudited: true, CreatedAt: base}); err != nil {
+		t.Fatalf("update silence: %v", err)
+	}
+	sils, err := ms.Silences(ctx)
+	if err != nil || len(sils) != 2 {
+		t.Fatalf("silences: %v %+v", err, sils)
+	}
+	if sils[0].ID != "s2" || sils[1].ID != "s1" {
+		t.Fatalf("expected newest first: %+v", sils)
+	}
+	s1 := sils[1]
+	if !s1.EndsAt.Equal(base.Add(time.Minute)) || !s1.ExpiryAudited || s1.CreatedBy != "alice" || len(s1.Matchers) != 2 || s1.Matchers[1] != matchers[1] {
+		t.Fatalf("unexpected silence: %+v", s1)
+	}
+
+	ends := base.Add(2 * time.Hour)
+	windows := []storage.MaintenanceWindow{
+		{Name: "nightly", Schedule: "0 2 * * *", Duration: 90 * time.Minute, Matchers: []storage.LabelMatcher{}, CreatedBy: "bob", CreatedAt: base},
+		{Name: "upgrade", StartsAt: &base, EndsAt: &ends, Matchers: matchers, Comment: "db", CreatedAt: base},
+	}
+	for _, w := range windows {
+		if err := ms.SaveMaintenanceWindow(ctx, w); err != nil {
+			t.Fatalf("save window: %v", err)
+		}
+	}
+	got, err := ms.MaintenanceWindows(ctx)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("windows: %v %+v", err, got)
+	}
+	if got[0].Name != "nightly" || got[0].Duration != 90*time.Minute || got[0].StartsAt != nil || got[0].EndsAt != nil || got[0].Schedule != "0 2 * * *" {
+		t.Fatalf("unexpected recurring window: %+v", got[0])
+	}
+	if got[1].StartsAt == nil || !got[1].StartsAt.Equal(base) || got[1].EndsAt == nil || !got[1].EndsAt.Equal(ends) || len(got[1].Matchers) != 2 {
+		t.Fatalf("unexpected one-off window: %+v", got[1])
+	}
+	if err := ms.DeleteMaintenanceWindow(ctx, "upgrade"); err != nil {
+		t.Fatalf("delete window: %v", err)
+	}
+	if err := ms.DeleteMaintenanceWindow(ctx, "upgrade"); !errors.Is(err, storage.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if got, _ := ms.MaintenanceWindows(ctx); len(got) != 1 {
+		t.Fatalf("window not deleted: %+v", got)
+	}
+}
+
+func seed(t *testing.T, st storage.Store, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		ev := storage.AuditEvent{
+			Subject:   fmt.Sprintf("u%d", i%2),
+			Action:    "host:status",
+			Source:    "telegram",
+			Status:    "ok",
+			RequestID: fmt.Sprintf("r%d", i),
+			Payload:   []byte(fmt.Sprintf(`{"i":%d}`, i)),
+			// Пары событий с одинаковым ts проверяют порядок по id.
+			TS: base.Add(time.Duration(i/2) * time.Second),
+		}
+		if i%3 == 0 {
+			ev.Status = "denied"
+		}
+		if err := st.SaveAudit(context.Background(), ev); err != nil {
+			t.Fatalf("save audit: %v", err)
+		}
+	}
+}
+
+func testAuditQuery(t *testing.T, st storage.Store) {
+	ctx := context.Background()
+	seed(t, st, 10)
+
+	events, err := st.QueryAudit(ctx, storage.AuditQuery{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(events) != 10 {
+		t.Fatalf("got %d events, want 10", len(events))
+	}
+	for i := 1; i < len(events); i++ {
+		prev, ev := events[i-1], events[i]
+		if ev.TS.After(prev.TS) || (ev.TS.Equal(prev.TS) && ev.ID >= prev.ID) {
+			t.Fatalf("order broken at %d: %d after %d", i, ev.ID, prev.ID)
+		}
+	}
+	if got := events[len(events)-1]; string(got.Payload) != `{"i":0}` || got.RequestID != "r0" || !got.TS.Equal(base) {
+		t.Fatalf("oldest event mismatch: %#v", got)
+	}
+
+	for _, tc := range []struct {
+		q    storage.AuditQuery
+		want int
+	}{
+		{storage.AuditQuery{Subject: "u1"}, 5},
+		{storage.AuditQuery{Status: "denied"}, 4},
+		{storage.AuditQuery{RequestID: "r7"}, 1},
+		{storage.AuditQuery{Action: "nope"}, 0},
+		{storage.AuditQuery{From: base.Add(time.Second), To: base.Add(3 * time.Second)}, 6},
+		{storage.AuditQuery{Limit: 3}, 3},
+	} {
+		got, err := st.QueryAudit(ctx, tc.q)
+		if err != nil {
+			t.Fatalf("query %+v: %v", tc.q, err)
+		}
+		if len(got) != tc.want {
+			t.Fatalf("query %+v: got %d events, want %d", tc.q, len(got), tc.want)
+		}
+	}
+}
+
+func testAuditChain(t *testing.T, st storage.Store) {
+	seed(t, st, 5)
+	events, err := st.QueryAudit(context.Background(), storage.AuditQuery{})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	byID := make(map[int64]storage.AuditEvent, len(events))
+	for _, ev := range events {
+		if ev.ID <= 0 || ev.Hash == "" {
+			t.Fatalf("event without id/hash: %#v", ev)
+		}
+		byID[ev.ID] = ev
+	}
+	if len(byID) != 5 {
+		t.Fatalf("ids are not unique: %d", len(byID))
+	}
+	for _, ev := range byID {
+		if want := storage.AuditEventHash(ev.PrevHash, ev); ev.Hash != want {
+			t.Fatalf("event %d hash mismatch", ev.ID)
+		}
+	}
+
+	chain, ok := st.(storage.AuditChainStore)
+	if !ok {
+		return
+	}
+	report, err := chain.VerifyAuditChain(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !report.OK || report.Checked != 5 {
+		t.Fatalf("unexpected chain report: %#v", report)
+	}
+}
+
+func testAuditPager(t *testing.T, st storage.Store) {
+	pager, ok := st.(storage.AuditPager)
+	if !ok {
+		t.Skip("store does not implement storage.AuditPager")
+	}
+	ctx := context.Background()
+	seed(t, st, 9)
+
+	seen := make(map[int64]bool)
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination does not terminate")
+		}
+		page, err := pager.QueryAuditPage(ctx, storage.AuditQuery{Limit: 2, Cursor: cursor, Subject: "u0"})
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		for _, ev := range page.Events {
+			if seen[ev.ID] {
+				t.Fatalf("event %d returned twice", ev.ID)
+			}
+			seen[ev.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 5 {
+		t.Fatalf("walked %d events, want 5", len(seen))
+	}
+	if _, err := pager.QueryAuditPage(ctx, storage.AuditQuery{Cursor: "garbage"}); !errors.Is(err, storage.ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func testAuditBatch(t *testing.T, st storage.Store) {
+	batch, ok := st.(storage.AuditBatchWriter)
+	if !ok {
+		t.Skip("store does not implement storage.AuditBatchWriter")
+	}
+	ctx := context.Background()
+	events := []storage.AuditEvent{
+		{Subject: "u1", Action: "a", TS: base},
+		{Subject: "u1", Action: "b", TS: base.Add(time.Second), Payload: []byte(`{}`)},
+		{Subject: "u1", Action: "c", TS: base.Add(2 * time.Second)},
+	}
+	if err := batch.SaveAuditBatch(ctx, events); err != nil {
+		t.Fatalf("save batch: %v", err)
+	}
+	got, err := st.QueryAudit(ctx, storage.AuditQuery{Subject: "u1"})
+	if err != nil || len(got) != 3 {
+		t.Fatalf("query batch: %v, %d events", err, len(got))
+	}
+	if got[0].Action != "c" || got[1].PrevHash != got[2].Hash || got[0].PrevHash != got[1].Hash {
+		t.Fatalf("batch not chained in order: %#v", got)
+	}
+	if !bytes.Equal(got[1].Payload, []byte(`{}`)) {
+		t.Fatalf("payload = %q", got[1].Payload)
+	}
+	for i, ev := range events {
+		want := got[len(got)-1-i]
+		if ev.ID != want.ID || !ev.TS.Equal(want.TS) || ev.PrevHash != want.PrevHash || ev.Hash != want.Hash {
+			t.Fatalf("batch event %d not filled from store: %#v, stored %#v", i, ev, want)
+		}
+	}
+}
+
+func testAuditAggregate(t *testing.T, st storage.Store) {
+	agg, ok := st.(storage.AuditAggregator)
+	if !ok {
+		t.Skip("store does not implement storage.AuditAggregator")
+	}
+	seed(t, st, 10)
+	rows, err := agg.AggregateAudit(context.Background(), storage.AuditStatsQuery{
+		Bucket:  2 * time.Second,
+		GroupBy: []string{"status"},
+	})
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	// ts: 0,0,1,1,2,2,3,3,4,4 с; окна по 2 с содержат по 4 события (последнее - 2).
+	var total int64
+	buckets := make(map[time.Time]int64)
+	for _, row := range rows {
+		total += row.Count
+		buckets[row.Bucket] += row.Count
+		if row.Status == "" || row.Subject != "" {
+			t.Fatalf("unexpected grouping: %#v", row)
+		}
+	}
+	if total != 10 || buckets[base] != 4 || buckets[base.Add(4*time.Second)] != 2 {
+		t.Fatalf("unexpected buckets: total=%d %v", total, buckets)
+	}
+	if _, err := agg.AggregateAudit(context.Background(), storage.AuditStatsQuery{GroupBy: []string{"payload"}}); !errors.Is(err, storage.ErrInvalidGroupBy) {
+		t.Fatalf("expected ErrInvalidGroupBy, got %v", err)
+	}
+}
+
+func testConcurrentAudit(t *testing.T, st storage.Store) {
+	const writers, perWriter = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				ev := storage.AuditEvent{Subject: fmt.Sprintf("w%d", w), Action: "x", Status: "ok"}
+				if err := st.SaveAudit(context.Background(), ev); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent save: %v", err)
+	}
+	events, err := st.QueryAudit(context.Background(), storage.AuditQuery{Limit: storage.MaxAuditPageSize})
+	if err != nil || len(events) != writers*perWriter {
+		t.Fatalf("query: %v, %d events", err, len(events))
+	}
+	if chain, ok := st.(storage.AuditChainStore); ok {
+		report, err := chain.VerifyAuditChain(context.Background(), nil)
+		if err != nil || !report.OK {
+			t.Fatalf("chain after concurrent writes: %v %#v", err, report)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// Subscription - подписка чата транспорта на тему уведомлений.
+// MinSeverity отсекает менее важные уведомления; пустая - все.
+type Subscription struct {
+	Transport   string    `json:"transport"`
+	ChatID      string    `json:"chat_id"`
+	Topic       string    `json:"topic"`
+	MinSeverity string    `json:"min_severity,omitempty"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// SubscriptionQuery выбирает подписки; пустые поля не фильтруют.
+type SubscriptionQuery struct {
+	Transport string
+	ChatID    string
+	Topic     string
+}
+
+// SubscriptionStore хранит подписки чатов на уведомления.
+type SubscriptionStore interface {
+	// Subscribe создает подписку или обновляет MinSeverity существующей.
+	Subscribe(ctx context.Context, sub Subscription) error
+	// Unsubscribe удаляет подписки чата на topic (пустой topic - на все темы).
+	Unsubscribe(ctx context.Context, transport, chatID, topic string) (int64, error)
+	Subscriptions(ctx context.Context, q SubscriptionQuery) ([]Subscription, error)
+}
@@ -0,0 +1,301 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/audit"
+	"goadmin/internal/config"
+	"goadmin/internal/storage"
+	"goadmin/internal/transports/web"
+)
+
+func newAuditCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Операции с журналом аудита",
+	}
+	cmd.AddCommand(newAuditVerifyCmd(cfgPath))
+	cmd.AddCommand(newAuditKeygenCmd())
+	cmd.AddCommand(newAuditExportCmd(cfgPath))
+	return cmd
+}
+
+func newAuditVerifyCmd(cfgPath *string) *cobra.Command {
+	var file, pubKeyPath string
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Проверить hash-цепочку аудита",
+		Long: "Проходит цепочку audit_events и сообщает о первом разрыве.\n" +
+			"С --file проверяет экспорт /v1/audit офлайн, без доступа к базе.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var pub ed25519.PublicKey
+			if pubKeyPath != "" {
+				key, err := storage.LoadAuditPublicKey(pubKeyPath)
+				if err != nil {
+					return err
+				}
+				pub = key
+			}
+
+			var report storage.AuditChainReport
+			if file != "" {
+				r, err := verifyAuditExport(file, pub)
+				if err != nil {
+					return err
+				}
+				report = r
+			} else {
+				cfg, err := config.Load(*cfgPath)
+				if err != nil {
+					return fmt.Errorf("load config: %w", err)
+				}
+				st, err := app.OpenStore(cfg)
+				if err != nil {
+					return err
+				}
+				defer st.Close()
+				r, err := st.VerifyAuditChain(cmd.Context(), pub)
+				if err != nil {
+					return err
+				}
+				report = r
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+			if !report.OK {
+				return fmt.Errorf("audit chain broken at id %d: %s", report.BrokenAt, report.Reason)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&file, "file", "", "ответ /v1/audit (JSON) или NDJSON-экспорт для офлайн-проверки")
+	cmd.Flags().StringVar(&pubKeyPath, "pubkey", "", "публичный ed25519 ключ (base64) для проверки подписей")
+	return cmd
+}
+
+func verifyAuditExport(path string, pub ed25519.PublicKey) (storage.AuditChainReport, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- файл экспорта указывает оператор.
+	if err != nil {
+		return storage.AuditChainReport{}, fmt.Errorf("read export: %w", err)
+	}
+	events, exportCheckpoints, err := decodeAuditExport(data)
+	if err != nil {
+		return storage.AuditChainReport{}, err
+	}
+	if len(events) == 0 {
+		return storage.AuditChainReport{}, errors.New("export contains no audit events")
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	first, last := events[0].ID, events[len(events)-1].ID
+	checkpoints := make([]storage.AuditCheckpoint, 0, len(exportCheckpoints))
+	for _, item := range exportCheckpoints {
+		if item.EventID < first || item.EventID > last {
+			continue
+		}
+		checkpoints = append(checkpoints, item.Checkpoint())
+	}
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].EventID < checkpoints[j].EventID })
+
+	// Экспорт - фрагмент цепочки: якорем служит prev_hash первой записи.
+	v := storage.NewChainVerifier(events[0].PrevHash, checkpoints, pub)
+	for _, ev := range events {
+		if !v.Add(ev) {
+			break
+		}
+	}
+	return v.Finish(), nil
+}
+
+// decodeAuditExport читает ответ /v1/audit ({"items": ...}) или NDJSON из
+// goadmin audit export / /v1/audit/export (итоговая строка пропускается;
+// контрольных точек в NDJSON нет).
+func decodeAuditExport(data []byte) ([]storage.AuditEvent, []web.AuditCheckpointDTO, error) {
+	var export struct {
+		Items       []web.AuditEventDTO      `json:"items"`
+		Checkpoints []web.AuditCheckpointDTO `json:"checkpoints"`
+	}
+	if err := json.Unmarshal(data, &export); err == nil && export.Items != nil {
+		events := make([]storage.AuditEvent, 0, len(export.Items))
+		for _, item := range export.Items {
+			ev, err := item.Event()
+			if err != nil {
+				return nil, nil, fmt.Errorf("event %d: %w", item.ID, err)
+			}
+			events = append(events, ev)
+		}
+		return events, export.Checkpoints, nil
+	}
+	var events []storage.AuditEvent
+	for n, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || bytes.HasPrefix(line, []byte(`{"summary"`)) {
+			continue
+		}
+		var rec audit.Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, nil, fmt.Errorf("decode export line %d: %w", n+1, err)
+		}
+		ev, err := rec.Event()
+		if err != nil {
+			return nil, nil, fmt.Errorf("event %d: %w", rec.ID, err)
+		}
+		events = append(events, ev)
+	}
+	return events, nil, nil
+}
+
+func newAuditKeygenCmd() *cobra.Command {
+	var out string
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Создать ed25519 ключ для подписи цепочки аудита",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if out == "" {
+				return errors.New("--out is required")
+			}
+			priv, pub, err := storage.GenerateAuditKey()
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(out, []byte(priv+"\n"), 0o600); err != nil {
+				return fmt.Errorf("write private key: %w", err)
+			}
+			if err := os.WriteFile(out+".pub", []byte(pub+"\n"), 0o600); err != nil {
+				return fmt.Errorf("write public key: %w", err)
+			}
+			pubKey, err := storage.LoadAuditPublicKey(out + ".pub")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "key_id: %s\nprivate: %s\npublic: %s.pub\n", storage.AuditKeyID(pubKey), out, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&out, "out", "", "путь к файлу приватного ключа")
+	return cmd
+}
+
+func newAuditExportCmd(cfgPath *string) *cobra.Command {
+	var (
+		format, out, from, to string
+		gzipOut               bool
+		q                     storage.AuditQuery
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Выгрузить аудит в NDJSON или CSV",
+		Long: "Потоково выгружает все подходящие события (ts DESC) и дописывает итоговую запись\n" +
+			"с числом событий, диапазоном времени и sha256 строк событий. Сам экспорт аудируется.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !audit.ValidExportFormat(format) {
+				return fmt.Errorf("unknown format %q: expected ndjson or csv", format)
+			}
+			for _, tsFlag := range []struct {
+				value string
+				dst   *time.Time
+			}{{from, &q.From}, {to, &q.To}} {
+				if tsFlag.value == "" {
+					continue
+				}
+				ts, err := time.Parse(time.RFC3339, tsFlag.value)
+				if err != nil {
+					return fmt.Errorf("parse time %q: %w", tsFlag.value, err)
+				}
+				*tsFlag.dst = ts
+			}
+
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			st, err := app.OpenStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			var w io.Writer = cmd.OutOrStdout()
+			if out != "" && out != "-" {
+				f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // #nosec G304 -- путь указывает оператор.
+				if err != nil {
+					return fmt.Errorf("create export file: %w", err)
+				}
+				defer f.Close()
+				w = f
+			}
+			var gz *gzip.Writer
+			if gzipOut {
+				gz = gzip.NewWriter(w)
+				w = gz
+			}
+
+			summary, err := audit.Export(cmd.Context(), st, w, audit.ExportOptions{Format: format, Query: q})
+			if gz != nil {
+				if cerr := gz.Close(); cerr != nil && err == nil {
+					err = cerr
+				}
+			}
+
+			status := "ok"
+			if err != nil {
+				status = "error"
+			}
+			payload, _ := json.Marshal(map[string]interface{}{"format": format, "out": out, "summary": summary})
+			if aerr := st.SaveAudit(context.WithoutCancel(cmd.Context()), storage.AuditEvent{
+				Subject: localSubject(),
+				Action:  "audit:export",
+				Source:  "cli",
+				Status:  status,
+				Payload: payload,
+			}); aerr != nil && err == nil {
+				err = fmt.Errorf("audit export event: %w", aerr)
+			}
+
+			enc := json.NewEncoder(cmd.ErrOrStderr())
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(summary)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", audit.ExportNDJSON, "ndjson или csv")
+	cmd.Flags().StringVar(&out, "out", "", "файл выгрузки (по умолчанию stdout)")
+	cmd.Flags().BoolVar(&gzipOut, "gzip", false, "сжать выгрузку gzip")
+	cmd.Flags().StringVar(&from, "from", "", "начало диапазона (RFC3339)")
+	cmd.Flags().StringVar(&to, "to", "", "конец диапазона (RFC3339)")
+	cmd.Flags().StringVar(&q.Subject, "subject", "", "фильтр по subject")
+	cmd.Flags().StringVar(&q.Action, "action", "", "фильтр по action (module:command)")
+	cmd.Flags().StringVar(&q.Source, "source", "", "фильтр по source")
+	cmd.Flags().StringVar(&q.Status, "status", "", "фильтр по status")
+	cmd.Flags().StringVar(&q.RequestID, "request-id", "", "фильтр по request_id")
+	return cmd
+}
+
+// localSubject возвращает имя пользователя ОС для аудита локальных CLI-операций.
+func localSubject() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return fmt.Sprintf("uid:%d", os.Getuid())
+}
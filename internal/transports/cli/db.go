@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/sqlite"
+)
+
+func newDBCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Обслуживание базы SQLite",
+	}
+	cmd.AddCommand(newDBRekeyCmd(cfgPath))
+	cmd.AddCommand(newDBKeygenCmd())
+	return cmd
+}
+
+func newDBRekeyCmd(cfgPath *string) *cobra.Command {
+	var (
+		batch int
+		pause time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "rekey",
+		Short: "Перешифровать payload активным ключом",
+		Long: "Перешифровывает строки metrics и audit_events, записанные другим ключом или открытым текстом,\n" +
+			"небольшими транзакциями. Можно запускать при работающем агенте; прерванный запуск\n" +
+			"продолжается повторным вызовом. Прогресс пишется в stderr, итог - в stdout.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			if !cfg.SQLite.Encryption.Enabled {
+				return errors.New("sqlite.encryption.enabled is false")
+			}
+			st, err := app.OpenStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			progress := json.NewEncoder(cmd.ErrOrStderr())
+			result, err := st.Rekey(cmd.Context(), sqlite.RekeyOptions{
+				BatchSize: batch,
+				Pause:     pause,
+				Progress:  func(p sqlite.RekeyProgress) { _ = progress.Encode(p) },
+			})
+
+			status := "ok"
+			if err != nil {
+				status = "error"
+			}
+			payload, _ := json.Marshal(map[string]interface{}{"tables": result})
+			if aerr := st.SaveAudit(context.WithoutCancel(cmd.Context()), storage.AuditEvent{
+				Subject: localSubject(),
+				Action:  "db:rekey",
+				Source:  "cli",
+				Status:  status,
+				Payload: payload,
+			}); aerr != nil && err == nil {
+				err = fmt.Errorf("audit rekey event: %w", aerr)
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(result)
+			return err
+		},
+	}
+	cmd.Flags().IntVar(&batch, "batch", 500, "строк в одной транзакции")
+	cmd.Flags().DurationVar(&pause, "pause", 50*time.Millisecond, "пауза между пачками")
+	return cmd
+}
+
+func newDBKeygenCmd() *cobra.Command {
+	var id, out string
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Создать ключ шифрования payload",
+		Long: "Печатает строку key_id:base64 для sqlite.encryption.key_file или $GOADMIN_DB_KEYS.\n" +
+			"С --out дописывает ключ в файл (создается с правами 0600).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			line, err := storage.GeneratePayloadKey(id)
+			if err != nil {
+				return err
+			}
+			if out == "" {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+				return nil
+			}
+			f, err := os.OpenFile(out, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) // #nosec G304 -- путь указывает оператор.
+			if err != nil {
+				return fmt.Errorf("open key file: %w", err)
+			}
+			if _, err := fmt.Fprintln(f, line); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("write key file: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("write key file: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "key_id: %s\nfile: %s\n", id, out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&id, "id", "", "идентификатор ключа (например k2)")
+	cmd.Flags().StringVar(&out, "out", "", "файл ключей, в который дописать новый ключ")
+	_ = cmd.MarkFlagRequired("id")
+	return cmd
+}
@@ -0,0 +1,367 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/render"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+	"goadmin/internal/transports/common"
+)
+
+// Коды выхода команд модулей (goadmin exec и сгенерированных подкоманд).
+const (
+	ExitFailure     = 1 // ошибка модуля или CLI
+	ExitUsage       = 2 // неизвестный модуль/команда, неверные аргументы
+	ExitDenied      = 3 // access_denied
+	ExitNotFound    = 4 // not_found
+	ExitTimeout     = 5 // истек --timeout
+	ExitUnavailable = 6 // rate_limited, audit_unavailable: повторить позже
+)
+
+// ExitError - ошибка с кодом выхода процесса; обрабатывается в main.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string { return e.Err.Error() }
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+// exitCode сопоставляет core.Response.ErrorCode коду выхода.
+func exitCode(errorCode string) int {
+	switch errorCode {
+	case "bad_command", "bad_args", "unknown_command", "module_not_found", "unsupported", "bad_topic", "bad_severity":
+		return ExitUsage
+	case "access_denied":
+		return ExitDenied
+	case "not_found":
+		return ExitNotFound
+	case "timeout":
+		return ExitTimeout
+	case "rate_limited", "audit_unavailable":
+		return ExitUnavailable
+	default:
+		return ExitFailure
+	}
+}
+
+// execOptions - общие флаги exec и сгенерированных подкоманд.
+type execOptions struct {
+	output  string
+	timeout time.Duration
+}
+
+func addExecFlags(cmd *cobra.Command, opts *execOptions) {
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "json", "формат вывода: json|yaml|table|text")
+	cmd.Flags().DurationVar(&opts.timeout, "timeout", 10*time.Second, "предел времени выполнения команды")
+}
+
+func newExecCmd(cfgPath *string) *cobra.Command {
+	var opts execOptions
+	cmd := &cobra.Command{
+		Use:   "exec <module> <command> [args...]",
+		Short: "Выполнить команду модуля",
+		Long: "Выполняет команду модуля локально и пишет событие аудита с пользователем ОС\n" +
+			"в качестве субъекта. Аргументы, начинающиеся с \"-\", передаются после \"--\".\n" +
+			"Код выхода зависит от error_code ответа:\n" +
+			"1 - ошибка модуля, 2 - неверная команда или аргументы, 3 - доступ запрещен,\n" +
+			"4 - не найдено, 5 - истек --timeout, 6 - временно недоступно.",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runModuleCommand(cmd, *cfgPath, opts, args[0], args[1], args[2:])
+		},
+	}
+	addExecFlags(cmd, &opts)
+	return cmd
+}
+
+// moduleRegistry строит по конфигу cfgPath реестр для подкоманд модулей и
+// шаблонов вывода: нужны только метаданные, поэтому хранилище - в памяти.
+func moduleRegistry(ctx context.Context, cfgPath string) (*core.Registry, error) {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	registry, _, err := app.Modules(ctx, cfg, memory.New(), nil)
+	return registry, err
+}
+
+// configFlag находит значение --config в аргументах до разбора флагов cobra.
+func configFlag(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "--config" && i+1 < len(args):
+			return args[i+1]
+		case strings.HasPrefix(arg, "--config="):
+			return strings.TrimPrefix(arg, "--config=")
+		}
+	}
+	return ""
+}
+
+// addModuleCmds добавляет подкоманды "<module> <command>" по метаданным
+// реестра; модуль с именем встроенной команды доступен только через exec.
+func addModuleCmds(root *cobra.Command, registry *core.Registry, cfgPath *string) {
+	taken := map[string]bool{}
+	for _, c := range root.Commands() {
+		taken[c.Name()] = true
+	}
+	modules := registry.Providers()
+	sort.Strings(modules)
+	for _, module := range modules {
+		if taken[module] {
+			continue
+		}
+		root.AddCommand(newModuleCmd(registry, cfgPath, module))
+	}
+}
+
+func newModuleCmd(registry *core.Registry, cfgPath *string, module string) *cobra.Command {
+	infos, described := registry.Describe(module)
+	if !described {
+		// Без Describer команды неизвестны: первым аргументом идет имя команды.
+		var opts execOptions
+		cmd := &cobra.Command{
+			Use:   module + " <command> [args...]",
+			Short: "Команды модуля " + module,
+			Args:  cobra.MinimumNArgs(1),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runModuleCommand(cmd, *cfgPath, opts, module, args[0], args[1:])
+			},
+		}
+		addExecFlags(cmd, &opts)
+		return cmd
+	}
+	cmd := &cobra.Command{
+		Use:   module,
+		Short: "Команды модуля " + module,
+	}
+	for _, info := range infos {
+		cmd.AddCommand(newModuleCommandCmd(registry, cfgPath, module, info))
+	}
+	return cmd
+}
+
+func newModuleCommandCmd(registry *core.Registry, cfgPath *string, module string, info core.CommandInfo) *cobra.Command {
+	var opts execOptions
+	short := info.Summary
+	if info.Mutating {
+		short += " (изменяет состояние)"
+	}
+	cmd := &cobra.Command{
+		Use:   strings.TrimSpace(info.Name + " " + info.Usage),
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runModuleCommand(cmd, *cfgPath, opts, module, info.Name, args)
+		},
+	}
+	addExecFlags(cmd, &opts)
+	return cmd
+}
+
+// runModuleCommand выполняет команду модуля из реестра, построенного по
+// конфигу поверх его базы, от имени пользователя ОС: проверяет права
+// субъекта cli, аудирует команду как транспорты (<module>:<command>,
+// request id, fan-out в audit.sinks) и печатает ответ. При audit.fail_closed
+// команда не выполняется без сохраненного события "started".
+func runModuleCommand(cmd *cobra.Command, cfgPath string, opts execOptions, module, command string, args []string) error {
+	switch opts.output {
+	case "json", "yaml", "table", "text":
+	default:
+		return &ExitError{Code: ExitUsage, Err: fmt.Errorf("unknown output %q (json|yaml|table|text)", opts.output)}
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	redactor, err := app.BuildRedactor(cfg)
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	subject := core.Subject{Source: "cli", ID: localSubject()}
+	action := core.Action{Module: module, Command: command}
+	requestID := common.NewRequestID()
+	// Без базы модули работают с пустым состоянием в памяти, а аудит не пишется.
+	var (
+		backend   storage.Store = memory.New()
+		auditSink storage.AuditWriter
+	)
+	if st, err := app.OpenStore(cfg); err != nil {
+		if cfg.Audit.FailClosed {
+			return &ExitError{Code: ExitUnavailable, Err: fmt.Errorf("audit_unavailable: %w", err)}
+		}
+		slog.Warn("cli exec is not audited", "err", err)
+	} else {
+		defer st.Close()
+		sink, closeAudit, err := app.OpenAudit(cfg, st)
+		if err != nil {
+			return err
+		}
+		defer closeAudit()
+		backend, auditSink = st, sink
+	}
+	registry, authz, err := app.Modules(cmd.Context(), cfg, backend, auditSink)
+	if err != nil {
+		return err
+	}
+	writeAudit := func(status string) error {
+		if auditSink == nil {
+			return errors.New("audit store is not available")
+		}
+		return auditSink.Write(context.WithoutCancel(cmd.Context()), common.CommandAuditEvent(subject, action, status, requestID, args, redactor))
+	}
+	writeAuditBestEffort := func(status string) {
+		if auditSink == nil {
+			return
+		}
+		if err := writeAudit(status); err != nil {
+			slog.Warn("cli exec audit failed", "action", module+":"+command, "status", status, "request_id", requestID, "err", err)
+		}
+	}
+
+	if err := authz.Authorize(subject, action); err != nil {
+		writeAuditBestEffort("denied")
+		resp := core.Response{Status: "error", ErrorCode: "access_denied"}
+		err = accessDenied(subject.ID, err)
+		if perr := printResponse(cmd, registry, opts.output, module, command, resp, resp, err); perr != nil {
+			return perr
+		}
+		return &ExitError{Code: ExitDenied, Err: err}
+	}
+	if cfg.Audit.FailClosed {
+		if err := writeAudit("started"); err != nil {
+			return &ExitError{Code: ExitUnavailable, Err: fmt.Errorf("audit_unavailable: %w", err)}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(core.WithSubject(cmd.Context(), subject), opts.timeout)
+	defer cancel()
+	resp, execErr := registry.Execute(ctx, module, command, args)
+	if execErr != nil && resp.ErrorCode == "" && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		resp = core.Response{Status: "error", ErrorCode: "timeout"}
+	}
+	status := "ok"
+	if execErr != nil || resp.Status == "error" {
+		status = "error"
+	}
+	// Итоговое событие best-effort: в fail-closed режиме факт запуска уже сохранен.
+	writeAuditBestEffort(status)
+	resp.Data = redactor.Value(resp.Data)
+	if execErr != nil {
+		execErr = errors.New(redactor.String(execErr.Error()))
+	}
+
+	if err := printResponse(cmd, registry, opts.output, module, command, resp, resp, execErr); err != nil {
+		return err
+	}
+	if status == "ok" {
+		return nil
+	}
+	if execErr == nil {
+		execErr = fmt.Errorf("%s %s: %s", module, command, resp.ErrorCode)
+	}
+	return &ExitError{Code: exitCode(resp.ErrorCode), Err: execErr}
+}
+
+// printResponse печатает ответ: json и yaml - doc целиком (core.Response
+// или ответ API), table - данные таблицей, text - через шаблоны модулей.
+func printResponse(cmd *cobra.Command, registry *core.Registry, output, module, command string, doc interface{}, resp core.Response, execErr error) error {
+	out := cmd.OutOrStdout()
+	switch output {
+	case "json", "yaml":
+		return printDoc(out, output, doc)
+	case "table":
+		if table, ok := dataTable(resp.Data); ok && execErr == nil && resp.Status != "error" {
+			fmt.Fprintln(out, table)
+			return nil
+		}
+	}
+	renderer, err := render.FromRegistry(registry)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, renderer.Render(render.Target{Format: render.Plain}, module, command, resp, execErr))
+	return nil
+}
+
+// printDoc печатает v в json или yaml; yaml строится через JSON, чтобы
+// ключи совпадали с json-тегами.
+func printDoc(out io.Writer, output string, v interface{}) error {
+	if output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// dataTable - список объектов таблицей, объект - таблицей key/value;
+// ok=false - данные другого вида (печатаются как text).
+func dataTable(data interface{}) (string, bool) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", false
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			return "", false
+		}
+		for _, it := range v {
+			if _, ok := it.(map[string]interface{}); !ok {
+				return "", false
+			}
+		}
+		return render.Table(v, nil), true
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		rows := make([]map[string]interface{}, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, map[string]interface{}{"key": k, "value": render.Keyed(k, v[k])})
+		}
+		return render.Table(rows, []string{"key", "value"}), true
+	default:
+		return "", false
+	}
+}
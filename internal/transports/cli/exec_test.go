@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/transports/common"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := []struct {
+		errorCode string
+		want      int
+	}{
+		{"bad_command", ExitUsage},
+		{"bad_args", ExitUsage},
+		{"unknown_command", ExitUsage},
+		{"module_not_found", ExitUsage},
+		{"unsupported", ExitUsage},
+		{"bad_topic", ExitUsage},
+		{"bad_severity", ExitUsage},
+		{"access_denied", ExitDenied},
+		{"not_found", ExitNotFound},
+		{"timeout", ExitTimeout},
+		{"rate_limited", ExitUnavailable},
+		{"audit_unavailable", ExitUnavailable},
+		{"internal", ExitFailure},
+		{"", ExitFailure},
+	}
+	for _, tc := range cases {
+		if got := exitCode(tc.errorCode); got != tc.want {
+			t.Fatalf("exitCode(%q) = %d, want %d", tc.errorCode, got, tc.want)
+		}
+	}
+}
+
+func TestDataTable(t *testing.T) {
+	cases := []struct {
+		name   string
+		data   interface{}
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "list of objects",
+			data:   []map[string]interface{}{{"name": "a", "state": "firing"}, {"name": "bb", "state": "ok"}},
+			want:   "name  state\na     firing\nbb    ok",
+			wantOK: true,
+		},
+		{
+			name:   "object",
+			data:   map[string]interface{}{"uptime": "1h", "host": "db1"},
+			want:   "key     value\nhost    db1\nuptime  1h",
+			wantOK: true,
+		},
+		{
+			name:   "struct",
+			data:   struct{ Name string }{Name: "db1"},
+			want:   "key   value\nName  db1",
+			wantOK: true,
+		},
+		{name: "empty list", data: []interface{}{}},
+		{name: "list of scalars", data: []string{"a", "b"}},
+		{name: "mixed list", data: []interface{}{map[string]interface{}{"a": 1}, "b"}},
+		{name: "scalar", data: "text"},
+		{name: "nil", data: nil},
+	}
+	for _, tc := range cases {
+		got, ok := dataTable(tc.data)
+		if ok != tc.wantOK || got != tc.want {
+			t.Fatalf("%s: dataTable = %q, %v; want %q, %v", tc.name, got, ok, tc.want, tc.wantOK)
+		}
+	}
+}
+
+func TestPrintResponse(t *testing.T) {
+	data := []map[string]interface{}{{"name": "a"}}
+	ok := core.Response{Status: "ok", Data: data}
+	failed := core.Response{Status: "error", ErrorCode: "not_found", Data: data}
+	cases := []struct {
+		name    string
+		output  string
+		resp    core.Response
+		execErr error
+		want    []string
+		reject  []string
+	}{
+		{name: "json", output: "json", resp: ok, want: []string{`"status": "ok"`, `"name": "a"`}},
+		{name: "yaml", output: "yaml", resp: ok, want: []string{"status: ok", "- name: a"}},
+		{name: "table", output: "table", resp: ok, want: []string{"name\na\n"}},
+		{name: "table error", output: "table", resp: failed, execErr: errors.New("rule missing"), want: []string{"rule missing"}, reject: []string{"name\na\n"}},
+		{name: "text", output: "text", resp: ok, want: []string{"a"}, reject: []string{`"status"`}},
+		{name: "json error", output: "json", resp: failed, execErr: errors.New("rule missing"), want: []string{`"error_code": "not_found"`}},
+	}
+	for _, tc := range cases {
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		if err := printResponse(cmd, core.NewRegistry(), tc.output, "alerts", "list", tc.resp, tc.resp, tc.execErr); err != nil {
+			t.Fatalf("%s: printResponse: %v", tc.name, err)
+		}
+		for _, s := range tc.want {
+			if !strings.Contains(out.String(), s) {
+				t.Fatalf("%s: output %q does not contain %q", tc.name, out.String(), s)
+			}
+		}
+		for _, s := range tc.reject {
+			if strings.Contains(out.String(), s) {
+				t.Fatalf("%s: output %q contains %q", tc.name, out.String(), s)
+			}
+		}
+	}
+}
+
+func TestRunModuleCommandAudit(t *testing.T) {
+	cases := []struct {
+		name       string
+		failClosed bool
+		backend    string
+		allowed    bool
+		wantCode   int
+		wantStatus []string
+	}{
+		{name: "fail closed writes started", failClosed: true, backend: config.StorageSQLite, allowed: true, wantStatus: []string{"started", "ok"}},
+		{name: "best effort", backend: config.StorageSQLite, allowed: true, wantStatus: []string{"ok"}},
+		{name: "denied", failClosed: true, backend: config.StorageSQLite, wantCode: ExitDenied, wantStatus: []string{"denied"}},
+		{name: "fail closed without store", failClosed: true, backend: config.StorageMemory, allowed: true, wantCode: ExitUnavailable},
+	}
+	for _, tc := range cases {
+		dir := t.TempDir()
+		dbPath := filepath.Join(dir, "state.db")
+		sinkPath := filepath.Join(dir, "audit.ndjson")
+		cfgPath := filepath.Join(dir, "config.yaml")
+		allow := "[]"
+		if tc.allowed {
+			allow = fmt.Sprintf("[%q]", localSubject())
+		}
+		yml := fmt.Sprintf("security:\n  auth_allowlist:\n    cli: %s\nstorage:\n  backend: %s\nsqlite:\n  path: %s\n"+
+			"audit:\n  fail_closed: %v\n  sinks:\n    - name: file\n      type: file\n      file: {path: %s}\n",
+			allow, tc.backend, dbPath, tc.failClosed, sinkPath)
+		if err := os.WriteFile(cfgPath, []byte(yml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		cmd := &cobra.Command{}
+		cmd.SetOut(&out)
+		cmd.SetContext(context.Background())
+		err := runModuleCommand(cmd, cfgPath, execOptions{output: "json", timeout: time.Second}, "silence", "list", []string{"--all"})
+		if tc.wantCode != 0 {
+			var exitErr *ExitError
+			if !errors.As(err, &exitErr) || exitErr.Code != tc.wantCode {
+				t.Fatalf("%s: err = %v, want exit code %d", tc.name, err, tc.wantCode)
+			}
+			if strings.Contains(out.String(), `"status": "ok"`) {
+				t.Fatalf("%s: command ran: %q", tc.name, out.String())
+			}
+		} else if err != nil || !strings.Contains(out.String(), `"status": "ok"`) {
+			t.Fatalf("%s: runModuleCommand = %v, output %q", tc.name, err, out.String())
+		}
+		if len(tc.wantStatus) == 0 {
+			continue
+		}
+
+		cfg, err := config.Load(cfgPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		st, err := app.OpenStore(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		events, err := st.QueryAudit(context.Background(), storage.AuditQuery{Action: "silence:list"})
+		st.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(events) != len(tc.wantStatus) {
+			t.Fatalf("%s: events = %+v, want statuses %v", tc.name, events, tc.wantStatus)
+		}
+		want := common.CommandAuditEvent(core.Subject{Source: "cli", ID: localSubject()}, core.Action{Module: "silence", Command: "list"}, "", "", []string{"--all"}, nil)
+		statuses := map[string]bool{}
+		for _, ev := range events {
+			if ev.Source != "cli" || ev.Subject != want.Subject || string(ev.Payload) != string(want.Payload) {
+				t.Fatalf("%s: event = %+v, want payload %s", tc.name, ev, want.Payload)
+			}
+			if ev.RequestID == "" || ev.RequestID != events[0].RequestID {
+				t.Fatalf("%s: request ids differ or are empty: %+v", tc.name, events)
+			}
+			statuses[ev.Status] = true
+		}
+		for _, s := range tc.wantStatus {
+			if !statuses[s] {
+				t.Fatalf("%s: no %q event in %+v", tc.name, s, events)
+			}
+		}
+		// События идут через fan-out: файловый sink получает их после записи.
+		sunk, err := os.ReadFile(sinkPath)
+		if err != nil || strings.Count(string(sunk), "silence:list") != len(tc.wantStatus) {
+			t.Fatalf("%s: file sink = %q, %v", tc.name, sunk, err)
+		}
+	}
+}
+
+func TestExecuteLoadsConfigOnlyForModuleCommands(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	if err := Execute(context.Background(), "1.0", []string{"version", "--config", missing}); err != nil {
+		t.Fatalf("version with missing config: %v", err)
+	}
+	err := Execute(context.Background(), "1.0", []string{"alerts", "list", "--config", missing})
+	if err == nil || !strings.Contains(err.Error(), "load config") {
+		t.Fatalf("module command with missing config: %v", err)
+	}
+}
+
+func TestConfigFlag(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"exec", "silence", "list", "--config", "a.yaml"}, "a.yaml"},
+		{[]string{"--config=b.yaml", "alerts", "list"}, "b.yaml"},
+		{[]string{"exec", "host", "status", "--", "--config", "c.yaml"}, ""},
+		{[]string{"version"}, ""},
+	}
+	for _, tc := range cases {
+		if got := configFlag(tc.args); got != tc.want {
+			t.Fatalf("configFlag(%v) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
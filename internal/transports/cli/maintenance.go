@@ -0,0 +1,293 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
+	"goadmin/internal/storage"
+)
+
+func newSilenceCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "silence",
+		Short: "Silences: подавление уведомлений по меткам",
+		Long: "Команды работают с базой SQLite: работающий демон подхватывает\n" +
+			"изменения в течение maintenance.sync_interval_seconds.",
+	}
+	cmd.AddCommand(newSilenceListCmd(cfgPath))
+	cmd.AddCommand(newSilenceAddCmd(cfgPath))
+	cmd.AddCommand(newSilenceExpireCmd(cfgPath))
+	return cmd
+}
+
+func newMaintenanceCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "maintenance",
+		Short: "Окна обслуживания",
+		Long: "Окно подавляет уведомления по меткам и открывает действия из\n" +
+			"maintenance.require_window, пока оно активно.",
+	}
+	cmd.AddCommand(newMaintenanceListCmd(cfgPath))
+	cmd.AddCommand(newMaintenanceAddCmd(cfgPath))
+	cmd.AddCommand(newMaintenanceDeleteCmd(cfgPath))
+	return cmd
+}
+
+// maintenanceSession - менеджер поверх базы CLI и проверка прав пользователя ОС.
+type maintenanceSession struct {
+	store   storage.Store
+	mgr     *maintenance.Manager
+	authz   core.Authorizer
+	subject core.Subject
+}
+
+func openMaintenance(cmd *cobra.Command, cfgPath string) (*maintenanceSession, error) {
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	st, err := app.OpenStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mgr, authz, err := app.Maintenance(cmd.Context(), cfg, st, st)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+	return &maintenanceSession{
+		store:   st,
+		mgr:     mgr,
+		authz:   authz,
+		subject: core.Subject{Source: "cli", ID: localSubject()},
+	}, nil
+}
+
+// authorize проверяет module:command; отказ аудируется как auditAction со статусом denied.
+func (s *maintenanceSession) authorize(ctx context.Context, module, command, auditAction string, payload map[string]string) error {
+	err := s.authz.Authorize(s.subject, core.Action{Module: module, Command: command})
+	if err == nil {
+		return nil
+	}
+	payload["error"] = err.Error()
+	raw, _ := json.Marshal(payload)
+	_ = s.store.SaveAudit(context.WithoutCancel(ctx), storage.AuditEvent{
+		Subject: s.subject.ID,
+		Action:  auditAction,
+		Source:  "cli",
+		Status:  "denied",
+		Payload: raw,
+	})
+	return accessDenied(s.subject.ID, err)
+}
+
+// accessDenied добавляет к отказу подсказку про allowlist, если дело не в окне обслуживания.
+func accessDenied(subject string, err error) error {
+	if errors.Is(err, maintenance.ErrOutsideWindow) {
+		return fmt.Errorf("access denied: %w", err)
+	}
+	return fmt.Errorf("access denied (add %q to security.auth_allowlist.cli): %w", subject, err)
+}
+
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func newSilenceListCmd(cfgPath *string) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Показать действующие и будущие silences",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			return printJSON(cmd, s.mgr.Silences(all))
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "включить истекшие silences")
+	return cmd
+}
+
+func newSilenceAddCmd(cfgPath *string) *cobra.Command {
+	var (
+		matchers []string
+		duration time.Duration
+		start    string
+		end      string
+		comment  string
+	)
+	cmd := &cobra.Command{
+		Use:     "add",
+		Short:   "Создать silence",
+		Example: "  goadmin silence add -m alertname=disk_full -m 'host=~db-.*' --duration 2h --comment 'замена диска'",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec := maintenance.SilenceSpec{Matchers: matchers, Duration: duration, Comment: comment}
+			var err error
+			if spec.StartsAt, err = parseFlagTime("start", start); err != nil {
+				return err
+			}
+			if spec.EndsAt, err = parseFlagTime("end", end); err != nil {
+				return err
+			}
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			if err := s.authorize(cmd.Context(), "silence", "add", "silence:create", map[string]string{}); err != nil {
+				return err
+			}
+			sil, err := s.mgr.CreateSilence(cmd.Context(), spec, s.subject)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, sil)
+		},
+	}
+	cmd.Flags().StringArrayVarP(&matchers, "matcher", "m", nil, "matcher метки: name=value, name!=value, name=~regex, name!~regex")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "длительность от начала (например 2h)")
+	cmd.Flags().StringVar(&start, "start", "", "начало в RFC3339 (по умолчанию сейчас)")
+	cmd.Flags().StringVar(&end, "end", "", "окончание в RFC3339 (вместо --duration)")
+	cmd.Flags().StringVar(&comment, "comment", "", "комментарий")
+	return cmd
+}
+
+func newSilenceExpireCmd(cfgPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "expire <id>",
+		Short: "Досрочно завершить silence",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			if err := s.authorize(cmd.Context(), "silence", "expire", "silence:expire", map[string]string{"id": args[0]}); err != nil {
+				return err
+			}
+			sil, err := s.mgr.ExpireSilence(cmd.Context(), args[0], s.subject)
+			if err != nil {
+				return fmt.Errorf("expire silence %s: %w", args[0], err)
+			}
+			return printJSON(cmd, sil)
+		},
+	}
+}
+
+func newMaintenanceListCmd(cfgPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Показать окна обслуживания и их состояние",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			return printJSON(cmd, s.mgr.Windows())
+		},
+	}
+}
+
+func newMaintenanceAddCmd(cfgPath *string) *cobra.Command {
+	var (
+		schedule string
+		duration time.Duration
+		start    string
+		end      string
+		matchers []string
+		comment  string
+	)
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Создать или заменить окно обслуживания",
+		Example: "  goadmin maintenance add nightly --schedule '0 2 * * *' --duration 1h -m 'host=~db-.*'\n" +
+			"  goadmin maintenance add upgrade --start 2026-01-10T22:00:00Z --end 2026-01-11T01:00:00Z",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			spec := maintenance.WindowSpec{Name: args[0], Schedule: schedule, Duration: duration, Matchers: matchers, Comment: comment}
+			for _, f := range []struct {
+				name, value string
+				dst         **time.Time
+			}{{"start", start, &spec.StartsAt}, {"end", end, &spec.EndsAt}} {
+				t, err := parseFlagTime(f.name, f.value)
+				if err != nil {
+					return err
+				}
+				if !t.IsZero() {
+					*f.dst = &t
+				}
+			}
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			if err := s.authorize(cmd.Context(), "maintenance", "start", "maintenance:create", map[string]string{"name": args[0]}); err != nil {
+				return err
+			}
+			win, err := s.mgr.CreateWindow(cmd.Context(), spec, s.subject)
+			if err != nil {
+				return err
+			}
+			return printJSON(cmd, win)
+		},
+	}
+	cmd.Flags().StringVar(&schedule, "schedule", "", "cron-выражение начала повторяющегося окна")
+	cmd.Flags().DurationVar(&duration, "duration", 0, "длительность окна")
+	cmd.Flags().StringVar(&start, "start", "", "начало разового окна в RFC3339")
+	cmd.Flags().StringVar(&end, "end", "", "окончание разового окна в RFC3339")
+	cmd.Flags().StringArrayVarP(&matchers, "matcher", "m", nil, "matcher метки уведомлений, подавляемых окном")
+	cmd.Flags().StringVar(&comment, "comment", "", "комментарий")
+	return cmd
+}
+
+func newMaintenanceDeleteCmd(cfgPath *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Удалить окно обслуживания",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := openMaintenance(cmd, *cfgPath)
+			if err != nil {
+				return err
+			}
+			defer s.store.Close()
+			if err := s.authorize(cmd.Context(), "maintenance", "delete", "maintenance:delete", map[string]string{"name": args[0]}); err != nil {
+				return err
+			}
+			if err := s.mgr.DeleteWindow(cmd.Context(), args[0], s.subject); err != nil {
+				return fmt.Errorf("delete window %s: %w", args[0], err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+// parseFlagTime разбирает RFC3339; пустое значение - нулевое время.
+func parseFlagTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--%s: expected RFC3339 time: %w", flag, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/redact"
+)
+
+func newRedactCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "redact",
+		Short: "Правила скрытия секретов",
+	}
+	cmd.AddCommand(newRedactTestCmd(cfgPath))
+	return cmd
+}
+
+type redactTestReport struct {
+	Enabled bool              `json:"enabled"`
+	Mask    string            `json:"mask"`
+	Command string            `json:"command,omitempty"`
+	Args    []redact.Decision `json:"args,omitempty"`
+	Text    *redact.Decision  `json:"text,omitempty"`
+	JSON    json.RawMessage   `json:"json,omitempty"`
+}
+
+func newRedactTestCmd(cfgPath *string) *cobra.Command {
+	var text, jsonFile string
+	cmd := &cobra.Command{
+		Use:   "test [module command [-- args...]]",
+		Short: "Показать, что будет скрыто в аргументах, тексте или JSON",
+		Long: "Применяет правила redaction из конфига и печатает решение по каждому значению.\n" +
+			"Аргументы команды, похожие на флаги, передаются после --:\n" +
+			"  goadmin redact test db connect -- --password s3cret host=db1",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				return fmt.Errorf("expected module and command")
+			}
+			if len(args) == 0 && text == "" && jsonFile == "" {
+				return fmt.Errorf("nothing to test: pass module command args, --text or --json")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			r, err := app.BuildRedactor(cfg)
+			if err != nil {
+				return err
+			}
+
+			report := redactTestReport{Enabled: r != nil, Mask: r.Mask()}
+			if len(args) >= 2 {
+				report.Command = args[0] + ":" + args[1]
+				report.Args = r.ExplainArgs(args[0], args[1], args[2:])
+			}
+			if text != "" {
+				out := r.String(text)
+				report.Text = &redact.Decision{Input: text, Output: out, Redacted: out != text}
+			}
+			if jsonFile != "" {
+				data, err := os.ReadFile(jsonFile) // #nosec G304 -- файл указывает оператор.
+				if err != nil {
+					return fmt.Errorf("read json: %w", err)
+				}
+				out := r.JSON(data)
+				if !json.Valid(out) {
+					return fmt.Errorf("%s: invalid json", jsonFile)
+				}
+				report.JSON = out
+			}
+
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(report)
+		},
+	}
+	cmd.Flags().StringVar(&text, "text", "", "произвольный текст (сообщение, вывод модуля)")
+	cmd.Flags().StringVar(&jsonFile, "json", "", "JSON-файл (payload аудита, ответ модуля)")
+	return cmd
+}
@@ -0,0 +1,309 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/core"
+	"goadmin/internal/render"
+	"goadmin/pkg/client"
+)
+
+// remoteOptions - общие флаги goadmin remote.
+type remoteOptions struct {
+	profile      string
+	profilesPath string
+	requestID    string
+	output       string
+	timeout      time.Duration
+}
+
+func newRemoteCmd(cfgPath *string) *cobra.Command {
+	opts := &remoteOptions{}
+	cmd := &cobra.Command{
+		Use:   "remote",
+		Short: "Команды к /v1 API другого агента",
+		Long: "Вызывает REST API агента по профилю из файла профилей\n" +
+			"(по умолчанию ~/.config/goadmin/remote.yaml):\n\n" +
+			"  default: prod\n" +
+			"  profiles:\n" +
+			"    prod:\n" +
+			"      url: https://agent1.example.org:8080\n" +
+			"      token_file: ~/.config/goadmin/prod.token\n" +
+			"      ca_file: /etc/goadmin/ca.pem\n\n" +
+			"X-Request-ID берется из --request-id или генерируется и попадает в аудит\n" +
+			"удаленного агента. Коды выхода - как у goadmin exec.",
+	}
+	cmd.PersistentFlags().StringVarP(&opts.profile, "profile", "p", os.Getenv("GOADMIN_PROFILE"), "профиль подключения (по умолчанию default из файла, $GOADMIN_PROFILE)")
+	cmd.PersistentFlags().StringVar(&opts.profilesPath, "profiles", "", "файл профилей (по умолчанию ~/.config/goadmin/remote.yaml)")
+	cmd.PersistentFlags().StringVar(&opts.requestID, "request-id", "", "X-Request-ID запроса")
+	cmd.PersistentFlags().StringVarP(&opts.output, "output", "o", "json", "формат вывода: json|yaml|table|text")
+	cmd.PersistentFlags().DurationVar(&opts.timeout, "timeout", 0, "предел времени запроса (по умолчанию из профиля, 10s)")
+
+	cmd.AddCommand(newRemoteExecCmd(cfgPath, opts))
+	cmd.AddCommand(newRemoteModulesCmd(opts))
+	cmd.AddCommand(newRemoteMetricsCmd(opts))
+	cmd.AddCommand(newRemoteAuditCmd(opts))
+	cmd.AddCommand(newRemoteMeCmd(opts))
+	return cmd
+}
+
+// connect проверяет флаги и создает клиент выбранного профиля.
+func (o *remoteOptions) connect(cmd *cobra.Command) (*client.Client, context.Context, error) {
+	switch o.output {
+	case "json", "yaml", "table", "text":
+	default:
+		return nil, nil, &ExitError{Code: ExitUsage, Err: fmt.Errorf("unknown output %q (json|yaml|table|text)", o.output)}
+	}
+	path := o.profilesPath
+	if path == "" {
+		p, err := client.DefaultProfilesPath()
+		if err != nil {
+			return nil, nil, err
+		}
+		path = p
+	}
+	profiles, err := client.LoadProfiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	profile, err := profiles.Profile(o.profile)
+	if err != nil {
+		return nil, nil, &ExitError{Code: ExitUsage, Err: err}
+	}
+	cfg, err := profile.Config()
+	if err != nil {
+		return nil, nil, err
+	}
+	if o.timeout > 0 {
+		cfg.Timeout = o.timeout
+	}
+	c, err := client.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd.SilenceUsage = true
+	ctx := cmd.Context()
+	if o.requestID != "" {
+		ctx = client.WithRequestID(ctx, o.requestID)
+	}
+	return c, ctx, nil
+}
+
+// remoteError добавляет к ошибке API код выхода по HTTP-статусу.
+func remoteError(err error) error {
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		code := ExitFailure
+		switch apiErr.StatusCode {
+		case 400, 413:
+			code = ExitUsage
+		case 401, 403:
+			code = ExitDenied
+		case 404:
+			code = ExitNotFound
+		case 504:
+			code = ExitTimeout
+		case 429, 503:
+			code = ExitUnavailable
+		}
+		return &ExitError{Code: code, Err: err}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return &ExitError{Code: ExitTimeout, Err: err}
+	}
+	return &ExitError{Code: ExitFailure, Err: err}
+}
+
+func newRemoteExecCmd(cfgPath *string, opts *remoteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "exec <module> <command> [args...]",
+		Short: "Выполнить команду модуля на агенте",
+		Long:  "Аргументы модуля, начинающиеся с \"-\", передаются после \"--\".",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ctx, err := opts.connect(cmd)
+			if err != nil {
+				return err
+			}
+			module, command := args[0], args[1]
+			res, err := c.Execute(ctx, module, command, args[2:])
+			if err != nil {
+				return remoteError(err)
+			}
+			resp := core.Response{Status: res.Status, Data: res.Data, ErrorCode: res.ErrorCode}
+			var execErr error
+			if res.Status == "error" {
+				execErr = fmt.Errorf("%s %s: %s, request_id %s", module, command, res.ErrorCode, res.RequestID)
+			}
+			// Шаблоны text-вывода берутся из локальных модулей.
+			registry, err := moduleRegistry(cmd.Context(), *cfgPath)
+			if err != nil {
+				return err
+			}
+			if err := printResponse(cmd, registry, opts.output, module, command, res, resp, execErr); err != nil {
+				return err
+			}
+			if execErr != nil {
+				return &ExitError{Code: exitCode(res.ErrorCode), Err: execErr}
+			}
+			return nil
+		},
+	}
+}
+
+func newRemoteModulesCmd(opts *remoteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "modules",
+		Short: "Модули агента",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ctx, err := opts.connect(cmd)
+			if err != nil {
+				return err
+			}
+			items, err := c.Modules(ctx)
+			if err != nil {
+				return remoteError(err)
+			}
+			if opts.output == "json" || opts.output == "yaml" {
+				return printDoc(cmd.OutOrStdout(), opts.output, items)
+			}
+			for _, name := range items {
+				fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+			return nil
+		},
+	}
+}
+
+func newRemoteMetricsCmd(opts *remoteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "metrics <metric>",
+		Short: "Последняя метрика коллектора (GET /v1/metrics/latest)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ctx, err := opts.connect(cmd)
+			if err != nil {
+				return err
+			}
+			m, err := c.LatestMetric(ctx, args[0])
+			if err != nil {
+				return remoteError(err)
+			}
+			if opts.output == "json" || opts.output == "yaml" {
+				return printDoc(cmd.OutOrStdout(), opts.output, m)
+			}
+			var payload interface{}
+			if err := json.Unmarshal(m.Payload, &payload); err != nil {
+				return fmt.Errorf("metrics: decode payload: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s at %s\n", m.Module, m.TS.Local().Format(time.DateTime))
+			if table, ok := dataTable(payload); ok {
+				fmt.Fprintln(cmd.OutOrStdout(), table)
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), render.Keyed("", payload))
+			return nil
+		},
+	}
+}
+
+func newRemoteAuditCmd(opts *remoteOptions) *cobra.Command {
+	var (
+		q        client.AuditQuery
+		from, to string
+	)
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "События аудита агента (GET /v1/audit)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, t := range []struct {
+				flag string
+				raw  string
+				dst  *time.Time
+			}{{"from", from, &q.From}, {"to", to, &q.To}} {
+				if t.raw == "" {
+					continue
+				}
+				v, err := time.Parse(time.RFC3339, t.raw)
+				if err != nil {
+					return &ExitError{Code: ExitUsage, Err: fmt.Errorf("--%s: want RFC3339: %w", t.flag, err)}
+				}
+				*t.dst = v
+			}
+			c, ctx, err := opts.connect(cmd)
+			if err != nil {
+				return err
+			}
+			page, err := c.Audit(ctx, q)
+			if err != nil {
+				return remoteError(err)
+			}
+			if opts.output == "json" || opts.output == "yaml" {
+				return printDoc(cmd.OutOrStdout(), opts.output, page)
+			}
+			rows := make([]map[string]interface{}, 0, len(page.Items))
+			for _, ev := range page.Items {
+				rows = append(rows, map[string]interface{}{
+					"ts": ev.TS, "subject": ev.Subject, "source": ev.Source,
+					"action": ev.Action, "status": ev.Status, "request_id": ev.RequestID,
+				})
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), render.Table(rows, []string{"ts", "subject", "source", "action", "status", "request_id"}))
+			if page.NextCursor != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "next: --cursor %s\n", page.NextCursor)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&from, "from", "", "начало диапазона (RFC3339)")
+	cmd.Flags().StringVar(&to, "to", "", "конец диапазона (RFC3339)")
+	cmd.Flags().StringVar(&q.Subject, "subject", "", "фильтр по субъекту")
+	cmd.Flags().StringVar(&q.Action, "action", "", "фильтр по action (module:command)")
+	cmd.Flags().StringVar(&q.Source, "source", "", "фильтр по источнику")
+	cmd.Flags().StringVar(&q.Status, "status", "", "фильтр по статусу")
+	cmd.Flags().StringVar(&q.RequestID, "event-request-id", "", "фильтр по request_id события")
+	cmd.Flags().IntVar(&q.Limit, "limit", 0, "размер страницы (по умолчанию 50, не больше 1000)")
+	cmd.Flags().StringVar(&q.Cursor, "cursor", "", "next_cursor предыдущей страницы")
+	return cmd
+}
+
+func newRemoteMeCmd(opts *remoteOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "me",
+		Short: "Субъект и роли токена профиля",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, ctx, err := opts.connect(cmd)
+			if err != nil {
+				return err
+			}
+			me, err := c.Me(ctx)
+			if err != nil {
+				return remoteError(err)
+			}
+			if opts.output == "json" || opts.output == "yaml" {
+				return printDoc(cmd.OutOrStdout(), opts.output, me)
+			}
+			rows := []map[string]interface{}{
+				{"key": "subject", "value": me.Subject},
+				{"key": "roles", "value": strings.Join(me.Roles, ", ")},
+				{"key": "auth_method", "value": me.AuthMethod},
+				{"key": "request_id", "value": me.RequestID},
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), render.Table(rows, []string{"key", "value"}))
+			return nil
+		},
+	}
+}
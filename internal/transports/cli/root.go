@@ -2,37 +2,63 @@ package cli
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"log/slog"
+	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/spf13/cobra"
 
 	"goadmin/internal/app"
 	"goadmin/internal/config"
-	"goadmin/internal/core"
 )
 
-// New создает корневую CLI-команду.
-func New(registry *core.Registry, version string) *cobra.Command {
+// Execute запускает CLI с аргументами args. Встроенные команды не читают
+// конфиг заранее; подкоманды "<module> <command>" строятся, только если args
+// не совпали со встроенной командой: реестру нужен конфиг из --config.
+func Execute(ctx context.Context, version string, args []string) error {
+	root, cfgPath := newRoot(version)
+	root.SetArgs(args)
+	if _, _, err := root.Find(args); err != nil {
+		registry, err := moduleRegistry(ctx, configFlag(args))
+		if err != nil {
+			return err
+		}
+		addModuleCmds(root, registry, cfgPath)
+	}
+	return root.ExecuteContext(ctx)
+}
+
+// newRoot создает корневую команду со встроенными подкомандами и
+// возвращает значение флага --config.
+func newRoot(version string) (*cobra.Command, *string) {
 	root := &cobra.Command{
 		Use:   "goadmin",
 		Short: "Инфраструктурный агент goadmin",
+		Long: "Инфраструктурный агент goadmin.\n" +
+			"Команды модулей: goadmin <module> <command> или goadmin exec <module> <command>.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
 		},
 	}
 
-	var cfgPath string
-	root.PersistentFlags().StringVar(&cfgPath, "config", "", "путь к config.yaml")
+	cfgPath := new(string)
+	root.PersistentFlags().StringVar(cfgPath, "config", "", "путь к config.yaml")
 
 	root.AddCommand(newVersionCmd(version))
-	root.AddCommand(newHostStatusCmd(registry))
-	root.AddCommand(newServeCmd(&cfgPath))
+	root.AddCommand(newExecCmd(cfgPath))
+	root.AddCommand(newServeCmd(cfgPath))
+	root.AddCommand(newAuditCmd(cfgPath))
+	root.AddCommand(newRedactCmd(cfgPath))
+	root.AddCommand(newDBCmd(cfgPath))
+	root.AddCommand(newScheduleCmd(cfgPath))
+	root.AddCommand(newSilenceCmd(cfgPath))
+	root.AddCommand(newMaintenanceCmd(cfgPath))
+	root.AddCommand(newTransportsCmd(cfgPath))
+	root.AddCommand(newRemoteCmd(cfgPath))
 
-	return root
+	return root, cfgPath
 }
 
 func newVersionCmd(version string) *cobra.Command {
@@ -45,25 +71,6 @@ func newVersionCmd(version string) *cobra.Command {
 	}
 }
 
-func newHostStatusCmd(registry *core.Registry) *cobra.Command {
-	return &cobra.Command{
-		Use:   "host status",
-		Short: "Показать состояние узла",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Second)
-			defer cancel()
-
-			resp, err := registry.Execute(ctx, "host", "status", nil)
-			if err != nil {
-				return err
-			}
-			enc := json.NewEncoder(cmd.OutOrStdout())
-			enc.SetIndent("", "  ")
-			return enc.Encode(resp)
-		},
-	}
-}
-
 func newServeCmd(cfgPath *string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "serve",
@@ -83,6 +90,25 @@ func newServeCmd(cfgPath *string) *cobra.Command {
 			}
 			defer application.Close()
 
+			// SIGHUP перечитывает конфиг; без перезапуска применяется секция webhooks.
+			hup := make(chan os.Signal, 1)
+			signal.Notify(hup, syscall.SIGHUP)
+			defer signal.Stop(hup)
+			go func() {
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-hup:
+						if err := application.Reload(ctx, *cfgPath); err != nil {
+							slog.Error("config reload failed", "err", err)
+						} else {
+							slog.Info("config reloaded")
+						}
+					}
+				}
+			}()
+
 			fmt.Fprintln(cmd.OutOrStdout(), "goadmin serve started")
 			if err := application.Serve(ctx); err != nil && ctx.Err() == nil {
 				return err
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/app"
+	"goadmin/internal/config"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+func newScheduleCmd(cfgPath *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Задачи планировщика: история, пауза, ручной запуск",
+		Long: "Команды работают с базой SQLite: пауза и запросы ручного запуска\n" +
+			"применяются работающим демоном в течение нескольких секунд.",
+	}
+	cmd.AddCommand(newScheduleListCmd(cfgPath))
+	cmd.AddCommand(newScheduleControlCmd(cfgPath, "run-now", "run", "Запустить задачу вне расписания"))
+	cmd.AddCommand(newScheduleControlCmd(cfgPath, "pause", "pause", "Приостановить плановые запуски задачи"))
+	cmd.AddCommand(newScheduleControlCmd(cfgPath, "resume", "resume", "Возобновить плановые запуски задачи"))
+	return cmd
+}
+
+// scheduleListItem - задача из конфига с сохраненным состоянием и историей.
+type scheduleListItem struct {
+	Name         string           `json:"name"`
+	Schedule     string           `json:"schedule"`
+	Overlap      string           `json:"overlap"`
+	Paused       bool             `json:"paused"`
+	RunRequested bool             `json:"run_requested"`
+	UpdatedBy    string           `json:"updated_by,omitempty"`
+	UpdatedAt    *time.Time       `json:"updated_at,omitempty"`
+	LastResult   *storage.JobRun  `json:"last_result,omitempty"`
+	Runs         []storage.JobRun `json:"runs,omitempty"`
+}
+
+func newScheduleListCmd(cfgPath *string) *cobra.Command {
+	var history int
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Показать задачи и последние запуски",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			jobs, err := app.ScheduledJobs(cfg)
+			if err != nil {
+				return err
+			}
+			st, err := app.OpenStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			states, err := st.JobStates(cmd.Context())
+			if err != nil {
+				return err
+			}
+			byJob := make(map[string]storage.JobState, len(states))
+			for _, s := range states {
+				byJob[s.Job] = s
+			}
+			limit := history
+			if limit < 1 {
+				limit = 1
+			}
+			items := make([]scheduleListItem, 0, len(jobs))
+			for _, job := range jobs {
+				item := scheduleListItem{Name: job.Name, Schedule: job.Schedule, Overlap: string(job.Overlap)}
+				if s, ok := byJob[job.Name]; ok {
+					updated := s.UpdatedAt
+					item.Paused, item.RunRequested, item.UpdatedBy, item.UpdatedAt = s.Paused, s.RunRequested, s.UpdatedBy, &updated
+				}
+				runs, err := st.JobRuns(cmd.Context(), storage.JobRunQuery{Job: job.Name, Limit: limit})
+				if err != nil {
+					return err
+				}
+				if len(runs) > 0 {
+					item.LastResult = &runs[0]
+				}
+				if history > 0 {
+					item.Runs = runs
+				}
+				items = append(items, item)
+			}
+			enc := json.NewEncoder(cmd.OutOrStdout())
+			enc.SetIndent("", "  ")
+			return enc.Encode(items)
+		},
+	}
+	cmd.Flags().IntVar(&history, "history", 0, "сколько последних запусков показать для каждой задачи")
+	return cmd
+}
+
+// newScheduleControlCmd создает pause|resume|run-now. Действие проверяется
+// security.auth_allowlist.cli (subject - пользователь ОС) и аудируется как schedule:<op>.
+func newScheduleControlCmd(cfgPath *string, use, op, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   use + " <job>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			cfg, err := config.Load(*cfgPath)
+			if err != nil {
+				return fmt.Errorf("load config: %w", err)
+			}
+			jobs, err := app.ScheduledJobs(cfg)
+			if err != nil {
+				return err
+			}
+			st, err := app.OpenStore(cfg)
+			if err != nil {
+				return err
+			}
+			defer st.Close()
+
+			subject := localSubject()
+			writeAudit := func(status string, cause error) error {
+				payload := map[string]string{"job": name}
+				if cause != nil {
+					payload["error"] = cause.Error()
+				}
+				raw, _ := json.Marshal(payload)
+				return st.SaveAudit(context.WithoutCancel(cmd.Context()), storage.AuditEvent{
+					Subject: subject,
+					Action:  "schedule:" + op,
+					Source:  "cli",
+					Status:  status,
+					Payload: raw,
+				})
+			}
+
+			// Политика maintenance.require_window действует и для CLI.
+			_, authz, err := app.Maintenance(cmd.Context(), cfg, st, st)
+			if err != nil {
+				return err
+			}
+			if err := authz.Authorize(core.Subject{Source: "cli", ID: subject}, core.Action{Module: "schedules", Command: op}); err != nil {
+				_ = writeAudit("denied", err)
+				return accessDenied(subject, err)
+			}
+
+			known := false
+			for _, job := range jobs {
+				known = known || job.Name == name
+			}
+			switch {
+			case !known:
+				err = fmt.Errorf("%s: %w", name, core.ErrUnknownJob)
+			case op == "run":
+				err = st.RequestJobRun(cmd.Context(), name, subject)
+			default:
+				err = st.SetJobPaused(cmd.Context(), name, op == "pause", subject)
+			}
+			status := "ok"
+			if err != nil {
+				status = "error"
+			}
+			if aerr := writeAudit(status, err); aerr != nil && err == nil {
+				err = fmt.Errorf("audit schedule event: %w", aerr)
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s requested\n", name, op)
+			return nil
+		},
+	}
+}
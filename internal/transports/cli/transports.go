@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goadmin/internal/config"
+	"goadmin/internal/render"
+	"goadmin/pkg/client"
+)
+
+func newTransportsCmd(cfgPath *string) *cobra.Command {
+	var (
+		url      string
+		tokenEnv string
+		output   string
+	)
+	cmd := &cobra.Command{
+		Use:   "transports",
+		Short: "Состояние транспортов работающего демона",
+		Long: "Запрашивает GET /v1/transports у демона (web.listen_addr из конфига\n" +
+			"или --url); bearer-токен берется из переменной --token-env.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if url == "" {
+				cfg, err := config.Load(*cfgPath)
+				if err != nil {
+					return fmt.Errorf("load config: %w", err)
+				}
+				url = "http://" + cfg.Web.ListenAddr
+			}
+			c, err := client.New(client.Config{BaseURL: url, Token: os.Getenv(tokenEnv)})
+			if err != nil {
+				return err
+			}
+			items, err := c.Transports(cmd.Context())
+			if err != nil {
+				return err
+			}
+			switch output {
+			case "json":
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(items)
+			case "text":
+				rows := make([]map[string]interface{}, 0, len(items))
+				for _, it := range items {
+					row := map[string]interface{}{"name": it.Name, "state": it.State, "restarts": it.Restarts, "since": "-", "last_error": it.LastError}
+					if it.Since != nil {
+						row["since"] = it.Since.Local().Format(time.DateTime)
+					}
+					rows = append(rows, row)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), render.Table(rows, []string{"name", "state", "since", "restarts", "last_error"}))
+				return nil
+			default:
+				return fmt.Errorf("unknown output %q: want json or text", output)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&url, "url", "", "адрес API демона (по умолчанию http://<web.listen_addr>)")
+	cmd.Flags().StringVar(&tokenEnv, "token-env", "GOADMIN_TOKEN", "переменная окружения с bearer-токеном")
+	cmd.Flags().StringVarP(&output, "output", "o", "text", "формат вывода: json|text")
+	return cmd
+}
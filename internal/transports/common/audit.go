@@ -2,12 +2,11 @@ package common
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"time"
 
+	"goadmin/internal/core"
+	"goadmin/internal/redact"
 	"goadmin/internal/storage"
 )
 
@@ -16,12 +15,18 @@ type AuditSink interface {
 	Write(ctx context.Context, ev storage.AuditEvent) error
 }
 
-func newRequestID() string {
-	buf := make([]byte, 8)
-	if _, err := rand.Read(buf); err != nil {
-		return fmt.Sprintf("req-%d", time.Now().UnixNano())
+// CommandAuditEvent - событие аудита команды модуля в формате транспортов:
+// действие module:command, payload {module, command, args} с аргументами,
+// скрытыми по правилам redactor.
+func CommandAuditEvent(subject core.Subject, action core.Action, status, requestID string, args []string, redactor *redact.Redactor) storage.AuditEvent {
+	return storage.AuditEvent{
+		Subject:   subject.ID,
+		Action:    fmt.Sprintf("%s:%s", action.Module, action.Command),
+		Source:    subject.Source,
+		Status:    status,
+		RequestID: requestID,
+		Payload:   buildAuditPayload(action.Module, action.Command, redactor.Args(action.Module, action.Command, args)),
 	}
-	return hex.EncodeToString(buf)
 }
 
 func buildAuditPayload(module, command string, args []string) []byte {
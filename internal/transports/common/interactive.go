@@ -0,0 +1,328 @@
+package common
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/render"
+)
+
+// Button - кнопка inline-клавиатуры; Data - токен callback.
+type Button struct {
+	Text string
+	Data string
+}
+
+// Outgoing - ответ чату: Text в разметке транспорта, Plain - без разметки
+// (повтор, если платформа отклонила разметку), Buttons - клавиатура,
+// Notice - всплывающее уведомление на нажатие кнопки.
+type Outgoing struct {
+	Text    string
+	Plain   string
+	Buttons [][]Button
+	Notice  string
+}
+
+// CallbackPrefix отличает токены goadmin в данных callback.
+const CallbackPrefix = "ga:"
+
+// maxChoices - предел кнопок выбора аргумента.
+const maxChoices = 24
+
+type callbackKind int
+
+const (
+	cbModule  callbackKind = iota + 1 // команды модуля
+	cbCommand                         // следующий аргумент или запуск
+	cbRun                             // аргументы собраны
+	cbConfirm
+	cbCancel
+)
+
+type callbackEntry struct {
+	kind      callbackKind
+	chatID    string
+	subjectID string
+	module    string
+	command   string
+	args      []string
+	// group связывает Confirm и Cancel: нажатие одной гасит обе.
+	group   string
+	expires time.Time
+}
+
+var (
+	errCallbackExpired = errors.New("button expired, send /menu again")
+	errCallbackForeign = errors.New("this button belongs to another user")
+)
+
+// Callbacks хранит токены кнопок; токен привязан к пользователю и чату и
+// истекает через TTL, токены подтверждения одноразовые.
+type Callbacks struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]callbackEntry
+	now     func() time.Time
+}
+
+// NewCallbacks создает хранилище токенов; ttl <= 0 - 5 минут.
+func NewCallbacks(ttl time.Duration) *Callbacks {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	return &Callbacks{ttl: ttl, max: 10000, entries: map[string]callbackEntry{}, now: time.Now}
+}
+
+func (c *Callbacks) issue(e callbackEntry) string {
+	token := randomToken()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if len(c.entries) >= c.max {
+		for k, old := range c.entries {
+			if now.After(old.expires) || len(c.entries) >= c.max {
+				delete(c.entries, k)
+			}
+		}
+	}
+	e.expires = now.Add(c.ttl)
+	c.entries[token] = e
+	return CallbackPrefix + token
+}
+
+// take проверяет токен нажатой кнопки; кнопки группы гасятся после нажатия.
+func (c *Callbacks) take(data, chatID, subjectID string) (callbackEntry, error) {
+	token := strings.TrimPrefix(data, CallbackPrefix)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[token]
+	if !ok || c.now().After(e.expires) {
+		delete(c.entries, token)
+		return callbackEntry{}, errCallbackExpired
+	}
+	if e.subjectID != subjectID || e.chatID != chatID {
+		return callbackEntry{}, errCallbackForeign
+	}
+	if e.group != "" {
+		for k, other := range c.entries {
+			if other.group == e.group {
+				delete(c.entries, k)
+			}
+		}
+	}
+	return e, nil
+}
+
+func randomToken() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Reply исполняет текстовую команду и возвращает ответ в разметке t. С
+// включенными Callbacks /menu открывает меню, а изменяющая команда сначала
+// запрашивает подтверждение.
+func (s *Service) Reply(ctx context.Context, chatID, subjectID, text string, t render.Target) Outgoing {
+	if s.Callbacks != nil {
+		if commandHead(text) == "menu" {
+			if !s.allow(subjectID) {
+				return s.prompt(t, errRateLimited.Error(), nil)
+			}
+			return s.menu(chatID, subjectID, t)
+		}
+		module, command, args, builtin, err := s.parse(text)
+		if err == nil && !builtin && s.mutating(module, command) && s.authorized(subjectID, module, command) {
+			return s.confirm(chatID, subjectID, module, command, args, t)
+		}
+	}
+	return s.renderResult(t, s.Run(ctx, chatID, subjectID, text))
+}
+
+// Callback обрабатывает нажатие кнопки: переход по меню, выбор аргумента,
+// подтверждение или отмену. Исполнение идет через тот же authz, rate limit
+// и аудит, что и текстовые команды.
+func (s *Service) Callback(ctx context.Context, chatID, subjectID, data string, t render.Target) Outgoing {
+	if s.Callbacks == nil {
+		return Outgoing{Notice: errCallbackExpired.Error()}
+	}
+	e, err := s.Callbacks.take(data, chatID, subjectID)
+	if err != nil {
+		return Outgoing{Notice: err.Error()}
+	}
+	if e.kind == cbConfirm {
+		// Rate limit и authz проверяет execute.
+		return s.renderResult(t, s.execute(ctx, chatID, subjectID, e.module, e.command, e.args, false))
+	}
+	if !s.allow(subjectID) {
+		return Outgoing{Notice: errRateLimited.Error()}
+	}
+	switch e.kind {
+	case cbModule:
+		return s.moduleMenu(chatID, subjectID, e.module, t)
+	case cbCommand:
+		return s.advance(ctx, chatID, subjectID, e.module, e.command, e.args, t)
+	case cbRun:
+		return s.runOrConfirm(ctx, chatID, subjectID, e.module, e.command, e.args, t)
+	default:
+		subject := core.Subject{Source: s.Source, ID: subjectID}
+		_ = s.writeAudit(ctx, subject, core.Action{Module: e.module, Command: e.command}, "cancelled", NewRequestID(), e.args)
+		return s.prompt(t, "Cancelled: "+commandLine(e.module, e.command, e.args), nil)
+	}
+}
+
+// menu - модули, в которых пользователю доступна хотя бы одна команда.
+func (s *Service) menu(chatID, subjectID string, t render.Target) Outgoing {
+	modules := s.Registry.Providers()
+	sort.Strings(modules)
+	var row []Button
+	var rows [][]Button
+	for _, m := range modules {
+		if len(s.allowedCommands(subjectID, m)) == 0 {
+			continue
+		}
+		row = append(row, Button{Text: m, Data: s.Callbacks.issue(callbackEntry{kind: cbModule, chatID: chatID, subjectID: subjectID, module: m})})
+		if len(row) == 2 {
+			rows, row = append(rows, row), nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return s.prompt(t, "No commands available.", nil)
+	}
+	return s.prompt(t, "Choose a module:", rows)
+}
+
+func (s *Service) moduleMenu(chatID, subjectID, module string, t render.Target) Outgoing {
+	var rows [][]Button
+	for _, c := range s.allowedCommands(subjectID, module) {
+		label := c.Name
+		if c.Summary != "" {
+			label += " - " + c.Summary
+		}
+		rows = append(rows, []Button{{Text: label, Data: s.Callbacks.issue(callbackEntry{
+			kind: cbCommand, chatID: chatID, subjectID: subjectID, module: module, command: c.Name,
+		})}})
+	}
+	if len(rows) == 0 {
+		return s.prompt(t, "No commands available.", nil)
+	}
+	return s.prompt(t, "Choose a command of "+module+":", rows)
+}
+
+// advance предлагает кнопки следующего аргумента или запускает команду.
+func (s *Service) advance(ctx context.Context, chatID, subjectID, module, command string, args []string, t render.Target) Outgoing {
+	if !s.authorized(subjectID, module, command) {
+		// Отказ аудируется тем же путем, что и для текста.
+		return s.renderResult(t, s.execute(ctx, chatID, subjectID, module, command, args, false))
+	}
+	info, _ := s.Registry.Command(module, command)
+	if len(args) >= len(info.Args) {
+		return s.runOrConfirm(ctx, chatID, subjectID, module, command, args, t)
+	}
+	arg := info.Args[len(args)]
+	choices := arg.Choices
+	if arg.Dynamic {
+		var err error
+		if choices, err = s.Registry.Complete(ctx, module, command, args); err != nil {
+			return s.prompt(t, "Cannot list "+arg.Name+": "+err.Error(), nil)
+		}
+	}
+	if len(choices) == 0 && !arg.Optional {
+		line := commandLine(module, command, args)
+		if info.Usage != "" && len(args) == 0 {
+			line += " " + info.Usage
+		}
+		return s.prompt(t, "Nothing to choose for "+arg.Name+". Type the command: "+line, nil)
+	}
+	var rows [][]Button
+	for i, choice := range choices {
+		if i == maxChoices {
+			break
+		}
+		next := append(append([]string(nil), args...), choice)
+		rows = append(rows, []Button{{Text: choice, Data: s.Callbacks.issue(callbackEntry{
+			kind: cbCommand, chatID: chatID, subjectID: subjectID, module: module, command: command, args: next,
+		})}})
+	}
+	if arg.Optional {
+		rows = append(rows, []Button{{Text: "Run without " + arg.Name, Data: s.Callbacks.issue(callbackEntry{
+			kind: cbRun, chatID: chatID, subjectID: subjectID, module: module, command: command, args: args,
+		})}})
+	}
+	return s.prompt(t, "Choose "+arg.Name+" for "+commandLine(module, command, args)+":", rows)
+}
+
+func (s *Service) runOrConfirm(ctx context.Context, chatID, subjectID, module, command string, args []string, t render.Target) Outgoing {
+	if s.mutating(module, command) {
+		return s.confirm(chatID, subjectID, module, command, args, t)
+	}
+	return s.renderResult(t, s.execute(ctx, chatID, subjectID, module, command, args, false))
+}
+
+// confirm - запрос подтверждения изменяющей команды.
+func (s *Service) confirm(chatID, subjectID, module, command string, args []string, t render.Target) Outgoing {
+	e := callbackEntry{chatID: chatID, subjectID: subjectID, module: module, command: command, args: args, group: randomToken()}
+	ok, cancel := e, e
+	ok.kind, cancel.kind = cbConfirm, cbCancel
+	return s.prompt(t, "Run "+commandLine(module, command, args)+"?", [][]Button{{
+		{Text: "Confirm", Data: s.Callbacks.issue(ok)},
+		{Text: "Cancel", Data: s.Callbacks.issue(cancel)},
+	}})
+}
+
+func (s *Service) allowedCommands(subjectID, module string) []core.CommandInfo {
+	cmds, _ := s.Registry.Describe(module)
+	var out []core.CommandInfo
+	for _, c := range cmds {
+		if s.authorized(subjectID, module, c.Name) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (s *Service) authorized(subjectID, module, command string) bool {
+	return s.Authorizer.Authorize(core.Subject{Source: s.Source, ID: subjectID}, core.Action{Module: module, Command: command}) == nil
+}
+
+func (s *Service) mutating(module, command string) bool {
+	info, ok := s.Registry.Command(module, command)
+	return ok && info.Mutating
+}
+
+func (s *Service) allow(subjectID string) bool {
+	return s.RateLimiter == nil || s.RateLimiter.Allow(s.Source+":"+subjectID, time.Now())
+}
+
+func (s *Service) prompt(t render.Target, text string, buttons [][]Button) Outgoing {
+	return Outgoing{Text: render.Escape(t.Format, text), Plain: text, Buttons: buttons}
+}
+
+// renderResult оформляет результат команды; Plain - тот же ответ без разметки.
+func (s *Service) renderResult(t render.Target, res Result) Outgoing {
+	out := Outgoing{Text: s.Renderer.Render(t, res.Module, res.Command, res.Response, res.Err)}
+	out.Plain = out.Text
+	if t.Format != render.Plain && t.Format != "" {
+		out.Plain = s.Renderer.Render(render.Target{Format: render.Plain, Limit: t.Limit}, res.Module, res.Command, res.Response, res.Err)
+	}
+	return out
+}
+
+// commandLine - команда в виде, который можно ввести заново.
+func commandLine(module, command string, args []string) string {
+	parts := []string{"/" + module, command}
+	for _, a := range args {
+		parts = append(parts, QuoteArg(a))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,130 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/render"
+)
+
+type menuProvider struct{ runs []string }
+
+func (m *menuProvider) Name() string                   { return "svc" }
+func (m *menuProvider) Init(ctx context.Context) error { return nil }
+func (m *menuProvider) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	m.runs = append(m.runs, strings.TrimSpace(cmd+" "+strings.Join(args, " ")))
+	return core.Response{Status: "ok"}, nil
+}
+
+func (m *menuProvider) Commands() []core.CommandInfo {
+	return []core.CommandInfo{
+		{Name: "status", Summary: "state"},
+		{Name: "restart", Mutating: true, Args: []core.ArgInfo{{Name: "unit", Dynamic: true}}},
+	}
+}
+
+func (m *menuProvider) Complete(ctx context.Context, command string, args []string) ([]string, error) {
+	return []string{"nginx", "sshd"}, nil
+}
+
+func newMenuService(t *testing.T) (*Service, *menuProvider, *fakeAuditSink) {
+	t.Helper()
+	p := &menuProvider{}
+	r := core.NewRegistry()
+	if err := r.Register(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	sink := &fakeAuditSink{}
+	return &Service{
+		Source:      "telegram",
+		Registry:    r,
+		Authorizer:  core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1", "2"}}),
+		RateLimiter: NewRateLimiter(100, 0),
+		AuditSink:   sink,
+		Callbacks:   NewCallbacks(time.Minute),
+	}, p, sink
+}
+
+func pressButton(t *testing.T, out Outgoing, text string) string {
+	t.Helper()
+	for _, row := range out.Buttons {
+		for _, b := range row {
+			if b.Text == text {
+				return b.Data
+			}
+		}
+	}
+	t.Fatalf("no button %q in %#v", text, out.Buttons)
+	return ""
+}
+
+func TestInteractiveMenuConfirm(t *testing.T) {
+	svc, p, sink := newMenuService(t)
+	ctx := context.Background()
+	tgt := render.Target{Format: render.Plain}
+
+	out := svc.Reply(ctx, "10", "1", "/menu", tgt)
+	out = svc.Callback(ctx, "10", "1", pressButton(t, out, "svc"), tgt)
+	out = svc.Callback(ctx, "10", "1", pressButton(t, out, "restart"), tgt)
+	out = svc.Callback(ctx, "10", "1", pressButton(t, out, "nginx"), tgt)
+	if !strings.Contains(out.Text, "Run /svc restart nginx?") || len(p.runs) != 0 {
+		t.Fatalf("expected confirmation before run, got %q, runs %v", out.Text, p.runs)
+	}
+	confirm := pressButton(t, out, "Confirm")
+	cancel := pressButton(t, out, "Cancel")
+
+	if got := svc.Callback(ctx, "10", "2", confirm, tgt); got.Notice != errCallbackForeign.Error() {
+		t.Fatalf("foreign user must be rejected, got %#v", got)
+	}
+	svc.Callback(ctx, "10", "1", confirm, tgt)
+	if len(p.runs) != 1 || p.runs[0] != "restart nginx" {
+		t.Fatalf("unexpected runs: %v", p.runs)
+	}
+	if sink.last.Action != "svc:restart" || sink.last.Status != "ok" {
+		t.Fatalf("unexpected audit: %#v", sink.last)
+	}
+	// Confirm и Cancel одноразовые.
+	if got := svc.Callback(ctx, "10", "1", cancel, tgt); got.Notice != errCallbackExpired.Error() {
+		t.Fatalf("expected used buttons to expire, got %#v", got)
+	}
+}
+
+func TestInteractiveTypedMutatingCommand(t *testing.T) {
+	svc, p, sink := newMenuService(t)
+	ctx := context.Background()
+	tgt := render.Target{Format: render.Plain}
+
+	out := svc.Reply(ctx, "10", "1", "/svc restart sshd", tgt)
+	svc.Callback(ctx, "10", "1", pressButton(t, out, "Cancel"), tgt)
+	if len(p.runs) != 0 || sink.last.Status != "cancelled" {
+		t.Fatalf("cancel must not run the command: runs %v, audit %#v", p.runs, sink.last)
+	}
+	svc.Reply(ctx, "10", "1", "/svc status", tgt)
+	if len(p.runs) != 1 {
+		t.Fatalf("read-only command must run without confirmation: %v", p.runs)
+	}
+	out = svc.Reply(ctx, "10", "3", "/svc restart sshd", tgt)
+	if len(out.Buttons) != 0 || len(p.runs) != 1 {
+		t.Fatalf("unauthorized user must be denied without a prompt: %#v", out)
+	}
+}
+
+func TestInteractiveCallbackExpires(t *testing.T) {
+	svc, p, _ := newMenuService(t)
+	ctx := context.Background()
+	tgt := render.Target{Format: render.Plain}
+	now := time.Now()
+	svc.Callbacks.now = func() time.Time { return now }
+
+	out := svc.Reply(ctx, "10", "1", "/svc restart sshd", tgt)
+	now = now.Add(2 * time.Minute)
+	if got := svc.Callback(ctx, "10", "1", pressButton(t, out, "Confirm"), tgt); got.Notice != errCallbackExpired.Error() {
+		t.Fatalf("expected expired button, got %#v", got)
+	}
+	if len(p.runs) != 0 {
+		t.Fatalf("expired confirmation must not run: %v", p.runs)
+	}
+}
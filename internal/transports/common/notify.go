@@ -0,0 +1,169 @@
+package common
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// NotifyModule - модуль в action встроенных команд подписок (notify:subscribe и т.д.).
+const NotifyModule = "notify"
+
+var (
+	// ErrNoSender - есть подписчики, но у транспорта не настроена отправка.
+	ErrNoSender    = errors.New("transport has no outbound sender")
+	errBadTopic    = errors.New("unknown notification topic")
+	errBadSeverity = errors.New("unknown severity")
+)
+
+// parseSubscriptionCommand распознает /subscribe, /unsubscribe и /subscriptions,
+// если подписки включены.
+func (s *Service) parseSubscriptionCommand(text string) (string, string, []string, bool) {
+	if s.Subscriptions == nil {
+		return "", "", nil, false
+	}
+	tokens, err := tokenize(text)
+	if err != nil || len(tokens) == 0 {
+		return "", "", nil, false
+	}
+	switch name := commandHead(tokens[0].text); name {
+	case "subscribe", "unsubscribe", "subscriptions":
+		args := make([]string, 0, len(tokens)-1)
+		for _, tok := range tokens[1:] {
+			args = append(args, tok.text)
+		}
+		return NotifyModule, name, args, true
+	}
+	return "", "", nil, false
+}
+
+// executeSubscription выполняет команду подписки для чата chatID.
+func (s *Service) executeSubscription(ctx context.Context, chatID, subjectID, command string, args []string) (core.Response, error) {
+	switch command {
+	case "subscribe":
+		if len(args) == 0 || len(args) > 2 {
+			return core.Response{Status: "error", ErrorCode: "bad_command"}, fmt.Errorf("usage: /subscribe <topic> [min_severity]: %w", errEmptyCommand)
+		}
+		sub := storage.Subscription{Transport: s.Source, ChatID: chatID, Topic: args[0], CreatedBy: subjectID}
+		if !s.knownTopic(sub.Topic) {
+			return core.Response{Status: "error", ErrorCode: "bad_topic"}, fmt.Errorf("%w: %q (known: %s)", errBadTopic, sub.Topic, strings.Join(s.Topics, ", "))
+		}
+		if len(args) == 2 {
+			if core.SeverityRank(args[1]) < 0 {
+				return core.Response{Status: "error", ErrorCode: "bad_severity"}, fmt.Errorf("%w: %q", errBadSeverity, args[1])
+			}
+			sub.MinSeverity = args[1]
+		}
+		if err := s.Subscriptions.Subscribe(ctx, sub); err != nil {
+			return core.Response{Status: "error", ErrorCode: "internal_error"}, err
+		}
+		return core.Response{Status: "ok", Data: sub}, nil
+	case "unsubscribe":
+		if len(args) > 1 {
+			return core.Response{Status: "error", ErrorCode: "bad_command"}, fmt.Errorf("usage: /unsubscribe [topic]: %w", errEmptyCommand)
+		}
+		topic := ""
+		if len(args) == 1 {
+			topic = args[0]
+		}
+		n, err := s.Subscriptions.Unsubscribe(ctx, s.Source, chatID, topic)
+		if err != nil {
+			return core.Response{Status: "error", ErrorCode: "internal_error"}, err
+		}
+		return core.Response{Status: "ok", Data: map[string]interface{}{"removed": n}}, nil
+	default:
+		subs, err := s.Subscriptions.Subscriptions(ctx, storage.SubscriptionQuery{Transport: s.Source, ChatID: chatID})
+		if err != nil {
+			return core.Response{Status: "error", ErrorCode: "internal_error"}, err
+		}
+		if subs == nil {
+			subs = []storage.Subscription{}
+		}
+		return core.Response{Status: "ok", Data: subs}, nil
+	}
+}
+
+func (s *Service) knownTopic(topic string) bool {
+	if len(s.Topics) == 0 {
+		return topic != ""
+	}
+	for _, t := range s.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify ставит уведомление в очередь доставки всем чатам, подписанным на его
+// тему с подходящим порогом важности. Без подписчиков ничего не делает.
+func (s *Service) Notify(ctx context.Context, n core.Notification) error {
+	if s.Subscriptions == nil {
+		return nil
+	}
+	subs, err := s.Subscriptions.Subscriptions(ctx, storage.SubscriptionQuery{Transport: s.Source, Topic: n.Topic})
+	if err != nil {
+		return fmt.Errorf("load subscriptions: %w", err)
+	}
+	var chats []string
+	for _, sub := range subs {
+		if Matches(sub, n) {
+			chats = append(chats, sub.ChatID)
+		}
+	}
+	if len(chats) == 0 {
+		return nil
+	}
+	if s.Outbox == nil {
+		return ErrNoSender
+	}
+	text := s.Redactor.String(FormatNotification(n))
+	key := NotificationKey(n)
+	// Дубликаты и переполнение учитываются в Outbox.Stats.
+	for _, chat := range chats {
+		s.Outbox.Enqueue(chat, key, text)
+	}
+	return nil
+}
+
+// Matches сообщает, проходит ли уведомление порог подписки. Уведомление без
+// уровня считается info, неизвестный уровень - warning.
+func Matches(sub storage.Subscription, n core.Notification) bool {
+	if sub.MinSeverity == "" {
+		return true
+	}
+	rank := core.SeverityRank(n.Severity)
+	switch {
+	case n.Severity == "":
+		rank = core.SeverityRank(core.SeverityInfo)
+	case rank < 0:
+		rank = core.SeverityRank(core.SeverityWarning)
+	}
+	return rank >= core.SeverityRank(sub.MinSeverity)
+}
+
+// FormatNotification - текст сообщения чата: заголовок и тело.
+func FormatNotification(n core.Notification) string {
+	if n.Text == "" {
+		return n.Title
+	}
+	if n.Title == "" {
+		return n.Text
+	}
+	return n.Title + "\n" + n.Text
+}
+
+// NotificationKey - ключ дедупликации: Key или хеш темы и текста.
+func NotificationKey(n core.Notification) string {
+	if n.Key != "" {
+		return n.Key
+	}
+	sum := sha256.Sum256([]byte(n.Topic + "\x00" + n.Title + "\x00" + n.Text))
+	return hex.EncodeToString(sum[:])
+}
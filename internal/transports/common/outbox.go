@@ -0,0 +1,205 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrPermanent помечает ошибку отправки, которую бессмысленно повторять
+// (чат не найден, бот заблокирован).
+var ErrPermanent = errors.New("permanent delivery error")
+
+// Sender - исходящий API чат-транспорта.
+type Sender interface {
+	SendMessage(ctx context.Context, chatID, text string) error
+}
+
+// OutboxConfig задает очередь доставки; нулевые поля берут значения по умолчанию.
+type OutboxConfig struct {
+	QueueSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	// DedupWindow - одно и то же событие не отправляется в чат повторно в течение окна.
+	DedupWindow time.Duration
+	// SendTimeout ограничивает одну попытку отправки.
+	SendTimeout time.Duration
+}
+
+// OutboxStats - счетчики доставки.
+type OutboxStats struct {
+	Queued       uint64 `json:"queued"`
+	Sent         uint64 `json:"sent"`
+	Retried      uint64 `json:"retried"`
+	Failed       uint64 `json:"failed"`
+	Deduplicated uint64 `json:"deduplicated"`
+	Dropped      uint64 `json:"dropped"`
+	Pending      int    `json:"pending"`
+}
+
+type delivery struct {
+	chatID  string
+	key     string
+	text    string
+	attempt int
+}
+
+// Outbox доставляет сообщения через Sender с повторами (экспоненциальная
+// пауза) и дедупликацией по (чат, ключ события). Состояние только в памяти.
+type Outbox struct {
+	sender Sender
+	cfg    OutboxConfig
+	queue  chan delivery
+	now    func() time.Time
+
+	mu        sync.Mutex
+	seen      map[string]time.Time
+	lastPrune time.Time
+	stats     OutboxStats
+}
+
+// NewOutbox создает очередь доставки; отправка начинается после Run.
+func NewOutbox(sender Sender, cfg OutboxConfig) *Outbox {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 5
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = time.Second
+	}
+	if cfg.MaxBackoff < cfg.Backoff {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.DedupWindow <= 0 {
+		cfg.DedupWindow = time.Hour
+	}
+	if cfg.SendTimeout <= 0 {
+		cfg.SendTimeout = 10 * time.Second
+	}
+	return &Outbox{
+		sender: sender,
+		cfg:    cfg,
+		queue:  make(chan delivery, cfg.QueueSize),
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Enqueue ставит сообщение в очередь; false - дубликат в окне или очередь переполнена.
+func (o *Outbox) Enqueue(chatID, key, text string) bool {
+	dedup := chatID + "\x00" + key
+	now := o.now()
+	o.mu.Lock()
+	if now.Sub(o.lastPrune) > o.cfg.DedupWindow/4 {
+		for k, at := range o.seen {
+			if now.Sub(at) >= o.cfg.DedupWindow {
+				delete(o.seen, k)
+			}
+		}
+		o.lastPrune = now
+	}
+	if at, ok := o.seen[dedup]; ok && now.Sub(at) < o.cfg.DedupWindow {
+		o.stats.Deduplicated++
+		o.mu.Unlock()
+		return false
+	}
+	o.seen[dedup] = now
+	o.mu.Unlock()
+
+	if !o.push(delivery{chatID: chatID, key: key, text: text, attempt: 1}) {
+		o.forget(dedup)
+		return false
+	}
+	o.mu.Lock()
+	o.stats.Queued++
+	o.mu.Unlock()
+	return true
+}
+
+func (o *Outbox) push(d delivery) bool {
+	select {
+	case o.queue <- d:
+		return true
+	default:
+		o.mu.Lock()
+		o.stats.Dropped++
+		o.mu.Unlock()
+		slog.Warn("notification dropped: outbox is full", "chat", d.chatID)
+		return false
+	}
+}
+
+func (o *Outbox) forget(dedup string) {
+	o.mu.Lock()
+	delete(o.seen, dedup)
+	o.mu.Unlock()
+}
+
+// Run отправляет сообщения до отмены ctx.
+func (o *Outbox) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-o.queue:
+			o.deliver(ctx, d)
+		}
+	}
+}
+
+func (o *Outbox) deliver(ctx context.Context, d delivery) {
+	sendCtx, cancel := context.WithTimeout(ctx, o.cfg.SendTimeout)
+	err := o.sender.SendMessage(sendCtx, d.chatID, d.text)
+	cancel()
+	if err == nil {
+		o.mu.Lock()
+		o.stats.Sent++
+		o.mu.Unlock()
+		return
+	}
+	if errors.Is(err, ErrPermanent) || d.attempt >= o.cfg.MaxAttempts || ctx.Err() != nil {
+		o.mu.Lock()
+		o.stats.Failed++
+		o.mu.Unlock()
+		// Неудачную доставку можно повторить тем же событием позже.
+		o.forget(d.chatID + "\x00" + d.key)
+		slog.Error("notification delivery failed", "chat", d.chatID, "attempts", d.attempt, "err", err)
+		return
+	}
+	wait := o.backoff(d.attempt)
+	d.attempt++
+	o.mu.Lock()
+	o.stats.Retried++
+	o.mu.Unlock()
+	time.AfterFunc(wait, func() {
+		if ctx.Err() == nil {
+			o.push(d)
+		}
+	})
+}
+
+// backoff возвращает паузу перед попыткой attempt+1: Backoff*2^(attempt-1), не больше MaxBackoff.
+func (o *Outbox) backoff(attempt int) time.Duration {
+	wait := o.cfg.Backoff
+	for i := 1; i < attempt && wait < o.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > o.cfg.MaxBackoff {
+		wait = o.cfg.MaxBackoff
+	}
+	return wait
+}
+
+// Stats возвращает счетчики доставки.
+func (o *Outbox) Stats() OutboxStats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	st := o.stats
+	st.Pending = len(o.queue)
+	return st
+}
@@ -0,0 +1,99 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type flakySender struct {
+	mu    sync.Mutex
+	fails int
+	err   error
+	sent  []string
+	calls int
+}
+
+func (f *flakySender) SendMessage(ctx context.Context, chatID, text string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.fails {
+		return f.err
+	}
+	f.sent = append(f.sent, chatID+":"+text)
+	return nil
+}
+
+func waitStats(t *testing.T, o *Outbox, done func(OutboxStats) bool) OutboxStats {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if st := o.Stats(); done(st) {
+			return st
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("outbox did not settle: %+v", o.Stats())
+	return OutboxStats{}
+}
+
+func TestOutboxRetriesAndDedup(t *testing.T) {
+	sender := &flakySender{fails: 2, err: errors.New("503")}
+	o := NewOutbox(sender, OutboxConfig{Backoff: time.Millisecond, MaxBackoff: 4 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.Run(ctx)
+
+	if !o.Enqueue("100", "k1", "hello") {
+		t.Fatal("first enqueue must be accepted")
+	}
+	if o.Enqueue("100", "k1", "hello") {
+		t.Fatal("duplicate must be rejected")
+	}
+	if !o.Enqueue("200", "k1", "hello") {
+		t.Fatal("same event for another chat must be accepted")
+	}
+	st := waitStats(t, o, func(st OutboxStats) bool { return st.Sent == 2 })
+	if st.Retried != 2 || st.Deduplicated != 1 || st.Failed != 0 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestOutboxGivesUp(t *testing.T) {
+	sender := &flakySender{fails: 100, err: errors.New("timeout")}
+	o := NewOutbox(sender, OutboxConfig{MaxAttempts: 3, Backoff: time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go o.Run(ctx)
+
+	o.Enqueue("100", "k1", "hello")
+	waitStats(t, o, func(st OutboxStats) bool { return st.Failed == 1 })
+	if sender.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", sender.calls)
+	}
+	// После окончательной ошибки то же событие можно отправить снова.
+	if !o.Enqueue("100", "k1", "hello") {
+		t.Fatal("failed event must not stay deduplicated")
+	}
+
+	perm := &flakySender{fails: 100, err: ErrPermanent}
+	p := NewOutbox(perm, OutboxConfig{Backoff: time.Millisecond})
+	go p.Run(ctx)
+	p.Enqueue("100", "k1", "hello")
+	waitStats(t, p, func(st OutboxStats) bool { return st.Failed == 1 })
+	if perm.calls != 1 {
+		t.Fatalf("permanent error must not be retried, got %d calls", perm.calls)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	o := NewOutbox(&flakySender{}, OutboxConfig{Backoff: time.Second, MaxBackoff: 5 * time.Second})
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := o.backoff(attempt); got != want {
+			t.Fatalf("backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
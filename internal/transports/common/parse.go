@@ -0,0 +1,226 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"goadmin/internal/core"
+)
+
+// Command - разобранная текстовая команда "/module command args...".
+type Command struct {
+	Module  string
+	Command string
+	// Args - аргументы после команды в исходном порядке, без кавычек; их
+	// получает модуль.
+	Args []string
+	// Params - аргументы вида key=value.
+	Params map[string]string
+	// Flags - опции --name и --name=value (без значения - "true").
+	Flags map[string]string
+}
+
+// ParseError - синтаксическая ошибка команды; Column - позиция символа в
+// тексте, начиная с 1.
+type ParseError struct {
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse error at column %d: %s", e.Column, e.Msg)
+}
+
+// token - слово команды и позиция его начала.
+type token struct {
+	text   string
+	column int
+}
+
+// ParseCommand разбирает текст как строку shell: слова делятся пробелами,
+// '...' берется буквально, в "..." и вне кавычек "\" экранирует следующий
+// символ. Суффикс бота Telegram (/host@bot) отбрасывается. После "--"
+// аргументы не разбираются на Params и Flags.
+func ParseCommand(text string) (Command, error) {
+	tokens, err := tokenize(text)
+	if err != nil {
+		return Command{}, err
+	}
+	if len(tokens) == 0 {
+		return Command{}, &ParseError{Column: 1, Msg: "empty command"}
+	}
+	module := strings.TrimPrefix(tokens[0].text, "/")
+	if i := strings.IndexByte(module, '@'); i >= 0 {
+		module = module[:i]
+	}
+	if module == "" {
+		return Command{}, &ParseError{Column: tokens[0].column, Msg: "expected module name"}
+	}
+	if len(tokens) < 2 {
+		return Command{}, &ParseError{Column: tokens[0].column + len([]rune(tokens[0].text)), Msg: "expected command after module " + module}
+	}
+	cmd := Command{Module: module, Command: tokens[1].text, Args: []string{}}
+	for _, tok := range tokens[2:] {
+		cmd.Args = append(cmd.Args, tok.text)
+	}
+	opts, bad := parseOptions(cmd.Args)
+	if bad >= 0 {
+		tok := tokens[2+bad]
+		return Command{}, &ParseError{Column: tok.column, Msg: fmt.Sprintf("invalid flag %q", tok.text)}
+	}
+	cmd.Params, cmd.Flags = opts.Params, opts.Flags
+	return cmd, nil
+}
+
+// parseOptions собирает key=value и --flag из args до "--"; bad - индекс
+// первого неверного флага или -1.
+func parseOptions(args []string) (core.CommandOptions, int) {
+	opts := core.CommandOptions{Params: map[string]string{}, Flags: map[string]string{}}
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return opts, -1
+		case strings.HasPrefix(arg, "--"):
+			name, value, ok := strings.Cut(arg[2:], "=")
+			if !validName(name) {
+				return opts, i
+			}
+			if !ok {
+				value = "true"
+			}
+			opts.Flags[name] = value
+		default:
+			if key, value, ok := strings.Cut(arg, "="); ok && validName(key) {
+				opts.Params[key] = value
+			}
+		}
+	}
+	return opts, -1
+}
+
+// ParseTextCommand переводит текст в (module, command, args); см. ParseCommand.
+// Params и Flags не теряются: Service восстанавливает их из args и передает
+// модулю через core.WithCommandOptions.
+func ParseTextCommand(text string) (string, string, []string, error) {
+	cmd, err := ParseCommand(text)
+	if err != nil {
+		return "", "", nil, err
+	}
+	return cmd.Module, cmd.Command, cmd.Args, nil
+}
+
+// QuoteArg возвращает аргумент в кавычках, если без них ParseCommand
+// разобрал бы его иначе.
+func QuoteArg(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '\'' || r == '"' || r == '\\'
+	}) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// tokenize делит текст на слова с учетом кавычек и экранирования.
+func tokenize(text string) ([]token, error) {
+	var (
+		tokens []token
+		cur    strings.Builder
+		inWord bool
+		start  int
+		quote  rune
+		qcol   int
+		escape bool
+		ecol   int
+	)
+	col := 0
+	for _, r := range text {
+		col++
+		switch {
+		case escape:
+			escape = false
+			cur.WriteRune(r)
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escape, ecol = true, col
+			default:
+				cur.WriteRune(r)
+			}
+		case unicode.IsSpace(r):
+			if inWord {
+				tokens = append(tokens, token{text: cur.String(), column: start})
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			if !inWord {
+				inWord, start = true, col
+			}
+			switch r {
+			case '\'', '"':
+				quote, qcol = r, col
+			case '\\':
+				escape, ecol = true, col
+			default:
+				cur.WriteRune(r)
+			}
+		}
+	}
+	switch {
+	case escape:
+		return nil, &ParseError{Column: ecol, Msg: "trailing backslash"}
+	case quote == '\'':
+		return nil, &ParseError{Column: qcol, Msg: "unterminated single quote"}
+	case quote == '"':
+		return nil, &ParseError{Column: qcol, Msg: "unterminated double quote"}
+	}
+	if inWord {
+		tokens = append(tokens, token{text: cur.String(), column: start})
+	}
+	return tokens, nil
+}
+
+// validName - имя флага или параметра: буква, затем буквы, цифры, "_", "-", ".".
+func validName(s string) bool {
+	for i, r := range s {
+		switch {
+		case unicode.IsLetter(r):
+		case i > 0 && (unicode.IsDigit(r) || r == '_' || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// commandHead - первое слово без "/" и суффикса бота: "/menu@bot" -> "menu".
+func commandHead(text string) string {
+	head, _, _ := strings.Cut(strings.TrimSpace(text), " ")
+	head = strings.TrimPrefix(head, "/")
+	if i := strings.IndexByte(head, '@'); i >= 0 {
+		head = head[:i]
+	}
+	return head
+}
+
+// stripMention убирает суффикс бота из первого слова: "/st@bot" -> "/st".
+func stripMention(text string) string {
+	t := strings.TrimSpace(text)
+	head, rest, ok := strings.Cut(t, " ")
+	if i := strings.IndexByte(head, '@'); i > 0 && strings.HasPrefix(head, "/") {
+		head = head[:i]
+	}
+	if !ok {
+		return head
+	}
+	return head + " " + rest
+}
@@ -0,0 +1,79 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	cases := []struct {
+		text   string
+		args   []string
+		params map[string]string
+		flags  map[string]string
+	}{
+		{text: "/logs search 'disk full' /var/log", args: []string{"disk full", "/var/log"}},
+		{text: `/logs search "say \"hi\"" a\ b`, args: []string{`say "hi"`, "a b"}},
+		{text: `/logs search '' ""`, args: []string{"", ""}},
+		{text: "/host@goadmin_bot status", args: []string{}},
+		{
+			text:   "/silence add 1h alertname=disk --quiet --limit=5 -- done=yes --x",
+			args:   []string{"1h", "alertname=disk", "--quiet", "--limit=5", "--", "done=yes", "--x"},
+			params: map[string]string{"alertname": "disk"},
+			flags:  map[string]string{"quiet": "true", "limit": "5"},
+		},
+		{text: "logs search path='/srv/my app'", args: []string{"path=/srv/my app"}, params: map[string]string{"path": "/srv/my app"}},
+	}
+	for _, c := range cases {
+		cmd, err := ParseCommand(c.text)
+		if err != nil {
+			t.Fatalf("%q: %v", c.text, err)
+		}
+		if c.params == nil {
+			c.params = map[string]string{}
+		}
+		if c.flags == nil {
+			c.flags = map[string]string{}
+		}
+		if !reflect.DeepEqual(cmd.Args, c.args) || !reflect.DeepEqual(cmd.Params, c.params) || !reflect.DeepEqual(cmd.Flags, c.flags) {
+			t.Fatalf("%q: got %#v", c.text, cmd)
+		}
+	}
+	if cmd, _ := ParseCommand("/host@goadmin_bot status"); cmd.Module != "host" || cmd.Command != "status" {
+		t.Fatalf("mention not stripped: %#v", cmd)
+	}
+}
+
+func TestParseCommandErrors(t *testing.T) {
+	cases := []struct {
+		text   string
+		column int
+	}{
+		{text: "", column: 1},
+		{text: "/host", column: 6},
+		{text: `/logs search "unterminated`, column: 14},
+		{text: "/logs search it's", column: 16},
+		{text: `/logs search trailing\`, column: 22},
+		{text: "/host status --=1", column: 14},
+	}
+	for _, c := range cases {
+		_, err := ParseCommand(c.text)
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Column != c.column {
+			t.Fatalf("%q: expected error at column %d, got %v", c.text, c.column, err)
+		}
+	}
+}
+
+func TestQuoteArgRoundTrip(t *testing.T) {
+	args := []string{"plain", "two words", "it's", `back\slash`, ""}
+	line := "/logs search"
+	for _, a := range args {
+		line += " " + QuoteArg(a)
+	}
+	cmd, err := ParseCommand(line)
+	if err != nil || !reflect.DeepEqual(cmd.Args, args) {
+		t.Fatalf("%q: got %#v, %v", line, cmd.Args, err)
+	}
+}
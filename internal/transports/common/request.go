@@ -0,0 +1,99 @@
+package common
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Ограничения запроса Execute, общие для REST и gRPC.
+const (
+	maxModuleLen  = 64
+	maxCommandLen = 64
+	maxArgsCount  = 16
+	maxArgLen     = 256
+)
+
+// ValidateExecute проверяет модуль, команду и аргументы запроса Execute;
+// возвращает код ошибки или пустую строку.
+func ValidateExecute(module, command string, args []string) string {
+	if module == "" || command == "" {
+		return "bad_command"
+	}
+	if len(module) > maxModuleLen || !isSafeToken(module) {
+		return "bad_module"
+	}
+	if len(command) > maxCommandLen || !isSafeToken(command) {
+		return "bad_command"
+	}
+	if len(args) > maxArgsCount {
+		return "too_many_args"
+	}
+	for _, arg := range args {
+		if len(arg) > maxArgLen {
+			return "arg_too_long"
+		}
+	}
+	return ""
+}
+
+func isSafeToken(v string) bool {
+	for _, ch := range v {
+		if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') {
+			continue
+		}
+		switch ch {
+		case '_', '-', '.':
+			continue
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeRequestID возвращает переданный клиентом request id или пустую
+// строку, если он длиннее 64 символов или содержит недопустимые символы.
+func SanitizeRequestID(v string) string {
+	id := strings.TrimSpace(v)
+	if id == "" || len(id) > 64 {
+		return ""
+	}
+	for _, ch := range id {
+		if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') {
+			continue
+		}
+		switch ch {
+		case '-', '_', '.', ':':
+			continue
+		default:
+			return ""
+		}
+	}
+	return id
+}
+
+// NewRequestID создает случайный request id.
+func NewRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("req-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// TokenHash - hex sha256 bearer-токена, как в token_sha256 конфига.
+func TokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeTokenHash приводит token_sha256 из конфига к ключу поиска;
+// ok=false - значение не похоже на sha256.
+func NormalizeTokenHash(h string) (string, bool) {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return h, len(h) == 64
+}
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateExecute(t *testing.T) {
+	cases := []struct {
+		module, command string
+		args            []string
+		want            string
+	}{
+		{"host", "status", nil, ""},
+		{"host", "status", []string{strings.Repeat("a", 256)}, ""},
+		{"", "status", nil, "bad_command"},
+		{"host", "", nil, "bad_command"},
+		{"host/..", "status", nil, "bad_module"},
+		{strings.Repeat("m", 65), "status", nil, "bad_module"},
+		{"host", "st atus", nil, "bad_command"},
+		{"host", "status", make([]string, 17), "too_many_args"},
+		{"host", "status", []string{strings.Repeat("a", 257)}, "arg_too_long"},
+	}
+	for _, c := range cases {
+		if got := ValidateExecute(c.module, c.command, c.args); got != c.want {
+			t.Fatalf("%q %q: got %q, want %q", c.module, c.command, got, c.want)
+		}
+	}
+}
+
+func TestSanitizeRequestID(t *testing.T) {
+	cases := map[string]string{
+		" req-1:a.b_c ":         "req-1:a.b_c",
+		"":                      "",
+		"bad id":                "",
+		"id\n":                  "id",
+		strings.Repeat("a", 65): "",
+	}
+	for in, want := range cases {
+		if got := SanitizeRequestID(in); got != want {
+			t.Fatalf("%q: got %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestTokenHash(t *testing.T) {
+	h, ok := NormalizeTokenHash(" " + strings.ToUpper(TokenHash("secret")) + " ")
+	if !ok || h != TokenHash("secret") {
+		t.Fatalf("normalized hash %q, %v", h, ok)
+	}
+	if _, ok := NormalizeTokenHash("REPLACE_WITH_SHA256"); ok {
+		t.Fatal("placeholder accepted as token hash")
+	}
+}
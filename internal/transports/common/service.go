@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/render"
 	"goadmin/internal/storage"
 )
 
 var (
-	errEmptyCommand = errors.New("empty command")
-	errRateLimited  = errors.New("rate limit exceeded")
+	errEmptyCommand     = errors.New("empty command")
+	errRateLimited      = errors.New("rate limit exceeded")
+	errAuditUnavailable = errors.New("audit is unavailable")
 )
 
 // Service объединяет общий пайплайн command->authz->ratelimit->core.
@@ -23,66 +28,147 @@ type Service struct {
 	Authorizer  core.Authorizer
 	RateLimiter *RateLimiter
 	AuditSink   AuditSink
+	// AuditRequired включает fail-closed: перед выполнением пишется событие
+	// "started", и если его не удалось сохранить, команда отклоняется.
+	AuditRequired bool
+	// Redactor скрывает секреты в аргументах аудита, данных ответа и ошибках.
+	Redactor *redact.Redactor
+	// Aliases разворачивает короткие команды: "alerts" -> "alerts list" (для /alerts).
+	Aliases map[string]string
+	// Subscriptions включает команды /subscribe, /unsubscribe, /subscriptions и Notify.
+	Subscriptions storage.SubscriptionStore
+	// Topics ограничивает темы подписок; пусто - любые.
+	Topics []string
+	// Outbox доставляет уведомления; nil - транспорт не умеет отправлять.
+	Outbox *Outbox
+	// Renderer превращает ответы в текст чата; nil - оформление по умолчанию.
+	Renderer *render.Renderer
+	// Callbacks включает меню /menu, выбор аргументов кнопками и
+	// подтверждение изменяющих команд; nil - выключено.
+	Callbacks *Callbacks
+
+	auditFailures atomic.Uint64
+}
+
+// AuditFailures возвращает число событий аудита, которые не удалось записать
+// без отказа в команде (denied, rate_limited и итоговые события).
+func (s *Service) AuditFailures() uint64 {
+	return s.auditFailures.Load()
 }
 
-// ExecuteText парсит команду транспорта и вызывает core-модуль.
+// ExecuteText парсит команду транспорта и вызывает core-модуль; ответ идет в
+// личный чат пользователя.
 func (s *Service) ExecuteText(ctx context.Context, subjectID, text string) (core.Response, error) {
-	module, command, args, err := ParseTextCommand(text)
+	return s.ExecuteChat(ctx, subjectID, subjectID, text)
+}
+
+// ExecuteChat исполняет команду пользователя subjectID из чата chatID;
+// chatID используется командами подписок.
+func (s *Service) ExecuteChat(ctx context.Context, chatID, subjectID, text string) (core.Response, error) {
+	res := s.Run(ctx, chatID, subjectID, text)
+	return res.Response, res.Err
+}
+
+// Result - исполненная чат-команда; Module и Command выбирают шаблон ответа.
+type Result struct {
+	Module   string
+	Command  string
+	Response core.Response
+	Err      error
+}
+
+// Run исполняет команду как ExecuteChat и сохраняет имя команды.
+func (s *Service) Run(ctx context.Context, chatID, subjectID, text string) Result {
+	module, command, args, builtin, err := s.parse(text)
 	if err != nil {
-		return core.Response{Status: "error", ErrorCode: "bad_command"}, err
+		return Result{Response: core.Response{Status: "error", ErrorCode: "bad_command"}, Err: err}
 	}
+	return s.execute(ctx, chatID, subjectID, module, command, args, builtin)
+}
+
+// parse разворачивает алиас и разбирает текст; builtin - команда подписок.
+func (s *Service) parse(text string) (module, command string, args []string, builtin bool, err error) {
+	if full, ok := s.Aliases[strings.TrimPrefix(stripMention(text), "/")]; ok {
+		text = full
+	}
+	module, command, args, builtin = s.parseSubscriptionCommand(text)
+	if !builtin {
+		module, command, args, err = ParseTextCommand(text)
+	}
+	return module, command, args, builtin, err
+}
+
+// execute проводит разобранную команду через authz, rate limit, аудит и модуль.
+func (s *Service) execute(ctx context.Context, chatID, subjectID, module, command string, args []string, builtin bool) Result {
 	subject := core.Subject{Source: s.Source, ID: subjectID}
 	action := core.Action{Module: module, Command: command}
+	requestID := NewRequestID()
 	if err := s.Authorizer.Authorize(subject, action); err != nil {
-		s.writeAudit(ctx, subject, action, "denied", module, command, args)
-		return core.Response{Status: "error", ErrorCode: "access_denied"}, err
+		s.writeAuditBestEffort(ctx, subject, action, "denied", requestID, args)
+		return Result{module, command, core.Response{Status: "error", ErrorCode: "access_denied"}, err}
 	}
 	if s.RateLimiter != nil {
 		if !s.RateLimiter.Allow(fmt.Sprintf("%s:%s", s.Source, subjectID), time.Now()) {
-			s.writeAudit(ctx, subject, action, "rate_limited", module, command, args)
-			return core.Response{Status: "error", ErrorCode: "rate_limited"}, errRateLimited
+			s.writeAuditBestEffort(ctx, subject, action, "rate_limited", requestID, args)
+			return Result{module, command, core.Response{Status: "error", ErrorCode: "rate_limited"}, errRateLimited}
+		}
+	}
+	if s.AuditRequired {
+		if err := s.writeAudit(ctx, subject, action, "started", requestID, args); err != nil {
+			return Result{module, command, core.Response{Status: "error", ErrorCode: "audit_unavailable"}, fmt.Errorf("%w: %v", errAuditUnavailable, err)}
 		}
 	}
-	resp, execErr := s.Registry.Execute(ctx, module, command, args)
+	var (
+		resp    core.Response
+		execErr error
+	)
+	if builtin {
+		resp, execErr = s.executeSubscription(ctx, chatID, subjectID, command, args)
+	} else {
+		// Аргументы из меню и подтверждения разбираются так же, как набранные текстом.
+		opts, _ := parseOptions(args)
+		resp, execErr = s.Registry.Execute(core.WithCommandOptions(core.WithSubject(ctx, subject), opts), module, command, args)
+	}
 	status := "ok"
 	if execErr != nil || resp.Status == "error" {
 		status = "error"
 	}
-	s.writeAudit(ctx, subject, action, status, module, command, args)
-	return resp, execErr
+	// Итоговое событие best-effort: в fail-closed режиме факт запуска уже сохранен.
+	s.writeAuditBestEffort(ctx, subject, action, status, requestID, args)
+	if s.Redactor != nil {
+		resp.Data = s.Redactor.Value(resp.Data)
+		if execErr != nil {
+			execErr = &redactedError{msg: s.Redactor.String(execErr.Error()), err: execErr}
+		}
+	}
+	return Result{module, command, resp, execErr}
 }
 
-func (s *Service) writeAudit(ctx context.Context, subject core.Subject, action core.Action, status, module, command string, args []string) {
-	if s.AuditSink == nil {
-		return
-	}
-	_ = s.AuditSink.Write(ctx, storage.AuditEvent{
-		Subject:   subject.ID,
-		Action:    fmt.Sprintf("%s:%s", action.Module, action.Command),
-		Source:    subject.Source,
-		Status:    status,
-		RequestID: newRequestID(),
-		Payload:   buildAuditPayload(module, command, args),
-	})
+// redactedError скрывает секреты в тексте ошибки, сохраняя цепочку для errors.Is.
+type redactedError struct {
+	msg string
+	err error
 }
 
-// ParseTextCommand переводит текст в (module, command, args).
-// Формат: /module command arg1 arg2
-func ParseTextCommand(text string) (string, string, []string, error) {
-	t := strings.TrimSpace(text)
-	if t == "" {
-		return "", "", nil, errEmptyCommand
-	}
-	t = strings.TrimPrefix(t, "/")
-	parts := strings.Fields(t)
-	if len(parts) < 2 {
-		return "", "", nil, fmt.Errorf("invalid command format: %w", errEmptyCommand)
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+// writeAuditBestEffort пишет событие, не влияющее на исход команды; ошибка
+// логируется и учитывается в AuditFailures.
+func (s *Service) writeAuditBestEffort(ctx context.Context, subject core.Subject, action core.Action, status, requestID string, args []string) {
+	if err := s.writeAudit(ctx, subject, action, status, requestID, args); err != nil {
+		s.auditFailures.Add(1)
+		slog.Warn("audit write failed", "source", s.Source, "action", action.Module+":"+action.Command,
+			"status", status, "request_id", requestID, "err", err)
 	}
-	module := parts[0]
-	command := parts[1]
-	args := []string{}
-	if len(parts) > 2 {
-		args = parts[2:]
+}
+
+func (s *Service) writeAudit(ctx context.Context, subject core.Subject, action core.Action, status, requestID string, args []string) error {
+	if s.AuditSink == nil {
+		if s.AuditRequired {
+			return errAuditUnavailable
+		}
+		return nil
 	}
-	return module, command, args, nil
+	return s.AuditSink.Write(ctx, CommandAuditEvent(subject, action, status, requestID, args, s.Redactor))
 }
@@ -2,19 +2,26 @@ package common
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"goadmin/internal/core"
+	"goadmin/internal/redact"
 	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
 )
 
 type fakeAuditSink struct {
 	count int
+	err   error
+	last  storage.AuditEvent
 }
 
 func (f *fakeAuditSink) Write(ctx context.Context, ev storage.AuditEvent) error {
 	f.count++
-	return nil
+	f.last = ev
+	return f.err
 }
 
 func TestParseTextCommand(t *testing.T) {
@@ -56,10 +63,179 @@ func TestServiceWritesAudit(t *testing.T) {
 	}
 }
 
-type testProvider struct{}
+func TestServiceAuditRequiredRejects(t *testing.T) {
+	provider := &testProvider{}
+	registry := core.NewRegistry()
+	_ = registry.Register(context.Background(), provider)
+	svc := &Service{
+		Source:        "telegram",
+		Registry:      registry,
+		Authorizer:    core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+		AuditSink:     &fakeAuditSink{err: errors.New("disk full")},
+		AuditRequired: true,
+	}
+	resp, err := svc.ExecuteText(context.Background(), "1", "/host status")
+	if err == nil || resp.ErrorCode != "audit_unavailable" {
+		t.Fatalf("expected audit_unavailable, got %#v, %v", resp, err)
+	}
+	if provider.calls != 0 {
+		t.Fatalf("command must not run without audit")
+	}
+}
+
+func TestServiceCountsFinalAuditFailures(t *testing.T) {
+	provider := &testProvider{}
+	registry := core.NewRegistry()
+	_ = registry.Register(context.Background(), provider)
+	svc := &Service{
+		Source:     "telegram",
+		Registry:   registry,
+		Authorizer: core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+		AuditSink:  &fakeAuditSink{err: errors.New("disk full")},
+	}
+	if _, err := svc.ExecuteText(context.Background(), "1", "/host status"); err != nil {
+		t.Fatalf("command must run when audit is best-effort: %v", err)
+	}
+	if _, err := svc.ExecuteText(context.Background(), "2", "/host status"); err == nil {
+		t.Fatal("expected access denied")
+	}
+	if provider.calls != 1 || svc.AuditFailures() != 2 {
+		t.Fatalf("calls = %d, audit failures = %d", provider.calls, svc.AuditFailures())
+	}
+}
+
+func TestServiceRedactsAuditArgs(t *testing.T) {
+	sink := &fakeAuditSink{}
+	registry := core.NewRegistry()
+	_ = registry.Register(context.Background(), &testProvider{})
+	svc := &Service{
+		Source:     "telegram",
+		Registry:   registry,
+		Authorizer: core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+		AuditSink:  sink,
+		Redactor:   redact.Default(),
+	}
+	_, _ = svc.ExecuteText(context.Background(), "1", "/host status password=hunter2")
+	if strings.Contains(string(sink.last.Payload), "hunter2") {
+		t.Fatalf("secret leaked to audit: %s", sink.last.Payload)
+	}
+	if !strings.Contains(string(sink.last.Payload), "password=[REDACTED]") {
+		t.Fatalf("expected redacted arg, got %s", sink.last.Payload)
+	}
+}
+
+type testProvider struct {
+	calls int
+	opts  core.CommandOptions
+}
 
 func (t *testProvider) Name() string                   { return "host" }
 func (t *testProvider) Init(ctx context.Context) error { return nil }
 func (t *testProvider) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	t.calls++
+	t.opts, _ = core.CommandOptionsFromContext(ctx)
 	return core.Response{Status: "ok"}, nil
 }
+
+func TestServicePassesCommandOptions(t *testing.T) {
+	prov := &testProvider{}
+	r := core.NewRegistry()
+	_ = r.Register(context.Background(), prov)
+	svc := &Service{
+		Source:     "telegram",
+		Registry:   r,
+		Authorizer: core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+	}
+	if _, err := svc.ExecuteText(context.Background(), "1", "/host status path='/srv/my app' --verbose --limit=5 -- x=y"); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if prov.opts.Params["path"] != "/srv/my app" || prov.opts.Params["x"] != "" || prov.opts.Flags["verbose"] != "true" || prov.opts.Flags["limit"] != "5" {
+		t.Fatalf("unexpected options: %#v", prov.opts)
+	}
+}
+
+func TestServiceAliases(t *testing.T) {
+	sink := &fakeAuditSink{}
+	prov := &testProvider{}
+	r := core.NewRegistry()
+	_ = r.Register(context.Background(), prov)
+	svc := &Service{
+		Source:     "telegram",
+		Registry:   r,
+		Authorizer: core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+		AuditSink:  sink,
+		Aliases:    map[string]string{"h": "host status"},
+	}
+	if _, err := svc.ExecuteText(context.Background(), "1", " /h "); err != nil {
+		t.Fatalf("alias: %v", err)
+	}
+	if prov.calls != 1 || sink.last.Action != "host:status" {
+		t.Fatalf("alias not expanded: calls=%d action=%s", prov.calls, sink.last.Action)
+	}
+	if _, err := svc.ExecuteText(context.Background(), "1", "/host"); err == nil {
+		t.Fatal("non-alias single word must still fail")
+	}
+}
+
+func TestServiceSubscriptions(t *testing.T) {
+	ctx := context.Background()
+	sink := &fakeAuditSink{}
+	store := memory.New()
+	sender := &flakySender{}
+	svc := &Service{
+		Source:        "telegram",
+		Registry:      core.NewRegistry(),
+		Authorizer:    core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1"}}),
+		AuditSink:     sink,
+		Subscriptions: store,
+		Topics:        []string{"alerts", "jobs"},
+		Outbox:        NewOutbox(sender, OutboxConfig{}),
+	}
+	if _, err := svc.ExecuteChat(ctx, "-500", "1", "/subscribe alerts critical"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if sink.last.Action != "notify:subscribe" || sink.last.Status != "ok" {
+		t.Fatalf("unexpected audit: %+v", sink.last)
+	}
+	if _, err := svc.ExecuteChat(ctx, "-500", "2", "/subscribe jobs"); err == nil {
+		t.Fatal("non-allowlisted user must not subscribe")
+	}
+	if resp, err := svc.ExecuteText(ctx, "1", "/subscribe weather"); err == nil || resp.ErrorCode != "bad_topic" {
+		t.Fatalf("expected bad_topic, got %+v %v", resp, err)
+	}
+	if resp, err := svc.ExecuteText(ctx, "1", "/subscribe jobs loud"); err == nil || resp.ErrorCode != "bad_severity" {
+		t.Fatalf("expected bad_severity, got %+v %v", resp, err)
+	}
+	if _, err := svc.ExecuteText(ctx, "1", "/subscribe jobs"); err != nil {
+		t.Fatalf("subscribe private chat: %v", err)
+	}
+
+	// warning ниже порога группы, а личный чат подписан только на jobs.
+	if err := svc.Notify(ctx, core.Notification{Topic: "alerts", Severity: "warning", Title: "[FIRING] load"}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	if err := svc.Notify(ctx, core.Notification{Topic: "alerts", Severity: "critical", Title: "[FIRING] mem", Key: "a1"}); err != nil {
+		t.Fatalf("notify: %v", err)
+	}
+	_ = svc.Notify(ctx, core.Notification{Topic: "alerts", Severity: "critical", Title: "[FIRING] mem", Key: "a1"})
+	if st := svc.Outbox.Stats(); st.Queued != 1 || st.Deduplicated != 1 {
+		t.Fatalf("unexpected outbox stats: %+v", st)
+	}
+
+	resp, err := svc.ExecuteText(ctx, "1", "/unsubscribe")
+	if err != nil || resp.Data.(map[string]interface{})["removed"] != int64(1) {
+		t.Fatalf("unsubscribe: %+v %v", resp, err)
+	}
+	subs, _ := store.Subscriptions(ctx, storage.SubscriptionQuery{})
+	if len(subs) != 1 || subs[0].ChatID != "-500" || subs[0].CreatedBy != "1" {
+		t.Fatalf("unexpected subscriptions: %+v", subs)
+	}
+
+	svc.Outbox = nil
+	if err := svc.Notify(ctx, core.Notification{Topic: "alerts", Severity: "critical"}); !errors.Is(err, ErrNoSender) {
+		t.Fatalf("expected ErrNoSender, got %v", err)
+	}
+	if err := svc.Notify(ctx, core.Notification{Topic: "jobs"}); err != nil {
+		t.Fatalf("no subscribers must not fail: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package common
+
+import "strings"
+
+// SplitText делит текст сообщения на части не длиннее limit UTF-16 символов
+// (так длину считают Bot API), по возможности по переводу строки.
+func SplitText(text string, limit int) []string {
+	var parts []string
+	for text != "" {
+		cut, units, lastNL := len(text), 0, -1
+		for i, r := range text {
+			n := 1
+			if r >= 0x10000 {
+				n = 2
+			}
+			if units+n > limit {
+				cut = i
+				break
+			}
+			units += n
+			if r == '\n' {
+				lastNL = i
+			}
+		}
+		if cut < len(text) && lastNL > 0 {
+			cut = lastNL + 1
+		}
+		if part := strings.TrimRight(text[:cut], "\n"); part != "" {
+			parts = append(parts, part)
+		}
+		text = text[cut:]
+	}
+	if len(parts) == 0 {
+		parts = append(parts, "")
+	}
+	return parts
+}
@@ -0,0 +1,209 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/storage"
+	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/grpcapi/adminv1"
+)
+
+// Token - bearer-токен gRPC API (тот же формат, что у web.auth.tokens).
+type Token struct {
+	ID          string
+	TokenSHA256 string
+	Subject     string
+	Enabled     bool
+}
+
+// ScheduleController - задачи планировщика для StreamJob (реализуется app.Schedules).
+type ScheduleController interface {
+	Jobs() []core.JobStatus
+	RunNow(ctx context.Context, name, by string) error
+	Runs(ctx context.Context, name string, limit int) ([]storage.JobRun, error)
+}
+
+// Config определяет параметры gRPC-транспорта.
+type Config struct {
+	ListenAddr string
+	// TLS - серверный сертификат; с ClientCAs и RequireAndVerifyClientCert
+	// клиенты аутентифицируются сертификатом (mTLS). nil - без TLS.
+	TLS    *tls.Config
+	Tokens []Token
+	// ClientSubjects сопоставляет CN клиентского сертификата субъекту
+	// авторизации; без записи субъект - сам CN.
+	ClientSubjects map[string]string
+	// RequestTimeout ограничивает unary-вызовы (по умолчанию 3s).
+	RequestTimeout time.Duration
+	// StreamInterval - период опроса состояния задачи в StreamJob (по умолчанию 1s).
+	StreamInterval time.Duration
+	// MaxRecvBytes - предел размера входящего сообщения (по умолчанию 1 MiB).
+	MaxRecvBytes int
+	// Reflection включает grpc.reflection (доступен после аутентификации).
+	Reflection bool
+	// AuditWriter получает события аудита; по умолчанию пишется напрямую в store.
+	AuditWriter storage.AuditWriter
+	// AuditRequired отклоняет Execute, если событие "started" не удалось сохранить.
+	AuditRequired bool
+	// Redactor скрывает секреты в аудите и данных ответов; nil - без изменений.
+	Redactor *redact.Redactor
+	// Schedules - задачи для StreamJob; nil - Unimplemented.
+	Schedules ScheduleController
+}
+
+// Adapter реализует gRPC transport: сервис goadmin.v1.Admin, health и reflection.
+type Adapter struct {
+	adminv1.UnimplementedAdminServer
+
+	registry   *core.Registry
+	authorizer core.Authorizer
+	store      storage.Store
+	limiter    *common.RateLimiter
+	cfg        Config
+
+	tokensByHash map[string]Token
+
+	mu     sync.Mutex
+	server *grpc.Server
+	health *health.Server
+	addr   string
+	// closing закрывается в Stop и завершает потоки StreamJob.
+	closing chan struct{}
+	// failed получает ошибку gRPC-сервера после Start (core.FailureNotifier).
+	failed chan error
+}
+
+// NewAdapter создает gRPC transport; limiter ограничивает вызовы одного
+// субъекта (nil - без ограничения).
+func NewAdapter(registry *core.Registry, authorizer core.Authorizer, store storage.Store, limiter *common.RateLimiter, cfg Config) *Adapter {
+	if cfg.ListenAddr == "" {
+		cfg.ListenAddr = "127.0.0.1:9090"
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 3 * time.Second
+	}
+	if cfg.StreamInterval <= 0 {
+		cfg.StreamInterval = time.Second
+	}
+	if cfg.MaxRecvBytes <= 0 {
+		cfg.MaxRecvBytes = 1 << 20
+	}
+	tokensByHash := make(map[string]Token, len(cfg.Tokens))
+	for _, token := range cfg.Tokens {
+		h, ok := common.NormalizeTokenHash(token.TokenSHA256)
+		if !ok {
+			continue
+		}
+		tokensByHash[h] = token
+	}
+	return &Adapter{
+		registry:     registry,
+		authorizer:   authorizer,
+		store:        store,
+		limiter:      limiter,
+		cfg:          cfg,
+		tokensByHash: tokensByHash,
+		failed:       make(chan error, 1),
+	}
+}
+
+func (a *Adapter) Name() string { return "grpc" }
+
+// Start занимает адрес и запускает gRPC server.
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.server != nil {
+		return errors.New("grpc transport already started")
+	}
+	ln, err := net.Listen("tcp", a.cfg.ListenAddr)
+	if err != nil {
+		return fmt.Errorf("grpc listen: %w", err)
+	}
+	srv := grpc.NewServer(a.serverOptions()...)
+	adminv1.RegisterAdminServer(srv, a)
+	a.health = health.NewServer()
+	a.health.SetServingStatus(adminv1.Admin_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, a.health)
+	if a.cfg.Reflection {
+		reflection.Register(srv)
+	}
+	a.server = srv
+	a.addr = ln.Addr().String()
+	a.closing = make(chan struct{})
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			slog.Error("grpc server failed", "addr", ln.Addr().String(), "err", err)
+			_ = a.writeAudit(context.Background(), "", "grpc:serve", "error", map[string]string{"error": err.Error()}, "")
+			select {
+			case a.failed <- err:
+			default:
+			}
+		}
+	}()
+	return nil
+}
+
+// Addr - адрес сервера после Start (с портом :0 - выбранный системой).
+func (a *Adapter) Addr() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.addr
+}
+
+// Failed сообщает об ошибке gRPC-сервера после Start (core.FailureNotifier).
+func (a *Adapter) Failed() <-chan error { return a.failed }
+
+// Stop переводит health в NOT_SERVING, завершает потоки StreamJob и
+// дожидается остальных вызовов не дольше ctx.
+func (a *Adapter) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	srv, hs, closing := a.server, a.health, a.closing
+	a.server, a.health = nil, nil
+	a.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+	hs.Shutdown()
+	close(closing)
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		srv.Stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (a *Adapter) serverOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(a.cfg.MaxRecvBytes),
+		grpc.ChainUnaryInterceptor(a.unaryInterceptor),
+		grpc.ChainStreamInterceptor(a.streamInterceptor),
+	}
+	if a.cfg.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(a.cfg.TLS)))
+	}
+	return opts
+}
@@ -0,0 +1,466 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+
+	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
+	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/grpcapi/adminv1"
+)
+
+type fakeProvider struct{}
+
+func (fakeProvider) Name() string                   { return "host" }
+func (fakeProvider) Init(ctx context.Context) error { return nil }
+func (fakeProvider) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	if cmd != "status" {
+		return core.Response{Status: "error", ErrorCode: "unknown_command"}, errors.New("unknown command")
+	}
+	return core.Response{Status: "ok", Data: map[string]interface{}{"node": "n1", "load": 0.5}}, nil
+}
+func (fakeProvider) Commands() []core.CommandInfo {
+	return []core.CommandInfo{{Name: "status", Summary: "host status"}}
+}
+
+// fakeSchedules завершает запуск задачи через 20ms после RunNow.
+type fakeSchedules struct {
+	mu   sync.Mutex
+	job  core.JobStatus
+	runs []storage.JobRun
+}
+
+func (s *fakeSchedules) Jobs() []core.JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return []core.JobStatus{s.job}
+}
+
+func (s *fakeSchedules) RunNow(ctx context.Context, name, by string) error {
+	s.mu.Lock()
+	s.job.Running = true
+	s.mu.Unlock()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		now := time.Now()
+		s.job.Running = false
+		s.job.Runs++
+		s.job.LastRun = &now
+		s.runs = append([]storage.JobRun{{ID: int64(len(s.runs) + 1), Job: name, Trigger: core.TriggerManual, StartedAt: now, FinishedAt: now, Status: "ok"}}, s.runs...)
+	}()
+	return nil
+}
+
+func (s *fakeSchedules) Runs(ctx context.Context, name string, limit int) ([]storage.JobRun, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.runs) > limit {
+		return append([]storage.JobRun(nil), s.runs[:limit]...), nil
+	}
+	return append([]storage.JobRun(nil), s.runs...), nil
+}
+
+func tokenSHA256(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startAdapter запускает адаптер на свободном порту; субъекты u1 и svc-ci
+// разрешены allowlist.
+func startAdapter(t *testing.T, store storage.Store, limiter *common.RateLimiter, cfg Config) *Adapter {
+	t.Helper()
+	registry := core.NewRegistry()
+	if err := registry.Register(context.Background(), fakeProvider{}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	authz := core.NewAllowlistAuthorizer(map[string][]string{Source: {"u1", "svc-ci"}})
+	cfg.ListenAddr = "127.0.0.1:0"
+	cfg.Tokens = append(cfg.Tokens,
+		Token{ID: "t1", TokenSHA256: tokenSHA256("test-token"), Subject: "u1", Enabled: true},
+		Token{ID: "t2", TokenSHA256: tokenSHA256("guest-token"), Subject: "guest", Enabled: true},
+	)
+	a := NewAdapter(registry, authz, store, limiter, cfg)
+	if err := a.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_ = a.Stop(ctx)
+	})
+	return a
+}
+
+func dial(t *testing.T, addr string, creds credentials.TransportCredentials) *grpc.ClientConn {
+	t.Helper()
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+func lastAudit(t *testing.T, store *memory.Store, action string) storage.AuditEvent {
+	t.Helper()
+	events, err := store.QueryAudit(context.Background(), storage.AuditQuery{Action: action, Limit: 1})
+	if err != nil || len(events) == 0 {
+		t.Fatalf("no audit event %s: %v", action, err)
+	}
+	return events[0]
+}
+
+func TestExecuteBearer(t *testing.T) {
+	store := memory.New()
+	a := startAdapter(t, store, nil, Config{})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(withToken("test-token"), "x-request-id", "req-1")
+	resp, err := client.Execute(ctx, &adminv1.ExecuteRequest{Module: "host", Command: "status"}, grpc.Header(&header))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.Status != "ok" || resp.RequestId != "req-1" || resp.Data.GetStructValue().GetFields()["node"].GetStringValue() != "n1" {
+		t.Fatalf("unexpected response: %v", resp)
+	}
+	if got := header.Get("x-request-id"); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("x-request-id header = %v", got)
+	}
+	ev := lastAudit(t, store, "grpc:execute")
+	if ev.Source != Source || ev.Subject != "u1" || ev.Status != "ok" || ev.RequestID != "req-1" {
+		t.Fatalf("unexpected audit event: %+v", ev)
+	}
+}
+
+func TestExecuteErrors(t *testing.T) {
+	store := memory.New()
+	a := startAdapter(t, store, nil, Config{})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+
+	cases := []struct {
+		name string
+		ctx  context.Context
+		req  *adminv1.ExecuteRequest
+		code codes.Code
+		msg  string
+	}{
+		{"no auth", context.Background(), &adminv1.ExecuteRequest{Module: "host", Command: "status"}, codes.Unauthenticated, "auth_required"},
+		{"bad token", withToken("nope"), &adminv1.ExecuteRequest{Module: "host", Command: "status"}, codes.Unauthenticated, "invalid_token"},
+		{"denied", withToken("guest-token"), &adminv1.ExecuteRequest{Module: "host", Command: "status"}, codes.PermissionDenied, "access_denied"},
+		{"bad module", withToken("test-token"), &adminv1.ExecuteRequest{Module: "host/..", Command: "status"}, codes.InvalidArgument, "bad_module"},
+		{"failed", withToken("test-token"), &adminv1.ExecuteRequest{Module: "host", Command: "reboot"}, codes.FailedPrecondition, "unknown_command"},
+	}
+	for _, c := range cases {
+		_, err := client.Execute(c.ctx, c.req)
+		if st := status.Convert(err); st.Code() != c.code || st.Message() != c.msg {
+			t.Fatalf("%s: got %v", c.name, err)
+		}
+	}
+	if ev := lastAudit(t, store, "grpc:execute"); ev.Status != "error" {
+		t.Fatalf("expected error audit, got %+v", ev)
+	}
+	denied, _ := store.QueryAudit(context.Background(), storage.AuditQuery{Subject: "guest", Status: "denied"})
+	if len(denied) != 1 {
+		t.Fatalf("expected denied audit for guest, got %+v", denied)
+	}
+}
+
+func TestExecuteRequiresAudit(t *testing.T) {
+	a := startAdapter(t, memory.New(), nil, Config{AuditWriter: failingAuditWriter{}, AuditRequired: true})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+	_, err := client.Execute(withToken("test-token"), &adminv1.ExecuteRequest{Module: "host", Command: "status"})
+	if st := status.Convert(err); st.Code() != codes.Unavailable || st.Message() != "audit_unavailable" {
+		t.Fatalf("expected audit_unavailable, got %v", err)
+	}
+}
+
+type failingAuditWriter struct{}
+
+func (failingAuditWriter) Write(ctx context.Context, ev storage.AuditEvent) error {
+	return errors.New("spool full")
+}
+
+func TestRateLimit(t *testing.T) {
+	store := memory.New()
+	a := startAdapter(t, store, common.NewRateLimiter(1, time.Minute), Config{})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+	if _, err := client.ListModules(withToken("test-token"), &adminv1.ListModulesRequest{}); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	_, err := client.ListModules(withToken("test-token"), &adminv1.ListModulesRequest{})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+	if ev := lastAudit(t, store, "grpc:modules"); ev.Status != "rate_limited" {
+		t.Fatalf("expected rate_limited audit, got %+v", ev)
+	}
+}
+
+func TestListModulesAndQueryAudit(t *testing.T) {
+	store := memory.New()
+	a := startAdapter(t, store, nil, Config{})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+
+	mods, err := client.ListModules(withToken("test-token"), &adminv1.ListModulesRequest{})
+	if err != nil {
+		t.Fatalf("list modules: %v", err)
+	}
+	if len(mods.Modules) != 1 || mods.Modules[0].Name != "host" || len(mods.Modules[0].Commands) != 1 || mods.Modules[0].Commands[0].Name != "status" {
+		t.Fatalf("unexpected modules: %v", mods)
+	}
+
+	page, err := client.QueryAudit(withToken("test-token"), &adminv1.QueryAuditRequest{Action: "grpc:modules", Limit: 10})
+	if err != nil {
+		t.Fatalf("query audit: %v", err)
+	}
+	if len(page.Events) != 1 || page.Events[0].Subject != "u1" || page.Events[0].Ts.AsTime().IsZero() {
+		t.Fatalf("unexpected audit page: %v", page)
+	}
+	if _, err := client.QueryAudit(withToken("test-token"), &adminv1.QueryAuditRequest{Cursor: "garbage"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for bad cursor, got %v", err)
+	}
+}
+
+func TestQueryAuditKeepsStoredPayload(t *testing.T) {
+	store := memory.New()
+	stored := []byte(`{"password":"[REDACTED]","host":"db1"}`)
+	for _, action := range []string{"web:execute", "db:connect"} {
+		if err := store.SaveAudit(context.Background(), storage.AuditEvent{
+			Subject: "u2", Action: action, Source: "cli", Status: "ok", RequestID: "r-" + action, Payload: stored,
+		}); err != nil {
+			t.Fatalf("save audit: %v", err)
+		}
+	}
+	redactor, err := redact.New(redact.Config{Fields: []string{"host"}})
+	if err != nil {
+		t.Fatalf("redactor: %v", err)
+	}
+	a := startAdapter(t, store, nil, Config{Redactor: redactor})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+	page, err := client.QueryAudit(withToken("test-token"), &adminv1.QueryAuditRequest{Action: "db:connect"})
+	if err != nil {
+		t.Fatalf("query audit: %v", err)
+	}
+	if len(page.Events) != 1 || string(page.Events[0].Payload) != string(stored) {
+		t.Fatalf("payload changed: %v", page.Events)
+	}
+	ev := page.Events[0]
+	v := storage.NewChainVerifier(ev.PrevHash, nil, nil)
+	v.Add(storage.AuditEvent{
+		ID: ev.Id, TS: ev.Ts.AsTime(), Subject: ev.Subject, Action: ev.Action, Source: ev.Source,
+		Status: ev.Status, RequestID: ev.RequestId, Payload: ev.Payload, PrevHash: ev.PrevHash, Hash: ev.Hash,
+	})
+	if report := v.Finish(); !report.OK {
+		t.Fatalf("exported event does not verify: %+v", report)
+	}
+}
+
+func TestStreamJobRunNow(t *testing.T) {
+	sched := &fakeSchedules{job: core.JobStatus{Name: "backup", Schedule: "@every 1h"}}
+	a := startAdapter(t, memory.New(), nil, Config{Schedules: sched, StreamInterval: 5 * time.Millisecond})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+
+	ctx, cancel := context.WithTimeout(withToken("test-token"), 5*time.Second)
+	defer cancel()
+	stream, err := client.StreamJob(ctx, &adminv1.StreamJobRequest{Job: "backup", RunNow: true})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	var events []*adminv1.JobEvent
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("recv: %v", err)
+		}
+		events = append(events, ev)
+	}
+	if len(events) < 2 || !events[0].Running {
+		t.Fatalf("expected running then finished events, got %v", events)
+	}
+	last := events[len(events)-1]
+	if last.Running || last.Runs != 1 || last.LastResult.GetTrigger() != core.TriggerManual {
+		t.Fatalf("unexpected final event: %v", last)
+	}
+
+	stream, err = client.StreamJob(ctx, &adminv1.StreamJobRequest{Job: "missing"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestStopEndsStreams(t *testing.T) {
+	sched := &fakeSchedules{job: core.JobStatus{Name: "backup"}}
+	a := startAdapter(t, memory.New(), nil, Config{Schedules: sched})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), nil))
+	stream, err := client.StreamJob(withToken("test-token"), &adminv1.StreamJobRequest{Job: "backup"})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("first event: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.Stop(ctx); err != nil {
+		t.Fatalf("stop must not wait for open streams: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable after stop, got %v", err)
+	}
+}
+
+func TestHealthWithoutAuthAndReflectionWithAuth(t *testing.T) {
+	a := startAdapter(t, memory.New(), nil, Config{Reflection: true})
+	conn := dial(t, a.Addr(), nil)
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: adminv1.Admin_ServiceDesc.ServiceName})
+	if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("health: %v %v", resp, err)
+	}
+
+	list := func(ctx context.Context) ([]string, error) {
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}); err != nil {
+			return nil, err
+		}
+		out, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, s := range out.GetListServicesResponse().GetService() {
+			names = append(names, s.Name)
+		}
+		return names, nil
+	}
+	if _, err := list(context.Background()); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected reflection to require auth, got %v", err)
+	}
+	names, err := list(withToken("test-token"))
+	if err != nil {
+		t.Fatalf("reflection: %v", err)
+	}
+	found := false
+	for _, n := range names {
+		found = found || n == adminv1.Admin_ServiceDesc.ServiceName
+	}
+	if !found {
+		t.Fatalf("admin service not listed: %v", names)
+	}
+}
+
+func TestMutualTLSSubject(t *testing.T) {
+	ca, caKey := newCert(t, "test-ca", nil, nil, true)
+	serverCert, serverKey := newCert(t, "localhost", ca, caKey, false)
+	clientCert, clientKey := newCert(t, "ci-runner", ca, caKey, false)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	store := memory.New()
+	a := startAdapter(t, store, nil, Config{
+		TLS: &tls.Config{
+			Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+			ClientCAs:    pool,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+		},
+		ClientSubjects: map[string]string{"ci-runner": "svc-ci"},
+	})
+	creds := credentials.NewTLS(&tls.Config{
+		RootCAs:      pool,
+		ServerName:   "localhost",
+		Certificates: []tls.Certificate{{Certificate: [][]byte{clientCert.Raw}, PrivateKey: clientKey}},
+	})
+	client := adminv1.NewAdminClient(dial(t, a.Addr(), creds))
+	if _, err := client.Execute(context.Background(), &adminv1.ExecuteRequest{Module: "host", Command: "status"}); err != nil {
+		t.Fatalf("execute over mTLS: %v", err)
+	}
+	if ev := lastAudit(t, store, "grpc:execute"); ev.Subject != "svc-ci" || ev.Status != "ok" {
+		t.Fatalf("unexpected audit event: %+v", ev)
+	}
+
+	// Без клиентского сертификата нужен токен.
+	anon := adminv1.NewAdminClient(dial(t, a.Addr(), credentials.NewTLS(&tls.Config{RootCAs: pool, ServerName: "localhost"})))
+	if _, err := anon.Execute(context.Background(), &adminv1.ExecuteRequest{Module: "host", Command: "status"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated without certificate, got %v", err)
+	}
+}
+
+// newCert выпускает сертификат, подписанный parent (nil - самоподписанный).
+func newCert(t *testing.T, cn string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{cn},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	if isCA {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert, key
+}
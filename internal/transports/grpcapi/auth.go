@@ -0,0 +1,200 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/grpcapi/adminv1"
+)
+
+// Source - источник субъектов и событий аудита gRPC.
+const Source = "grpc"
+
+// healthService доступен без аутентификации, как GET /v1/health.
+const healthService = "/grpc.health.v1.Health/"
+
+type contextKey string
+
+const ctxCaller contextKey = "caller"
+
+// caller - аутентифицированный вызывающий.
+type caller struct {
+	Subject    string
+	AuthMethod string
+	RequestID  string
+}
+
+func callerFromContext(ctx context.Context) caller {
+	c, _ := ctx.Value(ctxCaller).(caller)
+	return c
+}
+
+func (a *Adapter) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if strings.HasPrefix(info.FullMethod, healthService) {
+		return handler(ctx, req)
+	}
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, a.cfg.RequestTimeout)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+func (a *Adapter) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if strings.HasPrefix(info.FullMethod, healthService) {
+		return handler(srv, ss)
+	}
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+}
+
+// wrappedStream подменяет контекст потока.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context { return s.ctx }
+
+// authenticate определяет субъекта по bearer-токену или клиентскому
+// сертификату, применяет rate limit и кладет caller в контекст.
+func (a *Adapter) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := common.SanitizeRequestID(first(md, "x-request-id"))
+	if requestID == "" {
+		requestID = common.NewRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestID))
+
+	subject, authMethod, code := a.resolveSubject(ctx, md)
+	if code != "" {
+		return nil, status.Error(codes.Unauthenticated, code)
+	}
+	if a.limiter != nil && !a.limiter.Allow(Source+":"+subject, time.Now()) {
+		_ = a.writeAudit(ctx, subject, auditAction(method), "rate_limited", map[string]string{"auth_method": authMethod}, requestID)
+		return nil, status.Error(codes.ResourceExhausted, "rate_limited")
+	}
+	return context.WithValue(ctx, ctxCaller, caller{Subject: subject, AuthMethod: authMethod, RequestID: requestID}), nil
+}
+
+// resolveSubject возвращает субъекта, способ аутентификации или код ошибки.
+// Заголовок authorization проверяется первым: неверный токен не
+// подменяется сертификатом.
+func (a *Adapter) resolveSubject(ctx context.Context, md metadata.MD) (string, string, string) {
+	if auth := first(md, "authorization"); auth != "" {
+		if !strings.HasPrefix(strings.ToLower(auth), "bearer ") {
+			return "", "", "invalid_token"
+		}
+		token := strings.TrimSpace(auth[7:])
+		if token == "" {
+			return "", "", "invalid_token"
+		}
+		entry, ok := a.tokensByHash[common.TokenHash(token)]
+		if !ok || !entry.Enabled || entry.Subject == "" {
+			return "", "", "invalid_token"
+		}
+		return entry.Subject, "bearer", ""
+	}
+	if cn := verifiedClientCN(ctx); cn != "" {
+		if subject, ok := a.cfg.ClientSubjects[cn]; ok {
+			return subject, "mtls", ""
+		}
+		return cn, "mtls", ""
+	}
+	return "", "", "auth_required"
+}
+
+// verifiedClientCN - CN клиентского сертификата, прошедшего проверку цепочки.
+func verifiedClientCN(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || !verified(info.State) {
+		return ""
+	}
+	return strings.TrimSpace(info.State.VerifiedChains[0][0].Subject.CommonName)
+}
+
+func verified(state tls.ConnectionState) bool {
+	return len(state.VerifiedChains) > 0 && len(state.VerifiedChains[0]) > 0
+}
+
+// authorize проверяет право и аудирует отказ.
+func (a *Adapter) authorize(ctx context.Context, action string, authAction core.Action, payload map[string]string) error {
+	c := callerFromContext(ctx)
+	if err := a.authorizer.Authorize(core.Subject{Source: Source, ID: c.Subject}, authAction); err != nil {
+		payload["auth_method"] = c.AuthMethod
+		_ = a.writeAudit(ctx, c.Subject, action, "denied", payload, c.RequestID)
+		return status.Error(codes.PermissionDenied, "access_denied")
+	}
+	return nil
+}
+
+// auditAction - действие аудита для метода: "/goadmin.v1.Admin/Execute" -> "grpc:execute".
+func auditAction(method string) string {
+	switch method {
+	case adminv1.Admin_Execute_FullMethodName:
+		return "grpc:execute"
+	case adminv1.Admin_ListModules_FullMethodName:
+		return "grpc:modules"
+	case adminv1.Admin_StreamJob_FullMethodName:
+		return "grpc:stream_job"
+	case adminv1.Admin_QueryAudit_FullMethodName:
+		return "grpc:audit_query"
+	default:
+		return "grpc:call"
+	}
+}
+
+func (a *Adapter) writeAudit(ctx context.Context, subject, action, status string, payload interface{}, requestID string) error {
+	var rawPayload []byte
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		rawPayload = a.cfg.Redactor.JSON(data)
+	}
+	ev := storage.AuditEvent{
+		Subject:   subject,
+		Action:    action,
+		Source:    Source,
+		Status:    status,
+		RequestID: requestID,
+		Payload:   rawPayload,
+	}
+	if a.cfg.AuditWriter != nil {
+		return a.cfg.AuditWriter.Write(ctx, ev)
+	}
+	if a.store == nil {
+		return nil
+	}
+	return a.store.SaveAudit(ctx, ev)
+}
+
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return strings.TrimSpace(v[0])
+	}
+	return ""
+}
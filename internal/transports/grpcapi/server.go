@@ -0,0 +1,323 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/grpcapi/adminv1"
+)
+
+// Execute исполняет команду модуля; проверки и аудит как у POST /v1/commands/execute.
+func (a *Adapter) Execute(ctx context.Context, req *adminv1.ExecuteRequest) (*adminv1.ExecuteResponse, error) {
+	c := callerFromContext(ctx)
+	if code := common.ValidateExecute(req.Module, req.Command, req.Args); code != "" {
+		_ = a.writeAudit(ctx, c.Subject, "grpc:execute", "error", map[string]string{"error_code": code, "auth_method": c.AuthMethod}, c.RequestID)
+		return nil, status.Error(codes.InvalidArgument, code)
+	}
+	payload := map[string]string{"module": req.Module, "command": req.Command, "auth_method": c.AuthMethod}
+	if err := a.authorize(ctx, "grpc:execute", core.Action{Module: req.Module, Command: req.Command}, payload); err != nil {
+		return nil, err
+	}
+	if a.cfg.AuditRequired {
+		if err := a.writeAudit(ctx, c.Subject, "grpc:execute", "started", payload, c.RequestID); err != nil {
+			return nil, status.Error(codes.Unavailable, "audit_unavailable")
+		}
+	}
+
+	resp, err := a.registry.Execute(core.WithSubject(ctx, core.Subject{Source: Source, ID: c.Subject}), req.Module, req.Command, req.Args)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			payload["error_code"] = "request_timeout"
+			_ = a.writeAudit(ctx, c.Subject, "grpc:execute", "error", payload, c.RequestID)
+			return nil, status.Error(codes.DeadlineExceeded, "request_timeout")
+		}
+		_ = a.writeAudit(ctx, c.Subject, "grpc:execute", "error", payload, c.RequestID)
+		code := resp.ErrorCode
+		if code == "" {
+			code = "command_failed"
+		}
+		return nil, status.Error(codes.FailedPrecondition, code)
+	}
+
+	data, err := toValue(a.cfg.Redactor.Value(resp.Data))
+	if err != nil {
+		payload["error_code"] = "bad_response"
+		_ = a.writeAudit(ctx, c.Subject, "grpc:execute", "error", payload, c.RequestID)
+		return nil, status.Error(codes.Internal, "bad_response")
+	}
+	_ = a.writeAudit(ctx, c.Subject, "grpc:execute", "ok", payload, c.RequestID)
+	return &adminv1.ExecuteResponse{
+		RequestId: c.RequestID,
+		Status:    resp.Status,
+		Data:      data,
+		ErrorCode: resp.ErrorCode,
+	}, nil
+}
+
+// ListModules возвращает модули и описания их команд.
+func (a *Adapter) ListModules(ctx context.Context, _ *adminv1.ListModulesRequest) (*adminv1.ListModulesResponse, error) {
+	c := callerFromContext(ctx)
+	if err := a.authorize(ctx, "grpc:modules", core.Action{Module: "grpc", Command: "modules"}, map[string]string{}); err != nil {
+		return nil, err
+	}
+	providers := a.registry.Providers()
+	sort.Strings(providers)
+	out := &adminv1.ListModulesResponse{Modules: make([]*adminv1.Module, 0, len(providers))}
+	for _, name := range providers {
+		m := &adminv1.Module{Name: name}
+		infos, _ := a.registry.Describe(name)
+		for _, info := range infos {
+			m.Commands = append(m.Commands, &adminv1.CommandInfo{
+				Name:     info.Name,
+				Summary:  info.Summary,
+				Usage:    info.Usage,
+				Mutating: info.Mutating,
+			})
+		}
+		out.Modules = append(out.Modules, m)
+	}
+	_ = a.writeAudit(ctx, c.Subject, "grpc:modules", "ok", map[string]string{"auth_method": c.AuthMethod}, c.RequestID)
+	return out, nil
+}
+
+// StreamJob присылает состояние задачи при каждом изменении. С run_now задача
+// запускается, и поток завершается после окончания этого запуска.
+func (a *Adapter) StreamJob(req *adminv1.StreamJobRequest, stream adminv1.Admin_StreamJobServer) error {
+	ctx := stream.Context()
+	c := callerFromContext(ctx)
+	if a.cfg.Schedules == nil {
+		return status.Error(codes.Unimplemented, "not_supported")
+	}
+	payload := map[string]string{"job": req.Job, "auth_method": c.AuthMethod}
+	if err := a.authorize(ctx, "grpc:stream_job", core.Action{Module: "schedules", Command: "read"}, payload); err != nil {
+		return err
+	}
+	before, ok := a.jobStatus(req.Job)
+	if !ok {
+		return status.Error(codes.NotFound, "job_not_found")
+	}
+	// Запуск и запись в историю до RunNow: новый запуск завершен, когда
+	// вырос счетчик, а история получила запись с другим ID.
+	var prevRun int64
+	if last, err := a.lastRun(ctx, req.Job); err != nil {
+		return status.Error(codes.Internal, "query_failed")
+	} else if last != nil {
+		prevRun = last.ID
+	}
+	if req.RunNow {
+		if err := a.authorize(ctx, "grpc:schedule_run", core.Action{Module: "schedules", Command: "run"}, map[string]string{"job": req.Job}); err != nil {
+			return err
+		}
+		err := a.cfg.Schedules.RunNow(ctx, req.Job, c.Subject)
+		runStatus, code := "ok", codes.OK
+		switch {
+		case err == nil:
+		case errors.Is(err, core.ErrJobRunning):
+			runStatus, code = "error", codes.FailedPrecondition
+		case errors.Is(err, core.ErrSchedulerStopped):
+			runStatus, code = "error", codes.Unavailable
+		default:
+			runStatus, code = "error", codes.Internal
+		}
+		_ = a.writeAudit(ctx, c.Subject, "grpc:schedule_run", runStatus, map[string]string{"job": req.Job, "auth_method": c.AuthMethod}, c.RequestID)
+		if err != nil {
+			return status.Error(code, err.Error())
+		}
+	}
+	_ = a.writeAudit(ctx, c.Subject, "grpc:stream_job", "ok", payload, c.RequestID)
+
+	a.mu.Lock()
+	closing := a.closing
+	a.mu.Unlock()
+	ticker := time.NewTicker(a.cfg.StreamInterval)
+	defer ticker.Stop()
+	var (
+		sent *adminv1.JobEvent
+		// finished - новый запуск завершен, ждем его запись в истории
+		// (без хранилища истории ее не будет).
+		finished bool
+	)
+	for {
+		st, ok := a.jobStatus(req.Job)
+		if !ok {
+			return status.Error(codes.NotFound, "job_not_found")
+		}
+		last, err := a.lastRun(ctx, req.Job)
+		if err != nil {
+			return status.Error(codes.Internal, "query_failed")
+		}
+		ev := newJobEvent(st, last)
+		if sent == nil || !jobEventsEqual(sent, ev) {
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+			sent = ev
+		}
+		if req.RunNow && st.Runs > before.Runs && !st.Running {
+			if finished || (last != nil && last.ID != prevRun) {
+				return nil
+			}
+			finished = true
+		}
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-closing:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-ticker.C:
+		}
+	}
+}
+
+// QueryAudit возвращает страницу аудита; фильтры как у GET /v1/audit.
+func (a *Adapter) QueryAudit(ctx context.Context, req *adminv1.QueryAuditRequest) (*adminv1.QueryAuditResponse, error) {
+	c := callerFromContext(ctx)
+	if err := a.authorize(ctx, "grpc:audit_query", core.Action{Module: "audit", Command: "read"}, map[string]string{}); err != nil {
+		return nil, err
+	}
+	q := storage.AuditQuery{
+		Subject:   req.Subject,
+		Action:    req.Action,
+		Source:    req.Source,
+		Status:    req.Status,
+		RequestID: req.RequestId,
+		Limit:     int(req.Limit),
+		Cursor:    req.Cursor,
+	}
+	if q.Limit <= 0 {
+		q.Limit = storage.DefaultAuditPageSize
+	}
+	if req.From != nil {
+		q.From = req.From.AsTime()
+	}
+	if req.To != nil {
+		q.To = req.To.AsTime()
+	}
+
+	var (
+		events     []storage.AuditEvent
+		nextCursor string
+		err        error
+	)
+	if pager, ok := a.store.(storage.AuditPager); ok {
+		var page storage.AuditPage
+		page, err = pager.QueryAuditPage(ctx, q)
+		events, nextCursor = page.Events, page.NextCursor
+	} else if q.Cursor != "" {
+		return nil, status.Error(codes.Unimplemented, "not_supported")
+	} else {
+		events, err = a.store.QueryAudit(ctx, q)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			return nil, status.Error(codes.InvalidArgument, "bad_cursor")
+		}
+		code, msg := codes.Internal, "query_failed"
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			code, msg = codes.DeadlineExceeded, "request_timeout"
+		}
+		_ = a.writeAudit(ctx, c.Subject, "grpc:audit_query", "error", map[string]string{"error_code": msg, "auth_method": c.AuthMethod}, c.RequestID)
+		return nil, status.Error(code, msg)
+	}
+
+	out := &adminv1.QueryAuditResponse{RequestId: c.RequestID, NextCursor: nextCursor, Events: make([]*adminv1.AuditEvent, 0, len(events))}
+	for _, ev := range events {
+		out.Events = append(out.Events, &adminv1.AuditEvent{
+			Id:        ev.ID,
+			Subject:   ev.Subject,
+			Action:    ev.Action,
+			Source:    ev.Source,
+			Status:    ev.Status,
+			RequestId: ev.RequestID,
+			Payload:   ev.Payload,
+			Ts:        timestamppb.New(ev.TS),
+			PrevHash:  ev.PrevHash,
+			Hash:      ev.Hash,
+		})
+	}
+	_ = a.writeAudit(ctx, c.Subject, "grpc:audit_query", "ok", map[string]string{"items": strconv.Itoa(len(events)), "auth_method": c.AuthMethod}, c.RequestID)
+	return out, nil
+}
+
+func (a *Adapter) jobStatus(name string) (core.JobStatus, bool) {
+	for _, job := range a.cfg.Schedules.Jobs() {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return core.JobStatus{}, false
+}
+
+func (a *Adapter) lastRun(ctx context.Context, name string) (*storage.JobRun, error) {
+	runs, err := a.cfg.Schedules.Runs(ctx, name, 1)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+func newJobEvent(st core.JobStatus, last *storage.JobRun) *adminv1.JobEvent {
+	ev := &adminv1.JobEvent{
+		Job:       st.Name,
+		Schedule:  st.Schedule,
+		Running:   st.Running,
+		Paused:    st.Paused,
+		Runs:      st.Runs,
+		Failures:  st.Failures,
+		LastError: st.LastError,
+	}
+	if st.LastRun != nil {
+		ev.LastRun = timestamppb.New(*st.LastRun)
+	}
+	if st.NextRun != nil {
+		ev.NextRun = timestamppb.New(*st.NextRun)
+	}
+	if last != nil {
+		ev.LastResult = &adminv1.JobRun{
+			Id:         last.ID,
+			Trigger:    last.Trigger,
+			StartedAt:  timestamppb.New(last.StartedAt),
+			FinishedAt: timestamppb.New(last.FinishedAt),
+			Status:     last.Status,
+			Error:      last.Error,
+		}
+	}
+	return ev
+}
+
+// jobEventsEqual сравнивает состояние без next_run: его сдвиг сам по себе не
+// повод для сообщения.
+func jobEventsEqual(x, y *adminv1.JobEvent) bool {
+	return x.Running == y.Running && x.Paused == y.Paused && x.Runs == y.Runs &&
+		x.Failures == y.Failures && x.LastError == y.LastError &&
+		x.GetLastResult().GetId() == y.GetLastResult().GetId()
+}
+
+// toValue переводит данные ответа в google.protobuf.Value через JSON, как их
+// видит REST API.
+func toValue(data interface{}) (*structpb.Value, error) {
+	if data == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+	return structpb.NewValue(v)
+}
@@ -0,0 +1,147 @@
+package mattermost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/render"
+	"goadmin/internal/transports/common"
+)
+
+// Adapter принимает slash-команды и outgoing webhooks Mattermost (и
+// совместимые slash-команды Slack).
+type Adapter struct {
+	svc    *common.Service
+	cfg    Config
+	http   *http.Client
+	mu     sync.Mutex
+	runCtx context.Context
+	cancel context.CancelFunc
+	server *http.Server
+	wg     sync.WaitGroup
+	// failed получает ошибку HTTP-сервера после Start (core.FailureNotifier).
+	failed chan error
+	// format - разметка ответов на команды.
+	format render.Format
+}
+
+// NewAdapter создает Mattermost адаптер; hc отправляет отложенные ответы
+// (nil - клиент по умолчанию).
+func NewAdapter(registry *core.Registry, authorizer core.Authorizer, limiter *common.RateLimiter, audit common.AuditSink, cfg Config, hc *http.Client) *Adapter {
+	if hc == nil {
+		hc = &http.Client{Timeout: sendTimeout}
+	}
+	return &Adapter{
+		svc: &common.Service{
+			Source:      "mattermost",
+			Registry:    registry,
+			Authorizer:  authorizer,
+			RateLimiter: limiter,
+			AuditSink:   audit,
+		},
+		cfg:    cfg.withDefaults(),
+		http:   hc,
+		failed: make(chan error, 1),
+		format: render.Markdown,
+	}
+}
+
+func (a *Adapter) Name() string { return "mattermost" }
+
+// Start запускает прием команд; с ListenAddr - на собственном HTTP-сервере.
+func (a *Adapter) Start(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.runCtx != nil {
+		return nil
+	}
+	a.runCtx, a.cancel = context.WithCancel(ctx)
+	if a.cfg.ListenAddr != "" {
+		ln, err := net.Listen("tcp", a.cfg.ListenAddr)
+		if err != nil {
+			a.cancel()
+			a.cancel, a.runCtx = nil, nil
+			return fmt.Errorf("mattermost listen: %w", err)
+		}
+		srv := &http.Server{
+			Handler:           a.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+		}
+		a.server = srv
+		go func() {
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("mattermost server failed", "addr", ln.Addr().String(), "err", err)
+				select {
+				case a.failed <- err:
+				default:
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// Stop закрывает сервер и ждет исполняемые команды (не дольше ctx).
+func (a *Adapter) Stop(ctx context.Context) error {
+	a.mu.Lock()
+	srv, cancel := a.server, a.cancel
+	a.server, a.cancel, a.runCtx = nil, nil, nil
+	a.mu.Unlock()
+	var err error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+	if cancel != nil {
+		cancel()
+	}
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return err
+}
+
+// Failed сообщает об ошибке HTTP-сервера после Start (core.FailureNotifier).
+func (a *Adapter) Failed() <-chan error { return a.failed }
+
+// RequireAudit включает fail-closed режим: команда не выполняется без сохраненного аудита.
+func (a *Adapter) RequireAudit(required bool) {
+	a.svc.AuditRequired = required
+}
+
+// SetRedactor задает правила скрытия секретов в аудите и ответах.
+func (a *Adapter) SetRedactor(r *redact.Redactor) {
+	a.svc.Redactor = r
+}
+
+// SetAliases задает короткие команды, например {"alerts": "alerts list"}.
+func (a *Adapter) SetAliases(aliases map[string]string) {
+	a.svc.Aliases = aliases
+}
+
+// SetRenderer задает оформление ответов: шаблоны модулей и разметку
+// (render.Markdown или render.Plain).
+func (a *Adapter) SetRenderer(r *render.Renderer, f render.Format) {
+	a.svc.Renderer = r
+	a.format = f
+}
+
+// HandleCommand исполняет команду пользователя userID через core.
+func (a *Adapter) HandleCommand(ctx context.Context, userID, text string) (core.Response, error) {
+	return a.svc.ExecuteText(ctx, a.subject(userID), text)
+}
@@ -0,0 +1,348 @@
+package mattermost
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"goadmin/internal/render"
+	"goadmin/internal/transports/common"
+)
+
+// Заголовки подписи Slack (signing secret).
+const (
+	HeaderSlackSignature = "X-Slack-Signature"
+	HeaderSlackTimestamp = "X-Slack-Request-Timestamp"
+)
+
+// maxBodyBytes - предел размера входящего запроса.
+const maxBodyBytes = 64 << 10
+
+// maxMessageLen - предел длины ответа (Mattermost - 16383 символа).
+const maxMessageLen = 16000
+
+// sendTimeout - срок отправки отложенного ответа в response_url.
+const sendTimeout = 10 * time.Second
+
+// Значения response_type slash-команды.
+const (
+	Ephemeral = "ephemeral"
+	InChannel = "in_channel"
+)
+
+// Config - параметры приема slash-команд и outgoing webhooks.
+type Config struct {
+	// ListenAddr - адрес собственного HTTP-сервера; пусто - только Handler().
+	ListenAddr string
+	// Path - путь приема (по умолчанию /mattermost/command).
+	Path string
+	// Tokens - токены проверки slash-команд и outgoing webhooks Mattermost
+	// (и legacy verification token Slack).
+	Tokens [][]byte
+	// SigningSecret - signing secret Slack; запрос с X-Slack-Signature
+	// проверяется HMAC-SHA256.
+	SigningSecret []byte
+	// MaxSkew - допустимое расхождение X-Slack-Request-Timestamp (по умолчанию 5m).
+	MaxSkew time.Duration
+	// Command - имя slash-команды, текст которой - "module command args"
+	// (по умолчанию /goadmin); другие slash-команды считаются модулем.
+	Command string
+	// Users сопоставляет ID пользователя рабочего пространства субъекту
+	// авторизации; без записи субъект - сам ID.
+	Users map[string]string
+	// Teams ограничивает team_id; пусто - любые.
+	Teams []string
+	// ImmediateTimeout - сколько ждать результата для ответа в теле запроса;
+	// дольше - подтверждение сразу и результат в response_url (по умолчанию 2s).
+	ImmediateTimeout time.Duration
+	// ResponseType - ephemeral (видит только автор) или in_channel.
+	ResponseType string
+	// ResponseHosts ограничивает хосты response_url; пусто - любые.
+	ResponseHosts []string
+}
+
+func (c Config) withDefaults() Config {
+	if c.Path == "" {
+		c.Path = "/mattermost/command"
+	}
+	if c.MaxSkew <= 0 {
+		c.MaxSkew = 5 * time.Minute
+	}
+	if c.Command == "" {
+		c.Command = "/goadmin"
+	}
+	if c.ImmediateTimeout <= 0 {
+		c.ImmediateTimeout = 2 * time.Second
+	}
+	if c.ResponseType != InChannel {
+		c.ResponseType = Ephemeral
+	}
+	return c
+}
+
+// Payload - slash-команда или outgoing webhook (form или JSON); у outgoing
+// webhook есть TriggerWord и нет ResponseURL.
+type Payload struct {
+	Token       string `json:"token"`
+	TeamID      string `json:"team_id"`
+	ChannelID   string `json:"channel_id"`
+	UserID      string `json:"user_id"`
+	UserName    string `json:"user_name"`
+	Command     string `json:"command"`
+	Text        string `json:"text"`
+	TriggerWord string `json:"trigger_word"`
+	ResponseURL string `json:"response_url"`
+}
+
+// reply - тело ответа Mattermost/Slack.
+type reply struct {
+	ResponseType string `json:"response_type,omitempty"`
+	Text         string `json:"text"`
+}
+
+var (
+	errBadToken     = errors.New("mattermost: bad token")
+	errBadSignature = errors.New("mattermost: bad signature")
+	errStaleRequest = errors.New("mattermost: request timestamp outside allowed skew")
+	errForeignTeam  = errors.New("mattermost: team is not allowed")
+	errResponseURL  = errors.New("mattermost: response_url is not allowed")
+)
+
+// Handler возвращает HTTP-обработчик команд.
+func (a *Adapter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+a.cfg.Path, a.handleCommand)
+	return mux
+}
+
+func (a *Adapter) handleCommand(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+	if err != nil || len(body) > maxBodyBytes {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	p, err := parsePayload(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if err := a.verify(r.Header, body, p.Token, time.Now()); err != nil {
+		slog.Warn("mattermost request rejected", "err", err, "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if err := a.checkRequest(p); err != nil {
+		slog.Warn("mattermost request rejected", "err", err, "team_id", p.TeamID, "user_id", p.UserID)
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	a.mu.Lock()
+	ctx := a.runCtx
+	if ctx != nil {
+		a.wg.Add(1)
+	}
+	a.mu.Unlock()
+	if ctx == nil {
+		http.Error(w, "not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	text := a.commandText(p)
+	done := make(chan common.Outgoing)
+	late := make(chan struct{})
+	go func() {
+		defer a.wg.Done()
+		out := a.svc.Reply(ctx, p.ChannelID, a.subject(p.UserID), text, a.target())
+		select {
+		case done <- out:
+		case <-late:
+			if p.ResponseURL != "" && ctx.Err() == nil {
+				a.respond(ctx, p.ResponseURL, out)
+			}
+		}
+	}()
+	// Без response_url (outgoing webhook) ответ возможен только в теле.
+	var timeout <-chan time.Time
+	if p.ResponseURL != "" {
+		t := time.NewTimer(a.cfg.ImmediateTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case out := <-done:
+		a.writeReply(w, a.responseType(p), out.Text)
+	case <-timeout:
+		close(late)
+		a.writeReply(w, Ephemeral, render.Escape(a.format, "Running "+strings.TrimSpace(text)+"…"))
+	case <-r.Context().Done():
+		close(late)
+	}
+}
+
+// parsePayload разбирает form (slash-команды, Slack) или JSON (outgoing webhook).
+func parsePayload(contentType string, body []byte) (Payload, error) {
+	var p Payload
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" {
+		if err := json.NewDecoder(bytes.NewReader(body)).Decode(&p); err != nil {
+			return Payload{}, fmt.Errorf("decode payload: %w", err)
+		}
+		return p, nil
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return Payload{}, fmt.Errorf("decode payload: %w", err)
+	}
+	return Payload{
+		Token:       form.Get("token"),
+		TeamID:      form.Get("team_id"),
+		ChannelID:   form.Get("channel_id"),
+		UserID:      form.Get("user_id"),
+		UserName:    form.Get("user_name"),
+		Command:     form.Get("command"),
+		Text:        form.Get("text"),
+		TriggerWord: form.Get("trigger_word"),
+		ResponseURL: form.Get("response_url"),
+	}, nil
+}
+
+// verify проверяет подпись Slack, если она есть и задан signing secret,
+// иначе токен запроса.
+func (a *Adapter) verify(h http.Header, body []byte, token string, now time.Time) error {
+	if sig := h.Get(HeaderSlackSignature); sig != "" && len(a.cfg.SigningSecret) > 0 {
+		ts, err := strconv.ParseInt(h.Get(HeaderSlackTimestamp), 10, 64)
+		if err != nil {
+			return errBadSignature
+		}
+		if d := now.Sub(time.Unix(ts, 0)); d > a.cfg.MaxSkew || d < -a.cfg.MaxSkew {
+			return errStaleRequest
+		}
+		if !hmac.Equal([]byte(sig), []byte(Sign(a.cfg.SigningSecret, ts, body))) {
+			return errBadSignature
+		}
+		return nil
+	}
+	if token != "" {
+		for _, t := range a.cfg.Tokens {
+			if subtle.ConstantTimeCompare([]byte(token), t) == 1 {
+				return nil
+			}
+		}
+	}
+	return errBadToken
+}
+
+// Sign - значение X-Slack-Signature для тела body с меткой времени ts.
+func Sign(secret []byte, ts int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = fmt.Fprintf(mac, "v0:%d:", ts)
+	_, _ = mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkRequest проверяет команду, team_id и response_url.
+func (a *Adapter) checkRequest(p Payload) error {
+	if p.UserID == "" {
+		return errBadToken
+	}
+	if len(a.cfg.Teams) > 0 && !contains(a.cfg.Teams, p.TeamID) {
+		return errForeignTeam
+	}
+	if p.ResponseURL == "" {
+		return nil
+	}
+	u, err := url.Parse(p.ResponseURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errResponseURL
+	}
+	if len(a.cfg.ResponseHosts) > 0 && !contains(a.cfg.ResponseHosts, u.Hostname()) {
+		return errResponseURL
+	}
+	return nil
+}
+
+// commandText - текст для общего пайплайна: "/goadmin host status" и
+// outgoing webhook "!goadmin host status" дают "host status", а slash-команда
+// /host - "/host status".
+func (a *Adapter) commandText(p Payload) string {
+	text := strings.TrimSpace(p.Text)
+	switch {
+	case p.TriggerWord != "":
+		text = strings.TrimSpace(strings.TrimPrefix(text, p.TriggerWord))
+	case p.Command != "" && p.Command != a.cfg.Command:
+		text = p.Command + " " + text
+	}
+	return text
+}
+
+func (a *Adapter) subject(userID string) string {
+	if s, ok := a.cfg.Users[userID]; ok {
+		return s
+	}
+	return userID
+}
+
+func (a *Adapter) target() render.Target {
+	return render.Target{Format: a.format, Limit: maxMessageLen}
+}
+
+// responseType - видимость ответа; ответ outgoing webhook всегда в канал.
+func (a *Adapter) responseType(p Payload) string {
+	if p.TriggerWord != "" {
+		return ""
+	}
+	return a.cfg.ResponseType
+}
+
+func (a *Adapter) writeReply(w http.ResponseWriter, responseType, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(reply{ResponseType: responseType, Text: text})
+}
+
+// respond отправляет отложенный результат в response_url; готовый результат
+// доставляется и во время Stop (Stop ждет его не дольше своего ctx).
+func (a *Adapter) respond(ctx context.Context, responseURL string, out common.Outgoing) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), sendTimeout)
+	defer cancel()
+	raw, _ := json.Marshal(reply{ResponseType: a.cfg.ResponseType, Text: out.Text})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(raw))
+	if err != nil {
+		slog.Warn("mattermost delayed response failed", "err", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := a.http.Do(req)
+	if err != nil {
+		// Ошибка может содержать URL с одноразовым токеном ответа.
+		slog.Warn("mattermost delayed response failed", "err", errors.Unwrap(err))
+		return
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode >= 300 {
+		slog.Warn("mattermost delayed response rejected", "status", resp.StatusCode)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
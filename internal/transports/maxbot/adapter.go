@@ -2,9 +2,18 @@ package maxbot
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
 	"sync"
+	"time"
 
 	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/render"
+	"goadmin/internal/storage"
 	"goadmin/internal/transports/common"
 )
 
@@ -13,6 +22,18 @@ type Adapter struct {
 	svc     *common.Service
 	mu      sync.Mutex
 	running bool
+	cancel  context.CancelFunc
+	// client, hook и replay заданы EnableWebhook; runCtx живет от Start до Stop.
+	client *Client
+	hook   WebhookConfig
+	replay *replayGuard
+	runCtx context.Context
+	server *http.Server
+	wg     sync.WaitGroup
+	// failed получает ошибку HTTP-сервера после Start (core.FailureNotifier).
+	failed chan error
+	// format - разметка ответов на команды.
+	format render.Format
 }
 
 // NewAdapter создает MaxBot адаптер.
@@ -25,6 +46,7 @@ func NewAdapter(registry *core.Registry, authorizer core.Authorizer, limiter *co
 			RateLimiter: limiter,
 			AuditSink:   audit,
 		},
+		failed: make(chan error, 1),
 	}
 }
 
@@ -32,19 +54,141 @@ func (a *Adapter) Name() string { return "maxbot" }
 
 func (a *Adapter) Start(ctx context.Context) error {
 	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return nil
+	}
 	a.running = true
-	a.mu.Unlock()
+	if a.svc.Outbox == nil && a.replay == nil {
+		return nil
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	a.cancel = cancel
+	if a.svc.Outbox != nil {
+		go a.svc.Outbox.Run(runCtx)
+	}
+	if a.replay == nil {
+		return nil
+	}
+	a.runCtx = runCtx
+	if a.hook.ListenAddr != "" {
+		ln, err := net.Listen("tcp", a.hook.ListenAddr)
+		if err != nil {
+			a.cancel()
+			a.cancel, a.runCtx, a.running = nil, nil, false
+			return fmt.Errorf("maxbot webhook listen: %w", err)
+		}
+		srv := &http.Server{
+			Handler:           a.Handler(),
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+		}
+		a.server = srv
+		go func() {
+			if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("maxbot webhook server failed", "addr", ln.Addr().String(), "err", err)
+				select {
+				case a.failed <- err:
+				default:
+				}
+			}
+		}()
+	}
 	return nil
 }
 
+// Stop закрывает webhook-сервер и ждет исполняемые команды (не дольше ctx).
 func (a *Adapter) Stop(ctx context.Context) error {
 	a.mu.Lock()
 	a.running = false
+	srv := a.server
+	a.server = nil
+	a.runCtx = nil
 	a.mu.Unlock()
-	return nil
+	var err error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+	a.mu.Lock()
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return err
+}
+
+// Failed сообщает об ошибке HTTP-сервера после Start (core.FailureNotifier).
+func (a *Adapter) Failed() <-chan error { return a.failed }
+
+// RequireAudit включает fail-closed режим: команда не выполняется без сохраненного аудита.
+func (a *Adapter) RequireAudit(required bool) {
+	a.svc.AuditRequired = required
+}
+
+// SetRedactor задает правила скрытия секретов в аудите и ответах.
+func (a *Adapter) SetRedactor(r *redact.Redactor) {
+	a.svc.Redactor = r
+}
+
+// SetAliases задает короткие чат-команды, например {"alerts": "alerts list"}.
+func (a *Adapter) SetAliases(aliases map[string]string) {
+	a.svc.Aliases = aliases
+}
+
+// SetRenderer задает оформление ответов: шаблоны модулей и разметку
+// (render.HTML, render.Markdown или render.Plain).
+func (a *Adapter) SetRenderer(r *render.Renderer, f render.Format) {
+	a.svc.Renderer = r
+	a.format = f
+}
+
+// EnableInteractive включает /menu, выбор аргументов кнопками и
+// подтверждение изменяющих команд; токены кнопок живут ttl.
+func (a *Adapter) EnableInteractive(ttl time.Duration) {
+	a.svc.Callbacks = common.NewCallbacks(ttl)
+}
+
+// SetSubscriptions включает чат-команды подписок; topics ограничивает темы.
+func (a *Adapter) SetSubscriptions(store storage.SubscriptionStore, topics []string) {
+	a.svc.Subscriptions = store
+	a.svc.Topics = topics
+}
+
+// SetSender включает исходящие уведомления через sender; вызывается до Start.
+func (a *Adapter) SetSender(sender common.Sender, cfg common.OutboxConfig) {
+	a.svc.Outbox = common.NewOutbox(sender, cfg)
+}
+
+// EnableWebhook включает прием команд через входящий webhook; ответы
+// отправляются клиентом client (nil - без ответов). Вызывается до Start.
+func (a *Adapter) EnableWebhook(client *Client, cfg WebhookConfig) {
+	a.client = client
+	a.hook = cfg.withDefaults()
+	a.replay = &replayGuard{window: a.hook.ReplayWindow, seen: map[string]time.Time{}}
+}
+
+// Notify доставляет уведомление подписанным чатам (core.Notifier).
+func (a *Adapter) Notify(ctx context.Context, n core.Notification) error {
+	return a.svc.Notify(ctx, n)
 }
 
 // HandleCommand принимает команду в чат-формате и исполняет через core.
 func (a *Adapter) HandleCommand(ctx context.Context, userID, text string) (core.Response, error) {
 	return a.svc.ExecuteText(ctx, userID, text)
 }
+
+// HandleMessage исполняет команду пользователя userID из чата chatID (группы).
+func (a *Adapter) HandleMessage(ctx context.Context, chatID, userID, text string) (core.Response, error) {
+	return a.svc.ExecuteChat(ctx, chatID, userID, text)
+}
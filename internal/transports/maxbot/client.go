@@ -0,0 +1,162 @@
+package maxbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"goadmin/internal/transports/common"
+)
+
+// DefaultAPIURL - адрес MaxBot API.
+const DefaultAPIURL = "https://platform-api.max.ru"
+
+// maxMessageLen - предел длины текста сообщения.
+const maxMessageLen = 4000
+
+// sendTimeout - срок запроса, если у контекста его нет.
+const sendTimeout = 10 * time.Second
+
+// Client - исходящий клиент MaxBot API: отправка сообщений в чат.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient создает клиент; пустой baseURL - DefaultAPIURL.
+func NewClient(baseURL, token string, hc *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	if hc == nil {
+		hc = &http.Client{Timeout: sendTimeout}
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: token, http: hc}
+}
+
+// APIError - не-2xx ответ MaxBot API.
+type APIError struct {
+	Path    string
+	Status  int
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("maxbot %s: status %d: %s %s", e.Path, e.Status, e.Code, e.Message)
+}
+
+// SendMessage отправляет текст в чат chatID (common.Sender); длинный текст
+// делится на несколько сообщений. Ответы 4xx, кроме 429, считаются
+// окончательными (common.ErrPermanent). Токен передается заголовком и не
+// попадает в текст ошибок.
+func (c *Client) SendMessage(ctx context.Context, chatID, text string) error {
+	return c.SendFormatted(ctx, chatID, text, "", nil)
+}
+
+// SendFormatted отправляет текст с разметкой format (markdown, html; пусто -
+// без разметки) и inline-клавиатурой buttons. Размеченный текст должен
+// укладываться в одно сообщение; клавиатура прикрепляется к последней части.
+func (c *Client) SendFormatted(ctx context.Context, chatID, text, format string, buttons [][]common.Button) error {
+	ctx, cancel := withDeadline(ctx)
+	defer cancel()
+	parts := []string{text}
+	if format == "" {
+		parts = common.SplitText(text, maxMessageLen)
+	}
+	for i, part := range parts {
+		msg := map[string]interface{}{"text": part}
+		if format != "" {
+			msg["format"] = format
+		}
+		if len(buttons) > 0 && i == len(parts)-1 {
+			msg["attachments"] = []interface{}{inlineKeyboard(buttons)}
+		}
+		if err := c.post(ctx, "/messages", url.Values{"chat_id": {chatID}}, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnswerCallback подтверждает нажатие кнопки; text - всплывающее уведомление.
+func (c *Client) AnswerCallback(ctx context.Context, callbackID, text string) error {
+	ctx, cancel := withDeadline(ctx)
+	defer cancel()
+	if text == "" {
+		text = "ok"
+	}
+	return c.post(ctx, "/answers", url.Values{"callback_id": {callbackID}}, map[string]string{"notification": text})
+}
+
+func withDeadline(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, sendTimeout)
+}
+
+type callbackButton struct {
+	Type    string `json:"type"`
+	Text    string `json:"text"`
+	Payload string `json:"payload"`
+}
+
+func inlineKeyboard(buttons [][]common.Button) map[string]interface{} {
+	rows := make([][]callbackButton, 0, len(buttons))
+	for _, row := range buttons {
+		r := make([]callbackButton, 0, len(row))
+		for _, b := range row {
+			r = append(r, callbackButton{Type: "callback", Text: b.Text, Payload: b.Data})
+		}
+		rows = append(rows, r)
+	}
+	return map[string]interface{}{"type": "inline_keyboard", "payload": map[string]interface{}{"buttons": rows}}
+}
+
+// post выполняет запрос к API; ответы 4xx, кроме 429, - common.ErrPermanent.
+func (c *Client) post(ctx context.Context, path string, query url.Values, payload interface{}) error {
+	err := c.do(ctx, path, query, payload)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status >= 400 && apiErr.Status < 500 && apiErr.Status != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", common.ErrPermanent, err)
+	}
+	return err
+}
+
+func (c *Client) do(ctx context.Context, path string, query url.Values, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("maxbot %s: %w", path, err)
+	}
+	endpoint := c.baseURL + path + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("maxbot %s: %w", path, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.token)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("maxbot %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+		return nil
+	}
+	var res struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&res)
+	return &APIError{Path: path, Status: resp.StatusCode, Code: res.Code, Message: res.Message}
+}
@@ -0,0 +1,236 @@
+package maxbot
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"goadmin/internal/render"
+	"goadmin/internal/transports/common"
+)
+
+// HeaderSecret - заголовок с секретом, заданным при подписке на webhook.
+const HeaderSecret = "X-Max-Bot-Api-Secret"
+
+// maxUpdateBytes - предел размера тела webhook.
+const maxUpdateBytes = 1 << 20
+
+// WebhookConfig - параметры входящего webhook.
+type WebhookConfig struct {
+	// ListenAddr - адрес собственного HTTP-сервера; пусто - только Handler().
+	ListenAddr string
+	// Path - путь webhook (по умолчанию /maxbot/webhook).
+	Path string
+	// Secret - общий секрет; запросы без него отклоняются.
+	Secret []byte
+	// ReplayWindow - допустимый возраст события; повтор mid внутри окна
+	// игнорируется (по умолчанию 5m).
+	ReplayWindow time.Duration
+}
+
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	if c.Path == "" {
+		c.Path = "/maxbot/webhook"
+	}
+	if c.ReplayWindow <= 0 {
+		c.ReplayWindow = 5 * time.Minute
+	}
+	return c
+}
+
+// Update - событие webhook: message_created или message_callback (нажатие
+// кнопки, Message - сообщение с кнопкой). Timestamp - unix-время в мс.
+type Update struct {
+	UpdateType string    `json:"update_type"`
+	Timestamp  int64     `json:"timestamp"`
+	Message    *Message  `json:"message,omitempty"`
+	Callback   *Callback `json:"callback,omitempty"`
+}
+
+// Callback - нажатие inline-кнопки.
+type Callback struct {
+	CallbackID string `json:"callback_id"`
+	Payload    string `json:"payload"`
+	User       *User  `json:"user,omitempty"`
+}
+
+// Message - входящее сообщение.
+type Message struct {
+	Sender    *User       `json:"sender,omitempty"`
+	Recipient Recipient   `json:"recipient"`
+	Timestamp int64       `json:"timestamp"`
+	Body      MessageBody `json:"body"`
+}
+
+// User - отправитель сообщения.
+type User struct {
+	UserID   int64  `json:"user_id"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+	IsBot    bool   `json:"is_bot"`
+}
+
+// Recipient - чат сообщения.
+type Recipient struct {
+	ChatID   int64  `json:"chat_id"`
+	ChatType string `json:"chat_type"`
+}
+
+// MessageBody - содержимое сообщения; MID уникален для сообщения.
+type MessageBody struct {
+	MID  string `json:"mid"`
+	Text string `json:"text,omitempty"`
+}
+
+var (
+	errBadSecret    = errors.New("maxbot webhook: bad secret")
+	errStaleUpdate  = errors.New("maxbot webhook: update outside replay window")
+	errReplayUpdate = errors.New("maxbot webhook: duplicate update")
+)
+
+// replayGuard помнит mid принятых сообщений в течение окна.
+type replayGuard struct {
+	mu     sync.Mutex
+	window time.Duration
+	seen   map[string]time.Time
+}
+
+// check проверяет возраст события и отмечает mid; пустой mid не дедуплицируется.
+func (g *replayGuard) check(mid string, at, now time.Time) error {
+	if d := now.Sub(at); d > g.window || d < -g.window {
+		return errStaleUpdate
+	}
+	if mid == "" {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for k, exp := range g.seen {
+		if now.After(exp) {
+			delete(g.seen, k)
+		}
+	}
+	if _, ok := g.seen[mid]; ok {
+		return errReplayUpdate
+	}
+	g.seen[mid] = now.Add(2 * g.window)
+	return nil
+}
+
+// Handler возвращает HTTP-обработчик webhook. Запрос подтверждается сразу,
+// команда исполняется в фоне до Stop; ответ отправляется в тот же чат.
+func (a *Adapter) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+a.hook.Path, a.handleWebhook)
+	return mux
+}
+
+func (a *Adapter) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	got := []byte(r.Header.Get(HeaderSecret))
+	if len(a.hook.Secret) == 0 || subtle.ConstantTimeCompare(got, a.hook.Secret) != 1 {
+		slog.Warn("maxbot webhook rejected", "err", errBadSecret, "remote", r.RemoteAddr)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var u Update
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxUpdateBytes)).Decode(&u); err != nil {
+		http.Error(w, "bad update", http.StatusBadRequest)
+		return
+	}
+	var (
+		id     string
+		handle func(ctx context.Context)
+	)
+	switch m, cb := u.Message, u.Callback; {
+	case u.UpdateType == "message_created" && m != nil && m.Sender != nil && !m.Sender.IsBot && m.Body.Text != "":
+		id = m.Body.MID
+		handle = func(ctx context.Context) { a.handleMessage(ctx, m) }
+	case u.UpdateType == "message_callback" && cb != nil && cb.User != nil && m != nil:
+		id = "callback:" + cb.CallbackID
+		handle = func(ctx context.Context) { a.handleCallback(ctx, m, cb) }
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	// Состояние проверяется до replay-защиты: на 503 API повторит доставку,
+	// и повтор не должен быть отклонен как дубликат.
+	a.mu.Lock()
+	ctx := a.runCtx
+	if ctx != nil {
+		a.wg.Add(1)
+	}
+	a.mu.Unlock()
+	if ctx == nil {
+		http.Error(w, "not running", http.StatusServiceUnavailable)
+		return
+	}
+	// Отклоненный повтор подтверждается 200, чтобы API не доставлял его снова.
+	if err := a.replay.check(id, time.UnixMilli(u.Timestamp), time.Now()); err != nil {
+		a.wg.Done()
+		slog.Warn("maxbot update ignored", "err", err, "id", id)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	go func() {
+		defer a.wg.Done()
+		handle(ctx)
+	}()
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleMessage исполняет текст через общий пайплайн и отвечает в чат.
+func (a *Adapter) handleMessage(ctx context.Context, m *Message) {
+	chatID := strconv.FormatInt(m.Recipient.ChatID, 10)
+	out := a.svc.Reply(ctx, chatID, strconv.FormatInt(m.Sender.UserID, 10), m.Body.Text, a.target())
+	if a.client == nil || ctx.Err() != nil {
+		return
+	}
+	a.send(ctx, chatID, out)
+}
+
+// handleCallback обрабатывает нажатие кнопки под сообщением m.
+func (a *Adapter) handleCallback(ctx context.Context, m *Message, cb *Callback) {
+	chatID := strconv.FormatInt(m.Recipient.ChatID, 10)
+	out := a.svc.Callback(ctx, chatID, strconv.FormatInt(cb.User.UserID, 10), cb.Payload, a.target())
+	if a.client == nil || ctx.Err() != nil {
+		return
+	}
+	if err := a.client.AnswerCallback(ctx, cb.CallbackID, out.Notice); err != nil {
+		slog.Warn("maxbot callback answer failed", "chat_id", chatID, "err", err)
+	}
+	if out.Text != "" {
+		a.send(ctx, chatID, out)
+	}
+}
+
+func (a *Adapter) target() render.Target {
+	return render.Target{Format: a.format, Limit: maxMessageLen}
+}
+
+// send отправляет ответ с разметкой; отклоненная разметка повторяется текстом.
+func (a *Adapter) send(ctx context.Context, chatID string, out common.Outgoing) {
+	err := a.client.SendFormatted(ctx, chatID, out.Text, messageFormat(a.format), out.Buttons)
+	if errors.Is(err, common.ErrPermanent) && out.Text != out.Plain {
+		err = a.client.SendFormatted(ctx, chatID, out.Plain, "", out.Buttons)
+	}
+	if err != nil {
+		slog.Warn("maxbot reply failed", "chat_id", chatID, "err", err)
+	}
+}
+
+// messageFormat - значение format сообщения MaxBot API для разметки.
+func messageFormat(f render.Format) string {
+	switch f {
+	case render.HTML, render.Markdown:
+		return string(f)
+	default:
+		return ""
+	}
+}
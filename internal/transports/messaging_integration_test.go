@@ -1,12 +1,27 @@
 package transports
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+	"unicode/utf16"
 
 	"goadmin/internal/core"
+	"goadmin/internal/render"
+	"goadmin/internal/storage/memory"
 	"goadmin/internal/transports/common"
+	"goadmin/internal/transports/mattermost"
+	"goadmin/internal/transports/maxbot"
 	"goadmin/internal/transports/telegram"
 )
 
@@ -49,3 +64,732 @@ func TestTelegramTransportPipeline(t *testing.T) {
 		t.Fatalf("rate-limit must block second immediate command")
 	}
 }
+
+func TestTelegramNotifySubscribers(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls int
+		got   []map[string]string
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`{"ok":false,"description":"bad gateway"}`))
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got = append(got, body)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	authz := core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1001"}})
+	tr := telegram.NewAdapter(core.NewRegistry(), authz, nil, nil)
+	tr.SetSubscriptions(memory.New(), []string{"alerts"})
+	tr.SetSender(telegram.NewClient(api.URL, "TOKEN", api.Client()), common.OutboxConfig{Backoff: time.Millisecond})
+	if err := tr.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() { _ = tr.Stop(ctx) }()
+
+	if _, err := tr.HandleCommand(ctx, "1001", "/subscribe alerts warning"); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	n := core.Notification{Topic: "alerts", Severity: "critical", Title: "[FIRING] high_memory", Text: "mem_used_pct > 90", Key: "alert:1"}
+	var notifier core.Notifier = tr
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(ctx, n); err != nil {
+			t.Fatalf("notify: %v", err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		done := len(got)
+		mu.Unlock()
+		if done > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0]["chat_id"] != "1001" || got[0]["text"] != "[FIRING] high_memory\nmem_used_pct > 90" {
+		t.Fatalf("expected one retried delivery, got %d calls: %+v", calls, got)
+	}
+}
+
+// fakeBotAPI - локальный Bot API: отдает заготовленные getUpdates и
+// записывает sendMessage и answerCallbackQuery.
+type fakeBotAPI struct {
+	mu      sync.Mutex
+	offsets []int64
+	updates [][]telegram.Update
+	failGet int
+	sent    []map[string]string
+	// keyboards[i] - кнопки (текст -> callback_data) сообщения sent[i].
+	keyboards []map[string]string
+	answers   []string
+	// rejectMarkup отвечает 400 на размеченное сообщение (ошибка разбора разметки).
+	rejectMarkup func(body map[string]string) bool
+}
+
+func (f *fakeBotAPI) handler(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/botTOKEN/getUpdates":
+		var req struct {
+			Offset int64 `json:"offset"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.offsets = append(f.offsets, req.Offset)
+		if f.failGet > 0 {
+			f.failGet--
+			f.mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"internal"}`))
+			return
+		}
+		var batch []telegram.Update
+		if len(f.updates) > 0 {
+			batch, f.updates = f.updates[0], f.updates[1:]
+		}
+		f.mu.Unlock()
+		if batch == nil {
+			// Long polling: держим запрос до таймаута или отмены клиента.
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+			batch = []telegram.Update{}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": batch})
+	case "/botTOKEN/sendMessage":
+		raw, _ := io.ReadAll(r.Body)
+		var body map[string]string
+		_ = json.Unmarshal(raw, &body)
+		var markup struct {
+			ReplyMarkup struct {
+				InlineKeyboard [][]struct {
+					Text         string `json:"text"`
+					CallbackData string `json:"callback_data"`
+				} `json:"inline_keyboard"`
+			} `json:"reply_markup"`
+		}
+		_ = json.Unmarshal(raw, &markup)
+		keyboard := map[string]string{}
+		for _, row := range markup.ReplyMarkup.InlineKeyboard {
+			for _, b := range row {
+				keyboard[b.Text] = b.CallbackData
+			}
+		}
+		if body["parse_mode"] != "" && f.rejectMarkup != nil && f.rejectMarkup(body) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: can't parse entities"}`))
+			return
+		}
+		f.mu.Lock()
+		f.sent = append(f.sent, body)
+		f.keyboards = append(f.keyboards, keyboard)
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":{}}`))
+	case "/botTOKEN/answerCallbackQuery":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.mu.Lock()
+		f.answers = append(f.answers, body["text"])
+		f.mu.Unlock()
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// push добавляет пачку updates для следующего getUpdates.
+func (f *fakeBotAPI) push(updates ...telegram.Update) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, updates)
+}
+
+// button возвращает callback_data кнопки text из сообщения sent[i].
+func (f *fakeBotAPI) button(t *testing.T, i int, text string) string {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.keyboards[i][text]
+	if !ok {
+		t.Fatalf("message %d has no button %q: %+v", i, text, f.keyboards[i])
+	}
+	return data
+}
+
+func (f *fakeBotAPI) waitSent(t *testing.T, n int) []map[string]string {
+	t.Helper()
+	waitFor(t, func() bool {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return len(f.sent) >= n
+	})
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]string(nil), f.sent...)
+}
+
+func waitFor(t *testing.T, ok func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !ok() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not reached in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func textMessage(updateID, userID int64, date time.Time, text string) telegram.Update {
+	return telegram.Update{UpdateID: updateID, Message: &telegram.Message{
+		MessageID: updateID,
+		From:      &telegram.User{ID: userID},
+		Chat:      telegram.Chat{ID: userID, Type: "private"},
+		Date:      date.Unix(),
+		Text:      text,
+	}}
+}
+
+func TestTelegramLongPolling(t *testing.T) {
+	now := time.Now()
+	fake := &fakeBotAPI{failGet: 1, updates: [][]telegram.Update{{
+		textMessage(7, 1001, now, "/host@goadmin_bot status"),
+		textMessage(8, 1001, now.Add(-time.Hour), "/host status"),
+		textMessage(9, 9999, now, "/host status"),
+		{UpdateID: 10},
+	}}}
+	fake.rejectMarkup = func(body map[string]string) bool { return strings.Contains(body["text"], "denied") }
+	api := httptest.NewServer(http.HandlerFunc(fake.handler))
+	defer api.Close()
+
+	ctx := context.Background()
+	r := core.NewRegistry()
+	if err := r.Register(ctx, &testModule{}); err != nil {
+		t.Fatalf("register module: %v", err)
+	}
+	authz := core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1001"}})
+	tr := telegram.NewAdapter(r, authz, nil, nil)
+	tr.SetRenderer(nil, render.HTML)
+	tr.EnablePolling(telegram.NewClient(api.URL, "TOKEN", api.Client()), telegram.PollConfig{
+		Timeout: time.Second,
+		Backoff: time.Millisecond,
+		MaxAge:  time.Minute,
+	})
+	if err := tr.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	sent := fake.waitSent(t, 2)
+	// Следующий запрос подтверждает обработанную пачку.
+	waitFor(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return len(fake.offsets) >= 3
+	})
+	if sent[0]["chat_id"] != "1001" || sent[0]["parse_mode"] != "HTML" || sent[0]["text"] != "<b>host status</b>\nresult: ok" {
+		t.Fatalf("reply to allowed command = %+v", sent[0])
+	}
+	// Отклоненная разметка повторяется простым текстом.
+	if sent[1]["chat_id"] != "9999" || sent[1]["parse_mode"] != "" || !strings.HasPrefix(sent[1]["text"], "access_denied\n") {
+		t.Fatalf("reply to denied command = %+v", sent[1])
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := tr.Stop(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.sent) != 2 {
+		t.Fatalf("stale message must be skipped: %+v", fake.sent)
+	}
+	// Первый запрос упал и повторен с тем же offset, затем подтверждены 7..10.
+	if len(fake.offsets) < 3 || fake.offsets[0] != 0 || fake.offsets[1] != 0 || fake.offsets[2] != 11 {
+		t.Fatalf("offsets = %v", fake.offsets)
+	}
+}
+
+// restartModule - модуль с изменяющей командой для чат-меню.
+type restartModule struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (m *restartModule) Name() string                   { return "svc" }
+func (m *restartModule) Init(ctx context.Context) error { return nil }
+func (m *restartModule) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs = append(m.runs, cmd+" "+strings.Join(args, " "))
+	return core.Response{Status: "ok"}, nil
+}
+
+func (m *restartModule) Commands() []core.CommandInfo {
+	return []core.CommandInfo{{Name: "restart", Mutating: true, Args: []core.ArgInfo{{Name: "unit", Choices: []string{"nginx"}}}}}
+}
+
+func callbackQuery(updateID, userID int64, data string) telegram.Update {
+	msg := textMessage(updateID, 1001, time.Now(), "")
+	return telegram.Update{UpdateID: updateID, CallbackQuery: &telegram.CallbackQuery{
+		ID:      fmt.Sprintf("cq%d", updateID),
+		From:    &telegram.User{ID: userID},
+		Message: msg.Message,
+		Data:    data,
+	}}
+}
+
+func TestTelegramInteractiveMenu(t *testing.T) {
+	fake := &fakeBotAPI{updates: [][]telegram.Update{{textMessage(1, 1001, time.Now(), "/menu")}}}
+	api := httptest.NewServer(http.HandlerFunc(fake.handler))
+	defer api.Close()
+
+	ctx := context.Background()
+	mod := &restartModule{}
+	r := core.NewRegistry()
+	if err := r.Register(ctx, mod); err != nil {
+		t.Fatalf("register module: %v", err)
+	}
+	authz := core.NewAllowlistAuthorizer(map[string][]string{"telegram": {"1001", "1002"}})
+	tr := telegram.NewAdapter(r, authz, common.NewRateLimiter(100, 0), nil)
+	tr.EnableInteractive(time.Minute)
+	tr.EnablePolling(telegram.NewClient(api.URL, "TOKEN", api.Client()), telegram.PollConfig{
+		Timeout: time.Second,
+		Backoff: time.Millisecond,
+	})
+	if err := tr.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		_ = tr.Stop(stopCtx)
+	}()
+
+	fake.waitSent(t, 1)
+	fake.push(callbackQuery(2, 1001, fake.button(t, 0, "svc")))
+	fake.waitSent(t, 2)
+	fake.push(callbackQuery(3, 1001, fake.button(t, 1, "restart")))
+	fake.waitSent(t, 3)
+	fake.push(callbackQuery(4, 1001, fake.button(t, 2, "nginx")))
+	sent := fake.waitSent(t, 4)
+	if sent[3]["text"] != "Run /svc restart nginx?" {
+		t.Fatalf("expected confirmation, got %+v", sent[3])
+	}
+	confirm := fake.button(t, 3, "Confirm")
+	// Чужое нажатие отклоняется уведомлением, команда не исполняется.
+	fake.push(callbackQuery(5, 1002, confirm))
+	waitFor(t, func() bool {
+		fake.mu.Lock()
+		defer fake.mu.Unlock()
+		return len(fake.answers) >= 4
+	})
+	fake.push(callbackQuery(6, 1001, confirm))
+	sent = fake.waitSent(t, 5)
+	if !strings.Contains(sent[4]["text"], "svc restart") {
+		t.Fatalf("expected command result, got %+v", sent[4])
+	}
+	fake.mu.Lock()
+	answers := append([]string(nil), fake.answers...)
+	fake.mu.Unlock()
+	if answers[3] != "this button belongs to another user" {
+		t.Fatalf("answers = %q", answers)
+	}
+	mod.mu.Lock()
+	defer mod.mu.Unlock()
+	if len(mod.runs) != 1 || mod.runs[0] != "restart nginx" {
+		t.Fatalf("runs = %v", mod.runs)
+	}
+}
+
+func TestTelegramSendMessageSplits(t *testing.T) {
+	fake := &fakeBotAPI{}
+	api := httptest.NewServer(http.HandlerFunc(fake.handler))
+	defer api.Close()
+
+	line := strings.Repeat("я", 99) + "😀\n" // 102 UTF-16 символа с переводом строки
+	text := strings.Repeat(line, 100)
+	client := telegram.NewClient(api.URL, "TOKEN", api.Client())
+	if err := client.SendMessage(context.Background(), "1001", text); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	var joined []string
+	for _, m := range fake.sent {
+		if n := len(utf16.Encode([]rune(m["text"]))); n > 4096 {
+			t.Fatalf("part of %d UTF-16 units", n)
+		}
+		if !strings.HasSuffix(m["text"], "😀") {
+			t.Fatalf("part must end on a line boundary: %q", m["text"][len(m["text"])-8:])
+		}
+		joined = append(joined, m["text"])
+	}
+	if len(fake.sent) != 3 || strings.Join(joined, "\n")+"\n" != text {
+		t.Fatalf("split into %d parts", len(fake.sent))
+	}
+}
+
+func maxUpdate(mid string, userID, chatID int64, at time.Time, text string) []byte {
+	raw, _ := json.Marshal(maxbot.Update{
+		UpdateType: "message_created",
+		Timestamp:  at.UnixMilli(),
+		Message: &maxbot.Message{
+			Sender:    &maxbot.User{UserID: userID},
+			Recipient: maxbot.Recipient{ChatID: chatID, ChatType: "dialog"},
+			Timestamp: at.UnixMilli(),
+			Body:      maxbot.MessageBody{MID: mid, Text: text},
+		},
+	})
+	return raw
+}
+
+func TestMaxBotWebhook(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []map[string]string
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" || r.Header.Get("Authorization") != "TOKEN" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":"verify.token","message":"invalid token"}`))
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		body["chat_id"] = r.URL.Query().Get("chat_id")
+		mu.Lock()
+		sent = append(sent, body)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"message":{}}`))
+	}))
+	defer api.Close()
+
+	ctx := context.Background()
+	r := core.NewRegistry()
+	if err := r.Register(ctx, &testModule{}); err != nil {
+		t.Fatalf("register module: %v", err)
+	}
+	authz := core.NewAllowlistAuthorizer(map[string][]string{"maxbot": {"2001"}})
+	mx := maxbot.NewAdapter(r, authz, nil, nil)
+	mx.EnableWebhook(maxbot.NewClient(api.URL, "TOKEN", api.Client()), maxbot.WebhookConfig{
+		Secret:       []byte("hook-secret"),
+		ReplayWindow: time.Minute,
+	})
+	if err := mx.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	hook := httptest.NewServer(mx.Handler())
+	defer hook.Close()
+
+	post := func(secret string, body []byte) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, hook.URL+"/maxbot/webhook", bytes.NewReader(body))
+		if secret != "" {
+			req.Header.Set(maxbot.HeaderSecret, secret)
+		}
+		resp, err := hook.Client().Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	now := time.Now()
+	update := maxUpdate("mid.1", 2001, 555, now, "/host status")
+	if code := post("", update); code != http.StatusUnauthorized {
+		t.Fatalf("missing secret = %d", code)
+	}
+	if code := post("wrong", update); code != http.StatusUnauthorized {
+		t.Fatalf("wrong secret = %d", code)
+	}
+	for _, body := range [][]byte{
+		update,
+		update, // повтор того же mid
+		maxUpdate("mid.2", 2001, 555, now.Add(-time.Hour), "/host status"),
+		maxUpdate("mid.3", 9999, 777, now, "/host status"),
+	} {
+		if code := post("hook-secret", body); code != http.StatusOK {
+			t.Fatalf("webhook = %d", code)
+		}
+	}
+
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(sent) >= 2
+	})
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := mx.Stop(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 {
+		t.Fatalf("replayed or stale updates executed: %+v", sent)
+	}
+	byChat := map[string]string{sent[0]["chat_id"]: sent[0]["text"], sent[1]["chat_id"]: sent[1]["text"]}
+	if byChat["555"] != "host status\nresult: ok" || !strings.HasPrefix(byChat["777"], "access_denied\n") {
+		t.Fatalf("replies = %+v", byChat)
+	}
+}
+
+func TestMaxBotWebhookCallback(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		texts   []string
+		buttons = map[string]string{}
+		answers []string
+	)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/messages":
+			var body struct {
+				Text        string `json:"text"`
+				Attachments []struct {
+					Payload struct {
+						Buttons [][]struct {
+							Text    string `json:"text"`
+							Payload string `json:"payload"`
+						} `json:"buttons"`
+					} `json:"payload"`
+				} `json:"attachments"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			texts = append(texts, body.Text)
+			for _, a := range body.Attachments {
+				for _, row := range a.Payload.Buttons {
+					for _, b := range row {
+						buttons[b.Text] = b.Payload
+					}
+				}
+			}
+		case "/answers":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			answers = append(answers, r.URL.Query().Get("callback_id")+":"+body["notification"])
+		}
+		_, _ = w.Write([]byte(`{"success":true}`))
+	}))
+	defer api.Close()
+
+	ctx := context.Background()
+	mod := &restartModule{}
+	r := core.NewRegistry()
+	if err := r.Register(ctx, mod); err != nil {
+		t.Fatalf("register module: %v", err)
+	}
+	mx := maxbot.NewAdapter(r, core.NewAllowlistAuthorizer(map[string][]string{"maxbot": {"2001"}}), nil, nil)
+	mx.EnableInteractive(time.Minute)
+	mx.EnableWebhook(maxbot.NewClient(api.URL, "TOKEN", api.Client()), maxbot.WebhookConfig{Secret: []byte("s")})
+	if err := mx.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer func() {
+		stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+		defer cancel()
+		_ = mx.Stop(stopCtx)
+	}()
+	hook := httptest.NewServer(mx.Handler())
+	defer hook.Close()
+	post := func(body []byte) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, hook.URL+"/maxbot/webhook", bytes.NewReader(body))
+		req.Header.Set(maxbot.HeaderSecret, "s")
+		resp, err := hook.Client().Do(req)
+		if err != nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("post: %v %v", resp, err)
+		}
+		resp.Body.Close()
+	}
+
+	post(maxUpdate("mid.1", 2001, 555, time.Now(), "/svc restart nginx"))
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return buttons["Confirm"] != ""
+	})
+	mu.Lock()
+	confirm := buttons["Confirm"]
+	mu.Unlock()
+	press, _ := json.Marshal(maxbot.Update{
+		UpdateType: "message_callback",
+		Timestamp:  time.Now().UnixMilli(),
+		Message:    &maxbot.Message{Recipient: maxbot.Recipient{ChatID: 555}},
+		Callback:   &maxbot.Callback{CallbackID: "cb1", Payload: confirm, User: &maxbot.User{UserID: 2001}},
+	})
+	post(press)
+	post(press) // повтор callback_id не исполняется
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(texts) >= 2 && len(answers) >= 1
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	if texts[0] != "Run /svc restart nginx?" || answers[0] != "cb1:ok" || !strings.Contains(texts[1], "svc restart") {
+		t.Fatalf("texts = %q, answers = %q", texts, answers)
+	}
+	mod.mu.Lock()
+	defer mod.mu.Unlock()
+	if len(mod.runs) != 1 {
+		t.Fatalf("runs = %v", mod.runs)
+	}
+}
+
+func TestMaxBotClientPermanentError(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"code":"verify.token","message":"invalid token"}`))
+	}))
+	defer api.Close()
+	err := maxbot.NewClient(api.URL, "SECRET-TOKEN", api.Client()).SendMessage(context.Background(), "1", "hi")
+	if !errors.Is(err, common.ErrPermanent) || strings.Contains(err.Error(), "SECRET-TOKEN") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// slowModule отвечает после закрытия release.
+type slowModule struct{ release chan struct{} }
+
+func (m *slowModule) Name() string                   { return "slow" }
+func (m *slowModule) Init(ctx context.Context) error { return nil }
+func (m *slowModule) Execute(ctx context.Context, cmd string, args []string) (core.Response, error) {
+	<-m.release
+	return core.Response{Status: "ok", Data: map[string]string{"done": cmd}}, nil
+}
+
+func TestMattermostSlashCommands(t *testing.T) {
+	delayed := make(chan map[string]string, 1)
+	responses := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		delayed <- body
+	}))
+	defer responses.Close()
+
+	ctx := context.Background()
+	slow := &slowModule{release: make(chan struct{})}
+	r := core.NewRegistry()
+	for _, m := range []core.CommandProvider{&testModule{}, slow} {
+		if err := r.Register(ctx, m); err != nil {
+			t.Fatalf("register module: %v", err)
+		}
+	}
+	authz := core.NewAllowlistAuthorizer(map[string][]string{"mattermost": {"alice", "u2"}})
+	mm := mattermost.NewAdapter(r, authz, nil, nil, mattermost.Config{
+		Tokens:           [][]byte{[]byte("cmd-token"), []byte("hook-token")},
+		SigningSecret:    []byte("slack-secret"),
+		Users:            map[string]string{"U1": "alice"},
+		Teams:            []string{"T1"},
+		ImmediateTimeout: 50 * time.Millisecond,
+	}, responses.Client())
+	if err := mm.Start(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	srv := httptest.NewServer(mm.Handler())
+	defer srv.Close()
+
+	post := func(contentType string, body []byte, header http.Header) (int, map[string]string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/mattermost/command", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatalf("post: %v", err)
+		}
+		defer resp.Body.Close()
+		var out map[string]string
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+	form := func(token, team, user, text, responseURL string) []byte {
+		return []byte(url.Values{
+			"token": {token}, "team_id": {team}, "channel_id": {"C1"}, "user_id": {user},
+			"command": {"/goadmin"}, "text": {text}, "response_url": {responseURL},
+		}.Encode())
+	}
+	const formType = "application/x-www-form-urlencoded"
+
+	code, out := post(formType, form("cmd-token", "T1", "u2", "host status", ""), nil)
+	if code != http.StatusOK || out["response_type"] != "ephemeral" || out["text"] != "**host status**\nresult: ok" {
+		t.Fatalf("slash command = %d %+v", code, out)
+	}
+	if code, _ := post(formType, form("wrong", "T1", "u2", "host status", ""), nil); code != http.StatusUnauthorized {
+		t.Fatalf("bad token = %d", code)
+	}
+	if code, _ := post(formType, form("cmd-token", "T2", "u2", "host status", ""), nil); code != http.StatusForbidden {
+		t.Fatalf("foreign team = %d", code)
+	}
+
+	// Slack: подпись вместо токена, U1 сопоставлен субъекту alice.
+	signed := form("", "T1", "U1", "host status", "")
+	ts := time.Now().Unix()
+	header := http.Header{}
+	header.Set(mattermost.HeaderSlackTimestamp, fmt.Sprint(ts))
+	header.Set(mattermost.HeaderSlackSignature, mattermost.Sign([]byte("slack-secret"), ts, signed))
+	if code, out := post(formType, signed, header); code != http.StatusOK || !strings.Contains(out["text"], "result: ok") {
+		t.Fatalf("signed slash command = %d %+v", code, out)
+	}
+	header.Set(mattermost.HeaderSlackSignature, mattermost.Sign([]byte("other"), ts, signed))
+	if code, _ := post(formType, signed, header); code != http.StatusUnauthorized {
+		t.Fatalf("bad signature = %d", code)
+	}
+	header.Set(mattermost.HeaderSlackTimestamp, fmt.Sprint(ts-3600))
+	header.Set(mattermost.HeaderSlackSignature, mattermost.Sign([]byte("slack-secret"), ts-3600, signed))
+	if code, _ := post(formType, signed, header); code != http.StatusUnauthorized {
+		t.Fatalf("stale signature = %d", code)
+	}
+
+	// Outgoing webhook: JSON, слово-триггер, ответ в канал.
+	hook, _ := json.Marshal(mattermost.Payload{Token: "hook-token", TeamID: "T1", UserID: "u2", Text: "!goadmin host status", TriggerWord: "!goadmin"})
+	if code, out := post("application/json", hook, nil); code != http.StatusOK || out["response_type"] != "" || !strings.Contains(out["text"], "result: ok") {
+		t.Fatalf("outgoing webhook = %d %+v", code, out)
+	}
+
+	// Долгая команда: подтверждение сразу, результат - в response_url.
+	code, out = post(formType, form("cmd-token", "T1", "u2", "slow run", responses.URL), nil)
+	if code != http.StatusOK || !strings.HasPrefix(out["text"], "Running slow run") {
+		t.Fatalf("slow command ack = %d %+v", code, out)
+	}
+	close(slow.release)
+	select {
+	case body := <-delayed:
+		if body["response_type"] != "ephemeral" || !strings.Contains(body["text"], "done: run") {
+			t.Fatalf("delayed response = %+v", body)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("delayed response not sent")
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := mm.Stop(stopCtx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+}
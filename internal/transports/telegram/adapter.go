@@ -2,9 +2,14 @@ package telegram
 
 import (
 	"context"
+	"fmt"
 	"sync"
+	"time"
 
 	"goadmin/internal/core"
+	"goadmin/internal/redact"
+	"goadmin/internal/render"
+	"goadmin/internal/storage"
 	"goadmin/internal/transports/common"
 )
 
@@ -13,6 +18,16 @@ type Adapter struct {
 	svc     *common.Service
 	mu      sync.Mutex
 	running bool
+	cancel  context.CancelFunc
+	// client и pollCfg заданы EnablePolling; done закрывается по выходу из poll.
+	client  *Client
+	pollCfg PollConfig
+	done    chan struct{}
+	// offset - следующий update_id для getUpdates; сохраняется между Stop и
+	// Start, чтобы обработанные обновления не исполнялись повторно.
+	offset int64
+	// format - разметка ответов на команды.
+	format render.Format
 }
 
 // NewAdapter создает Telegram адаптер.
@@ -32,19 +47,120 @@ func (a *Adapter) Name() string { return "telegram" }
 
 func (a *Adapter) Start(ctx context.Context) error {
 	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return nil
+	}
 	a.running = true
-	a.mu.Unlock()
+	if a.svc.Outbox == nil && a.client == nil {
+		return nil
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	a.cancel = cancel
+	if a.svc.Outbox != nil {
+		go a.svc.Outbox.Run(runCtx)
+	}
+	if a.client != nil {
+		a.done = make(chan struct{})
+		go a.poll(runCtx, a.done)
+	}
 	return nil
 }
 
+// Stop прерывает long polling, ждет выхода цикла (не дольше ctx) и
+// подтверждает Bot API обработанные обновления запросом getUpdates с
+// offset и нулевым timeout: иначе после рестарта процесса они пришли бы снова.
 func (a *Adapter) Stop(ctx context.Context) error {
 	a.mu.Lock()
 	a.running = false
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	done := a.done
+	a.done = nil
 	a.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	offset := a.pollOffset()
+	if offset == 0 {
+		return nil
+	}
+	if _, err := a.client.GetUpdates(ctx, offset, 0); err != nil {
+		return fmt.Errorf("telegram: confirm updates: %w", err)
+	}
 	return nil
 }
 
+func (a *Adapter) pollOffset() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.offset
+}
+
+// RequireAudit включает fail-closed режим: команда не выполняется без сохраненного аудита.
+func (a *Adapter) RequireAudit(required bool) {
+	a.svc.AuditRequired = required
+}
+
+// SetRedactor задает правила скрытия секретов в аудите и ответах.
+func (a *Adapter) SetRedactor(r *redact.Redactor) {
+	a.svc.Redactor = r
+}
+
+// SetAliases задает короткие чат-команды, например {"alerts": "alerts list"}.
+func (a *Adapter) SetAliases(aliases map[string]string) {
+	a.svc.Aliases = aliases
+}
+
+// SetRenderer задает оформление ответов: шаблоны модулей и разметку
+// (render.HTML, render.MarkdownV2 или render.Plain).
+func (a *Adapter) SetRenderer(r *render.Renderer, f render.Format) {
+	a.svc.Renderer = r
+	a.format = f
+}
+
+// EnableInteractive включает /menu, выбор аргументов кнопками и
+// подтверждение изменяющих команд; токены кнопок живут ttl.
+func (a *Adapter) EnableInteractive(ttl time.Duration) {
+	a.svc.Callbacks = common.NewCallbacks(ttl)
+}
+
+// SetSubscriptions включает чат-команды подписок; topics ограничивает темы.
+func (a *Adapter) SetSubscriptions(store storage.SubscriptionStore, topics []string) {
+	a.svc.Subscriptions = store
+	a.svc.Topics = topics
+}
+
+// SetSender включает исходящие уведомления через sender; вызывается до Start.
+func (a *Adapter) SetSender(sender common.Sender, cfg common.OutboxConfig) {
+	a.svc.Outbox = common.NewOutbox(sender, cfg)
+}
+
+// EnablePolling включает прием команд через getUpdates клиента; ответы
+// отправляются в тот же чат. Вызывается до Start.
+func (a *Adapter) EnablePolling(client *Client, cfg PollConfig) {
+	a.client = client
+	a.pollCfg = cfg.withDefaults()
+}
+
+// Notify доставляет уведомление подписанным чатам (core.Notifier).
+func (a *Adapter) Notify(ctx context.Context, n core.Notification) error {
+	return a.svc.Notify(ctx, n)
+}
+
 // HandleCommand принимает команду в чат-формате и исполняет через core.
 func (a *Adapter) HandleCommand(ctx context.Context, userID, text string) (core.Response, error) {
 	return a.svc.ExecuteText(ctx, userID, text)
 }
+
+// HandleMessage исполняет команду пользователя userID из чата chatID (группы).
+func (a *Adapter) HandleMessage(ctx context.Context, chatID, userID, text string) (core.Response, error) {
+	return a.svc.ExecuteChat(ctx, chatID, userID, text)
+}
@@ -0,0 +1,239 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"goadmin/internal/transports/common"
+)
+
+// DefaultAPIURL - адрес Telegram Bot API.
+const DefaultAPIURL = "https://api.telegram.org"
+
+// maxMessageLen - предел длины текста sendMessage в UTF-16 символах.
+const maxMessageLen = 4096
+
+// sendTimeout - срок запроса, если у контекста его нет.
+const sendTimeout = 10 * time.Second
+
+// Client - минимальный клиент Telegram Bot API: getUpdates и sendMessage.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient создает клиент; пустой baseURL - DefaultAPIURL. У клиента по
+// умолчанию нет общего таймаута: long polling держит запрос дольше обычного,
+// сроки задаются контекстом каждого запроса.
+func NewClient(baseURL, token string, hc *http.Client) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	if hc == nil {
+		hc = &http.Client{}
+	}
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), token: token, http: hc}
+}
+
+// APIError - ответ Bot API с ok=false или не-200 статусом.
+type APIError struct {
+	Method      string
+	Status      int
+	Description string
+	// RetryAfter - пауза, которую просит API при 429.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("telegram %s: status %d: %s", e.Method, e.Status, e.Description)
+}
+
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+	Parameters  struct {
+		RetryAfter int `json:"retry_after"`
+	} `json:"parameters"`
+}
+
+// Update - событие getUpdates: сообщение или нажатие inline-кнопки.
+type Update struct {
+	UpdateID      int64          `json:"update_id"`
+	Message       *Message       `json:"message,omitempty"`
+	CallbackQuery *CallbackQuery `json:"callback_query,omitempty"`
+}
+
+// CallbackQuery - нажатие inline-кнопки; Message - сообщение с кнопкой.
+type CallbackQuery struct {
+	ID      string   `json:"id"`
+	From    *User    `json:"from,omitempty"`
+	Message *Message `json:"message,omitempty"`
+	Data    string   `json:"data,omitempty"`
+}
+
+// Message - входящее сообщение; Date - unix-время отправки.
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text,omitempty"`
+}
+
+// User - отправитель сообщения.
+type User struct {
+	ID       int64  `json:"id"`
+	IsBot    bool   `json:"is_bot"`
+	Username string `json:"username,omitempty"`
+}
+
+// Chat - чат сообщения.
+type Chat struct {
+	ID   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+// GetUpdates ждет новые сообщения до timeout (long polling) и возвращает
+// события начиная с offset; offset = последний update_id + 1 подтверждает
+// обработанные.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+sendTimeout)
+	defer cancel()
+	req := map[string]interface{}{
+		"offset":          offset,
+		"timeout":         int(timeout / time.Second),
+		"allowed_updates": []string{"message", "callback_query"},
+	}
+	var updates []Update
+	if err := c.call(ctx, "getUpdates", req, &updates); err != nil {
+		return nil, err
+	}
+	return updates, nil
+}
+
+// SendMessage отправляет текст в чат; длинный текст делится на несколько
+// сообщений по границам строк. Ответы 4xx, кроме 429, считаются
+// окончательными (common.ErrPermanent). Токен не попадает в текст ошибок.
+func (c *Client) SendMessage(ctx context.Context, chatID, text string) error {
+	return c.SendFormatted(ctx, chatID, text, "", nil)
+}
+
+// SendFormatted отправляет текст с parse_mode (HTML, MarkdownV2; пусто - без
+// разметки) и inline-клавиатурой buttons. Размеченный текст должен
+// укладываться в одно сообщение; клавиатура прикрепляется к последней части.
+func (c *Client) SendFormatted(ctx context.Context, chatID, text, parseMode string, buttons [][]common.Button) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+	}
+	parts := []string{text}
+	if parseMode == "" {
+		parts = common.SplitText(text, maxMessageLen)
+	}
+	for i, part := range parts {
+		req := map[string]interface{}{"chat_id": chatID, "text": part}
+		if parseMode != "" {
+			req["parse_mode"] = parseMode
+		}
+		if len(buttons) > 0 && i == len(parts)-1 {
+			req["reply_markup"] = inlineKeyboard(buttons)
+		}
+		if err := permanent(c.call(ctx, "sendMessage", req, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AnswerCallback подтверждает нажатие кнопки; text - всплывающее уведомление.
+func (c *Client) AnswerCallback(ctx context.Context, id, text string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+	}
+	req := map[string]interface{}{"callback_query_id": id}
+	if text != "" {
+		req["text"] = text
+	}
+	return permanent(c.call(ctx, "answerCallbackQuery", req, nil))
+}
+
+type inlineButton struct {
+	Text         string `json:"text"`
+	CallbackData string `json:"callback_data"`
+}
+
+func inlineKeyboard(buttons [][]common.Button) map[string]interface{} {
+	rows := make([][]inlineButton, 0, len(buttons))
+	for _, row := range buttons {
+		r := make([]inlineButton, 0, len(row))
+		for _, b := range row {
+			r = append(r, inlineButton{Text: b.Text, CallbackData: b.Data})
+		}
+		rows = append(rows, r)
+	}
+	return map[string]interface{}{"inline_keyboard": rows}
+}
+
+// permanent помечает ответы 4xx, кроме 429, как окончательные.
+func permanent(err error) error {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Status >= 400 && apiErr.Status < 500 && apiErr.Status != http.StatusTooManyRequests {
+		return fmt.Errorf("%w: %v", common.ErrPermanent, err)
+	}
+	return err
+}
+
+// call выполняет метод Bot API и декодирует result в out (nil - не нужен).
+func (c *Client) call(ctx context.Context, method string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/bot"+c.token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("telegram %s: bad api url", method)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		// url.Error содержит адрес с токеном.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	var res apiResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&res); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("telegram %s: decode response: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK || !res.OK {
+		return &APIError{
+			Method:      method,
+			Status:      resp.StatusCode,
+			Description: res.Description,
+			RetryAfter:  time.Duration(res.Parameters.RetryAfter) * time.Second,
+		}
+	}
+	if out != nil {
+		if err := json.Unmarshal(res.Result, out); err != nil {
+			return fmt.Errorf("telegram %s: decode result: %w", method, err)
+		}
+	}
+	return nil
+}
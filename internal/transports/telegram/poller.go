@@ -0,0 +1,145 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"goadmin/internal/render"
+	"goadmin/internal/transports/common"
+)
+
+// PollConfig - параметры приема команд через getUpdates.
+type PollConfig struct {
+	// Timeout - время ожидания long polling (по умолчанию 25s).
+	Timeout time.Duration
+	// Backoff и MaxBackoff - пауза после ошибки API, удваивается до MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// MaxAge - сообщения старше не исполняются (накопились, пока бот стоял); 0 - без ограничения.
+	MaxAge time.Duration
+}
+
+func (c PollConfig) withDefaults() PollConfig {
+	if c.Timeout <= 0 {
+		c.Timeout = 25 * time.Second
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = time.Second
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = max(time.Minute, c.Backoff)
+	}
+	return c
+}
+
+// poll читает getUpdates до отмены ctx. Offset сдвигается до исполнения
+// команд пачки: упавшая команда не повторяется при следующем запросе.
+func (a *Adapter) poll(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	cfg := a.pollCfg
+	backoff := cfg.Backoff
+	for ctx.Err() == nil {
+		updates, err := a.client.GetUpdates(ctx, a.pollOffset(), cfg.Timeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			wait := backoff
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+				wait = apiErr.RetryAfter
+			}
+			slog.Warn("telegram getUpdates failed", "err", err, "retry_in", wait)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+			backoff = min(backoff*2, cfg.MaxBackoff)
+			continue
+		}
+		backoff = cfg.Backoff
+		a.mu.Lock()
+		for _, u := range updates {
+			if u.UpdateID >= a.offset {
+				a.offset = u.UpdateID + 1
+			}
+		}
+		a.mu.Unlock()
+		for _, u := range updates {
+			a.handleUpdate(ctx, u)
+		}
+	}
+}
+
+// handleUpdate исполняет текст сообщения как команду или нажатие кнопки и
+// отвечает в тот же чат.
+func (a *Adapter) handleUpdate(ctx context.Context, u Update) {
+	if cq := u.CallbackQuery; cq != nil {
+		a.handleCallback(ctx, cq)
+		return
+	}
+	m := u.Message
+	if m == nil || m.From == nil || m.From.IsBot || strings.TrimSpace(m.Text) == "" {
+		return
+	}
+	chatID := strconv.FormatInt(m.Chat.ID, 10)
+	if a.pollCfg.MaxAge > 0 && time.Since(time.Unix(m.Date, 0)) > a.pollCfg.MaxAge {
+		slog.Warn("telegram message too old, skipped", "chat_id", chatID, "update_id", u.UpdateID)
+		return
+	}
+	out := a.svc.Reply(ctx, chatID, strconv.FormatInt(m.From.ID, 10), m.Text, a.target())
+	if ctx.Err() != nil {
+		return
+	}
+	a.send(ctx, chatID, out)
+}
+
+func (a *Adapter) handleCallback(ctx context.Context, cq *CallbackQuery) {
+	if cq.From == nil || cq.Message == nil {
+		_ = a.client.AnswerCallback(ctx, cq.ID, "")
+		return
+	}
+	chatID := strconv.FormatInt(cq.Message.Chat.ID, 10)
+	out := a.svc.Callback(ctx, chatID, strconv.FormatInt(cq.From.ID, 10), cq.Data, a.target())
+	if ctx.Err() != nil {
+		return
+	}
+	if err := a.client.AnswerCallback(ctx, cq.ID, out.Notice); err != nil {
+		slog.Warn("telegram answerCallbackQuery failed", "chat_id", chatID, "err", err)
+	}
+	if out.Text != "" {
+		a.send(ctx, chatID, out)
+	}
+}
+
+func (a *Adapter) target() render.Target {
+	return render.Target{Format: a.format, Limit: maxMessageLen}
+}
+
+// send отправляет ответ с разметкой; отклоненная разметка повторяется текстом.
+func (a *Adapter) send(ctx context.Context, chatID string, out common.Outgoing) {
+	err := a.client.SendFormatted(ctx, chatID, out.Text, parseMode(a.format), out.Buttons)
+	if errors.Is(err, common.ErrPermanent) && out.Text != out.Plain {
+		err = a.client.SendFormatted(ctx, chatID, out.Plain, "", out.Buttons)
+	}
+	if err != nil {
+		slog.Warn("telegram reply failed", "chat_id", chatID, "err", err)
+	}
+}
+
+// parseMode - значение parse_mode Bot API для разметки.
+func parseMode(f render.Format) string {
+	switch f {
+	case render.HTML:
+		return "HTML"
+	case render.MarkdownV2:
+		return "MarkdownV2"
+	default:
+		return ""
+	}
+}
@@ -1,14 +1,13 @@
 package web
 
 import (
+	"compress/gzip"
 	"context"
-	"crypto/rand"
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"sort"
 	"strconv"
@@ -16,8 +15,11 @@ import (
 	"sync"
 	"time"
 
+	"goadmin/internal/audit"
 	"goadmin/internal/core"
+	"goadmin/internal/redact"
 	"goadmin/internal/storage"
+	"goadmin/internal/transports/common"
 )
 
 type contextKey string
@@ -53,6 +55,29 @@ type Config struct {
 	CORSAllowedOrigins       []string
 	CORSAllowedMethods       []string
 	CORSAllowedHeaders       []string
+	// AuditWriter получает события аудита; по умолчанию пишется напрямую в store.
+	AuditWriter storage.AuditWriter
+	// AuditRequired отклоняет execute, экспорт аудита и изменения silences и окон
+	// обслуживания, если событие "started" не удалось сохранить.
+	AuditRequired bool
+	// AuditStats отдает счетчики подсистемы аудита для /v1/audit/health.
+	AuditStats func() interface{}
+	// Collectors отдает статистику коллекторов метрик для /v1/collectors.
+	Collectors func() interface{}
+	// Webhooks отдает счетчики доставки webhook для /v1/webhooks.
+	Webhooks func() interface{}
+	// Transports отдает состояние транспортов для /v1/transports.
+	Transports func() interface{}
+	// Schedules управляет задачами планировщика для /v1/schedules.
+	Schedules ScheduleController
+	// Alerts отдает алерты для /v1/alerts; all - включая неактивные и разрешенные.
+	Alerts func(all bool) interface{}
+	// Maintenance управляет silences и окнами обслуживания для /v1/silences и /v1/maintenance.
+	Maintenance MaintenanceController
+	// HealthChecks дополняют /v1/health состоянием подсистем (ok|degraded|failing).
+	HealthChecks map[string]func() string
+	// Redactor скрывает секреты в аудите и данных ответов; nil - без изменений.
+	Redactor *redact.Redactor
 }
 
 // Adapter реализует web transport поверх net/http.
@@ -67,6 +92,8 @@ tokensByHash map[string]TokenEntry
 
 	mu     sync.Mutex
 	server *http.Server
+	// failed получает ошибку HTTP-сервера после Start (core.FailureNotifier).
+	failed chan error
 }
 
 type executeRequest struct {
@@ -75,13 +102,6 @@ type executeRequest struct {
 	Args    []string `json:"args"`
 }
 
-const (
-	maxModuleLen  = 64
-	maxCommandLen = 64
-	maxArgsCount  = 16
-	maxArgLen     = 256
-)
-
 // NewAdapter создает web transport.
 func NewAdapter(registry *core.Registry, authorizer core.Authorizer, store storage.Store, cfg Config) *Adapter {
 	if cfg.ListenAddr == "" {
@@ -114,8 +134,8 @@ func NewAdapter(registry *core.Registry, authorizer core.Authorizer, store stora
 
 	tokensByHash := make(map[string]TokenEntry, len(cfg.Tokens))
 	for _, token := range cfg.Tokens {
-		h := strings.ToLower(strings.TrimSpace(token.TokenSHA256))
-		if len(h) != 64 {
+		h, ok := common.NormalizeTokenHash(token.TokenSHA256)
+		if !ok {
 			continue
 		}
 		tokensByHash[h] = token
@@ -137,6 +157,7 @@ func NewAdapter(registry *core.Registry, authorizer core.Authorizer, store stora
 		cfg:         cfg,
 		tokensByHash: tokensByHash,
 		corsOrigins: corsOrigins,
+		failed:      make(chan error, 1),
 	}
 }
 
@@ -155,6 +176,11 @@ func (a *Adapter) Start(ctx context.Context) error {
 		ReadTimeout:  a.cfg.ReadTimeout,
 		WriteTimeout: a.cfg.WriteTimeout,
 	}
+	ln, err := net.Listen("tcp", a.cfg.ListenAddr)
+	if err != nil {
+		a.mu.Unlock()
+		return fmt.Errorf("web listen: %w", err)
+	}
 	a.server = srv
 	a.mu.Unlock()
 
@@ -166,13 +192,20 @@ func (a *Adapter) Start(ctx context.Context) error {
 	}()
 
 	go func() {
-		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			_ = a.writeAudit(context.Background(), "", "web:serve", "error", map[string]string{"error": err.Error()}, "")
+			select {
+			case a.failed <- err:
+			default:
+			}
 		}
 	}()
 	return nil
 }
 
+// Failed сообщает об ошибке HTTP-сервера после Start (core.FailureNotifier).
+func (a *Adapter) Failed() <-chan error { return a.failed }
+
 // Stop завершает HTTP server.
 func (a *Adapter) Stop(ctx context.Context) error {
 	a.mu.Lock()
@@ -210,12 +243,14 @@ func (a *Adapter) routes() http.Handler {
 		a.timeoutMiddleware(),
 		a.authSubjectMiddleware(),
 		a.authorizeActionMiddleware("web:me", core.Action{Module: "web", Command: "me"}),
+		a.auditReadMiddleware("web:me"),
 	))
 
 	mux.Handle("GET /v1/modules", chain(http.HandlerFunc(a.handleModules),
 		a.timeoutMiddleware(),
 		a.authSubjectMiddleware(),
 		a.authorizeActionMiddleware("web:modules", core.Action{Module: "web", Command: "modules"}),
+		a.auditReadMiddleware("web:modules"),
 	))
 
 	mux.Handle("POST /v1/commands/execute", chain(http.HandlerFunc(a.handleExecute),
@@ -231,21 +266,78 @@ func (a *Adapter) routes() http.Handler {
 		a.authorizeMetricMiddleware(),
 	))
 
+	mux.Handle("GET /v1/collectors", chain(http.HandlerFunc(a.handleCollectors),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:collectors", core.Action{Module: "collectors", Command: "read"}),
+		a.auditReadMiddleware("web:collectors"),
+	))
+
+	a.registerScheduleRoutes(mux)
+	a.registerMaintenanceRoutes(mux)
+
+	mux.Handle("GET /v1/alerts", chain(http.HandlerFunc(a.handleAlerts),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:alerts", core.Action{Module: "alerts", Command: "read"}),
+		a.auditReadMiddleware("web:alerts"),
+	))
+
+	mux.Handle("GET /v1/webhooks", chain(http.HandlerFunc(a.handleWebhooks),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:webhooks", core.Action{Module: "webhooks", Command: "read"}),
+		a.auditReadMiddleware("web:webhooks"),
+	))
+
+	mux.Handle("GET /v1/transports", chain(http.HandlerFunc(a.handleTransports),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:transports", core.Action{Module: "transports", Command: "read"}),
+		a.auditReadMiddleware("web:transports"),
+	))
+
 	mux.Handle("GET /v1/audit", chain(http.HandlerFunc(a.handleAudit),
 		a.timeoutMiddleware(),
 		a.authSubjectMiddleware(),
 		a.authorizeActionMiddleware("web:audit_query", core.Action{Module: "audit", Command: "read"}),
 	))
 
+	mux.Handle("GET /v1/audit/stats", chain(http.HandlerFunc(a.handleAuditStats),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:audit_stats", core.Action{Module: "audit", Command: "read"}),
+	))
+
+	// Экспорт может идти дольше RequestTimeout, поэтому без timeoutMiddleware:
+	// дедлайн записи продлевается на каждой странице.
+	mux.Handle("GET /v1/audit/export", chain(http.HandlerFunc(a.handleAuditExport),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:audit_export", core.Action{Module: "audit", Command: "export"}),
+	))
+
+	mux.Handle("GET /v1/audit/health", chain(http.HandlerFunc(a.handleAuditHealth),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:audit_health", core.Action{Module: "audit", Command: "read"}),
+		a.auditReadMiddleware("web:audit_health"),
+	))
+
+	mux.Handle("GET /v1/audit/verify", chain(http.HandlerFunc(a.handleAuditVerify),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:audit_verify", core.Action{Module: "audit", Command: "verify"}),
+	))
+
 	return chain(mux, a.requestIDMiddleware(), a.corsMiddleware())
 }
 
 func (a *Adapter) requestIDMiddleware() middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			requestID := sanitizeRequestID(r.Header.Get("X-Request-ID"))
+			requestID := common.SanitizeRequestID(r.Header.Get("X-Request-ID"))
 			if requestID == "" {
-				requestID = newRequestID()
+				requestID = common.NewRequestID()
 			}
 			w.Header().Set("X-Request-ID", requestID)
 			ctx := context.WithValue(r.Context(), ctxRequestID, requestID)
@@ -339,9 +431,7 @@ func (a *Adapter) resolveSubject(r *http.Request) (string, []string, string, str
 			if token == "" {
 				return "", nil, "", "invalid_token"
 			}
-			sum := sha256.Sum256([]byte(token))
-			hash := hex.EncodeToString(sum[:])
-			entry, ok := a.tokensByHash[hash]
+			entry, ok := a.tokensByHash[common.TokenHash(token)]
 			if !ok || !entry.Enabled || entry.Subject == "" {
 				return "", nil, "", "invalid_token"
 			}
@@ -387,6 +477,35 @@ func (a *Adapter) authorizeActionMiddleware(auditAction string, authAction core.
 	}
 }
 
+// auditReadMiddleware аудирует чтение, которое обработчик не аудирует сам:
+// каждый авторизованный GET /v1/* оставляет событие со статусом ответа.
+func (a *Adapter) auditReadMiddleware(auditAction string) middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			status := "ok"
+			payload := map[string]string{"auth_method": authMethodFromContext(r.Context())}
+			if rec.status >= http.StatusBadRequest {
+				status = "error"
+				payload["http_status"] = strconv.Itoa(rec.status)
+			}
+			_ = a.writeAudit(context.WithoutCancel(r.Context()), subjectIDFromContext(r.Context()), auditAction, status, payload, requestIDFromContext(r.Context()))
+		})
+	}
+}
+
+// statusRecorder запоминает код ответа для аудита.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
 func (a *Adapter) authorizeExecuteMiddleware() middleware {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -450,73 +569,104 @@ func decodeExecuteRequest(r *http.Request) (executeRequest, string, int) {
 	if err := dec.Decode(&trailing); err != io.EOF {
 		return executeRequest{}, "invalid_json", http.StatusBadRequest
 	}
-	if code := validateExecuteRequest(req); code != "" {
+	if code := common.ValidateExecute(req.Module, req.Command, req.Args); code != "" {
 		return executeRequest{}, code, http.StatusBadRequest
 	}
 	return req, "", 0
 }
 
-func validateExecuteRequest(req executeRequest) string {
-	if req.Module == "" || req.Command == "" {
-		return "bad_command"
-	}
-	if len(req.Module) > maxModuleLen || !isSafeToken(req.Module) {
-		return "bad_module"
-	}
-	if len(req.Command) > maxCommandLen || !isSafeToken(req.Command) {
-		return "bad_command"
-	}
-	if len(req.Args) > maxArgsCount {
-		return "too_many_args"
+func isBodyTooLargeErr(err error) bool {
+	return strings.Contains(err.Error(), "request body too large")
+}
+
+
+func (a *Adapter) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if len(a.cfg.HealthChecks) == 0 {
+		writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+		return
 	}
-	for _, arg := range req.Args {
-		if len(arg) > maxArgLen {
-			return "arg_too_long"
+	overall := "ok"
+	checks := make(map[string]string, len(a.cfg.HealthChecks))
+	for name, check := range a.cfg.HealthChecks {
+		status := check()
+		checks[name] = status
+		switch {
+		case status == "failing":
+			overall = "failing"
+		case status != "ok" && overall == "ok":
+			overall = "degraded"
 		}
 	}
-	return ""
+	code := http.StatusOK
+	if overall == "failing" {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, r, code, map[string]interface{}{"status": overall, "checks": checks})
 }
 
-func isBodyTooLargeErr(err error) bool {
-	return strings.Contains(err.Error(), "request body too large")
+func (a *Adapter) handleAuditHealth(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFromContext(r.Context())
+	if a.cfg.AuditStats == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestID,
+		"audit":      a.cfg.AuditStats(),
+	})
 }
 
-func isSafeToken(v string) bool {
-	for _, ch := range v {
-		if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') {
-			continue
-		}
-		switch ch {
-		case '_', '-', '.':
-			continue
-		default:
-			return false
-		}
+func (a *Adapter) handleCollectors(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Collectors == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
 	}
-	return true
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Collectors(),
+	})
 }
 
-func sanitizeRequestID(v string) string {
-	id := strings.TrimSpace(v)
-	if id == "" || len(id) > 64 {
-		return ""
+func (a *Adapter) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Webhooks == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
 	}
-	for _, ch := range id {
-		if (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9') {
-			continue
-		}
-		switch ch {
-		case '-', '_', '.', ':':
-			continue
-		default:
-			return ""
-		}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Webhooks(),
+	})
+}
+
+func (a *Adapter) handleTransports(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Transports == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
 	}
-	return id
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Transports(),
+	})
 }
 
-func (a *Adapter) handleHealth(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, r, http.StatusOK, map[string]string{"status": "ok"})
+func (a *Adapter) handleAlerts(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Alerts == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	var all bool
+	switch r.URL.Query().Get("state") {
+	case "", "active":
+	case "all":
+		all = true
+	default:
+		writeError(w, r, http.StatusBadRequest, "bad_state")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Alerts(all),
+	})
 }
 
 func (a *Adapter) handleMe(w http.ResponseWriter, r *http.Request) {
@@ -550,7 +700,14 @@ func (a *Adapter) handleExecute(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	resp, err := a.registry.Execute(r.Context(), req.Module, req.Command, req.Args)
+	if a.cfg.AuditRequired {
+		if err := a.writeAudit(r.Context(), subjectID, "web:execute", "started", map[string]string{"module": req.Module, "command": req.Command, "auth_method": authMethod}, requestID); err != nil {
+			writeError(w, r, http.StatusServiceUnavailable, "audit_unavailable")
+			return
+		}
+	}
+
+	resp, err := a.registry.Execute(core.WithSubject(r.Context(), core.Subject{Source: "web", ID: subjectID}), req.Module, req.Command, req.Args)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded) {
 			writeError(w, r, http.StatusGatewayTimeout, "request_timeout")
@@ -569,7 +726,7 @@ func (a *Adapter) handleExecute(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, r, http.StatusOK, map[string]interface{}{
 		"request_id": requestID,
 		"status":     resp.Status,
-		"data":       resp.Data,
+		"data":       a.cfg.Redactor.Value(resp.Data),
 		"error_code": resp.ErrorCode,
 	})
 	_ = a.writeAudit(r.Context(), subjectID, "web:execute", "ok", map[string]string{"module": req.Module, "command": req.Command, "auth_method": authMethod}, requestID)
@@ -602,7 +759,7 @@ func (a *Adapter) handleLatestMetric(w http.ResponseWriter, r *http.Request) {
 		"request_id": requestID,
 		"module":     rec.Module,
 		"ts":         rec.TS.UTC().Format(time.RFC3339),
-		"payload":    json.RawMessage(rec.Payload),
+		"payload":    json.RawMessage(a.cfg.Redactor.JSON(rec.Payload)),
 	})
 	_ = a.writeAudit(r.Context(), subjectID, "web:metrics_latest", "ok", map[string]string{"module": module, "auth_method": authMethod}, requestID)
 }
@@ -612,72 +769,298 @@ func (a *Adapter) handleAudit(w http.ResponseWriter, r *http.Request) {
 	subjectID := subjectIDFromContext(r.Context())
 	authMethod := authMethodFromContext(r.Context())
 
-	q := storage.AuditQuery{
-		Subject: r.URL.Query().Get("subject"),
-		Limit:   parseLimit(r.URL.Query().Get("limit")),
+	q, code := parseAuditQuery(r)
+	if code != "" {
+		writeError(w, r, http.StatusBadRequest, code)
+		return
 	}
-	if from := r.URL.Query().Get("from"); from != "" {
-		ts, err := time.Parse(time.RFC3339, from)
+
+	var (
+		events     []storage.AuditEvent
+		nextCursor string
+		err        error
+	)
+	if pager, ok := a.store.(storage.AuditPager); ok {
+		var page storage.AuditPage
+		page, err = pager.QueryAuditPage(r.Context(), q)
+		events, nextCursor = page.Events, page.NextCursor
+	} else if q.Cursor != "" {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	} else {
+		events, err = a.store.QueryAudit(r.Context(), q)
+	}
+	if err != nil {
+		if errors.Is(err, storage.ErrInvalidCursor) {
+			writeError(w, r, http.StatusBadRequest, "bad_cursor")
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			writeError(w, r, http.StatusGatewayTimeout, "request_timeout")
+			_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "error", map[string]string{"error_code": "request_timeout", "auth_method": authMethod}, requestID)
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, "query_failed")
+		_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "error", map[string]string{"auth_method": authMethod}, requestID)
+		return
+	}
+
+	payload := make([]AuditEventDTO, 0, len(events))
+	var minID, maxID int64
+	for _, ev := range events {
+		payload = append(payload, NewAuditEventDTO(ev))
+		if minID == 0 || ev.ID < minID {
+			minID = ev.ID
+		}
+		if ev.ID > maxID {
+			maxID = ev.ID
+		}
+	}
+
+	resp := map[string]interface{}{
+		"request_id": requestID,
+		"items":      payload,
+	}
+	if nextCursor != "" {
+		resp["next_cursor"] = nextCursor
+	}
+	if chainStore, ok := a.store.(storage.AuditChainStore); ok && maxID > 0 {
+		checkpoints, err := chainStore.AuditCheckpoints(r.Context(), minID, maxID)
 		if err != nil {
-			writeError(w, r, http.StatusBadRequest, "bad_from")
+			writeError(w, r, http.StatusInternalServerError, "query_failed")
+			_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "error", map[string]string{"auth_method": authMethod}, requestID)
 			return
 		}
-		q.From = ts
+		resp["checkpoints"] = newCheckpointDTOs(checkpoints)
 	}
-	if to := r.URL.Query().Get("to"); to != "" {
-		ts, err := time.Parse(time.RFC3339, to)
+
+	writeJSON(w, r, http.StatusOK, resp)
+	_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "ok", map[string]string{"items": strconv.Itoa(len(payload)), "auth_method": authMethod}, requestID)
+}
+
+func (a *Adapter) handleAuditStats(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFromContext(r.Context())
+	subjectID := subjectIDFromContext(r.Context())
+	authMethod := authMethodFromContext(r.Context())
+
+	aggregator, ok := a.store.(storage.AuditAggregator)
+	if !ok {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	q, code := parseAuditQuery(r)
+	if code != "" {
+		writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	sq := storage.AuditStatsQuery{AuditQuery: q, GroupBy: []string{"action", "status"}}
+	if v := r.URL.Query().Get("bucket"); v != "" {
+		bucket, err := time.ParseDuration(v)
+		if err != nil || bucket < time.Second || bucket%time.Second != 0 {
+			writeError(w, r, http.StatusBadRequest, "bad_bucket")
+			return
+		}
+		sq.Bucket = bucket
+	}
+	if v, ok := r.URL.Query()["group_by"]; ok {
+		fields, err := storage.ValidateAuditGroupBy(strings.Split(strings.Join(v, ","), ","))
 		if err != nil {
-			writeError(w, r, http.StatusBadRequest, "bad_to")
+			writeError(w, r, http.StatusBadRequest, "bad_group_by")
 			return
 		}
-		q.To = ts
+		sq.GroupBy = fields
 	}
 
-	events, err := a.store.QueryAudit(r.Context(), q)
+	rows, err := aggregator.AggregateAudit(r.Context(), sq)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded) {
 			writeError(w, r, http.StatusGatewayTimeout, "request_timeout")
-			_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "error", map[string]string{"error_code": "request_timeout", "auth_method": authMethod}, requestID)
+			_ = a.writeAudit(r.Context(), subjectID, "web:audit_stats", "error", map[string]string{"error_code": "request_timeout", "auth_method": authMethod}, requestID)
 			return
 		}
 		writeError(w, r, http.StatusInternalServerError, "query_failed")
-		_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "error", map[string]string{"auth_method": authMethod}, requestID)
+		_ = a.writeAudit(r.Context(), subjectID, "web:audit_stats", "error", map[string]string{"auth_method": authMethod}, requestID)
 		return
 	}
 
-	type eventDTO struct {
-		Subject   string          `json:"subject"`
-		Action    string          `json:"action"`
-		Source    string          `json:"source"`
-		Status    string          `json:"status"`
-		RequestID string          `json:"request_id"`
-		Payload   json.RawMessage `json:"payload,omitempty"`
-		TS        string          `json:"ts"`
+	items := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		item := map[string]interface{}{"count": row.Count}
+		if sq.Bucket > 0 {
+			item["bucket"] = row.Bucket.UTC().Format(time.RFC3339)
+		}
+		for _, f := range sq.GroupBy {
+			switch f {
+			case "subject":
+				item["subject"] = row.Subject
+			case "action":
+				item["action"] = row.Action
+			case "source":
+				item["source"] = row.Source
+			case "status":
+				item["status"] = row.Status
+			}
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id":     requestID,
+		"bucket_seconds": int64(sq.Bucket / time.Second),
+		"group_by":       sq.GroupBy,
+		"items":          items,
+	})
+	_ = a.writeAudit(r.Context(), subjectID, "web:audit_stats", "ok", map[string]string{"items": strconv.Itoa(len(items)), "auth_method": authMethod}, requestID)
+}
+
+// exportPageTimeout - дедлайн записи одной страницы экспорта.
+const exportPageTimeout = 30 * time.Second
+
+func (a *Adapter) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFromContext(r.Context())
+	subjectID := subjectIDFromContext(r.Context())
+	authMethod := authMethodFromContext(r.Context())
+
+	pager, ok := a.store.(storage.AuditPager)
+	if !ok {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
 	}
-	payload := make([]eventDTO, 0, len(events))
-	for _, ev := range events {
-		payload = append(payload, eventDTO{
-			Subject:   ev.Subject,
-			Action:    ev.Action,
-			Source:    ev.Source,
-			Status:    ev.Status,
-			RequestID: ev.RequestID,
-			Payload:   json.RawMessage(ev.Payload),
-			TS:        ev.TS.UTC().Format(time.RFC3339),
-		})
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = audit.ExportNDJSON
+	}
+	if !audit.ValidExportFormat(format) {
+		writeError(w, r, http.StatusBadRequest, "bad_format")
+		return
+	}
+	q, code := parseAuditQuery(r)
+	if code != "" {
+		writeError(w, r, http.StatusBadRequest, code)
+		return
+	}
+	auditPayload := map[string]string{"format": format, "auth_method": authMethod}
+	if a.cfg.AuditRequired {
+		if err := a.writeAudit(r.Context(), subjectID, "web:audit_export", "started", auditPayload, requestID); err != nil {
+			writeError(w, r, http.StatusServiceUnavailable, "audit_unavailable")
+			return
+		}
+	}
+
+	ext, contentType := "ndjson", "application/x-ndjson"
+	if format == audit.ExportCSV {
+		ext, contentType = "csv", "text/csv; charset=utf-8"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="audit-%s.%s"`, time.Now().UTC().Format("20060102T150405Z"), ext))
+	w.Header().Set("X-Request-ID", requestID)
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	var (
+		out io.Writer = w
+		gz  *gzip.Writer
+	)
+	if r.URL.Query().Get("gzip") == "true" || strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz = gzip.NewWriter(w)
+		out = gz
+	}
+	rc := http.NewResponseController(w)
+	summary, err := audit.Export(r.Context(), pager, out, audit.ExportOptions{
+		Format: format,
+		Query:  q,
+		OnPage: func() {
+			_ = rc.SetWriteDeadline(time.Now().Add(exportPageTimeout))
+			if gz != nil {
+				_ = gz.Flush()
+			}
+			_ = rc.Flush()
+		},
+	})
+	if gz != nil {
+		if cerr := gz.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		auditPayload["error"] = summary.Error
+	}
+	auditPayload["count"] = strconv.FormatInt(summary.Count, 10)
+	auditPayload["sha256"] = summary.SHA256
+	auditPayload["from"] = summary.From
+	auditPayload["to"] = summary.To
+	// Клиент мог оборвать выгрузку, но факт экспорта все равно фиксируется.
+	_ = a.writeAudit(context.WithoutCancel(r.Context()), subjectID, "web:audit_export", status, auditPayload, requestID)
+}
+
+// parseAuditQuery разбирает фильтры /v1/audit; второй результат - код ошибки.
+func parseAuditQuery(r *http.Request) (storage.AuditQuery, string) {
+	values := r.URL.Query()
+	q := storage.AuditQuery{
+		Subject:   values.Get("subject"),
+		Action:    values.Get("action"),
+		Source:    values.Get("source"),
+		Status:    values.Get("status"),
+		RequestID: values.Get("request_id"),
+		Cursor:    values.Get("cursor"),
+		Limit:     parseLimit(values.Get("limit")),
+	}
+	if from := values.Get("from"); from != "" {
+		ts, err := time.Parse(time.RFC3339, from)
+		if err != nil {
+			return q, "bad_from"
+		}
+		q.From = ts
+	}
+	if to := values.Get("to"); to != "" {
+		ts, err := time.Parse(time.RFC3339, to)
+		if err != nil {
+			return q, "bad_to"
+		}
+		q.To = ts
+	}
+	return q, ""
+}
+
+func (a *Adapter) handleAuditVerify(w http.ResponseWriter, r *http.Request) {
+	requestID := requestIDFromContext(r.Context())
+	subjectID := subjectIDFromContext(r.Context())
+	authMethod := authMethodFromContext(r.Context())
+
+	chainStore, ok := a.store.(storage.AuditChainStore)
+	if !ok {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	report, err := chainStore.VerifyAuditChain(r.Context(), nil)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(r.Context().Err(), context.DeadlineExceeded) {
+			writeError(w, r, http.StatusGatewayTimeout, "request_timeout")
+			_ = a.writeAudit(r.Context(), subjectID, "web:audit_verify", "error", map[string]string{"error_code": "request_timeout", "auth_method": authMethod}, requestID)
+			return
+		}
+		writeError(w, r, http.StatusInternalServerError, "verify_failed")
+		_ = a.writeAudit(r.Context(), subjectID, "web:audit_verify", "error", map[string]string{"auth_method": authMethod}, requestID)
+		return
 	}
 
 	writeJSON(w, r, http.StatusOK, map[string]interface{}{
 		"request_id": requestID,
-		"items":      payload,
+		"report":     report,
 	})
-	_ = a.writeAudit(r.Context(), subjectID, "web:audit_query", "ok", map[string]string{"items": strconv.Itoa(len(payload)), "auth_method": authMethod}, requestID)
+	status := "ok"
+	if !report.OK {
+		status = "broken"
+	}
+	_ = a.writeAudit(r.Context(), subjectID, "web:audit_verify", status, map[string]string{"checked": strconv.FormatInt(report.Checked, 10), "auth_method": authMethod}, requestID)
 }
 
 func requestIDFromContext(ctx context.Context) string {
 	v, ok := ctx.Value(ctxRequestID).(string)
 	if !ok || v == "" {
-		return newRequestID()
+		return common.NewRequestID()
 	}
 	return v
 }
@@ -704,16 +1087,20 @@ func (a *Adapter) writeAudit(ctx context.Context, subject, action, status string
 		if err != nil {
 			return err
 		}
-		rawPayload = data
+		rawPayload = a.cfg.Redactor.JSON(data)
 	}
-	return a.store.SaveAudit(ctx, storage.AuditEvent{
+	ev := storage.AuditEvent{
 		Subject:   subject,
 		Action:    action,
 		Source:    "web",
 		Status:    status,
 		RequestID: requestID,
 		Payload:   rawPayload,
-	})
+	}
+	if a.cfg.AuditWriter != nil {
+		return a.cfg.AuditWriter.Write(ctx, ev)
+	}
+	return a.store.SaveAudit(ctx, ev)
 }
 
 func parseLimit(v string) int {
@@ -724,14 +1111,6 @@ func parseLimit(v string) int {
 	return n
 }
 
-func newRequestID() string {
-	buf := make([]byte, 8)
-	if _, err := rand.Read(buf); err != nil {
-		return fmt.Sprintf("req-%d", time.Now().UnixNano())
-	}
-	return hex.EncodeToString(buf)
-}
-
 func writeError(w http.ResponseWriter, r *http.Request, statusCode int, code string) {
 	writeJSON(w, r, statusCode, map[string]string{
 		"request_id": requestIDFromContext(r.Context()),
@@ -758,6 +1137,32 @@ func errorMessage(code string) string {
 		return "arg is too long"
 	case "request_timeout":
 		return "request timeout"
+	case "bad_format":
+		return "format must be ndjson or csv"
+	case "bad_cursor":
+		return "cursor is invalid or expired"
+	case "bad_bucket":
+		return "bucket must be a duration of whole seconds, e.g. 1h"
+	case "bad_group_by":
+		return "group_by accepts subject, action, source, status"
+	case "bad_state":
+		return "state must be active or all"
+	case "job_not_found":
+		return "scheduled job not found"
+	case "job_running":
+		return "job is already running"
+	case "scheduler_stopped":
+		return "scheduler is not running"
+	case "bad_silence":
+		return "silence needs matchers such as alertname=x and an end in the future"
+	case "bad_window":
+		return "maintenance window needs a name and either starts_at/ends_at or a cron schedule with duration"
+	case "silence_not_found":
+		return "silence not found"
+	case "window_not_found":
+		return "maintenance window not found"
+	case "audit_unavailable":
+		return "audit log is unavailable, command rejected"
 	case "cors_denied", "cors_method_denied":
 		return "cors policy denied request"
 	default:
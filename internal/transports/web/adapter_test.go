@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -80,6 +81,45 @@ func TestHealthEndpoint(t *testing.T) {
 	}
 }
 
+func TestHealthEndpointReportsFailingCheck(t *testing.T) {
+	adapter := newTestAdapter(t, false, Config{
+		HealthChecks: map[string]func() string{"audit": func() string { return "failing" }},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/health", nil)
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), `"audit":"failing"`) {
+		t.Fatalf("expected audit check in body, got %s", rr.Body.String())
+	}
+}
+
+type failingAuditWriter struct{}
+
+func (failingAuditWriter) Write(ctx context.Context, ev storage.AuditEvent) error {
+	return errors.New("spool full")
+}
+
+func TestExecuteEndpointRejectsWhenAuditRequired(t *testing.T) {
+	adapter := newTestAdapter(t, false, Config{AuditWriter: failingAuditWriter{}, AuditRequired: true})
+
+	body := bytes.NewBufferString(`{"module":"host","command":"status","args":[]}`)
+	req := httptest.NewRequest(http.MethodPost, "/v1/commands/execute", body)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status 503, got %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "audit_unavailable") {
+		t.Fatalf("expected audit_unavailable, got %s", rr.Body.String())
+	}
+}
+
 func TestProtectedEndpointRequiresAuth(t *testing.T) {
 	adapter := newTestAdapter(t, false, Config{})
 	req := httptest.NewRequest(http.MethodGet, "/v1/audit", nil)
@@ -413,3 +453,32 @@ func tokenSHA256(token string) string {
 	sum := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(sum[:])
 }
+
+func TestAuditEventDTOKeepsPayloadBytesForHash(t *testing.T) {
+	ev := storage.AuditEvent{
+		ID:      5,
+		Subject: "u1",
+		Action:  "host:status",
+		Source:  "web",
+		Status:  "ok",
+		Payload: []byte(`{"cmd": "a<b && c>d"}`),
+		TS:      time.Date(2026, 5, 1, 0, 0, 0, 42, time.UTC),
+	}
+	ev.Hash = storage.AuditEventHash("prev", ev)
+	ev.PrevHash = "prev"
+	raw, err := json.Marshal(NewAuditEventDTO(ev))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var dto AuditEventDTO
+	if err := json.Unmarshal(raw, &dto); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	got, err := dto.Event()
+	if err != nil {
+		t.Fatalf("event: %v", err)
+	}
+	if storage.AuditEventHash(got.PrevHash, got) != ev.Hash {
+		t.Fatalf("hash mismatch after JSON round trip, payload %s", got.Payload)
+	}
+}
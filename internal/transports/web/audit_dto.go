@@ -0,0 +1,108 @@
+package web
+
+import (
+	"encoding/json"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// AuditEventDTO - JSON-представление записи аудита вместе с полями hash-цепочки.
+type AuditEventDTO struct {
+	ID        int64           `json:"id,omitempty"`
+	Subject   string          `json:"subject"`
+	Action    string          `json:"action"`
+	Source    string          `json:"source"`
+	Status    string          `json:"status"`
+	RequestID string          `json:"request_id"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	// PayloadRaw - сохраненные байты payload (base64). Hash записи считается
+	// по ним: Payload после JSON-кодирования сжат и с экранированием &, <, >.
+	PayloadRaw []byte `json:"payload_raw,omitempty"`
+	TS         string `json:"ts"`
+	PrevHash   string `json:"prev_hash,omitempty"`
+	Hash       string `json:"hash,omitempty"`
+}
+
+// AuditCheckpointDTO - JSON-представление подписи цепочки аудита.
+type AuditCheckpointDTO struct {
+	EventID   int64  `json:"event_id"`
+	Hash      string `json:"hash"`
+	KeyID     string `json:"key_id"`
+	Signature []byte `json:"signature"`
+	TS        string `json:"ts"`
+}
+
+// NewAuditEventDTO переводит запись хранилища в DTO.
+// Время пишется с наносекундами, иначе hash записи нельзя проверить офлайн.
+func NewAuditEventDTO(ev storage.AuditEvent) AuditEventDTO {
+	dto := AuditEventDTO{
+		ID:        ev.ID,
+		Subject:   ev.Subject,
+		Action:    ev.Action,
+		Source:    ev.Source,
+		Status:    ev.Status,
+		RequestID: ev.RequestID,
+		TS:        ev.TS.UTC().Format(time.RFC3339Nano),
+		PrevHash:  ev.PrevHash,
+		Hash:      ev.Hash,
+	}
+	if len(ev.Payload) > 0 {
+		dto.PayloadRaw = ev.Payload
+		if json.Valid(ev.Payload) {
+			dto.Payload = json.RawMessage(ev.Payload)
+		}
+	}
+	return dto
+}
+
+// Event переводит DTO обратно в запись хранилища; payload берется из
+// PayloadRaw, а в старых выгрузках без него - из Payload.
+func (d AuditEventDTO) Event() (storage.AuditEvent, error) {
+	ts, err := time.Parse(time.RFC3339Nano, d.TS)
+	if err != nil {
+		return storage.AuditEvent{}, err
+	}
+	payload := d.PayloadRaw
+	if len(payload) == 0 {
+		payload = []byte(d.Payload)
+	}
+	return storage.AuditEvent{
+		ID:        d.ID,
+		Subject:   d.Subject,
+		Action:    d.Action,
+		Source:    d.Source,
+		Status:    d.Status,
+		RequestID: d.RequestID,
+		Payload:   payload,
+		TS:        ts.UTC(),
+		PrevHash:  d.PrevHash,
+		Hash:      d.Hash,
+	}, nil
+}
+
+func newCheckpointDTOs(checkpoints []storage.AuditCheckpoint) []AuditCheckpointDTO {
+	out := make([]AuditCheckpointDTO, 0, len(checkpoints))
+	for _, cp := range checkpoints {
+		out = append(out, AuditCheckpointDTO{
+			EventID:   cp.EventID,
+			Hash:      cp.Hash,
+			KeyID:     cp.KeyID,
+			Signature: cp.Signature,
+			TS:        cp.TS.UTC().Format(time.RFC3339Nano),
+		})
+	}
+	return out
+}
+
+// Checkpoint переводит DTO подписи обратно в структуру хранилища.
+func (d AuditCheckpointDTO) Checkpoint() storage.AuditCheckpoint {
+	ts, _ := time.Parse(time.RFC3339Nano, d.TS)
+	return storage.AuditCheckpoint{
+		EventID:   d.EventID,
+		Hash:      d.Hash,
+		KeyID:     d.KeyID,
+		Signature: d.Signature,
+		TS:        ts.UTC(),
+	}
+}
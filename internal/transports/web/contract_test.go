@@ -2,10 +2,21 @@ package web
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
+	"goadmin/internal/storage"
+	"goadmin/internal/storage/memory"
 )
 
 func TestHTTPContractHealth(t *testing.T) {
@@ -149,3 +160,582 @@ func TestHTTPContractModules(t *testing.T) {
 		t.Fatalf("status = %d, want 200", rr.Code)
 	}
 }
+
+type pagedStore struct {
+	fakeStore
+	lastQuery storage.AuditQuery
+	lastStats storage.AuditStatsQuery
+}
+
+func (s *pagedStore) QueryAuditPage(ctx context.Context, q storage.AuditQuery) (storage.AuditPage, error) {
+	s.lastQuery = q
+	if q.Cursor == "bad" {
+		return storage.AuditPage{}, storage.ErrInvalidCursor
+	}
+	page := storage.AuditPage{Events: []storage.AuditEvent{{ID: 7, Action: q.Action, TS: time.Unix(100, 0)}}}
+	if q.Cursor == "" {
+		page.NextCursor = storage.EncodeAuditCursor(time.Unix(100, 0), 7)
+	}
+	return page, nil
+}
+
+func (s *pagedStore) AggregateAudit(ctx context.Context, q storage.AuditStatsQuery) ([]storage.AuditStatsRow, error) {
+	s.lastStats = q
+	return []storage.AuditStatsRow{{Bucket: time.Unix(3600, 0), Subject: "u1", Status: "ok", Count: 4}}, nil
+}
+
+func TestHTTPContractAuditCursorAndFilters(t *testing.T) {
+	store := &pagedStore{}
+	adapter := newAdapterWithStore(t, store, false, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/audit?action=host:status&source=telegram&status=denied&request_id=r1&limit=10", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	q := store.lastQuery
+	if q.Action != "host:status" || q.Source != "telegram" || q.Status != "denied" || q.RequestID != "r1" || q.Limit != 10 {
+		t.Fatalf("filters not passed: %#v", q)
+	}
+	var resp struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.NextCursor == "" {
+		t.Fatalf("missing next_cursor: %v %s", err, rr.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/audit?cursor=bad", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr = httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "bad_cursor") {
+		t.Fatalf("expected bad_cursor, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestHTTPContractAuditStats(t *testing.T) {
+	store := &pagedStore{}
+	adapter := newAdapterWithStore(t, store, false, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/audit/stats?bucket=1h&group_by=subject,status&source=web", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	if store.lastStats.Bucket != time.Hour || store.lastStats.Source != "web" || len(store.lastStats.GroupBy) != 2 {
+		t.Fatalf("stats query not passed: %#v", store.lastStats)
+	}
+	var resp struct {
+		BucketSeconds int64            `json:"bucket_seconds"`
+		Items         []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.BucketSeconds != 3600 || len(resp.Items) != 1 || resp.Items[0]["subject"] != "u1" || resp.Items[0]["bucket"] != "1970-01-01T01:00:00Z" {
+		t.Fatalf("unexpected stats response: %s", rr.Body.String())
+	}
+	if _, ok := resp.Items[0]["action"]; ok {
+		t.Fatalf("action must be omitted when not grouped: %s", rr.Body.String())
+	}
+
+	for _, bad := range []string{"bucket=10ms", "group_by=payload"} {
+		req = httptest.NewRequest(http.MethodGet, "/v1/audit/stats?"+bad, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr = httptest.NewRecorder()
+		adapter.routes().ServeHTTP(rr, req)
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status = %d, want 400", bad, rr.Code)
+		}
+	}
+}
+
+func TestHTTPContractAuditExport(t *testing.T) {
+	store := &pagedStore{}
+	adapter := newAdapterWithStore(t, store, false, Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/audit/export?format=ndjson&gzip=true&status=ok", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	if rr.Header().Get("Content-Encoding") != "gzip" || rr.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("unexpected headers: %v", rr.Header())
+	}
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	// Две страницы по одному событию и итог.
+	if len(lines) != 3 || !strings.Contains(lines[2], `"count":2`) {
+		t.Fatalf("unexpected export: %s", data)
+	}
+	if store.lastQuery.Status != "ok" {
+		t.Fatalf("filters not passed: %#v", store.lastQuery)
+	}
+
+	var exported bool
+	for _, ev := range store.audit {
+		if ev.Action == "web:audit_export" && ev.Status == "ok" && strings.Contains(string(ev.Payload), `"count":"2"`) {
+			exported = true
+		}
+	}
+	if !exported {
+		t.Fatalf("export must be audited: %#v", store.audit)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/audit/export?format=xml", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr = httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rr.Code)
+	}
+}
+
+func TestHTTPContractCollectors(t *testing.T) {
+	adapter := newAdapterWithStore(t, &fakeStore{}, false, Config{})
+	req := httptest.NewRequest(http.MethodGet, "/v1/collectors", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusNotImplemented {
+		t.Fatalf("status without collectors = %d, want 501", rr.Code)
+	}
+
+	store := &fakeStore{}
+	adapter = newAdapterWithStore(t, store, false, Config{
+		Collectors: func() interface{} {
+			return []map[string]interface{}{{"name": "host_status", "runs": 3, "failures": 1}}
+		},
+	})
+	rr = httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		RequestID string           `json:"request_id"`
+		Items     []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.RequestID == "" || len(resp.Items) != 1 || resp.Items[0]["name"] != "host_status" {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if n := len(store.audit); n == 0 || store.audit[n-1].Action != "web:collectors" || store.audit[n-1].Status != "ok" || store.audit[n-1].RequestID != resp.RequestID {
+		t.Fatalf("collectors read not audited: %#v", store.audit)
+	}
+}
+
+func TestHTTPContractReadsAudited(t *testing.T) {
+	store := &fakeStore{}
+	adapter := newAdapterWithStore(t, store, false, Config{
+		Alerts:     func(all bool) interface{} { return []string{} },
+		Transports: func() interface{} { return []string{} },
+	})
+	for _, tc := range []struct {
+		path, action, status string
+	}{
+		{"/v1/me", "web:me", "ok"},
+		{"/v1/modules", "web:modules", "ok"},
+		{"/v1/alerts", "web:alerts", "ok"},
+		{"/v1/transports", "web:transports", "ok"},
+		{"/v1/collectors", "web:collectors", "error"},
+		{"/v1/webhooks", "web:webhooks", "error"},
+		{"/v1/audit/health", "web:audit_health", "error"},
+		{"/v1/schedules", "web:schedules", "error"},
+		{"/v1/silences", "web:silences", "error"},
+		{"/v1/maintenance", "web:maintenance", "error"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr := httptest.NewRecorder()
+		adapter.routes().ServeHTTP(rr, req)
+		store.mu.Lock()
+		n := len(store.audit)
+		var last storage.AuditEvent
+		if n > 0 {
+			last = store.audit[n-1]
+		}
+		store.mu.Unlock()
+		if last.Action != tc.action || last.Status != tc.status || last.Subject != "u1" || last.RequestID != rr.Header().Get("X-Request-ID") {
+			t.Fatalf("GET %s (%d): last audit = %+v, want %s/%s", tc.path, rr.Code, last, tc.action, tc.status)
+		}
+	}
+}
+
+func TestHTTPContractWebhooks(t *testing.T) {
+	adapter := newAdapterWithStore(t, &fakeStore{}, false, Config{
+		Webhooks: func() interface{} {
+			return []map[string]interface{}{{"name": "ops", "format": "generic", "delivered": 2}}
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/webhooks", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0]["name"] != "ops" {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+func TestHTTPContractTransports(t *testing.T) {
+	adapter := newAdapterWithStore(t, &fakeStore{}, false, Config{
+		Transports: func() interface{} {
+			return []core.TransportStatus{{Name: "telegram", State: core.TransportFailed, Restarts: 2, LastError: "bind"}}
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/v1/transports", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr := httptest.NewRecorder()
+	adapter.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Items []map[string]any `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0]["state"] != "failed" || resp.Items[0]["restarts"] != float64(2) {
+		t.Fatalf("unexpected response: %s", rr.Body.String())
+	}
+}
+
+// fakeSchedules - ScheduleController с одной задачей "collect".
+type fakeSchedules struct {
+	paused  bool
+	running bool
+	runs    []storage.JobRun
+}
+
+func (f *fakeSchedules) Jobs() []core.JobStatus {
+	return []core.JobStatus{{Name: "collect", Schedule: "@every 1m", Overlap: core.OverlapSkip, Paused: f.paused, Running: f.running}}
+}
+
+func (f *fakeSchedules) check(name string) error {
+	if name != "collect" {
+		return core.ErrUnknownJob
+	}
+	return nil
+}
+
+func (f *fakeSchedules) Pause(_ context.Context, name, _ string) error {
+	if err := f.check(name); err != nil {
+		return err
+	}
+	f.paused = true
+	return nil
+}
+
+func (f *fakeSchedules) Resume(_ context.Context, name, _ string) error {
+	if err := f.check(name); err != nil {
+		return err
+	}
+	f.paused = false
+	return nil
+}
+
+func (f *fakeSchedules) RunNow(_ context.Context, name, _ string) error {
+	if err := f.check(name); err != nil {
+		return err
+	}
+	if f.running {
+		return core.ErrJobRunning
+	}
+	f.running = true
+	return nil
+}
+
+func (f *fakeSchedules) Runs(_ context.Context, name string, limit int) ([]storage.JobRun, error) {
+	if err := f.check(name); err != nil {
+		return nil, err
+	}
+	if limit < len(f.runs) {
+		return f.runs[:limit], nil
+	}
+	return f.runs, nil
+}
+
+func TestHTTPContractSchedules(t *testing.T) {
+	store := &fakeStore{}
+	started := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	sched := &fakeSchedules{runs: []storage.JobRun{
+		{ID: 2, Job: "collect", Trigger: "manual", StartedAt: started.Add(time.Minute), FinishedAt: started.Add(time.Minute), Status: "error", Error: "boom"},
+		{ID: 1, Job: "collect", Trigger: "schedule", StartedAt: started, FinishedAt: started, Status: "ok"},
+	}}
+	adapter := newAdapterWithStore(t, store, false, Config{Schedules: sched})
+	h := adapter.routes()
+	do := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodGet, "/v1/schedules")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("list status = %d: %s", rr.Code, rr.Body.String())
+	}
+	var list struct {
+		Items []struct {
+			Name       string          `json:"name"`
+			Paused     bool            `json:"paused"`
+			LastResult *storage.JobRun `json:"last_result"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Items) != 1 || list.Items[0].LastResult == nil || list.Items[0].LastResult.Error != "boom" {
+		t.Fatalf("unexpected list: %s", rr.Body.String())
+	}
+
+	if rr := do(http.MethodGet, "/v1/schedules/collect/runs?limit=1"); rr.Code != http.StatusOK || strings.Count(rr.Body.String(), `"job":"collect"`) != 1 {
+		t.Fatalf("runs: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodGet, "/v1/schedules/missing/runs"); rr.Code != http.StatusNotFound {
+		t.Fatalf("unknown job runs = %d, want 404", rr.Code)
+	}
+
+	for _, tc := range []struct {
+		path string
+		want int
+	}{
+		{"/v1/schedules/collect/pause", http.StatusAccepted},
+		{"/v1/schedules/collect/run", http.StatusAccepted},
+		{"/v1/schedules/collect/run", http.StatusConflict},
+		{"/v1/schedules/collect/resume", http.StatusAccepted},
+		{"/v1/schedules/missing/pause", http.StatusNotFound},
+	} {
+		if rr := do(http.MethodPost, tc.path); rr.Code != tc.want {
+			t.Fatalf("POST %s = %d, want %d: %s", tc.path, rr.Code, tc.want, rr.Body.String())
+		}
+	}
+	if sched.paused {
+		t.Fatal("job should be resumed")
+	}
+
+	var actions []string
+	for _, ev := range store.audit {
+		actions = append(actions, ev.Action+"/"+ev.Status)
+	}
+	want := []string{"web:schedules/ok", "web:schedules/ok", "web:schedules/error",
+		"web:schedule_pause/ok", "web:schedule_run/ok", "web:schedule_run/error", "web:schedule_resume/ok", "web:schedule_pause/error"}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Fatalf("audit = %v, want %v", actions, want)
+	}
+
+	denied := newAdapterWithStore(t, &fakeStore{}, false, Config{
+		Schedules: sched,
+		Tokens:    []TokenEntry{{ID: "t2", TokenSHA256: tokenSHA256("test-token"), Subject: "viewer", Enabled: true}},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/v1/schedules/collect/pause", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	rr = httptest.NewRecorder()
+	denied.routes().ServeHTTP(rr, req)
+	if rr.Code != http.StatusForbidden || sched.paused {
+		t.Fatalf("unauthorized pause = %d, paused %v", rr.Code, sched.paused)
+	}
+}
+
+func TestHTTPContractAlerts(t *testing.T) {
+	var gotAll bool
+	adapter := newAdapterWithStore(t, &fakeStore{}, false, Config{
+		Alerts: func(all bool) interface{} {
+			gotAll = all
+			return []storage.AlertState{{Rule: "high_memory", State: storage.AlertFiring, Severity: "critical", Value: 93}}
+		},
+	})
+	h := adapter.routes()
+	do := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do("/v1/alerts")
+	if rr.Code != http.StatusOK || gotAll {
+		t.Fatalf("status = %d all=%v: %s", rr.Code, gotAll, rr.Body.String())
+	}
+	var resp struct {
+		Items []storage.AlertState `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || len(resp.Items) != 1 || resp.Items[0].State != storage.AlertFiring {
+		t.Fatalf("unexpected response: %v %s", err, rr.Body.String())
+	}
+	if rr := do("/v1/alerts?state=all"); rr.Code != http.StatusOK || !gotAll {
+		t.Fatalf("state=all: %d all=%v", rr.Code, gotAll)
+	}
+	if rr := do("/v1/alerts?state=firing"); rr.Code != http.StatusBadRequest {
+		t.Fatalf("bad state = %d, want 400", rr.Code)
+	}
+}
+
+func TestHTTPContractMaintenance(t *testing.T) {
+	st := memory.New()
+	mgr := maintenance.NewManager(st, maintenance.Options{Audit: st})
+	adapter := newAdapterWithStore(t, &fakeStore{}, false, Config{Maintenance: mgr})
+	h := adapter.routes()
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := do(http.MethodPost, "/v1/silences", `{"matchers":["alertname=high_memory"],"duration":"2h","comment":"swap"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create silence = %d: %s", rr.Code, rr.Body.String())
+	}
+	var created struct {
+		Item storage.Silence `json:"item"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil || created.Item.ID == "" || created.Item.CreatedBy != "u1" {
+		t.Fatalf("unexpected silence: %v %s", err, rr.Body.String())
+	}
+	for body, want := range map[string]int{
+		`{"matchers":[],"duration":"1h"}`:                   http.StatusBadRequest,
+		`{"matchers":["alertname"],"duration":"1h"}`:        http.StatusBadRequest,
+		`{"matchers":["a=b"],"duration":"soon"}`:            http.StatusBadRequest,
+		`{"matchers":["a=b"],"duration":"1h","extra":true}`: http.StatusBadRequest,
+	} {
+		if rr := do(http.MethodPost, "/v1/silences", body); rr.Code != want {
+			t.Fatalf("POST %s = %d, want %d", body, rr.Code, want)
+		}
+	}
+	rr = do(http.MethodGet, "/v1/silences", "")
+	var list struct {
+		Items []maintenance.SilenceStatus `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil || len(list.Items) != 1 || list.Items[0].State != maintenance.StateActive {
+		t.Fatalf("unexpected list: %v %s", err, rr.Body.String())
+	}
+	if rr := do(http.MethodDelete, "/v1/silences/"+created.Item.ID, ""); rr.Code != http.StatusOK {
+		t.Fatalf("expire = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodDelete, "/v1/silences/missing", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("expire missing = %d", rr.Code)
+	}
+	if rr := do(http.MethodGet, "/v1/silences", ""); !strings.Contains(rr.Body.String(), `"items":[]`) {
+		t.Fatalf("expired silence must be hidden: %s", rr.Body.String())
+	}
+
+	if rr := do(http.MethodPost, "/v1/maintenance", `{"name":"nightly","schedule":"0 2 * * *","duration":"1h"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("create window = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodPost, "/v1/maintenance", `{"name":"broken","schedule":"0 2 * * *"}`); rr.Code != http.StatusBadRequest {
+		t.Fatalf("window without duration = %d", rr.Code)
+	}
+	rr = do(http.MethodGet, "/v1/maintenance", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"name":"nightly"`) {
+		t.Fatalf("list windows = %d: %s", rr.Code, rr.Body.String())
+	}
+	if rr := do(http.MethodDelete, "/v1/maintenance/nightly", ""); rr.Code != http.StatusOK {
+		t.Fatalf("delete window = %d", rr.Code)
+	}
+	if rr := do(http.MethodDelete, "/v1/maintenance/nightly", ""); rr.Code != http.StatusNotFound {
+		t.Fatalf("delete missing window = %d", rr.Code)
+	}
+
+	events, _ := st.QueryAudit(context.Background(), storage.AuditQuery{Limit: 20})
+	actions := map[string]int{}
+	for _, ev := range events {
+		actions[ev.Action]++
+	}
+	if actions["silence:create"] != 1 || actions["silence:expire"] != 1 || actions["maintenance:create"] != 1 || actions["maintenance:delete"] != 1 {
+		t.Fatalf("unexpected domain audit: %v", actions)
+	}
+}
+
+// actionRecorder разрешает все и запоминает проверенные действия.
+type actionRecorder struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *actionRecorder) Authorize(subject core.Subject, action core.Action) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, action.Module+":"+action.Command)
+	return nil
+}
+
+func TestHTTPContractMaintenanceFailClosed(t *testing.T) {
+	st := memory.New()
+	mgr := maintenance.NewManager(st, maintenance.Options{Audit: st})
+	authz := &actionRecorder{}
+	adapter := NewAdapter(core.NewRegistry(), authz, &fakeStore{}, Config{
+		Maintenance:   mgr,
+		AuditWriter:   failingAuditWriter{},
+		AuditRequired: true,
+		Tokens: []TokenEntry{{
+			ID: "t1", TokenSHA256: tokenSHA256("test-token"), Subject: "u1", Roles: []string{"admin"}, Enabled: true,
+		}},
+	})
+	h := adapter.routes()
+	for _, tc := range []struct{ method, path, body, action string }{
+		{http.MethodGet, "/v1/silences", "", "silence:list"},
+		{http.MethodPost, "/v1/silences", `{"matchers":["a=b"],"duration":"1h"}`, "silence:add"},
+		{http.MethodDelete, "/v1/silences/x", "", "silence:expire"},
+		{http.MethodGet, "/v1/maintenance", "", "maintenance:list"},
+		{http.MethodPost, "/v1/maintenance", `{"name":"w","duration":"1h"}`, "maintenance:start"},
+		{http.MethodDelete, "/v1/maintenance/w", "", "maintenance:delete"},
+	} {
+		authz.mu.Lock()
+		authz.actions = nil
+		authz.mu.Unlock()
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer test-token")
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		authz.mu.Lock()
+		actions := strings.Join(authz.actions, ",")
+		authz.mu.Unlock()
+		if actions != tc.action {
+			t.Fatalf("%s %s authorized %q, want %q", tc.method, tc.path, actions, tc.action)
+		}
+		want := http.StatusOK
+		if tc.method != http.MethodGet {
+			want = http.StatusServiceUnavailable
+		}
+		if rr.Code != want || want == http.StatusServiceUnavailable && !strings.Contains(rr.Body.String(), "audit_unavailable") {
+			t.Fatalf("%s %s = %d: %s", tc.method, tc.path, rr.Code, rr.Body.String())
+		}
+	}
+	if len(mgr.Silences(true)) != 0 || len(mgr.Windows()) != 0 {
+		t.Fatal("changes must not run without a started audit event")
+	}
+}
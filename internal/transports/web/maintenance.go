@@ -0,0 +1,286 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/maintenance"
+	"goadmin/internal/storage"
+)
+
+// MaintenanceController управляет silences и окнами обслуживания (реализуется maintenance.Manager).
+type MaintenanceController interface {
+	Silences(all bool) []maintenance.SilenceStatus
+	CreateSilence(ctx context.Context, spec maintenance.SilenceSpec, by core.Subject) (storage.Silence, error)
+	ExpireSilence(ctx context.Context, id string, by core.Subject) (storage.Silence, error)
+	Windows() []maintenance.WindowStatus
+	CreateWindow(ctx context.Context, spec maintenance.WindowSpec, by core.Subject) (maintenance.WindowStatus, error)
+	DeleteWindow(ctx context.Context, name string, by core.Subject) error
+}
+
+type silenceRequest struct {
+	Matchers []string   `json:"matchers"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Duration string     `json:"duration"`
+	Comment  string     `json:"comment"`
+}
+
+type windowRequest struct {
+	Name     string     `json:"name"`
+	Schedule string     `json:"schedule"`
+	Duration string     `json:"duration"`
+	StartsAt *time.Time `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+	Matchers []string   `json:"matchers"`
+	Comment  string     `json:"comment"`
+}
+
+// registerMaintenanceRoutes регистрирует /v1/silences и /v1/maintenance; права
+// проверяются по командам модулей silence и maintenance.
+func (a *Adapter) registerMaintenanceRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /v1/silences", chain(http.HandlerFunc(a.handleSilences),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:silences", core.Action{Module: "silence", Command: "list"}),
+		a.auditReadMiddleware("web:silences"),
+	))
+	mux.Handle("POST /v1/silences", chain(http.HandlerFunc(a.handleCreateSilence),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.maxBodyMiddleware(),
+		a.authorizeActionMiddleware("web:silence_create", core.Action{Module: "silence", Command: "add"}),
+	))
+	mux.Handle("DELETE /v1/silences/{id}", chain(http.HandlerFunc(a.handleExpireSilence),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:silence_expire", core.Action{Module: "silence", Command: "expire"}),
+	))
+	mux.Handle("GET /v1/maintenance", chain(http.HandlerFunc(a.handleWindows),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:maintenance", core.Action{Module: "maintenance", Command: "list"}),
+		a.auditReadMiddleware("web:maintenance"),
+	))
+	mux.Handle("POST /v1/maintenance", chain(http.HandlerFunc(a.handleCreateWindow),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.maxBodyMiddleware(),
+		a.authorizeActionMiddleware("web:maintenance_create", core.Action{Module: "maintenance", Command: "start"}),
+	))
+	mux.Handle("DELETE /v1/maintenance/{name}", chain(http.HandlerFunc(a.handleDeleteWindow),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:maintenance_delete", core.Action{Module: "maintenance", Command: "delete"}),
+	))
+}
+
+func (a *Adapter) handleSilences(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if state != "" && state != "active" && state != "all" {
+		writeError(w, r, http.StatusBadRequest, "bad_state")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Maintenance.Silences(state == "all"),
+	})
+}
+
+func (a *Adapter) handleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	var req silenceRequest
+	if code, status := decodeJSONBody(r, &req); code != "" {
+		a.maintenanceResult(w, r, "web:silence_create", status, code, nil, nil)
+		return
+	}
+	spec := maintenance.SilenceSpec{Matchers: req.Matchers, Comment: req.Comment}
+	if req.StartsAt != nil {
+		spec.StartsAt = *req.StartsAt
+	}
+	if req.EndsAt != nil {
+		spec.EndsAt = *req.EndsAt
+	}
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			a.maintenanceResult(w, r, "web:silence_create", http.StatusBadRequest, "bad_silence", nil, nil)
+			return
+		}
+		spec.Duration = d
+	}
+	if !a.maintenanceStarted(w, r, "web:silence_create", nil) {
+		return
+	}
+	sil, err := a.cfg.Maintenance.CreateSilence(r.Context(), spec, a.webSubject(r))
+	code, status := maintenanceError(err, "bad_silence", "silence_not_found")
+	if code == "" {
+		status = http.StatusCreated
+	}
+	a.maintenanceResult(w, r, "web:silence_create", status, code, map[string]string{"id": sil.ID}, sil)
+}
+
+func (a *Adapter) handleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	id := r.PathValue("id")
+	if !a.maintenanceStarted(w, r, "web:silence_expire", map[string]string{"id": id}) {
+		return
+	}
+	sil, err := a.cfg.Maintenance.ExpireSilence(r.Context(), id, a.webSubject(r))
+	code, status := maintenanceError(err, "bad_silence", "silence_not_found")
+	a.maintenanceResult(w, r, "web:silence_expire", status, code, map[string]string{"id": id}, sil)
+}
+
+func (a *Adapter) handleWindows(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      a.cfg.Maintenance.Windows(),
+	})
+}
+
+func (a *Adapter) handleCreateWindow(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	var req windowRequest
+	if code, status := decodeJSONBody(r, &req); code != "" {
+		a.maintenanceResult(w, r, "web:maintenance_create", status, code, nil, nil)
+		return
+	}
+	spec := maintenance.WindowSpec{
+		Name:     req.Name,
+		Schedule: req.Schedule,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Matchers: req.Matchers,
+		Comment:  req.Comment,
+	}
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			a.maintenanceResult(w, r, "web:maintenance_create", http.StatusBadRequest, "bad_window", map[string]string{"name": req.Name}, nil)
+			return
+		}
+		spec.Duration = d
+	}
+	if !a.maintenanceStarted(w, r, "web:maintenance_create", map[string]string{"name": req.Name}) {
+		return
+	}
+	win, err := a.cfg.Maintenance.CreateWindow(r.Context(), spec, a.webSubject(r))
+	code, status := maintenanceError(err, "bad_window", "window_not_found")
+	if code == "" {
+		status = http.StatusCreated
+	}
+	a.maintenanceResult(w, r, "web:maintenance_create", status, code, map[string]string{"name": req.Name}, win)
+}
+
+func (a *Adapter) handleDeleteWindow(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Maintenance == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	name := r.PathValue("name")
+	if !a.maintenanceStarted(w, r, "web:maintenance_delete", map[string]string{"name": name}) {
+		return
+	}
+	err := a.cfg.Maintenance.DeleteWindow(r.Context(), name, a.webSubject(r))
+	code, status := maintenanceError(err, "bad_window", "window_not_found")
+	a.maintenanceResult(w, r, "web:maintenance_delete", status, code, map[string]string{"name": name}, map[string]string{"deleted": name})
+}
+
+func (a *Adapter) webSubject(r *http.Request) core.Subject {
+	return core.Subject{Source: "web", ID: subjectIDFromContext(r.Context())}
+}
+
+// maintenanceStarted при AuditRequired пишет событие "started" до изменения;
+// если его не удалось сохранить, отвечает 503 и возвращает false.
+func (a *Adapter) maintenanceStarted(w http.ResponseWriter, r *http.Request, auditAction string, payload map[string]string) bool {
+	if !a.cfg.AuditRequired {
+		return true
+	}
+	ctx := r.Context()
+	started := map[string]string{"auth_method": authMethodFromContext(ctx)}
+	for k, v := range payload {
+		started[k] = v
+	}
+	if err := a.writeAudit(ctx, subjectIDFromContext(ctx), auditAction, "started", started, requestIDFromContext(ctx)); err != nil {
+		writeError(w, r, http.StatusServiceUnavailable, "audit_unavailable")
+		return false
+	}
+	return true
+}
+
+// maintenanceResult аудирует изменение и пишет ответ: item при успехе, ошибку иначе.
+func (a *Adapter) maintenanceResult(w http.ResponseWriter, r *http.Request, auditAction string, status int, code string, payload map[string]string, item interface{}) {
+	ctx := r.Context()
+	if payload == nil {
+		payload = map[string]string{}
+	}
+	payload["auth_method"] = authMethodFromContext(ctx)
+	auditStatus := "ok"
+	if code != "" {
+		auditStatus = "error"
+		payload["error_code"] = code
+	}
+	// Итоговое событие best-effort: в fail-closed режиме "started" уже сохранено.
+	_ = a.writeAudit(ctx, subjectIDFromContext(ctx), auditAction, auditStatus, payload, requestIDFromContext(ctx))
+	if code != "" {
+		writeError(w, r, status, code)
+		return
+	}
+	writeJSON(w, r, status, map[string]interface{}{
+		"request_id": requestIDFromContext(ctx),
+		"item":       item,
+	})
+}
+
+// maintenanceError переводит ошибку менеджера в код и HTTP-статус; "" - успех.
+func maintenanceError(err error, invalid, notFound string) (string, int) {
+	switch {
+	case err == nil:
+		return "", http.StatusOK
+	case maintenance.IsInvalid(err):
+		return invalid, http.StatusBadRequest
+	case errors.Is(err, storage.ErrNotFound):
+		return notFound, http.StatusNotFound
+	default:
+		return "maintenance_failed", http.StatusInternalServerError
+	}
+}
+
+// decodeJSONBody читает один JSON-объект без лишних полей.
+func decodeJSONBody(r *http.Request, v interface{}) (string, int) {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		if isBodyTooLargeErr(err) {
+			return "payload_too_large", http.StatusRequestEntityTooLarge
+		}
+		return "invalid_json", http.StatusBadRequest
+	}
+	var trailing json.RawMessage
+	if err := dec.Decode(&trailing); err != io.EOF {
+		return "invalid_json", http.StatusBadRequest
+	}
+	return "", 0
+}
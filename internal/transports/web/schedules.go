@@ -0,0 +1,144 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// ScheduleController управляет задачами планировщика (реализуется app.Schedules).
+type ScheduleController interface {
+	Jobs() []core.JobStatus
+	Pause(ctx context.Context, name, by string) error
+	Resume(ctx context.Context, name, by string) error
+	RunNow(ctx context.Context, name, by string) error
+	Runs(ctx context.Context, name string, limit int) ([]storage.JobRun, error)
+}
+
+// scheduleItem - задача в /v1/schedules вместе с последним запуском из истории.
+type scheduleItem struct {
+	core.JobStatus
+	LastResult *storage.JobRun `json:"last_result,omitempty"`
+}
+
+func (a *Adapter) registerScheduleRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /v1/schedules", chain(http.HandlerFunc(a.handleSchedules),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:schedules", core.Action{Module: "schedules", Command: "read"}),
+		a.auditReadMiddleware("web:schedules"),
+	))
+	mux.Handle("GET /v1/schedules/{name}/runs", chain(http.HandlerFunc(a.handleScheduleRuns),
+		a.timeoutMiddleware(),
+		a.authSubjectMiddleware(),
+		a.authorizeActionMiddleware("web:schedules", core.Action{Module: "schedules", Command: "read"}),
+		a.auditReadMiddleware("web:schedules"),
+	))
+	for _, op := range []string{"run", "pause", "resume"} {
+		mux.Handle("POST /v1/schedules/{name}/"+op, chain(a.scheduleControlHandler(op),
+			a.timeoutMiddleware(),
+			a.authSubjectMiddleware(),
+			a.authorizeActionMiddleware("web:schedule_"+op, core.Action{Module: "schedules", Command: op}),
+		))
+	}
+}
+
+func (a *Adapter) handleSchedules(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Schedules == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	jobs := a.cfg.Schedules.Jobs()
+	items := make([]scheduleItem, 0, len(jobs))
+	for _, job := range jobs {
+		item := scheduleItem{JobStatus: job}
+		runs, err := a.cfg.Schedules.Runs(r.Context(), job.Name, 1)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "query_failed")
+			return
+		}
+		if len(runs) > 0 {
+			item.LastResult = &runs[0]
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      items,
+	})
+}
+
+func (a *Adapter) handleScheduleRuns(w http.ResponseWriter, r *http.Request) {
+	if a.cfg.Schedules == nil {
+		writeError(w, r, http.StatusNotImplemented, "not_supported")
+		return
+	}
+	runs, err := a.cfg.Schedules.Runs(r.Context(), r.PathValue("name"), parseLimit(r.URL.Query().Get("limit")))
+	switch {
+	case errors.Is(err, core.ErrUnknownJob):
+		writeError(w, r, http.StatusNotFound, "job_not_found")
+		return
+	case err != nil:
+		writeError(w, r, http.StatusInternalServerError, "query_failed")
+		return
+	}
+	if runs == nil {
+		runs = []storage.JobRun{}
+	}
+	writeJSON(w, r, http.StatusOK, map[string]interface{}{
+		"request_id": requestIDFromContext(r.Context()),
+		"items":      runs,
+	})
+}
+
+// scheduleControlHandler выполняет run|pause|resume и аудирует результат.
+func (a *Adapter) scheduleControlHandler(op string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if a.cfg.Schedules == nil {
+			writeError(w, r, http.StatusNotImplemented, "not_supported")
+			return
+		}
+		ctx := r.Context()
+		subjectID := subjectIDFromContext(ctx)
+		name := r.PathValue("name")
+		var err error
+		switch op {
+		case "run":
+			err = a.cfg.Schedules.RunNow(ctx, name, subjectID)
+		case "pause":
+			err = a.cfg.Schedules.Pause(ctx, name, subjectID)
+		case "resume":
+			err = a.cfg.Schedules.Resume(ctx, name, subjectID)
+		}
+
+		status, code, httpStatus := "ok", "", http.StatusAccepted
+		switch {
+		case err == nil:
+		case errors.Is(err, core.ErrUnknownJob):
+			status, code, httpStatus = "error", "job_not_found", http.StatusNotFound
+		case errors.Is(err, core.ErrJobRunning):
+			status, code, httpStatus = "error", "job_running", http.StatusConflict
+		case errors.Is(err, core.ErrSchedulerStopped):
+			status, code, httpStatus = "error", "scheduler_stopped", http.StatusServiceUnavailable
+		default:
+			status, code, httpStatus = "error", "schedule_failed", http.StatusInternalServerError
+		}
+		payload := map[string]string{"job": name, "auth_method": authMethodFromContext(ctx)}
+		if code != "" {
+			payload["error_code"] = code
+		}
+		_ = a.writeAudit(ctx, subjectID, "web:schedule_"+op, status, payload, requestIDFromContext(ctx))
+		if code != "" {
+			writeError(w, r, httpStatus, code)
+			return
+		}
+		writeJSON(w, r, httpStatus, map[string]interface{}{
+			"request_id": requestIDFromContext(ctx),
+			"job":        name,
+			"action":     op,
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// AlertmanagerMessage - тело webhook в формате Alertmanager (version 4).
+type AlertmanagerMessage struct {
+	Version           string              `json:"version"`
+	GroupKey          string              `json:"groupKey"`
+	TruncatedAlerts   int                 `json:"truncatedAlerts"`
+	Status            string              `json:"status"`
+	Receiver          string              `json:"receiver"`
+	GroupLabels       map[string]string   `json:"groupLabels"`
+	CommonLabels      map[string]string   `json:"commonLabels"`
+	CommonAnnotations map[string]string   `json:"commonAnnotations"`
+	ExternalURL       string              `json:"externalURL"`
+	Alerts            []AlertmanagerAlert `json:"alerts"`
+}
+
+// AlertmanagerAlert - алерт внутри AlertmanagerMessage.
+type AlertmanagerAlert struct {
+	Status       string            `json:"status"`
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	StartsAt     time.Time         `json:"startsAt"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+	Fingerprint  string            `json:"fingerprint"`
+}
+
+// NewAlertmanagerMessage собирает сообщение об одном алерте. Метка state
+// уведомления переходит в status (firing|resolved), остальные метки - в labels.
+func NewAlertmanagerMessage(receiver, externalURL string, n core.Notification) AlertmanagerMessage {
+	status := n.Labels["state"]
+	if status != storage.AlertResolved {
+		status = storage.AlertFiring
+	}
+	labels := make(map[string]string, len(n.Labels))
+	for k, v := range n.Labels {
+		if k != "state" {
+			labels[k] = v
+		}
+	}
+	annotations := map[string]string{"summary": n.Title}
+	if n.Text != "" {
+		annotations["description"] = n.Text
+	}
+	groupLabels := map[string]string{"alertname": labels["alertname"]}
+	alert := AlertmanagerAlert{
+		Status:       status,
+		Labels:       labels,
+		Annotations:  annotations,
+		StartsAt:     n.StartsAt.UTC(),
+		GeneratorURL: externalURL,
+		Fingerprint:  fingerprint(labels),
+	}
+	// Alertmanager передает нулевое время endsAt у активных алертов.
+	if status == storage.AlertResolved {
+		alert.EndsAt = n.EndsAt.UTC()
+	}
+	return AlertmanagerMessage{
+		Version:           "4",
+		GroupKey:          fmt.Sprintf("{}:{alertname=%q}", labels["alertname"]),
+		Status:            status,
+		Receiver:          receiver,
+		GroupLabels:       groupLabels,
+		CommonLabels:      labels,
+		CommonAnnotations: annotations,
+		ExternalURL:       externalURL,
+		Alerts:            []AlertmanagerAlert{alert},
+	}
+}
+
+// fingerprint - стабильный идентификатор набора меток (16 hex-символов, как у Alertmanager).
+func fingerprint(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	h := fnv.New64a()
+	for _, k := range names {
+		_, _ = h.Write([]byte(k + "\xff" + labels[k] + "\xff"))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// alertEvent - generic-представление уведомления об алерте.
+func alertEvent(n core.Notification) Event {
+	state := n.Labels["state"]
+	if state == "" {
+		state = storage.AlertFiring
+	}
+	data := map[string]interface{}{
+		"labels": n.Labels,
+		"title":  n.Title,
+		"text":   n.Text,
+	}
+	if !n.StartsAt.IsZero() {
+		data["starts_at"] = n.StartsAt.UTC()
+	}
+	if !n.EndsAt.IsZero() {
+		data["ends_at"] = n.EndsAt.UTC()
+	}
+	return Event{
+		Type:    EventAlertPrefix + strings.ToLower(state),
+		Source:  "alert",
+		Subject: n.Labels["alertname"],
+		Status:  n.Severity,
+		Data:    data,
+	}
+}
@@ -0,0 +1,416 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"goadmin/internal/alert"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+// Форматы тела запроса.
+const (
+	// FormatGeneric - Event в JSON.
+	FormatGeneric = "generic"
+	// FormatAlertmanager - AlertmanagerMessage; получает только алерты.
+	FormatAlertmanager = "alertmanager"
+)
+
+var errBadEndpoint = errors.New("invalid webhook endpoint")
+
+// EndpointConfig задает получателя. Нулевые параметры доставки заменяются
+// значениями по умолчанию: очередь 256, 5 попыток, пауза 1s с удвоением до 1m,
+// таймаут запроса 5s.
+type EndpointConfig struct {
+	Name   string
+	URL    string
+	Format string
+	// Events - шаблоны типов событий (MatchEvent); пусто - все.
+	Events []string
+	// Secret включает подпись HMAC-SHA256 (заголовки X-Goadmin-Timestamp и X-Goadmin-Signature).
+	Secret  []byte
+	Headers map[string]string
+	// ExternalURL - externalURL и generatorURL в формате Alertmanager.
+	ExternalURL string
+	QueueSize   int
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+	Client      *http.Client
+}
+
+// EndpointStats - счетчики доставки одного получателя.
+type EndpointStats struct {
+	Name      string `json:"name"`
+	Format    string `json:"format"`
+	Delivered uint64 `json:"delivered"`
+	Failed    uint64 `json:"failed"`
+	Retries   uint64 `json:"retries"`
+	Dropped   uint64 `json:"dropped"`
+	Queued    int    `json:"queued"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+type delivery struct {
+	event  Event
+	notice *core.Notification
+}
+
+type endpoint struct {
+	cfg    EndpointConfig
+	client *http.Client
+	queue  chan delivery
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	retries   atomic.Uint64
+	dropped   atomic.Uint64
+	lastErr   atomic.Value
+}
+
+// Dispatcher рассылает события получателям. У каждого получателя своя
+// ограниченная очередь и один обработчик: порядок доставки сохраняется,
+// медленный получатель не задерживает остальных, при переполнении новое
+// событие отбрасывается.
+type Dispatcher struct {
+	mu        sync.RWMutex
+	endpoints []*endpoint
+	closed    bool
+	now       func() time.Time
+}
+
+// NewDispatcher проверяет получателей и запускает их обработчики.
+func NewDispatcher(cfgs []EndpointConfig) (*Dispatcher, error) {
+	d := &Dispatcher{now: time.Now}
+	eps, err := newEndpoints(cfgs)
+	if err != nil {
+		return nil, err
+	}
+	d.endpoints = eps
+	return d, nil
+}
+
+func newEndpoints(cfgs []EndpointConfig) ([]*endpoint, error) {
+	seen := make(map[string]bool, len(cfgs))
+	eps := make([]*endpoint, 0, len(cfgs))
+	for i, c := range cfgs {
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("webhook-%d", i)
+		}
+		if seen[c.Name] {
+			cancelEndpoints(eps)
+			return nil, fmt.Errorf("%w: duplicate name %q", errBadEndpoint, c.Name)
+		}
+		seen[c.Name] = true
+		ep, err := newEndpoint(c)
+		if err != nil {
+			cancelEndpoints(eps)
+			return nil, fmt.Errorf("webhook %s: %w", c.Name, err)
+		}
+		eps = append(eps, ep)
+	}
+	for _, ep := range eps {
+		go ep.run()
+	}
+	return eps, nil
+}
+
+func newEndpoint(c EndpointConfig) (*endpoint, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be http(s)://host/...", errBadEndpoint)
+	}
+	switch c.Format {
+	case "":
+		c.Format = FormatGeneric
+	case FormatGeneric, FormatAlertmanager:
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", errBadEndpoint, c.Format)
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 256
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = time.Minute
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = c.Backoff
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: c.Timeout}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &endpoint{
+		cfg:    c,
+		client: client,
+		queue:  make(chan delivery, c.QueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Update заменяет получателей (перечитывание конфига); счетчики новых
+// получателей начинаются с нуля. Старые получатели досылают уже
+// поставленные события в фоне.
+func (d *Dispatcher) Update(cfgs []EndpointConfig) error {
+	eps, err := newEndpoints(cfgs)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		stopEndpoints(eps)
+		return errors.New("webhook dispatcher is closed")
+	}
+	old := d.endpoints
+	d.endpoints = eps
+	d.mu.Unlock()
+	stopEndpoints(old)
+	return nil
+}
+
+func cancelEndpoints(eps []*endpoint) {
+	for _, ep := range eps {
+		ep.cancel()
+	}
+}
+
+func stopEndpoints(eps []*endpoint) {
+	for _, ep := range eps {
+		close(ep.queue)
+	}
+}
+
+// Publish ставит событие в очереди подходящих generic-получателей; ID и Time
+// заполняются, если пусты. Не блокируется.
+func (d *Dispatcher) Publish(ev Event) {
+	if ev.ID == "" {
+		ev.ID = newEventID()
+	}
+	if ev.Time.IsZero() {
+		ev.Time = d.now()
+	}
+	d.enqueue(delivery{event: ev})
+}
+
+// Notify реализует core.Notifier: уведомления об алертах уходят
+// Alertmanager-получателям и generic-получателям как alert.<state>.
+// Уведомления других тем пропускаются: задачи публикуются как job.finished.
+func (d *Dispatcher) Notify(_ context.Context, n core.Notification) error {
+	if n.Topic != alert.TopicAlerts {
+		return nil
+	}
+	ev := alertEvent(n)
+	ev.ID = newEventID()
+	ev.Time = d.now()
+	d.enqueue(delivery{event: ev, notice: &n})
+	return nil
+}
+
+func (d *Dispatcher) enqueue(item delivery) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+	for _, ep := range d.endpoints {
+		if !ep.accepts(item) {
+			continue
+		}
+		select {
+		case ep.queue <- item:
+		default:
+			ep.dropped.Add(1)
+		}
+	}
+}
+
+// Stats возвращает счетчики получателей в порядке конфигурации.
+func (d *Dispatcher) Stats() []EndpointStats {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	out := make([]EndpointStats, 0, len(d.endpoints))
+	for _, ep := range d.endpoints {
+		st := EndpointStats{
+			Name:      ep.cfg.Name,
+			Format:    ep.cfg.Format,
+			Delivered: ep.delivered.Load(),
+			Failed:    ep.failed.Load(),
+			Retries:   ep.retries.Load(),
+			Dropped:   ep.dropped.Load(),
+			Queued:    len(ep.queue),
+		}
+		if v, ok := ep.lastErr.Load().(string); ok {
+			st.LastError = v
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// Close перестает принимать события и ждет доставки очередей до ctx;
+// после ctx текущие повторы прерываются.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	eps := d.endpoints
+	d.mu.Unlock()
+	stopEndpoints(eps)
+	for _, ep := range eps {
+		select {
+		case <-ep.done:
+		case <-ctx.Done():
+			cancelEndpoints(eps)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// AuditDestination возвращает приемник для audit.Fanout, публикующий
+// command.executed и access.denied (см. FromAudit).
+func (d *Dispatcher) AuditDestination() *AuditDestination {
+	return &AuditDestination{d: d}
+}
+
+// AuditDestination - копия аудита для webhook; закрывается вместе с Dispatcher.
+type AuditDestination struct {
+	d *Dispatcher
+}
+
+func (a *AuditDestination) Name() string { return "webhooks" }
+
+// Send публикует событие аудита, если у него есть webhook-тип.
+func (a *AuditDestination) Send(_ context.Context, ev storage.AuditEvent) error {
+	if out, ok := FromAudit(ev); ok {
+		a.d.Publish(out)
+	}
+	return nil
+}
+
+// Close ничего не делает: очередями владеет Dispatcher.
+func (a *AuditDestination) Close() error { return nil }
+
+func (ep *endpoint) accepts(item delivery) bool {
+	if ep.cfg.Format == FormatAlertmanager && item.notice == nil {
+		return false
+	}
+	return MatchEvent(ep.cfg.Events, item.event.Type)
+}
+
+func (ep *endpoint) run() {
+	defer close(ep.done)
+	defer ep.cancel()
+	for item := range ep.queue {
+		ep.deliver(item)
+	}
+}
+
+// deliver отправляет событие; 5xx, 429 и сетевые ошибки повторяются с
+// экспоненциальной паузой до MaxAttempts попыток.
+func (ep *endpoint) deliver(item delivery) {
+	var payload interface{} = item.event
+	if ep.cfg.Format == FormatAlertmanager {
+		payload = NewAlertmanagerMessage(ep.cfg.Name, ep.cfg.ExternalURL, *item.notice)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		ep.fail(err)
+		return
+	}
+	backoff := ep.cfg.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := ep.post(item.event, body)
+		if err == nil {
+			ep.delivered.Add(1)
+			return
+		}
+		if !retry || attempt >= ep.cfg.MaxAttempts {
+			ep.fail(err)
+			return
+		}
+		ep.retries.Add(1)
+		select {
+		case <-ep.ctx.Done():
+			ep.fail(err)
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > ep.cfg.MaxBackoff {
+			backoff = ep.cfg.MaxBackoff
+		}
+	}
+}
+
+func (ep *endpoint) fail(err error) {
+	ep.failed.Add(1)
+	ep.lastErr.Store(err.Error())
+}
+
+func (ep *endpoint) post(ev Event, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(ep.ctx, ep.cfg.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range ep.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(HeaderEvent, ev.Type)
+	req.Header.Set(HeaderDelivery, ev.ID)
+	if len(ep.cfg.Secret) > 0 {
+		ts := time.Now().Unix()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(HeaderSignature, Sign(ep.cfg.Secret, ts, body))
+	}
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		// url.Error содержит адрес получателя, в нем может быть токен.
+		var uerr *url.Error
+		if errors.As(err, &uerr) {
+			err = uerr.Err
+		}
+		return ep.ctx.Err() == nil, fmt.Errorf("post: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
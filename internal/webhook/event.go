@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"time"
+
+	"goadmin/internal/storage"
+)
+
+// Типы событий generic-формата.
+const (
+	EventCommandExecuted = "command.executed"
+	EventAccessDenied    = "access.denied"
+	EventJobFinished     = "job.finished"
+	EventConfigReloaded  = "config.reloaded"
+	// EventAlertPrefix - префикс событий алертов: alert.firing, alert.resolved.
+	EventAlertPrefix = "alert."
+)
+
+// Event - внутреннее событие агента в generic-формате webhook.
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Time      time.Time   `json:"time"`
+	Source    string      `json:"source,omitempty"`
+	Subject   string      `json:"subject,omitempty"`
+	Action    string      `json:"action,omitempty"`
+	Status    string      `json:"status,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// FromAudit переводит событие аудита в webhook-событие: отказ в доступе - access.denied,
+// итог команды ядра из web (web:execute) или чата - command.executed. Остальные
+// события аудита (запросы web API, изменения из CLI и фоновых задач) не публикуются;
+// команды отличаются от них наличием request_id.
+func FromAudit(ev storage.AuditEvent) (Event, bool) {
+	out := Event{
+		Time:      ev.TS,
+		Source:    ev.Source,
+		Subject:   ev.Subject,
+		Action:    ev.Action,
+		Status:    ev.Status,
+		RequestID: ev.RequestID,
+	}
+	switch {
+	case ev.Status == "denied":
+		out.Type = EventAccessDenied
+	case (ev.Status == "ok" || ev.Status == "error") && ev.RequestID != "" &&
+		(ev.Action == "web:execute" || ev.Source != "web"):
+		out.Type = EventCommandExecuted
+	default:
+		return Event{}, false
+	}
+	if len(ev.Payload) > 0 && json.Valid(ev.Payload) {
+		out.Data = json.RawMessage(ev.Payload)
+	}
+	return out, true
+}
+
+// MatchEvent проверяет тип события по шаблонам: точное имя, "job.*" или "*";
+// пустой список подходит для любого типа.
+func MatchEvent(patterns []string, eventType string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, eventType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func newEventID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Заголовки доставки.
+const (
+	HeaderEvent     = "X-Goadmin-Event"
+	HeaderDelivery  = "X-Goadmin-Delivery"
+	HeaderTimestamp = "X-Goadmin-Timestamp"
+	HeaderSignature = "X-Goadmin-Signature"
+)
+
+var (
+	// ErrBadSignature - подпись отсутствует или не совпадает.
+	ErrBadSignature = errors.New("webhook signature mismatch")
+	// ErrStaleTimestamp - метка времени вне допустимого окна (защита от повтора).
+	ErrStaleTimestamp = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign возвращает "sha256=<hex>" - HMAC-SHA256 от "<timestamp>.<body>".
+func Sign(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет подпись запроса на стороне получателя; tolerance <= 0
+// отключает проверку возраста метки времени.
+func Verify(secret []byte, h http.Header, body []byte, now time.Time, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(h.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return ErrBadSignature
+	}
+	if tolerance > 0 {
+		if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return ErrStaleTimestamp
+		}
+	}
+	got := h.Get(HeaderSignature)
+	if !strings.HasPrefix(got, "sha256=") || !hmac.Equal([]byte(got), []byte(Sign(secret, ts, body))) {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"goadmin/internal/alert"
+	"goadmin/internal/core"
+	"goadmin/internal/storage"
+)
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver - локальный получатель webhook; fail первых запросов отвечает 503.
+type receiver struct {
+	mu    sync.Mutex
+	calls []received
+	fail  atomic.Int32
+	got   chan struct{}
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	t.Helper()
+	rc := &receiver{got: make(chan struct{}, 100)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if rc.fail.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rc.mu.Lock()
+		rc.calls = append(rc.calls, received{header: r.Header.Clone(), body: body})
+		rc.mu.Unlock()
+		rc.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rc, srv
+}
+
+func (rc *receiver) wait(t *testing.T, n int) []received {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.got:
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d", i+1)
+		}
+	}
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return append([]received(nil), rc.calls...)
+}
+
+// waitStats ждет, пока счетчики первого получателя не удовлетворят ok.
+func waitStats(t *testing.T, d *Dispatcher, ok func(EndpointStats) bool) EndpointStats {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		st := d.Stats()[0]
+		if ok(st) {
+			return st
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v", st)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func closeDispatcher(t *testing.T, d *Dispatcher) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = d.Close(ctx)
+	})
+}
+
+func TestFromAudit(t *testing.T) {
+	cases := []struct {
+		ev   storage.AuditEvent
+		want string
+	}{
+		{storage.AuditEvent{Action: "host:status", Source: "telegram", Status: "ok", RequestID: "r1"}, EventCommandExecuted},
+		{storage.AuditEvent{Action: "web:execute", Source: "web", Status: "error", RequestID: "r2"}, EventCommandExecuted},
+		{storage.AuditEvent{Action: "web:audit_query", Source: "web", Status: "denied", RequestID: "r3"}, EventAccessDenied},
+		{storage.AuditEvent{Action: "schedule:run", Source: "cli", Status: "denied"}, EventAccessDenied},
+		{storage.AuditEvent{Action: "host:status", Source: "telegram", Status: "started", RequestID: "r4"}, ""},
+		{storage.AuditEvent{Action: "web:audit_query", Source: "web", Status: "ok", RequestID: "r5"}, ""},
+		{storage.AuditEvent{Action: "silence:create", Source: "telegram", Status: "ok"}, ""},
+	}
+	for _, tc := range cases {
+		ev, ok := FromAudit(tc.ev)
+		if ev.Type != tc.want || ok != (tc.want != "") {
+			t.Errorf("%s/%s/%s = %q, %v; want %q", tc.ev.Source, tc.ev.Action, tc.ev.Status, ev.Type, ok, tc.want)
+		}
+	}
+}
+
+func TestMatchEvent(t *testing.T) {
+	if !MatchEvent(nil, EventJobFinished) || !MatchEvent([]string{"*"}, EventAccessDenied) {
+		t.Fatal("empty list and * must match everything")
+	}
+	if !MatchEvent([]string{"job.*"}, EventJobFinished) || MatchEvent([]string{"job.*"}, EventCommandExecuted) {
+		t.Fatal("prefix pattern mismatch")
+	}
+}
+
+func TestGenericSignedDelivery(t *testing.T) {
+	rc, srv := newReceiver(t)
+	secret := []byte("s3cret")
+	d, err := NewDispatcher([]EndpointConfig{{
+		Name:    "ops",
+		URL:     srv.URL,
+		Events:  []string{"job.*", EventAccessDenied},
+		Secret:  secret,
+		Headers: map[string]string{"X-Team": "ops"},
+	}})
+	if err != nil {
+		t.Fatalf("new dispatcher: %v", err)
+	}
+	closeDispatcher(t, d)
+
+	d.Publish(Event{Type: EventCommandExecuted})
+	d.Publish(Event{Type: EventJobFinished, Subject: "backup", Status: "ok", Data: map[string]string{"job": "backup"}})
+	calls := rc.wait(t, 1)
+	if len(calls) != 1 {
+		t.Fatalf("filtered event delivered: %d calls", len(calls))
+	}
+	c := calls[0]
+	if err := Verify(secret, c.header, c.body, time.Now(), time.Minute); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if err := Verify([]byte("other"), c.header, c.body, time.Now(), time.Minute); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("verify with wrong secret = %v", err)
+	}
+	if err := Verify(secret, c.header, c.body, time.Now().Add(time.Hour), time.Minute); !errors.Is(err, ErrStaleTimestamp) {
+		t.Fatalf("verify stale = %v", err)
+	}
+	if c.header.Get(HeaderEvent) != EventJobFinished || c.header.Get("X-Team") != "ops" || c.header.Get(HeaderDelivery) == "" {
+		t.Fatalf("headers = %v", c.header)
+	}
+	var ev Event
+	if err := json.Unmarshal(c.body, &ev); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ev.Type != EventJobFinished || ev.Subject != "backup" || ev.ID != c.header.Get(HeaderDelivery) || ev.Time.IsZero() {
+		t.Fatalf("event = %+v", ev)
+	}
+}
+
+func TestRetriesAndStats(t *testing.T) {
+	rc, srv := newReceiver(t)
+	rc.fail.Store(2)
+	d, err := NewDispatcher([]EndpointConfig{{Name: "flaky", URL: srv.URL, Backoff: time.Millisecond, MaxAttempts: 3}})
+	if err != nil {
+		t.Fatalf("new dispatcher: %v", err)
+	}
+	closeDispatcher(t, d)
+
+	d.Publish(Event{Type: EventConfigReloaded, Status: "ok"})
+	st := waitStats(t, d, func(st EndpointStats) bool { return st.Delivered == 1 })
+	if st.Retries != 2 || st.Failed != 0 {
+		t.Fatalf("stats = %+v", st)
+	}
+
+	// Исчерпание попыток засчитывается как failed.
+	rc.fail.Store(10)
+	d.Publish(Event{Type: EventConfigReloaded, Status: "error"})
+	st = waitStats(t, d, func(st EndpointStats) bool { return st.Failed == 1 })
+	if st.Retries != 4 || st.LastError != "unexpected status 503" {
+		t.Fatalf("stats = %+v", st)
+	}
+}
+
+func TestAlertmanagerFormat(t *testing.T) {
+	rc, srv := newReceiver(t)
+	generic, gsrv := newReceiver(t)
+	d, err := NewDispatcher([]EndpointConfig{
+		{Name: "am", URL: srv.URL, Format: FormatAlertmanager, ExternalURL: "http://goadmin.local"},
+		{Name: "events", URL: gsrv.URL, Events: []string{"alert.*"}},
+	})
+	if err != nil {
+		t.Fatalf("new dispatcher: %v", err)
+	}
+	closeDispatcher(t, d)
+
+	fired := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	st := storage.AlertState{Rule: "high_memory", State: storage.AlertFiring, Severity: "critical", Expr: "mem_used_pct > 90", FiredAt: &fired, Labels: map[string]string{"team": "ops"}}
+	_ = d.Notify(context.Background(), core.Notification{Topic: core.TopicJobs, Title: "ignored"})
+	_ = d.Notify(context.Background(), alert.Notification(st))
+	resolved := fired.Add(time.Hour)
+	st.State, st.ResolvedAt = storage.AlertResolved, &resolved
+	_ = d.Notify(context.Background(), alert.Notification(st))
+	// Alertmanager-получатель не принимает generic-события.
+	d.Publish(Event{Type: EventJobFinished})
+
+	calls := rc.wait(t, 2)
+	var firing, done AlertmanagerMessage
+	if err := json.Unmarshal(calls[0].body, &firing); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if err := json.Unmarshal(calls[1].body, &done); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if firing.Version != "4" || firing.Status != "firing" || firing.Receiver != "am" || len(firing.Alerts) != 1 {
+		t.Fatalf("firing message = %+v", firing)
+	}
+	a := firing.Alerts[0]
+	if a.Labels["alertname"] != "high_memory" || a.Labels["team"] != "ops" || a.Labels["severity"] != "critical" || a.Labels["state"] != "" {
+		t.Fatalf("labels = %v", a.Labels)
+	}
+	if !a.StartsAt.Equal(fired) || !a.EndsAt.IsZero() || a.GeneratorURL != "http://goadmin.local" || len(a.Fingerprint) != 16 {
+		t.Fatalf("alert = %+v", a)
+	}
+	if firing.GroupKey != `{}:{alertname="high_memory"}` || firing.CommonAnnotations["summary"] == "" {
+		t.Fatalf("group = %q annotations = %v", firing.GroupKey, firing.CommonAnnotations)
+	}
+	r := done.Alerts[0]
+	if done.Status != "resolved" || r.Status != "resolved" || !r.EndsAt.Equal(resolved) || r.Fingerprint != a.Fingerprint {
+		t.Fatalf("resolved = %+v", done)
+	}
+
+	gcalls := generic.wait(t, 2)
+	if gcalls[0].header.Get(HeaderEvent) != "alert.firing" || gcalls[1].header.Get(HeaderEvent) != "alert.resolved" {
+		t.Fatalf("generic alert events = %s, %s", gcalls[0].header.Get(HeaderEvent), gcalls[1].header.Get(HeaderEvent))
+	}
+}
+
+func TestUpdateAndValidation(t *testing.T) {
+	for _, cfg := range []EndpointConfig{
+		{Name: "x", URL: "ftp://example.com"},
+		{Name: "x", URL: "http://example.com", Format: "slack"},
+	} {
+		if _, err := NewDispatcher([]EndpointConfig{cfg}); !errors.Is(err, errBadEndpoint) {
+			t.Fatalf("%+v: err = %v", cfg, err)
+		}
+	}
+	if _, err := NewDispatcher([]EndpointConfig{{Name: "a", URL: "http://a"}, {Name: "a", URL: "http://b"}}); !errors.Is(err, errBadEndpoint) {
+		t.Fatalf("duplicate names accepted: %v", err)
+	}
+
+	d, err := NewDispatcher(nil)
+	if err != nil {
+		t.Fatalf("new dispatcher: %v", err)
+	}
+	closeDispatcher(t, d)
+	d.Publish(Event{Type: EventJobFinished})
+
+	rc, srv := newReceiver(t)
+	if err := d.Update([]EndpointConfig{{Name: "late", URL: srv.URL}}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	d.Publish(Event{Type: EventConfigReloaded})
+	if calls := rc.wait(t, 1); calls[0].header.Get(HeaderEvent) != EventConfigReloaded {
+		t.Fatalf("event after update = %s", calls[0].header.Get(HeaderEvent))
+	}
+	if names := d.Stats(); len(names) != 1 || names[0].Name != "late" {
+		t.Fatalf("stats after update = %+v", names)
+	}
+}
@@ -0,0 +1,360 @@
+// Package client - Go-клиент REST API goadmin (/v1) для удаленного
+// управления агентом.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxBody ограничивает размер читаемого ответа.
+const maxBody = 8 << 20
+
+// Config определяет параметры клиента.
+type Config struct {
+	// BaseURL - адрес агента, например https://agent1:8080.
+	BaseURL string
+	// Token - bearer-токен; пусто - без заголовка Authorization.
+	Token string
+	// CAFile - PEM-бандл для проверки сертификата агента; пусто - системные CA.
+	CAFile string
+	// Timeout ограничивает один запрос (по умолчанию 10s).
+	Timeout time.Duration
+	// HTTPClient заменяет клиент по умолчанию (CAFile и Timeout тогда не применяются).
+	HTTPClient *http.Client
+}
+
+// Client вызывает /v1 API одного агента.
+type Client struct {
+	base  *url.URL
+	token string
+	http  *http.Client
+}
+
+// New создает клиент.
+func New(cfg Config) (*Client, error) {
+	if cfg.BaseURL == "" {
+		return nil, errors.New("client: base url is required")
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client: base url: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" || base.Host == "" {
+		return nil, fmt.Errorf("client: base url %q: want http(s)://host[:port]", cfg.BaseURL)
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = 10 * time.Second
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.CAFile != "" {
+			raw, err := os.ReadFile(cfg.CAFile) // #nosec G304 -- путь задает оператор.
+			if err != nil {
+				return nil, fmt.Errorf("client: read CA: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(raw) {
+				return nil, fmt.Errorf("client: CA %s: no certificates", cfg.CAFile)
+			}
+			transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		}
+		httpClient = &http.Client{Timeout: cfg.Timeout, Transport: transport}
+	}
+	return &Client{base: base, token: cfg.Token, http: httpClient}, nil
+}
+
+type contextKey string
+
+const ctxRequestID contextKey = "request_id"
+
+// WithRequestID задает X-Request-ID запросов с ctx; без него клиент
+// генерирует идентификатор на каждый запрос.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxRequestID, id)
+}
+
+// APIError - ответ агента с HTTP-статусом ошибки.
+type APIError struct {
+	StatusCode int
+	RequestID  string
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("status %d: %s", e.StatusCode, e.Code)
+	if e.Message != "" {
+		msg += " (" + e.Message + ")"
+	}
+	if e.RequestID != "" {
+		msg += ", request_id " + e.RequestID
+	}
+	return msg
+}
+
+// ExecuteResult - ответ POST /v1/commands/execute.
+type ExecuteResult struct {
+	RequestID string      `json:"request_id"`
+	Status    string      `json:"status"`
+	Data      interface{} `json:"data,omitempty"`
+	ErrorCode string      `json:"error_code,omitempty"`
+}
+
+// Me - профиль субъекта из GET /v1/me.
+type Me struct {
+	RequestID  string   `json:"request_id"`
+	Subject    string   `json:"subject"`
+	Roles      []string `json:"roles"`
+	AuthMethod string   `json:"auth_method"`
+}
+
+// Metric - последняя метрика модуля из GET /v1/metrics/latest.
+type Metric struct {
+	RequestID string          `json:"request_id"`
+	Module    string          `json:"module"`
+	TS        time.Time       `json:"ts"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// AuditQuery - фильтры GET /v1/audit; нулевые поля не передаются.
+type AuditQuery struct {
+	From      time.Time
+	To        time.Time
+	Subject   string
+	Action    string
+	Source    string
+	Status    string
+	RequestID string
+	Limit     int
+	Cursor    string
+}
+
+// AuditEvent - событие аудита.
+type AuditEvent struct {
+	ID        int64           `json:"id,omitempty"`
+	Subject   string          `json:"subject"`
+	Action    string          `json:"action"`
+	Source    string          `json:"source"`
+	Status    string          `json:"status"`
+	RequestID string          `json:"request_id"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	TS        string          `json:"ts"`
+	PrevHash  string          `json:"prev_hash,omitempty"`
+	Hash      string          `json:"hash,omitempty"`
+}
+
+// AuditPage - страница аудита; NextCursor пуст на последней странице.
+type AuditPage struct {
+	RequestID  string       `json:"request_id"`
+	Items      []AuditEvent `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// TransportStatus - состояние транспорта из GET /v1/transports.
+type TransportStatus struct {
+	Name        string     `json:"name"`
+	State       string     `json:"state"`
+	Since       *time.Time `json:"since,omitempty"`
+	Restarts    int64      `json:"restarts"`
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// Execute выполняет команду модуля. Ошибка команды (HTTP 400 с полем
+// status) возвращается в ExecuteResult.ErrorCode без error.
+func (c *Client) Execute(ctx context.Context, module, command string, args []string) (ExecuteResult, error) {
+	body, err := json.Marshal(map[string]interface{}{"module": module, "command": command, "args": args})
+	if err != nil {
+		return ExecuteResult{}, fmt.Errorf("execute: %w", err)
+	}
+	resp, err := c.do(ctx, http.MethodPost, "/v1/commands/execute", nil, body)
+	if err != nil {
+		return ExecuteResult{}, fmt.Errorf("execute: %w", err)
+	}
+	if resp.status == http.StatusBadRequest {
+		var probe struct {
+			Status *string `json:"status"`
+		}
+		if json.Unmarshal(resp.body, &probe) == nil && probe.Status != nil {
+			resp.status = http.StatusOK
+		}
+	}
+	var out ExecuteResult
+	if err := resp.decode(&out); err != nil {
+		return ExecuteResult{}, fmt.Errorf("execute: %w", err)
+	}
+	return out, nil
+}
+
+// Modules возвращает имена модулей агента.
+func (c *Client) Modules(ctx context.Context) ([]string, error) {
+	var out struct {
+		Items []string `json:"items"`
+	}
+	if err := c.get(ctx, "/v1/modules", nil, &out); err != nil {
+		return nil, fmt.Errorf("modules: %w", err)
+	}
+	return out.Items, nil
+}
+
+// Me возвращает субъекта, от имени которого работает токен.
+func (c *Client) Me(ctx context.Context) (Me, error) {
+	var out Me
+	if err := c.get(ctx, "/v1/me", nil, &out); err != nil {
+		return Me{}, fmt.Errorf("me: %w", err)
+	}
+	return out, nil
+}
+
+// LatestMetric возвращает последнюю метрику модуля.
+func (c *Client) LatestMetric(ctx context.Context, module string) (Metric, error) {
+	var out Metric
+	if err := c.get(ctx, "/v1/metrics/latest", url.Values{"module": {module}}, &out); err != nil {
+		return Metric{}, fmt.Errorf("metrics: %w", err)
+	}
+	return out, nil
+}
+
+// Audit возвращает страницу событий аудита.
+func (c *Client) Audit(ctx context.Context, q AuditQuery) (AuditPage, error) {
+	params := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	if !q.From.IsZero() {
+		set("from", q.From.UTC().Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		set("to", q.To.UTC().Format(time.RFC3339))
+	}
+	set("subject", q.Subject)
+	set("action", q.Action)
+	set("source", q.Source)
+	set("status", q.Status)
+	set("request_id", q.RequestID)
+	set("cursor", q.Cursor)
+	if q.Limit > 0 {
+		set("limit", strconv.Itoa(q.Limit))
+	}
+	var out AuditPage
+	if err := c.get(ctx, "/v1/audit", params, &out); err != nil {
+		return AuditPage{}, fmt.Errorf("audit: %w", err)
+	}
+	return out, nil
+}
+
+// Transports возвращает состояние транспортов агента.
+func (c *Client) Transports(ctx context.Context) ([]TransportStatus, error) {
+	var out struct {
+		Items []TransportStatus `json:"items"`
+	}
+	if err := c.get(ctx, "/v1/transports", nil, &out); err != nil {
+		return nil, fmt.Errorf("transports: %w", err)
+	}
+	return out.Items, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	resp, err := c.do(ctx, http.MethodGet, path, params, nil)
+	if err != nil {
+		return err
+	}
+	return resp.decode(out)
+}
+
+// response - прочитанный ответ агента.
+type response struct {
+	status    int
+	requestID string
+	body      []byte
+}
+
+// decode разбирает тело ответа 200 в out; иначе возвращает *APIError.
+func (r response) decode(out interface{}) error {
+	if r.status != http.StatusOK {
+		apiErr := &APIError{StatusCode: r.status, RequestID: r.requestID}
+		var e struct {
+			RequestID string `json:"request_id"`
+			Code      string `json:"error_code"`
+			Message   string `json:"message"`
+		}
+		if json.Unmarshal(r.body, &e) == nil {
+			apiErr.Code, apiErr.Message = e.Code, e.Message
+			if e.RequestID != "" {
+				apiErr.RequestID = e.RequestID
+			}
+		}
+		if apiErr.Code == "" {
+			apiErr.Code = http.StatusText(r.status)
+		}
+		return apiErr
+	}
+	if err := json.Unmarshal(r.body, out); err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+	return nil
+}
+
+// do отправляет запрос с X-Request-ID и bearer-токеном и читает ответ;
+// ошибка - только сетевая.
+func (c *Client) do(ctx context.Context, method, path string, params url.Values, body []byte) (response, error) {
+	u := *c.base
+	u.Path += path
+	u.RawQuery = params.Encode()
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return response{}, err
+	}
+	requestID, _ := ctx.Value(ctxRequestID).(string)
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	req.Header.Set("X-Request-ID", requestID)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return response{}, err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	if err != nil {
+		return response{}, err
+	}
+	return response{status: resp.StatusCode, requestID: resp.Header.Get("X-Request-ID"), body: raw}, nil
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("req-%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeAgent отвечает как /v1 API и запоминает последний запрос.
+func fakeAgent(t *testing.T, last **http.Request) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*last = r
+		requestID := r.Header.Get("X-Request-ID")
+		w.Header().Set("X-Request-ID", requestID)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(map[string]string{"request_id": requestID, "error_code": "invalid_token", "message": "token is invalid"})
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/commands/execute":
+			var req struct {
+				Module  string   `json:"module"`
+				Command string   `json:"command"`
+				Args    []string `json:"args"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			switch req.Command {
+			case "status":
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"request_id": requestID, "status": "ok", "data": map[string]interface{}{"args": req.Args}})
+			case "bad":
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"request_id": requestID, "status": "error", "error_code": "bad_args"})
+			default:
+				w.WriteHeader(http.StatusForbidden)
+				_ = json.NewEncoder(w).Encode(map[string]string{"request_id": requestID, "error_code": "access_denied", "message": "access denied"})
+			}
+		case "/v1/audit":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"request_id":  requestID,
+				"items":       []map[string]interface{}{{"id": 7, "subject": "alice", "action": "host:status", "source": "web", "status": "ok", "request_id": "r1", "ts": "2026-01-02T03:04:05Z"}},
+				"next_cursor": "c2",
+			})
+		case "/v1/me":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"request_id": requestID, "subject": "alice", "roles": []string{"admin"}, "auth_method": "bearer"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"request_id": requestID, "error_code": "not_found"})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientExecute(t *testing.T) {
+	var last *http.Request
+	srv := fakeAgent(t, &last)
+	c, err := New(Config{BaseURL: srv.URL + "/", Token: "secret"})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ctx := WithRequestID(context.Background(), "ops-42")
+
+	res, err := c.Execute(ctx, "host", "status", []string{"--short"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Status != "ok" || res.RequestID != "ops-42" || last.Header.Get("X-Request-ID") != "ops-42" {
+		t.Fatalf("unexpected result %+v, request id %q", res, last.Header.Get("X-Request-ID"))
+	}
+
+	// Ошибка команды - результат, а не error.
+	res, err = c.Execute(ctx, "host", "bad", nil)
+	if err != nil || res.Status != "error" || res.ErrorCode != "bad_args" {
+		t.Fatalf("command error: %+v, %v", res, err)
+	}
+
+	_, err = c.Execute(ctx, "host", "reboot", nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden || apiErr.Code != "access_denied" || apiErr.RequestID != "ops-42" {
+		t.Fatalf("expected access_denied APIError, got %v", err)
+	}
+}
+
+func TestClientGeneratesRequestIDAndReportsAuthErrors(t *testing.T) {
+	var last *http.Request
+	srv := fakeAgent(t, &last)
+	c, err := New(Config{BaseURL: srv.URL, Token: "wrong"})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	_, err = c.Me(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != "invalid_token" {
+		t.Fatalf("expected invalid_token, got %v", err)
+	}
+	if id := last.Header.Get("X-Request-ID"); id == "" || apiErr.RequestID != id {
+		t.Fatalf("request id not generated or not reported: %q vs %q", id, apiErr.RequestID)
+	}
+}
+
+func TestClientAuditQuery(t *testing.T) {
+	var last *http.Request
+	srv := fakeAgent(t, &last)
+	c, err := New(Config{BaseURL: srv.URL, Token: "secret"})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	page, err := c.Audit(context.Background(), AuditQuery{From: from, Action: "host:status", Limit: 10, Cursor: "c1"})
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	q := last.URL.Query()
+	if q.Get("from") != "2026-01-01T00:00:00Z" || q.Get("action") != "host:status" || q.Get("limit") != "10" || q.Get("cursor") != "c1" || q.Has("subject") {
+		t.Fatalf("unexpected query %s", last.URL.RawQuery)
+	}
+	if len(page.Items) != 1 || page.Items[0].Subject != "alice" || page.NextCursor != "c2" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+
+	_, err = c.LatestMetric(context.Background(), "host_status")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404, got %v", err)
+	}
+}
+
+func TestNewRejectsBadURL(t *testing.T) {
+	for _, u := range []string{"", "agent1:8080", "ftp://agent1"} {
+		if _, err := New(Config{BaseURL: u}); err == nil {
+			t.Fatalf("expected error for %q", u)
+		}
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "prod.token"), []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "remote.yaml")
+	raw := "default: prod\n" +
+		"profiles:\n" +
+		"  prod:\n" +
+		"    url: https://agent1:8080\n" +
+		"    token_file: prod.token\n" +
+		"    timeout: 5s\n" +
+		"  lab:\n" +
+		"    url: http://127.0.0.1:8080\n" +
+		"    token_env: GOADMIN_TEST_LAB_TOKEN\n"
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	prod, err := profiles.Profile("")
+	if err != nil {
+		t.Fatalf("default profile: %v", err)
+	}
+	cfg, err := prod.Config()
+	if err != nil {
+		t.Fatalf("config: %v", err)
+	}
+	if cfg.BaseURL != "https://agent1:8080" || cfg.Token != "secret" || cfg.Timeout != 5*time.Second {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+
+	lab, err := profiles.Profile("lab")
+	if err != nil {
+		t.Fatalf("lab profile: %v", err)
+	}
+	if _, err := lab.Config(); err == nil {
+		t.Fatal("expected error for empty token env")
+	}
+	t.Setenv("GOADMIN_TEST_LAB_TOKEN", "labtoken")
+	if cfg, err := lab.Config(); err != nil || cfg.Token != "labtoken" {
+		t.Fatalf("lab config: %+v, %v", cfg, err)
+	}
+
+	if _, err := profiles.Profile("staging"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+
+	if err := os.WriteFile(path, []byte("profiles:\n  prod:\n    url: https://a\n    tokn_file: x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadProfiles(path); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile - именованное подключение к агенту.
+type Profile struct {
+	URL string `yaml:"url"`
+	// TokenFile - файл с bearer-токеном; TokenEnv - переменная окружения
+	// с токеном (используется, если TokenFile пуст).
+	TokenFile string `yaml:"token_file"`
+	TokenEnv  string `yaml:"token_env"`
+	// CAFile - PEM-бандл для проверки сертификата агента.
+	CAFile  string        `yaml:"ca_file"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Profiles - файл профилей: профиль по умолчанию и профили по именам.
+type Profiles struct {
+	Default  string             `yaml:"default"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultProfilesPath - $XDG_CONFIG_HOME/goadmin/remote.yaml
+// (~/.config/goadmin/remote.yaml).
+func DefaultProfilesPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("profiles: %w", err)
+	}
+	return filepath.Join(dir, "goadmin", "remote.yaml"), nil
+}
+
+// LoadProfiles читает файл профилей; относительные token_file и ca_file
+// отсчитываются от каталога файла, "~/" - от домашнего каталога.
+func LoadProfiles(path string) (Profiles, error) {
+	raw, err := os.ReadFile(path) // #nosec G304 -- путь задает пользователь.
+	if err != nil {
+		return Profiles{}, fmt.Errorf("profiles: %w", err)
+	}
+	var out Profiles
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&out); err != nil {
+		return Profiles{}, fmt.Errorf("profiles %s: %w", path, err)
+	}
+	dir := filepath.Dir(path)
+	for name, p := range out.Profiles {
+		if p.URL == "" {
+			return Profiles{}, fmt.Errorf("profiles %s: profile %q: url is required", path, name)
+		}
+		if p.TokenFile, err = resolvePath(dir, p.TokenFile); err != nil {
+			return Profiles{}, fmt.Errorf("profiles %s: profile %q: %w", path, name, err)
+		}
+		if p.CAFile, err = resolvePath(dir, p.CAFile); err != nil {
+			return Profiles{}, fmt.Errorf("profiles %s: profile %q: %w", path, name, err)
+		}
+		out.Profiles[name] = p
+	}
+	if out.Default != "" {
+		if _, ok := out.Profiles[out.Default]; !ok {
+			return Profiles{}, fmt.Errorf("profiles %s: default profile %q is not defined", path, out.Default)
+		}
+	}
+	return out, nil
+}
+
+// Profile возвращает профиль по имени; пустое имя - профиль по умолчанию
+// или единственный профиль файла.
+func (p Profiles) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = p.Default
+	}
+	if name == "" {
+		if len(p.Profiles) != 1 {
+			return Profile{}, errors.New("profile is not selected: set default or pass a profile name")
+		}
+		for n := range p.Profiles {
+			name = n
+		}
+	}
+	prof, ok := p.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(p.Profiles))
+		for n := range p.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return Profile{}, fmt.Errorf("unknown profile %q (known: %s)", name, strings.Join(names, ", "))
+	}
+	return prof, nil
+}
+
+// Config собирает параметры клиента, читая токен из файла или окружения.
+func (p Profile) Config() (Config, error) {
+	cfg := Config{BaseURL: p.URL, CAFile: p.CAFile, Timeout: p.Timeout}
+	switch {
+	case p.TokenFile != "":
+		raw, err := os.ReadFile(p.TokenFile) // #nosec G304 -- путь задает пользователь.
+		if err != nil {
+			return Config{}, fmt.Errorf("read token: %w", err)
+		}
+		cfg.Token = strings.TrimSpace(string(raw))
+		if cfg.Token == "" {
+			return Config{}, fmt.Errorf("token file %s is empty", p.TokenFile)
+		}
+	case p.TokenEnv != "":
+		cfg.Token = os.Getenv(p.TokenEnv)
+		if cfg.Token == "" {
+			return Config{}, fmt.Errorf("token env %s is empty", p.TokenEnv)
+		}
+	}
+	return cfg, nil
+}
+
+func resolvePath(dir, path string) (string, error) {
+	switch {
+	case path == "" || filepath.IsAbs(path):
+		return path, nil
+	case strings.HasPrefix(path, "~/"):
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(home, path[2:]), nil
+	default:
+		return filepath.Join(dir, path), nil
+	}
+}